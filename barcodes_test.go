@@ -0,0 +1,186 @@
+package main
+
+import (
+	"kasir-api/models"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestProductBarcodes(t *testing.T) {
+	s := newTestServer(t)
+
+	category := s.createCategory("Minuman")
+	rec := s.do(http.MethodPost, "/api/product", models.Product{
+		Name:       "Teh Botol 450ml",
+		Price:      6000,
+		Stock:      20,
+		CategoryID: category.ID,
+		SKU:        " TB-450 ",
+		Barcodes:   []models.Barcode{{Code: "8992761111106"}, {Code: "036000291452"}, {Code: "RAK-A1", Type: "Internal"}},
+	}, nil)
+	expectStatus(t, rec, http.StatusCreated)
+	teh := decodeJSON[models.Product](t, rec)
+	if teh.SKU != "TB-450" || len(teh.Barcodes) != 3 || teh.Barcodes[0].Type != models.BarcodeEAN13 ||
+		teh.Barcodes[1].Type != models.BarcodeUPC || teh.Barcodes[2].Type != models.BarcodeInternal {
+		t.Errorf("product = %+v", teh)
+	}
+
+	// check digit salah, jenis tidak cocok, karakter aneh = 400; SKU / barcode bentrok = 409
+	for _, c := range []struct {
+		product models.Product
+		want    int
+	}{
+		{models.Product{Name: "A", CategoryID: category.ID, Barcodes: []models.Barcode{{Code: "8992761111107"}}}, http.StatusBadRequest},
+		{models.Product{Name: "B", CategoryID: category.ID, Barcodes: []models.Barcode{{Code: "036000291452", Type: models.BarcodeEAN13}}}, http.StatusBadRequest},
+		{models.Product{Name: "C", CategoryID: category.ID, Barcodes: []models.Barcode{{Code: "RAK A1"}}}, http.StatusBadRequest},
+		{models.Product{Name: "D", CategoryID: category.ID, SKU: "TB 450"}, http.StatusBadRequest},
+		{models.Product{Name: "E", CategoryID: category.ID, SKU: "TB-450"}, http.StatusConflict},
+		{models.Product{Name: "F", CategoryID: category.ID, Barcodes: []models.Barcode{{Code: "8992761111106"}}}, http.StatusConflict},
+	} {
+		expectStatus(t, s.do(http.MethodPost, "/api/product", c.product, nil), c.want)
+	}
+
+	// lookup scanner: barcode apa saja, atau SKU
+	for _, code := range []string{"8992761111106", "036000291452", "RAK-A1", "TB-450"} {
+		rec = s.doAuth(http.MethodGet, "/api/product/barcode/"+code, nil)
+		expectStatus(t, rec, http.StatusOK)
+		if p := decodeJSON[models.ProductResponse](t, rec); p.ID != teh.ID || len(p.Barcodes) != 3 {
+			t.Errorf("lookup %s = %+v", code, p)
+		}
+	}
+	expectStatus(t, s.doAuth(http.MethodGet, "/api/product/barcode/8992761111113", nil), http.StatusNotFound)
+	expectStatus(t, s.doAuth(http.MethodPost, "/api/product/barcode/TB-450", nil), http.StatusMethodNotAllowed)
+
+	// checkout lewat barcode / SKU
+	trx := s.checkout(
+		models.CheckoutItem{Barcode: "8992761111106", Quantity: 2},
+		models.CheckoutItem{SKU: "TB-450", Quantity: 1},
+		models.CheckoutItem{ProductID: teh.ID, Barcode: "RAK-A1", Quantity: 1},
+	)
+	if trx.TotalAmount != 4*6000 || trx.Details[0].ProductID != teh.ID || trx.Details[1].ProductID != teh.ID {
+		t.Errorf("transaction = %+v", trx)
+	}
+	if stock := s.productStock(teh.ID); stock != 16 {
+		t.Errorf("stock = %d, want 16", stock)
+	}
+	for _, item := range []models.CheckoutItem{
+		{Barcode: "8992761111113", Quantity: 1},
+		{Barcode: "8992761111106", SKU: "TB-450", Quantity: 1},
+		{ProductID: teh.ID + 1, SKU: "TB-450", Quantity: 1},
+	} {
+		rec = s.doAuth(http.MethodPost, "/api/checkout", models.CheckoutRequest{Items: []models.CheckoutItem{item}})
+		expectStatus(t, rec, http.StatusBadRequest)
+	}
+
+	// update tanpa barcodes tidak menyentuh barcode
+	path := "/api/product/" + strconv.Itoa(teh.ID)
+	rec = s.doAuth(http.MethodPut, path, map[string]any{"name": "Teh Botol 450ml", "price": 6000, "stock": 16, "sku": "TB-450"})
+	expectStatus(t, rec, http.StatusOK)
+	if p := decodeJSON[models.Product](t, rec); len(p.Barcodes) != 3 {
+		t.Errorf("barcodes after update without barcodes = %+v", p.Barcodes)
+	}
+
+	// update mengganti barcode pabrik, kode internal tetap walaupun tidak dikirim.
+	// Barcode yang dilepas bebas dipakai produk lain.
+	rec = s.doAuth(http.MethodPut, path, models.Product{
+		Name: "Teh Botol 450ml", Price: 6000, Stock: 16, SKU: "TB-450", Barcodes: []models.Barcode{{Code: "8992761111106"}},
+	})
+	expectStatus(t, rec, http.StatusOK)
+	if p := s.productLocations(teh.ID); len(p.Barcodes) != 2 || p.Barcodes[0].Code != "8992761111106" || p.Barcodes[1].Code != "RAK-A1" {
+		t.Errorf("barcodes after update = %+v", p.Barcodes)
+	}
+	expectStatus(t, s.doAuth(http.MethodGet, "/api/product/barcode/036000291452", nil), http.StatusNotFound)
+	rec = s.do(http.MethodPost, "/api/product", models.Product{Name: "Susu", CategoryID: category.ID, Barcodes: []models.Barcode{{Code: "036000291452"}}}, nil)
+	expectStatus(t, rec, http.StatusCreated)
+
+	// kode internal hanya dihapus eksplisit
+	rec = s.doAuth(http.MethodDelete, path+"/barcode/RAK-A1", nil)
+	expectStatus(t, rec, http.StatusOK)
+	if p := decodeJSON[models.ProductResponse](t, rec); len(p.Barcodes) != 1 {
+		t.Errorf("barcodes after delete = %+v", p.Barcodes)
+	}
+	expectStatus(t, s.doAuth(http.MethodDelete, path+"/barcode/RAK-A1", nil), http.StatusNotFound)
+	expectStatus(t, s.doAuth(http.MethodDelete, "/api/product/9999/barcode/RAK-A1", nil), http.StatusNotFound)
+	rec = s.do(http.MethodPost, "/api/product", models.Product{Name: "Rak", CategoryID: category.ID, Barcodes: []models.Barcode{{Code: "RAK-A1"}}}, nil)
+	expectStatus(t, rec, http.StatusCreated)
+}
+
+func TestBarcodeAssignAndLabels(t *testing.T) {
+	s := newTestServer(t)
+
+	category := s.createCategory("Roti")
+	tawar := s.createProduct("Roti Tawar <Kupas>", 16500, 10, category.ID)
+	sobek := s.createProduct("Roti Sobek", 12000, 10, category.ID)
+	donat := s.createProduct("Donat", 5000, 10, category.ID)
+
+	// EAN-13 internal berurutan dari prefix bawaan 200
+	for _, c := range []struct {
+		id   int
+		code string
+	}{{tawar.ID, "2000000000015"}, {sobek.ID, "2000000000022"}} {
+		rec := s.doAuth(http.MethodPost, "/api/product/"+strconv.Itoa(c.id)+"/barcode", nil)
+		expectStatus(t, rec, http.StatusCreated)
+		if p := decodeJSON[models.ProductResponse](t, rec); len(p.Barcodes) != 1 || p.Barcodes[0] != (models.Barcode{Code: c.code, Type: models.BarcodeEAN13, Assigned: true}) {
+			t.Errorf("assigned = %+v", p.Barcodes)
+		}
+	}
+	expectStatus(t, s.doAuth(http.MethodPost, "/api/product/"+strconv.Itoa(tawar.ID)+"/barcode", nil), http.StatusConflict)
+	expectStatus(t, s.doAuth(http.MethodPost, "/api/product/9999/barcode", nil), http.StatusNotFound)
+	s.checkout(models.CheckoutItem{Barcode: "2000000000022", Quantity: 1})
+
+	// EAN-13 buatan sistem tidak hilang walaupun tidak ikut dikirim di barcodes,
+	// dan assigned di body diabaikan
+	rec := s.doAuth(http.MethodPut, "/api/product/"+strconv.Itoa(sobek.ID), map[string]any{
+		"name": sobek.Name, "price": sobek.Price, "stock": 9, "barcodes": []models.Barcode{{Code: "RAK-B2", Assigned: true}},
+	})
+	expectStatus(t, rec, http.StatusOK)
+	want := []models.Barcode{{Code: "2000000000022", Type: models.BarcodeEAN13, Assigned: true}, {Code: "RAK-B2", Type: models.BarcodeInternal}}
+	if p := s.productLocations(sobek.ID); !slices.Equal(p.Barcodes, want) {
+		t.Errorf("barcodes after update = %+v, want %+v", p.Barcodes, want)
+	}
+
+	// render
+	rec = s.doAuth(http.MethodGet, "/api/barcodes?code=2000000000015&format=png", nil)
+	expectStatus(t, rec, http.StatusOK)
+	if ct := rec.Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("content type = %s", ct)
+	}
+	rec = s.doAuth(http.MethodGet, "/api/barcodes?code=RAK-A1", nil)
+	expectStatus(t, rec, http.StatusOK)
+	if ct := rec.Header().Get("Content-Type"); ct != "image/svg+xml" || !strings.HasPrefix(rec.Body.String(), "<svg") {
+		t.Errorf("svg = %s %s", ct, rec.Body.String())
+	}
+	for _, query := range []string{"", "?code=RAK-A1&symbology=ean13", "?code=RAK-A1&format=gif", "?code=RAK-A1&symbology=qr"} {
+		expectStatus(t, s.doAuth(http.MethodGet, "/api/barcodes"+query, nil), http.StatusBadRequest)
+	}
+
+	// lembar label: nama, harga, barcode; copies per produk
+	rec = s.doAuth(http.MethodGet, "/api/labels?product_ids="+strconv.Itoa(tawar.ID)+","+strconv.Itoa(donat.ID)+"&copies=2", nil)
+	expectStatus(t, rec, http.StatusOK)
+	page := rec.Body.String()
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("content type = %s", ct)
+	}
+	if strings.Count(page, `<div class="label">`) != 4 || strings.Count(page, "Roti Tawar &lt;Kupas&gt;") != 2 ||
+		!strings.Contains(page, "Rp 16.500") || strings.Count(page, ">2000000000015</text>") != 2 ||
+		strings.Count(page, "tanpa barcode") != 2 {
+		t.Errorf("label sheet = %s", page)
+	}
+	for _, query := range []string{"", "?product_ids=abc", "?product_ids=1&copies=-1", "?product_ids=1&copies=501"} {
+		expectStatus(t, s.doAuth(http.MethodGet, "/api/labels"+query, nil), http.StatusBadRequest)
+	}
+	expectStatus(t, s.doAuth(http.MethodGet, "/api/labels?product_ids=9999", nil), http.StatusNotFound)
+
+	// prefix dari konfigurasi
+	s = newTestServerWithConfig(t, Config{APIKey: testAPIKey, BarcodePrefix: "2991"})
+	category = s.createCategory("Roti")
+	roti := s.createProduct("Roti Coklat", 8000, 0, category.ID)
+	rec = s.doAuth(http.MethodPost, "/api/product/"+strconv.Itoa(roti.ID)+"/barcode", nil)
+	expectStatus(t, rec, http.StatusCreated)
+	if p := decodeJSON[models.ProductResponse](t, rec); p.Barcodes[0].Code != "2991000000016" {
+		t.Errorf("prefixed barcode = %+v", p.Barcodes)
+	}
+}
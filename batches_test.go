@@ -0,0 +1,158 @@
+package main
+
+import (
+	"kasir-api/models"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func (s *testServer) adjustLot(productID, quantity int, reason, lot, expiry string) *httptest.ResponseRecorder {
+	s.t.Helper()
+
+	return s.doAuth(http.MethodPost, "/api/inventory/adjustments", models.StockAdjustmentRequest{
+		Reason: reason,
+		Items:  []models.StockAdjustmentItem{{ProductID: productID, Quantity: quantity, LotNumber: lot, ExpiryDate: expiry}},
+	})
+}
+
+func (s *testServer) batches(productID int) map[string]int {
+	s.t.Helper()
+
+	rec := s.doAuth(http.MethodGet, "/api/inventory/batches?product_id="+strconv.Itoa(productID), nil)
+	expectStatus(s.t, rec, http.StatusOK)
+	lots := map[string]int{}
+	for _, b := range decodeJSON[[]models.Batch](s.t, rec) {
+		lots[b.LotNumber] += b.Quantity
+	}
+	return lots
+}
+
+func dateFromToday(days int) string {
+	return time.Now().AddDate(0, 0, days).Format(models.ExpiryDateLayout)
+}
+
+func TestBatchFEFOAndExpiry(t *testing.T) {
+	s := newVoidTestServer(t, time.Hour)
+
+	category := s.createCategory("Susu")
+	susu := s.createProduct("Susu UHT 1L", 18000, 0, category.ID)
+
+	for _, lot := range []struct {
+		number string
+		qty    int
+		expiry string
+	}{
+		{"LAMA", 5, dateFromToday(-1)},
+		{"B2", 4, dateFromToday(10)},
+		{"B1", 6, dateFromToday(3)},
+	} {
+		expectStatus(t, s.adjustLot(susu.ID, lot.qty, models.AdjustmentFound, lot.number, lot.expiry), http.StatusCreated)
+	}
+	expectStatus(t, s.adjustLot(susu.ID, 1, models.AdjustmentFound, "", dateFromToday(5)), http.StatusBadRequest)
+	expectStatus(t, s.adjustLot(susu.ID, 1, models.AdjustmentFound, "B3", "31-12-2030"), http.StatusBadRequest)
+
+	// FEFO: B1 (3 hari) habis dulu, lalu B2; LAMA sudah kedaluwarsa dilewati
+	trx := s.checkout(models.CheckoutItem{ProductID: susu.ID, Quantity: 8})
+	movements := s.stockMovements(susu.ID)
+	sale := movements[len(movements)-1]
+	if sale.Reason != models.StockReasonSale || len(sale.Batches) != 2 ||
+		sale.Batches[0].LotNumber != "B1" || sale.Batches[0].Quantity != 6 ||
+		sale.Batches[1].LotNumber != "B2" || sale.Batches[1].Quantity != 2 {
+		t.Fatalf("sale movement = %+v", sale)
+	}
+	if lots := s.batches(susu.ID); lots["LAMA"] != 5 || lots["B2"] != 2 || lots["B1"] != 0 {
+		t.Errorf("batches after sale = %v", lots)
+	}
+
+	// sisa stok 7, tapi yang belum kedaluwarsa tinggal 2
+	rec := s.doAuth(http.MethodPost, "/api/checkout", models.CheckoutRequest{
+		Items: []models.CheckoutItem{{ProductID: susu.ID, Quantity: 3}},
+	})
+	expectStatus(t, rec, http.StatusConflict)
+
+	rec = s.doAuth(http.MethodGet, "/api/inventory/expiring?days=7", nil)
+	expectStatus(t, rec, http.StatusOK)
+	if report := decodeJSON[models.ExpiringReport](t, rec); report.ExpiredQuantity != 5 || report.ExpiringQuantity != 0 ||
+		len(report.Batches) != 1 || !report.Batches[0].Expired || report.Batches[0].DaysLeft != -1 {
+		t.Errorf("expiring 7 days = %+v", report)
+	}
+	rec = s.doAuth(http.MethodGet, "/api/inventory/expiring?days=30", nil)
+	expectStatus(t, rec, http.StatusOK)
+	if report := decodeJSON[models.ExpiringReport](t, rec); report.ExpiredQuantity != 5 || report.ExpiringQuantity != 2 || len(report.Batches) != 2 {
+		t.Errorf("expiring 30 days = %+v", report)
+	}
+	for _, query := range []string{"?days=-1", "?days=400", "?days=abc"} {
+		expectStatus(t, s.doAuth(http.MethodGet, "/api/inventory/expiring"+query, nil), http.StatusBadRequest)
+	}
+
+	// void mengembalikan stok ke lot asalnya
+	rec = s.doAuth(http.MethodPost, "/api/transactions/"+strconv.Itoa(trx.ID)+"/void", models.VoidRequest{Reason: "salah input"})
+	expectStatus(t, rec, http.StatusOK)
+	if lots := s.batches(susu.ID); lots["B1"] != 6 || lots["B2"] != 4 || lots["LAMA"] != 5 {
+		t.Errorf("batches after void = %v", lots)
+	}
+
+	// yang kedaluwarsa dibuang lewat adjustment per lot
+	expectStatus(t, s.adjustLot(susu.ID, -5, models.AdjustmentExpired, "LAMA", ""), http.StatusCreated)
+	expectStatus(t, s.adjustLot(susu.ID, -1, models.AdjustmentExpired, "LAMA", ""), http.StatusConflict)
+	if stock := s.productStock(susu.ID); stock != 10 {
+		t.Errorf("stock = %d, want 10", stock)
+	}
+}
+
+func TestBatchReceiptAndTransfer(t *testing.T) {
+	s := newTestServer(t)
+
+	gudang := s.createLocation("Gudang Pusat", models.LocationWarehouse)
+	outlet := s.createLocation("Outlet Kemang", models.LocationOutlet)
+	category := s.createCategory("Obat")
+	obat := s.createProduct("Paracetamol 500mg", 12000, 0, category.ID)
+	supplier := s.createSupplier("PT Farma")
+	expiry := dateFromToday(180)
+
+	rec := s.doAuth(http.MethodPost, "/api/purchase-orders", models.PurchaseOrderRequest{
+		SupplierID: supplier.ID,
+		LocationID: gudang.ID,
+		Lines:      []models.PurchaseOrderLineRequest{{ProductID: obat.ID, Quantity: 10, UnitCost: 8000}},
+	})
+	expectStatus(t, rec, http.StatusCreated)
+	po := decodeJSON[models.PurchaseOrder](t, rec)
+	path := "/api/purchase-orders/" + strconv.Itoa(po.ID)
+	expectStatus(t, s.doAuth(http.MethodPost, path+"/send", nil), http.StatusOK)
+
+	rec = s.doAuth(http.MethodPost, path+"/receipts", models.GoodsReceiptRequest{
+		Items: []models.GoodsReceiptRequestItem{{PurchaseOrderLineID: po.Lines[0].ID, Quantity: 10, LotNumber: "PCT-01", ExpiryDate: "2030-13-01"}},
+	})
+	expectStatus(t, rec, http.StatusBadRequest)
+	rec = s.doAuth(http.MethodPost, path+"/receipts", models.GoodsReceiptRequest{
+		Items: []models.GoodsReceiptRequestItem{{PurchaseOrderLineID: po.Lines[0].ID, Quantity: 10, LotNumber: " PCT-01 ", ExpiryDate: expiry}},
+	})
+	expectStatus(t, rec, http.StatusCreated)
+	if receipt := decodeJSON[models.GoodsReceipt](t, rec); receipt.Items[0].LotNumber != "PCT-01" || receipt.Items[0].ExpiryDate != expiry {
+		t.Errorf("receipt = %+v", receipt)
+	}
+
+	rec = s.doAuth(http.MethodPost, "/api/inventory/transfers", models.StockTransferRequest{
+		FromLocationID: gudang.ID,
+		ToLocationID:   outlet.ID,
+		Items:          []models.StockTransferItem{{ProductID: obat.ID, Quantity: 4}},
+	})
+	expectStatus(t, rec, http.StatusCreated)
+	path = "/api/inventory/transfers/" + strconv.Itoa(decodeJSON[models.StockTransfer](t, rec).ID)
+	expectStatus(t, s.doAuth(http.MethodPost, path+"/ship", nil), http.StatusOK)
+	expectStatus(t, s.doAuth(http.MethodPost, path+"/receive", nil), http.StatusOK)
+
+	rec = s.doAuth(http.MethodGet, "/api/inventory/batches?product_id="+strconv.Itoa(obat.ID)+"&location_id="+strconv.Itoa(outlet.ID), nil)
+	expectStatus(t, rec, http.StatusOK)
+	batches := decodeJSON[[]models.Batch](t, rec)
+	if len(batches) != 1 || batches[0].LotNumber != "PCT-01" || batches[0].ExpiryDate != expiry ||
+		batches[0].Quantity != 4 || batches[0].LocationName != "Outlet Kemang" {
+		t.Errorf("outlet batches = %+v", batches)
+	}
+	if lots := s.batches(obat.ID); lots["PCT-01"] != 10 {
+		t.Errorf("all batches = %v", lots)
+	}
+}
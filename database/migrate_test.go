@@ -0,0 +1,78 @@
+package database
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadMigrationsOrdersByVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0010_add_index.up.sql":        {Data: []byte("CREATE INDEX")},
+		"migrations/0002_create_users.up.sql":     {Data: []byte("CREATE TABLE users")},
+		"migrations/0002_create_users.down.sql":   {Data: []byte("DROP TABLE users")},
+		"migrations/0001_initial_schema.up.sql":   {Data: []byte("CREATE TABLE products")},
+		"migrations/0001_initial_schema.down.sql": {Data: []byte("DROP TABLE products")},
+		"migrations/README.md":                    {Data: []byte("bukan migrasi")},
+	}
+
+	migrations, err := loadMigrations(fsys, "migrations")
+	if err != nil {
+		t.Fatalf("loadMigrations: %v", err)
+	}
+	if len(migrations) != 3 {
+		t.Fatalf("got %d migrations, want 3", len(migrations))
+	}
+
+	// urut numerik, bukan urut nama file
+	for i, want := range []int64{1, 2, 10} {
+		if migrations[i].Version != want {
+			t.Errorf("migrations[%d].Version = %d, want %d", i, migrations[i].Version, want)
+		}
+	}
+	if m := migrations[1]; m.Name != "create_users" || m.Up != "CREATE TABLE users" || m.Down != "DROP TABLE users" {
+		t.Errorf("migration 2 = %+v", m)
+	}
+	if migrations[2].Down != "" {
+		t.Errorf("migration 10 should have no down, got %q", migrations[2].Down)
+	}
+}
+
+func TestLoadMigrationsRejectsInvalidFiles(t *testing.T) {
+	cases := map[string]fstest.MapFS{
+		"unknown suffix":  {"migrations/0001_initial.sql": {Data: []byte("x")}},
+		"missing name":    {"migrations/0001.up.sql": {Data: []byte("x")}},
+		"invalid version": {"migrations/abc_initial.up.sql": {Data: []byte("x")}},
+		"missing up":      {"migrations/0001_initial.down.sql": {Data: []byte("x")}},
+		"two names": {
+			"migrations/0001_initial.up.sql": {Data: []byte("x")},
+			"migrations/0001_other.down.sql": {Data: []byte("x")},
+		},
+	}
+
+	for name, fsys := range cases {
+		if _, err := loadMigrations(fsys, "migrations"); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+// TestEmbeddedMigrations - migrasi yang ikut di-binary versinya berurutan tanpa
+// lompatan dan semuanya bisa di-rollback
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatalf("LoadMigrations: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no embedded migrations")
+	}
+
+	for i, m := range migrations {
+		if m.Version != int64(i+1) {
+			t.Errorf("migration %d_%s: want version %d", m.Version, m.Name, i+1)
+		}
+		if m.Down == "" {
+			t.Errorf("migration %d_%s has no .down.sql", m.Version, m.Name)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    scope         VARCHAR(50) NOT NULL,
+    key           VARCHAR(255) NOT NULL,
+    fingerprint   CHAR(64) NOT NULL,
+    status        VARCHAR(20) NOT NULL DEFAULT 'processing',
+    response_code INTEGER,
+    response_body JSONB,
+    created_at    TIMESTAMP NOT NULL DEFAULT NOW(),
+    completed_at  TIMESTAMP,
+    PRIMARY KEY (scope, key)
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_transactions_total_amount;
+DROP INDEX IF EXISTS idx_transaction_details_product_id;
+ALTER TABLE transaction_details DROP COLUMN IF EXISTS product_name;
@@ -0,0 +1,11 @@
+-- Nama produk disimpan saat transaksi, supaya detail transaksi lama tetap
+-- benar walaupun produk di-rename.
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS product_name VARCHAR(255) NOT NULL DEFAULT '';
+
+UPDATE transaction_details td
+SET product_name = p.name
+FROM products p
+WHERE p.id = td.product_id AND td.product_name = '';
+
+CREATE INDEX IF NOT EXISTS idx_transaction_details_product_id ON transaction_details (product_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_total_amount ON transactions (total_amount);
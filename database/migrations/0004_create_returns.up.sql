@@ -0,0 +1,22 @@
+-- Dokumen retur. total_amount dan amount bernilai negatif (uang keluar).
+CREATE TABLE IF NOT EXISTS returns (
+    id             SERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL REFERENCES transactions(id),
+    total_amount   INTEGER NOT NULL,
+    reason         TEXT NOT NULL DEFAULT '',
+    created_at     TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS return_items (
+    id                    SERIAL PRIMARY KEY,
+    return_id             INTEGER NOT NULL REFERENCES returns(id) ON DELETE CASCADE,
+    transaction_detail_id INTEGER NOT NULL REFERENCES transaction_details(id),
+    product_id            INTEGER NOT NULL REFERENCES products(id),
+    product_name          VARCHAR(255) NOT NULL DEFAULT '',
+    quantity              INTEGER NOT NULL CHECK (quantity > 0),
+    amount                INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_returns_transaction_id ON returns (transaction_id);
+CREATE INDEX IF NOT EXISTS idx_returns_created_at ON returns (created_at);
+CREATE INDEX IF NOT EXISTS idx_return_items_return_id ON return_items (return_id);
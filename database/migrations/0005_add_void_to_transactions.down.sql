@@ -0,0 +1,3 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS voided_by;
+ALTER TABLE transactions DROP COLUMN IF EXISTS void_reason;
+ALTER TABLE transactions DROP COLUMN IF EXISTS voided_at;
@@ -0,0 +1,3 @@
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS voided_at TIMESTAMP;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS void_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS voided_by VARCHAR(20) NOT NULL DEFAULT '';
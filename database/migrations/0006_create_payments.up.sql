@@ -0,0 +1,20 @@
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS paid_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS change_amount INTEGER NOT NULL DEFAULT 0;
+
+-- Transaksi lama dianggap dibayar tunai pas
+UPDATE transactions SET paid_amount = total_amount WHERE paid_amount = 0;
+
+CREATE TABLE IF NOT EXISTS payments (
+    id             SERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    method         VARCHAR(20) NOT NULL CHECK (method IN ('cash', 'card', 'qris', 'transfer')),
+    amount         INTEGER NOT NULL CHECK (amount > 0),
+    reference      VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+INSERT INTO payments (transaction_id, method, amount)
+SELECT id, 'cash', total_amount
+FROM transactions t
+WHERE total_amount > 0 AND NOT EXISTS (SELECT 1 FROM payments p WHERE p.transaction_id = t.id);
+
+CREATE INDEX IF NOT EXISTS idx_payments_transaction_id ON payments (transaction_id);
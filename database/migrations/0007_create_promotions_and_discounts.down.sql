@@ -0,0 +1,12 @@
+ALTER TABLE transaction_details DROP COLUMN IF EXISTS order_discount;
+ALTER TABLE transaction_details DROP COLUMN IF EXISTS line_discount;
+ALTER TABLE transaction_details DROP COLUMN IF EXISTS unit_price;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS promo_discount;
+ALTER TABLE transactions DROP COLUMN IF EXISTS promotion_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS promo_code;
+ALTER TABLE transactions DROP COLUMN IF EXISTS order_discount;
+ALTER TABLE transactions DROP COLUMN IF EXISTS line_discount;
+ALTER TABLE transactions DROP COLUMN IF EXISTS gross_amount;
+
+DROP TABLE IF EXISTS promotions;
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS promotions (
+    id          SERIAL PRIMARY KEY,
+    code        VARCHAR(50) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    type        VARCHAR(10) NOT NULL CHECK (type IN ('percent', 'fixed')),
+    value       INTEGER NOT NULL CHECK (value >= 0),
+    min_spend   INTEGER NOT NULL DEFAULT 0,
+    usage_limit INTEGER NOT NULL DEFAULT 0,
+    used_count  INTEGER NOT NULL DEFAULT 0,
+    starts_at   TIMESTAMP NOT NULL,
+    ends_at     TIMESTAMP NOT NULL,
+    active      BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS gross_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS line_discount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS order_discount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS promo_code VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS promotion_id INTEGER REFERENCES promotions(id) ON DELETE SET NULL;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS promo_discount INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS unit_price INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS line_discount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS order_discount INTEGER NOT NULL DEFAULT 0;
+
+-- Data lama belum punya diskon
+UPDATE transactions SET gross_amount = total_amount WHERE gross_amount = 0;
+UPDATE transaction_details SET unit_price = subtotal / quantity WHERE unit_price = 0 AND quantity > 0;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS transaction_promotions;
+
+ALTER TABLE transaction_details DROP COLUMN IF EXISTS rule_discount;
+ALTER TABLE transactions DROP COLUMN IF EXISTS rule_discount;
+
+DROP TABLE IF EXISTS promotion_rules;
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS promotion_rules (
+    id           SERIAL PRIMARY KEY,
+    name         VARCHAR(255) NOT NULL,
+    type         VARCHAR(20) NOT NULL CHECK (type IN ('buy_x_get_y', 'bundle', 'percent')),
+    product_ids  INTEGER[] NOT NULL DEFAULT '{}',
+    category_id  INTEGER REFERENCES categories(id) ON DELETE SET NULL,
+    buy_quantity INTEGER NOT NULL DEFAULT 0,
+    get_quantity INTEGER NOT NULL DEFAULT 0,
+    percent      INTEGER NOT NULL DEFAULT 0,
+    bundle_items JSONB NOT NULL DEFAULT '[]',
+    bundle_price INTEGER NOT NULL DEFAULT 0,
+    start_time   VARCHAR(5) NOT NULL DEFAULT '',
+    end_time     VARCHAR(5) NOT NULL DEFAULT '',
+    starts_at    TIMESTAMP,
+    ends_at      TIMESTAMP,
+    active       BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS rule_discount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS rule_discount INTEGER NOT NULL DEFAULT 0;
+
+-- Rincian promo otomatis per baris, untuk struk dan penjelasan ke pelanggan
+CREATE TABLE IF NOT EXISTS transaction_promotions (
+    id                    SERIAL PRIMARY KEY,
+    transaction_id        INTEGER NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    transaction_detail_id INTEGER NOT NULL REFERENCES transaction_details(id) ON DELETE CASCADE,
+    rule_id               INTEGER REFERENCES promotion_rules(id) ON DELETE SET NULL,
+    rule_name             VARCHAR(255) NOT NULL,
+    rule_type             VARCHAR(20) NOT NULL,
+    quantity              INTEGER NOT NULL,
+    discount              INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_promotions_transaction_id ON transaction_promotions (transaction_id);
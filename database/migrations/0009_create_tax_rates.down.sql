@@ -0,0 +1,16 @@
+ALTER TABLE return_items DROP COLUMN IF EXISTS tax_amount;
+
+ALTER TABLE transaction_details DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE transaction_details DROP COLUMN IF EXISTS tax_base;
+ALTER TABLE transaction_details DROP COLUMN IF EXISTS tax_rate;
+ALTER TABLE transaction_details DROP COLUMN IF EXISTS tax_name;
+ALTER TABLE transaction_details DROP COLUMN IF EXISTS tax_rate_id;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE transactions DROP COLUMN IF EXISTS tax_base;
+ALTER TABLE transactions DROP COLUMN IF EXISTS tax_inclusive;
+
+ALTER TABLE categories DROP COLUMN IF EXISTS tax_rate_id;
+ALTER TABLE products DROP COLUMN IF EXISTS tax_rate_id;
+
+DROP TABLE IF EXISTS tax_rates;
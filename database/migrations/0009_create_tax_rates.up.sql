@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS tax_rates (
+    id   SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    rate NUMERIC(5, 2) NOT NULL CHECK (rate >= 0 AND rate <= 100)
+);
+
+-- Tarif produk mengalahkan tarif category, keduanya boleh kosong (tidak kena pajak)
+ALTER TABLE products ADD COLUMN IF NOT EXISTS tax_rate_id INTEGER REFERENCES tax_rates(id) ON DELETE SET NULL;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS tax_rate_id INTEGER REFERENCES tax_rates(id) ON DELETE SET NULL;
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tax_inclusive BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tax_base INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tax_amount INTEGER NOT NULL DEFAULT 0;
+
+-- Tarif di-snapshot per baris, perubahan tarif tidak mengubah transaksi lama
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS tax_rate_id INTEGER REFERENCES tax_rates(id) ON DELETE SET NULL;
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS tax_name VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS tax_rate NUMERIC(5, 2) NOT NULL DEFAULT 0;
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS tax_base INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS tax_amount INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE return_items ADD COLUMN IF NOT EXISTS tax_amount INTEGER NOT NULL DEFAULT 0;
+
+-- Data lama tanpa pajak: DPP = nilai transaksi
+UPDATE transactions SET tax_base = total_amount WHERE tax_base = 0;
+UPDATE transaction_details SET tax_base = subtotal WHERE tax_base = 0;
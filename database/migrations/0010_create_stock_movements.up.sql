@@ -0,0 +1,22 @@
+-- Ledger stok append-only, products.stock tetap disimpan sebagai saldo berjalan
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id             SERIAL PRIMARY KEY,
+    product_id     INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    delta          INTEGER NOT NULL,
+    stock_after    INTEGER NOT NULL,
+    reason         VARCHAR(20) NOT NULL CHECK (reason IN ('sale', 'return', 'void', 'adjustment', 'receipt', 'transfer')),
+    reference_type VARCHAR(20) NOT NULL DEFAULT '',
+    reference_id   INTEGER,
+    created_by     VARCHAR(100) NOT NULL DEFAULT '',
+    note           TEXT NOT NULL DEFAULT '',
+    created_at     TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_product ON stock_movements (product_id, id);
+
+-- Saldo awal produk yang sudah ada, supaya jumlah delta = products.stock
+INSERT INTO stock_movements (product_id, delta, stock_after, reason, reference_type, reference_id, note)
+SELECT p.id, p.stock, p.stock, 'adjustment', 'product', p.id, 'saldo awal'
+FROM products p
+WHERE p.stock <> 0
+  AND NOT EXISTS (SELECT 1 FROM stock_movements sm WHERE sm.product_id = p.id);
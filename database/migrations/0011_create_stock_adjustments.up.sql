@@ -0,0 +1,8 @@
+-- Dokumen adjustment, item-nya tercatat di stock_movements (reference_type = 'adjustment')
+CREATE TABLE IF NOT EXISTS stock_adjustments (
+    id         SERIAL PRIMARY KEY,
+    reason     VARCHAR(20) NOT NULL CHECK (reason IN ('damaged', 'expired', 'lost', 'found', 'correction')),
+    note       TEXT NOT NULL DEFAULT '',
+    created_by VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
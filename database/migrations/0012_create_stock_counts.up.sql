@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS stock_counts (
+    id            SERIAL PRIMARY KEY,
+    status        VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'finalized', 'cancelled')),
+    note          TEXT NOT NULL DEFAULT '',
+    created_by    VARCHAR(100) NOT NULL DEFAULT '',
+    created_at    TIMESTAMP NOT NULL DEFAULT NOW(),
+    closed_at     TIMESTAMP,
+    closed_by     VARCHAR(100) NOT NULL DEFAULT '',
+    adjustment_id INTEGER REFERENCES stock_adjustments(id)
+);
+
+-- Snapshot stok sistem dan harga saat sesi dibuka
+CREATE TABLE IF NOT EXISTS stock_count_lines (
+    count_id     INTEGER NOT NULL REFERENCES stock_counts(id) ON DELETE CASCADE,
+    product_id   INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    product_name VARCHAR(255) NOT NULL,
+    category_id  INTEGER NOT NULL DEFAULT 0,
+    unit_price   INTEGER NOT NULL,
+    system_stock INTEGER NOT NULL,
+    PRIMARY KEY (count_id, product_id)
+);
+
+-- Hasil hitung per device, kiriman ulang dari device yang sama menimpa
+CREATE TABLE IF NOT EXISTS stock_count_entries (
+    count_id   INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    device     VARCHAR(100) NOT NULL,
+    quantity   INTEGER NOT NULL CHECK (quantity >= 0),
+    counted_by VARCHAR(100) NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (count_id, product_id, device),
+    FOREIGN KEY (count_id, product_id) REFERENCES stock_count_lines(count_id, product_id) ON DELETE CASCADE
+);
@@ -0,0 +1,53 @@
+-- Harga pokok produk, diperbarui (rata-rata tertimbang) setiap penerimaan barang
+ALTER TABLE products ADD COLUMN IF NOT EXISTS cost_price INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS suppliers (
+    id      SERIAL PRIMARY KEY,
+    name    VARCHAR(255) NOT NULL,
+    phone   VARCHAR(50) NOT NULL DEFAULT '',
+    email   VARCHAR(255) NOT NULL DEFAULT '',
+    address TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS purchase_orders (
+    id          SERIAL PRIMARY KEY,
+    supplier_id INTEGER NOT NULL REFERENCES suppliers(id),
+    status      VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'sent', 'partially_received', 'received')),
+    note        TEXT NOT NULL DEFAULT '',
+    created_by  VARCHAR(100) NOT NULL DEFAULT '',
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW(),
+    sent_at     TIMESTAMP,
+    received_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_supplier ON purchase_orders (supplier_id);
+
+CREATE TABLE IF NOT EXISTS purchase_order_lines (
+    id                SERIAL PRIMARY KEY,
+    purchase_order_id INTEGER NOT NULL REFERENCES purchase_orders(id) ON DELETE CASCADE,
+    product_id        INTEGER NOT NULL REFERENCES products(id),
+    product_name      VARCHAR(255) NOT NULL,
+    quantity          INTEGER NOT NULL CHECK (quantity > 0),
+    received_quantity INTEGER NOT NULL DEFAULT 0 CHECK (received_quantity >= 0 AND received_quantity <= quantity),
+    unit_cost         INTEGER NOT NULL CHECK (unit_cost >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_order_lines_po ON purchase_order_lines (purchase_order_id);
+
+CREATE TABLE IF NOT EXISTS goods_receipts (
+    id                SERIAL PRIMARY KEY,
+    purchase_order_id INTEGER NOT NULL REFERENCES purchase_orders(id),
+    note              TEXT NOT NULL DEFAULT '',
+    created_by        VARCHAR(100) NOT NULL DEFAULT '',
+    created_at        TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS goods_receipt_items (
+    id                     SERIAL PRIMARY KEY,
+    goods_receipt_id       INTEGER NOT NULL REFERENCES goods_receipts(id) ON DELETE CASCADE,
+    purchase_order_line_id INTEGER NOT NULL REFERENCES purchase_order_lines(id),
+    product_id             INTEGER NOT NULL REFERENCES products(id),
+    quantity               INTEGER NOT NULL CHECK (quantity > 0),
+    unit_cost              INTEGER NOT NULL CHECK (unit_cost >= 0),
+    movement_id            INTEGER REFERENCES stock_movements(id)
+);
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS stock_transfer_lines;
+DROP TABLE IF EXISTS stock_transfers;
+
+ALTER TABLE purchase_orders DROP COLUMN IF EXISTS location_id;
+ALTER TABLE stock_counts DROP COLUMN IF EXISTS location_id;
+ALTER TABLE stock_adjustments DROP COLUMN IF EXISTS location_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS location_id;
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS location_id;
+
+DROP TABLE IF EXISTS product_stocks;
+DROP TABLE IF EXISTS locations;
@@ -0,0 +1,56 @@
+-- Outlet dan gudang. Lokasi id 1 adalah lokasi bawaan, stok lama pindah ke sini
+CREATE TABLE IF NOT EXISTS locations (
+    id         SERIAL PRIMARY KEY,
+    name       VARCHAR(100) NOT NULL,
+    type       VARCHAR(20) NOT NULL CHECK (type IN ('outlet', 'warehouse')),
+    address    TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO locations (id, name, type) VALUES (1, 'Toko Utama', 'outlet') ON CONFLICT (id) DO NOTHING;
+SELECT setval(pg_get_serial_sequence('locations', 'id'), (SELECT MAX(id) FROM locations));
+
+-- Stok per lokasi, products.stock tetap disimpan sebagai total semua lokasi
+CREATE TABLE IF NOT EXISTS product_stocks (
+    product_id  INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    location_id INTEGER NOT NULL REFERENCES locations(id),
+    stock       INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (product_id, location_id)
+);
+
+INSERT INTO product_stocks (product_id, location_id, stock)
+SELECT id, 1, stock FROM products WHERE stock <> 0
+ON CONFLICT DO NOTHING;
+
+-- stock_after di ledger sekarang adalah stok di lokasi tersebut
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS location_id INTEGER NOT NULL DEFAULT 1 REFERENCES locations(id);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS location_id INTEGER NOT NULL DEFAULT 1 REFERENCES locations(id);
+ALTER TABLE stock_adjustments ADD COLUMN IF NOT EXISTS location_id INTEGER NOT NULL DEFAULT 1 REFERENCES locations(id);
+ALTER TABLE stock_counts ADD COLUMN IF NOT EXISTS location_id INTEGER NOT NULL DEFAULT 1 REFERENCES locations(id);
+ALTER TABLE purchase_orders ADD COLUMN IF NOT EXISTS location_id INTEGER NOT NULL DEFAULT 1 REFERENCES locations(id);
+
+CREATE TABLE IF NOT EXISTS stock_transfers (
+    id               SERIAL PRIMARY KEY,
+    from_location_id INTEGER NOT NULL REFERENCES locations(id),
+    to_location_id   INTEGER NOT NULL REFERENCES locations(id),
+    status           VARCHAR(20) NOT NULL DEFAULT 'requested' CHECK (status IN ('requested', 'shipped', 'received', 'cancelled')),
+    note             TEXT NOT NULL DEFAULT '',
+    created_by       VARCHAR(100) NOT NULL DEFAULT '',
+    created_at       TIMESTAMP NOT NULL DEFAULT NOW(),
+    shipped_by       VARCHAR(100) NOT NULL DEFAULT '',
+    shipped_at       TIMESTAMP,
+    received_by      VARCHAR(100) NOT NULL DEFAULT '',
+    received_at      TIMESTAMP,
+    CHECK (from_location_id <> to_location_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_status ON stock_transfers (status);
+
+CREATE TABLE IF NOT EXISTS stock_transfer_lines (
+    id           SERIAL PRIMARY KEY,
+    transfer_id  INTEGER NOT NULL REFERENCES stock_transfers(id) ON DELETE CASCADE,
+    product_id   INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    product_name VARCHAR(255) NOT NULL,
+    quantity     INTEGER NOT NULL CHECK (quantity > 0),
+    UNIQUE (transfer_id, product_id)
+);
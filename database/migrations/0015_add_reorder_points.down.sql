@@ -0,0 +1,3 @@
+ALTER TABLE products DROP COLUMN IF EXISTS supplier_id;
+ALTER TABLE products DROP COLUMN IF EXISTS reorder_quantity;
+ALTER TABLE products DROP COLUMN IF EXISTS reorder_point;
@@ -0,0 +1,5 @@
+-- Reorder point 0 = produk tidak dipantau stok minimumnya.
+-- supplier_id = supplier utama, dipakai untuk mengelompokkan laporan low stock.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS reorder_point INTEGER NOT NULL DEFAULT 0 CHECK (reorder_point >= 0);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS reorder_quantity INTEGER NOT NULL DEFAULT 0 CHECK (reorder_quantity >= 0);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS supplier_id INTEGER REFERENCES suppliers(id) ON DELETE SET NULL;
@@ -0,0 +1 @@
+ALTER TABLE suppliers DROP COLUMN IF EXISTS lead_time_days;
@@ -0,0 +1,2 @@
+-- Lead time = jumlah hari dari PO dikirim sampai barang diterima
+ALTER TABLE suppliers ADD COLUMN IF NOT EXISTS lead_time_days INTEGER NOT NULL DEFAULT 0 CHECK (lead_time_days >= 0);
@@ -0,0 +1,8 @@
+-- HPP per unit saat transaksi terjadi, supaya perubahan cost_price tidak
+-- mengubah laba transaksi lama
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS unit_cost INTEGER NOT NULL DEFAULT 0;
+
+-- Transaksi lama: pakai cost_price produk saat ini sebagai perkiraan
+UPDATE transaction_details td SET unit_cost = p.cost_price
+FROM products p
+WHERE p.id = td.product_id AND td.unit_cost = 0;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS cost_layers;
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS cost_method;
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS cost_amount;
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS unit_cost;
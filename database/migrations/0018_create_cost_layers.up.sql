@@ -0,0 +1,26 @@
+-- Nilai persediaan per movement. cost_amount bertanda sama dengan delta,
+-- jadi SUM(cost_amount) sampai tanggal tertentu = nilai stok saat itu.
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS unit_cost INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS cost_amount BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS cost_method VARCHAR(10) NOT NULL DEFAULT '';
+
+-- Satu layer untuk setiap stok masuk, remaining berkurang urut FIFO saat stok keluar
+CREATE TABLE IF NOT EXISTS cost_layers (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    movement_id INTEGER REFERENCES stock_movements(id) ON DELETE SET NULL,
+    unit_cost INTEGER NOT NULL DEFAULT 0,
+    quantity INTEGER NOT NULL CHECK (quantity >= 0),
+    remaining INTEGER NOT NULL CHECK (remaining >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cost_layers_open ON cost_layers (product_id, id) WHERE remaining > 0;
+
+-- Data lama: movement dinilai dengan cost_price saat ini, stok yang ada jadi layer pembuka
+UPDATE stock_movements sm SET unit_cost = p.cost_price, cost_amount = sm.delta * p.cost_price
+FROM products p
+WHERE p.id = sm.product_id AND sm.reason <> 'transfer';
+
+INSERT INTO cost_layers (product_id, unit_cost, quantity, remaining)
+SELECT id, cost_price, stock, stock FROM products WHERE stock > 0;
@@ -0,0 +1,4 @@
+ALTER TABLE goods_receipt_items DROP COLUMN IF EXISTS expiry_date;
+ALTER TABLE goods_receipt_items DROP COLUMN IF EXISTS lot_number;
+DROP TABLE IF EXISTS batch_movements;
+DROP TABLE IF EXISTS batches;
@@ -0,0 +1,29 @@
+-- Stok per lot di satu lokasi. Stok lokasi yang tidak tercakup batch dianggap
+-- stok tanpa lot (tanpa tanggal kedaluwarsa).
+CREATE TABLE IF NOT EXISTS batches (
+    id          SERIAL PRIMARY KEY,
+    product_id  INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    location_id INTEGER NOT NULL REFERENCES locations(id),
+    lot_number  VARCHAR(50) NOT NULL,
+    expiry_date DATE,
+    quantity    INTEGER NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- lot yang sama dengan tanggal kedaluwarsa berbeda dianggap batch berbeda
+CREATE UNIQUE INDEX IF NOT EXISTS uq_batches_lot
+    ON batches (product_id, location_id, lot_number, COALESCE(expiry_date, 'infinity'::date));
+CREATE INDEX IF NOT EXISTS idx_batches_expiry ON batches (expiry_date) WHERE quantity > 0;
+
+-- Batch yang dipakai tiap stock movement, quantity bertanda sama dengan delta
+CREATE TABLE IF NOT EXISTS batch_movements (
+    movement_id INTEGER NOT NULL REFERENCES stock_movements(id) ON DELETE CASCADE,
+    batch_id    INTEGER NOT NULL REFERENCES batches(id) ON DELETE CASCADE,
+    quantity    INTEGER NOT NULL,
+    PRIMARY KEY (movement_id, batch_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_batch_movements_batch ON batch_movements (batch_id);
+
+ALTER TABLE goods_receipt_items ADD COLUMN IF NOT EXISTS lot_number VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE goods_receipt_items ADD COLUMN IF NOT EXISTS expiry_date DATE;
@@ -0,0 +1,6 @@
+ALTER TABLE goods_receipt_items DROP COLUMN IF EXISTS serials;
+ALTER TABLE return_items DROP COLUMN IF EXISTS serials;
+ALTER TABLE transaction_details DROP COLUMN IF EXISTS serials;
+DROP TABLE IF EXISTS serial_movements;
+DROP TABLE IF EXISTS serial_numbers;
+ALTER TABLE products DROP COLUMN IF EXISTS track_serial;
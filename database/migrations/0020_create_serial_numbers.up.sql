@@ -0,0 +1,26 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS track_serial BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Unit produk yang dilacak per serial number (IMEI / S/N), unik per produk
+CREATE TABLE IF NOT EXISTS serial_numbers (
+    id         SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    serial     VARCHAR(100) NOT NULL,
+    status     VARCHAR(20) NOT NULL DEFAULT 'in_stock',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (product_id, serial)
+);
+
+CREATE INDEX IF NOT EXISTS idx_serial_numbers_serial ON serial_numbers (serial);
+
+-- Serial yang ikut tiap stock movement, dipakai untuk riwayat klaim garansi
+CREATE TABLE IF NOT EXISTS serial_movements (
+    movement_id INTEGER NOT NULL REFERENCES stock_movements(id) ON DELETE CASCADE,
+    serial_id   INTEGER NOT NULL REFERENCES serial_numbers(id) ON DELETE CASCADE,
+    PRIMARY KEY (movement_id, serial_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_serial_movements_serial ON serial_movements (serial_id);
+
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS serials TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE return_items ADD COLUMN IF NOT EXISTS serials TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE goods_receipt_items ADD COLUMN IF NOT EXISTS serials TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,9 @@
+-- Varian produk (ukuran, warna, ...) adalah baris products dengan parent_id ke
+-- produk induk, jadi stok, harga dan ledger-nya tetap per baris
+ALTER TABLE products ADD COLUMN IF NOT EXISTS parent_id INTEGER REFERENCES products(id) ON DELETE CASCADE;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS sku VARCHAR(64);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE products ADD COLUMN IF NOT EXISTS price_override BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_sku ON products (sku) WHERE sku IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_products_parent ON products (parent_id);
@@ -0,0 +1,11 @@
+-- Barcode produk (EAN-13, UPC-A atau kode internal), satu produk bisa punya
+-- beberapa barcode tapi satu barcode hanya milik satu produk
+CREATE TABLE IF NOT EXISTS product_barcodes (
+    id         SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    code       VARCHAR(64) NOT NULL UNIQUE,
+    type       VARCHAR(10) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_barcodes_product ON product_barcodes (product_id);
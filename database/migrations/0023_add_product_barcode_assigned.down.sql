@@ -0,0 +1 @@
+ALTER TABLE product_barcodes DROP COLUMN IF EXISTS assigned;
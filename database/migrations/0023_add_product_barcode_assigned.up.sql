@@ -0,0 +1,7 @@
+-- assigned = EAN-13 internal yang dibuatkan sistem (POST /api/product/{id}/barcode).
+-- Kode ini, seperti kode internal, tidak ikut terhapus saat update produk.
+ALTER TABLE product_barcodes ADD COLUMN IF NOT EXISTS assigned BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- EAN-13 berawalan 2 (GS1 20-29) hanya untuk pemakaian internal toko, bukan
+-- barcode pabrik, jadi yang sudah ada dianggap buatan sistem
+UPDATE product_barcodes SET assigned = TRUE WHERE type = 'ean13' AND code LIKE '2%';
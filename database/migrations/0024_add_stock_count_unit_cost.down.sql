@@ -0,0 +1 @@
+ALTER TABLE stock_count_lines DROP COLUMN IF EXISTS unit_cost;
@@ -0,0 +1,8 @@
+-- Selisih stock opname dinilai dengan HPP, sama dengan adjustment yang
+-- diposting saat finalize. unit_price tetap disimpan sebagai info harga jual.
+ALTER TABLE stock_count_lines ADD COLUMN IF NOT EXISTS unit_cost INTEGER NOT NULL DEFAULT 0;
+
+-- Sesi lama: pakai cost_price produk saat ini sebagai perkiraan
+UPDATE stock_count_lines l SET unit_cost = p.cost_price
+FROM products p
+WHERE p.id = l.product_id AND l.unit_cost = 0;
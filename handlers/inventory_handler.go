@@ -0,0 +1,389 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type InventoryHandler struct {
+	service *services.InventoryService
+}
+
+func NewInventoryHandler(service *services.InventoryService) *InventoryHandler {
+	return &InventoryHandler{service: service}
+}
+
+// HandleAdjustments - POST /api/inventory/adjustments
+func (h *InventoryHandler) HandleAdjustments(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.StockAdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	adj, err := h.service.Adjust(req, requestUser(r))
+	switch {
+	case errors.Is(err, services.ErrInvalidAdjustment), errors.Is(err, services.ErrProductNotFound),
+		errors.Is(err, services.ErrLocationNotFound), errors.Is(err, services.ErrInvalidSerial):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, services.ErrInsufficientStock), errors.Is(err, services.ErrInsufficientBatch),
+		errors.Is(err, services.ErrSerialUnavailable):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(adj)
+}
+
+// HandleLowStock - GET /api/inventory/low-stock?group_by=category|supplier
+func (h *InventoryHandler) HandleLowStock(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	report, err := h.service.LowStock(r.URL.Query().Get("group_by"))
+	switch {
+	case errors.Is(err, services.ErrInvalidLowStockGroup):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// HandleReorderSuggestions - GET /api/inventory/reorder-suggestions?window_days=&alpha=&cover_days=
+func (h *InventoryHandler) HandleReorderSuggestions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	var params models.ReorderParams
+	var err error
+	if v := q.Get("window_days"); v != "" {
+		if params.WindowDays, err = strconv.Atoi(v); err != nil {
+			http.Error(w, "Invalid window_days", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("alpha"); v != "" {
+		if params.Alpha, err = strconv.ParseFloat(v, 64); err != nil {
+			http.Error(w, "Invalid alpha", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("cover_days"); v != "" {
+		if params.CoverDays, err = strconv.Atoi(v); err != nil {
+			http.Error(w, "Invalid cover_days", http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := h.service.ReorderSuggestions(params)
+	switch {
+	case errors.Is(err, services.ErrInvalidReorderParams):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// HandleValuation - GET /api/inventory/valuation?as_of=YYYY-MM-DD (kosong = saat ini)
+func (h *InventoryHandler) HandleValuation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var asOf *time.Time
+	if v := r.URL.Query().Get("as_of"); v != "" {
+		date, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			http.Error(w, "Invalid as_of, format YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		asOf = &date
+	}
+
+	report, err := h.service.Valuation(asOf)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// HandleBatches - GET /api/inventory/batches?product_id=&location_id=
+func (h *InventoryHandler) HandleBatches(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	var filter models.BatchFilter
+	var err error
+	if v := q.Get("product_id"); v != "" {
+		if filter.ProductID, err = strconv.Atoi(v); err != nil {
+			http.Error(w, "Invalid product_id", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("location_id"); v != "" {
+		if filter.LocationID, err = strconv.Atoi(v); err != nil {
+			http.Error(w, "Invalid location_id", http.StatusBadRequest)
+			return
+		}
+	}
+
+	batches, err := h.service.Batches(filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(batches)
+}
+
+// HandleExpiring - GET /api/inventory/expiring?days=30&location_id=
+func (h *InventoryHandler) HandleExpiring(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	var days *int
+	if v := q.Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Invalid days", http.StatusBadRequest)
+			return
+		}
+		days = &n
+	}
+	locationID := 0
+	if v := q.Get("location_id"); v != "" {
+		var err error
+		if locationID, err = strconv.Atoi(v); err != nil {
+			http.Error(w, "Invalid location_id", http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := h.service.ExpiringBatches(days, locationID)
+	switch {
+	case errors.Is(err, services.ErrInvalidExpiryWindow):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// HandleSerialByNumber - GET /api/serials/{serial}, riwayat unit untuk klaim garansi
+func (h *InventoryHandler) HandleSerialByNumber(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	units, err := h.service.SerialHistory(strings.TrimPrefix(r.URL.Path, "/api/serials/"))
+	switch {
+	case errors.Is(err, services.ErrInvalidSerial):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, services.ErrSerialNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(units)
+}
+
+// HandleCounts - GET/POST /api/inventory/counts
+func (h *InventoryHandler) HandleCounts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.ListCounts(w, r)
+	case http.MethodPost:
+		h.OpenCount(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *InventoryHandler) ListCounts(w http.ResponseWriter, r *http.Request) {
+	counts, err := h.service.ListCounts()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(counts)
+}
+
+func (h *InventoryHandler) OpenCount(w http.ResponseWriter, r *http.Request) {
+	var req models.StockCountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	count, err := h.service.OpenCount(req, requestUser(r))
+	if err != nil {
+		http.Error(w, err.Error(), stockCountErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(count)
+}
+
+// HandleCountByID - GET /api/inventory/counts/{id}, GET .../variance,
+// POST .../submissions, .../finalize, .../cancel
+func (h *InventoryHandler) HandleCountByID(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/api/inventory/counts/")
+	idStr, action, _ := strings.Cut(rest, "/")
+	action = strings.TrimSuffix(action, "/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid stock count ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetCount(w, r, id)
+	case action == "variance" && r.Method == http.MethodGet:
+		h.CountVariance(w, r, id)
+	case action == "submissions" && r.Method == http.MethodPost:
+		h.SubmitCount(w, r, id)
+	case action == "finalize" && r.Method == http.MethodPost:
+		h.FinalizeCount(w, r, id)
+	case action == "cancel" && r.Method == http.MethodPost:
+		h.CancelCount(w, r, id)
+	case action != "" && action != "variance" && action != "submissions" && action != "finalize" && action != "cancel":
+		http.NotFound(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *InventoryHandler) GetCount(w http.ResponseWriter, r *http.Request, id int) {
+	count, err := h.service.GetCount(id)
+	if err != nil {
+		http.Error(w, err.Error(), stockCountErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(count)
+}
+
+func (h *InventoryHandler) CountVariance(w http.ResponseWriter, r *http.Request, id int) {
+	report, err := h.service.CountVariance(id)
+	if err != nil {
+		http.Error(w, err.Error(), stockCountErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// SubmitCount - POST /api/inventory/counts/{id}/submissions, boleh berkali-kali dari beberapa device
+func (h *InventoryHandler) SubmitCount(w http.ResponseWriter, r *http.Request, id int) {
+	var sub models.StockCountSubmission
+	if err := json.NewDecoder(r.Body).Decode(&sub); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	count, err := h.service.SubmitCount(id, sub, requestUser(r))
+	if err != nil {
+		http.Error(w, err.Error(), stockCountErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(count)
+}
+
+func (h *InventoryHandler) FinalizeCount(w http.ResponseWriter, r *http.Request, id int) {
+	count, err := h.service.FinalizeCount(id, requestUser(r))
+	if err != nil {
+		http.Error(w, err.Error(), stockCountErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(count)
+}
+
+func (h *InventoryHandler) CancelCount(w http.ResponseWriter, r *http.Request, id int) {
+	if err := h.service.CancelCount(id, requestUser(r)); err != nil {
+		http.Error(w, err.Error(), stockCountErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Stock count cancelled successfully",
+	})
+}
+
+func stockCountErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrStockCountNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidStockCount), errors.Is(err, services.ErrStockCountEmpty),
+		errors.Is(err, services.ErrStockCountUnknownProduct), errors.Is(err, services.ErrLocationNotFound):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrStockCountClosed), errors.Is(err, services.ErrInsufficientStock):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type LocationHandler struct {
+	service *services.LocationService
+}
+
+func NewLocationHandler(service *services.LocationService) *LocationHandler {
+	return &LocationHandler{service: service}
+}
+
+// HandleLocations - GET/POST /api/locations
+func (h *LocationHandler) HandleLocations(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetLocations(w, r)
+	case http.MethodPost:
+		h.CreateLocation(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *LocationHandler) GetLocations(w http.ResponseWriter, r *http.Request) {
+	locations, err := h.service.GetLocations()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(locations)
+}
+
+func (h *LocationHandler) CreateLocation(w http.ResponseWriter, r *http.Request) {
+	var location models.Location
+	if err := json.NewDecoder(r.Body).Decode(&location); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.CreateLocation(&location); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(location)
+}
+
+// HandleLocationByID - GET/PUT/DELETE /api/locations/{id}
+func (h *LocationHandler) HandleLocationByID(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/locations/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid location ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.GetLocation(w, r, id)
+	case http.MethodPut:
+		h.UpdateLocation(w, r, id)
+	case http.MethodDelete:
+		h.DeleteLocation(w, r, id)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *LocationHandler) GetLocation(w http.ResponseWriter, r *http.Request, id int) {
+	location, err := h.service.GetLocation(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(location)
+}
+
+func (h *LocationHandler) UpdateLocation(w http.ResponseWriter, r *http.Request, id int) {
+	var location models.Location
+	if err := json.NewDecoder(r.Body).Decode(&location); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	location.ID = id
+	err := h.service.UpdateLocation(&location)
+	if errors.Is(err, services.ErrLocationNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(location)
+}
+
+func (h *LocationHandler) DeleteLocation(w http.ResponseWriter, r *http.Request, id int) {
+	err := h.service.DeleteLocation(id)
+	switch {
+	case errors.Is(err, services.ErrLocationNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, services.ErrLocationInUse):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Location deleted successfully",
+	})
+}
+
+// HandleTransfers - GET /api/inventory/transfers?status=&location_id=, POST buat transfer
+func (h *LocationHandler) HandleTransfers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetTransfers(w, r)
+	case http.MethodPost:
+		h.CreateTransfer(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *LocationHandler) GetTransfers(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := models.StockTransferFilter{Status: q.Get("status")}
+	if v := q.Get("location_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id <= 0 {
+			http.Error(w, "Invalid location_id", http.StatusBadRequest)
+			return
+		}
+		filter.LocationID = id
+	}
+
+	transfers, err := h.service.GetTransfers(filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transfers)
+}
+
+func (h *LocationHandler) CreateTransfer(w http.ResponseWriter, r *http.Request) {
+	var req models.StockTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	transfer, err := h.service.CreateTransfer(req, requestUser(r))
+	if err != nil {
+		http.Error(w, err.Error(), transferErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(transfer)
+}
+
+// HandleTransferByID - GET /api/inventory/transfers/{id}, POST .../ship, .../receive, .../cancel
+func (h *LocationHandler) HandleTransferByID(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/api/inventory/transfers/")
+	idStr, action, _ := strings.Cut(rest, "/")
+	action = strings.TrimSuffix(action, "/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid transfer ID", http.StatusBadRequest)
+		return
+	}
+
+	var step func(int, string) (*models.StockTransfer, error)
+	switch action {
+	case "":
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetTransfer(w, r, id)
+		return
+	case "ship":
+		step = h.service.ShipTransfer
+	case "receive":
+		step = h.service.ReceiveTransfer
+	case "cancel":
+		step = h.service.CancelTransfer
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	transfer, err := step(id, requestUser(r))
+	if err != nil {
+		http.Error(w, err.Error(), transferErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transfer)
+}
+
+func (h *LocationHandler) GetTransfer(w http.ResponseWriter, r *http.Request, id int) {
+	transfer, err := h.service.GetTransfer(id)
+	if err != nil {
+		http.Error(w, err.Error(), transferErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transfer)
+}
+
+func transferErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrStockTransferNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidStockTransfer), errors.Is(err, services.ErrLocationNotFound),
+		errors.Is(err, services.ErrProductNotFound):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrStockTransferStatus), errors.Is(err, services.ErrInsufficientStock):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"kasir-api/models"
 	"kasir-api/services"
 	"net/http"
@@ -37,6 +38,13 @@ func (h *ProductHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	// listing ini tanpa API key, HPP hanya ada di GET /api/product/{id}
+	for i := range products {
+		products[i].CostPrice = 0
+		for j := range products[i].Variants {
+			products[i].Variants[j].CostPrice = 0
+		}
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(products)
@@ -50,9 +58,10 @@ func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	product.User = requestUser(r)
 	err = h.service.Create(&product)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), productErrorStatus(err))
 		return
 	}
 
@@ -61,30 +70,62 @@ func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(product)
 }
 
-// HandleProductByID - GET/PUT/DELETE /api/product/{id}
+// HandleProductByID - GET/PUT/DELETE /api/product/{id}, GET /api/product/{id}/movements,
+// GET/POST /api/product/{id}/variants, POST /api/product/{id}/barcode,
+// DELETE /api/product/{id}/barcode/{code}, GET /api/product/barcode/{code}
 func (h *ProductHandler) HandleProductByID(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		h.GetByID(w, r)
-	case http.MethodPut:
-		h.Update(w, r)
-	case http.MethodDelete:
-		h.Delete(w, r)
+	if code, ok := strings.CutPrefix(r.URL.Path, "/api/product/barcode/"); ok {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetByCode(w, r, code)
+		return
+	}
+
+	id, action, err := parseProductPath(r.URL.Path)
+	if err != nil {
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetByID(w, r, id)
+	case action == "" && r.Method == http.MethodPut:
+		h.Update(w, r, id)
+	case action == "" && r.Method == http.MethodDelete:
+		h.Delete(w, r, id)
+	case action == "movements" && r.Method == http.MethodGet:
+		h.GetMovements(w, r, id)
+	case action == "variants" && r.Method == http.MethodGet:
+		h.GetVariants(w, r, id)
+	case action == "variants" && r.Method == http.MethodPost:
+		h.CreateVariant(w, r, id)
+	case action == "barcode" && r.Method == http.MethodPost:
+		h.AssignBarcode(w, r, id)
+	case strings.HasPrefix(action, "barcode/") && r.Method == http.MethodDelete:
+		h.RemoveBarcode(w, r, id, strings.TrimPrefix(action, "barcode/"))
+	case action != "" && action != "movements" && action != "variants" && action != "barcode" && !strings.HasPrefix(action, "barcode/"):
+		http.NotFound(w, r)
 	default:
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
 }
 
-// GetByID - GET /api/product/{id}
-
-func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/product/")
+// parseProductPath - pecah /api/product/{id}[/{action}]
+func parseProductPath(path string) (int, string, error) {
+	rest := strings.TrimPrefix(path, "/api/product/")
+	idStr, action, _ := strings.Cut(rest, "/")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
-		return
+		return 0, "", err
 	}
+	return id, strings.TrimSuffix(action, "/"), nil
+}
 
+// GetByID - GET /api/product/{id}
+func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request, id int) {
 	product, err := h.service.GetByID(id)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
@@ -95,49 +136,223 @@ func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(product)
 }
 
-func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/product/")
-	id, err := strconv.Atoi(idStr)
+// GetByCode - GET /api/product/barcode/{code}, untuk scanner kasir
+func (h *ProductHandler) GetByCode(w http.ResponseWriter, r *http.Request, code string) {
+	product, err := h.service.GetByCode(code)
+	if errors.Is(err, services.ErrInvalidBarcode) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, services.ErrBarcodeNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	var product models.Product
-	err = json.NewDecoder(r.Body).Decode(&product)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(product)
+}
+
+func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request, id int) {
+	var update models.ProductUpdate
+	err := json.NewDecoder(r.Body).Decode(&update)
 	if err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	product.ID = id
-	err = h.service.Update(&product)
+	update.ID = id
+	update.User = requestUser(r)
+	err = h.service.Update(&update)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), productErrorStatus(err))
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(product)
+	json.NewEncoder(w).Encode(update.Product)
 }
 
 // Delete - DELETE /api/product/{id}
-func (h *ProductHandler) Delete(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/product/")
-	id, err := strconv.Atoi(idStr)
+func (h *ProductHandler) Delete(w http.ResponseWriter, r *http.Request, id int) {
+	err := h.service.Delete(id)
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	err = h.service.Delete(id)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Product deleted successfully",
+	})
+}
+
+// GetMovements - GET /api/product/{id}/movements, ledger stok dari yang paling lama
+func (h *ProductHandler) GetMovements(w http.ResponseWriter, r *http.Request, id int) {
+	movements, err := h.service.GetMovements(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(movements)
+}
+
+// GetVariants - GET /api/product/{id}/variants
+func (h *ProductHandler) GetVariants(w http.ResponseWriter, r *http.Request, id int) {
+	variants, err := h.service.GetVariants(id)
+	if errors.Is(err, services.ErrProductNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Product deleted successfully",
-	})
+	json.NewEncoder(w).Encode(variants)
+}
+
+// CreateVariant - POST /api/product/{id}/variants, body sama dengan produk
+// ditambah sku, attributes dan price_override
+func (h *ProductHandler) CreateVariant(w http.ResponseWriter, r *http.Request, id int) {
+	var variant models.Product
+	if err := json.NewDecoder(r.Body).Decode(&variant); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	variant.User = requestUser(r)
+	if err := h.service.CreateVariant(id, &variant); err != nil {
+		http.Error(w, err.Error(), productErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(variant)
+}
+
+// productErrorStatus - SKU / barcode bentrok = 409, kesalahan input lain tetap 400
+func productErrorStatus(err error) int {
+	if errors.Is(err, services.ErrDuplicateSKU) || errors.Is(err, services.ErrDuplicateBarcode) ||
+		errors.Is(err, services.ErrTrackSerialInUse) {
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
+
+// AssignBarcode - POST /api/product/{id}/barcode, buatkan EAN-13 internal dari
+// BARCODE_PREFIX untuk produk yang belum punya EAN-13 / UPC
+func (h *ProductHandler) AssignBarcode(w http.ResponseWriter, r *http.Request, id int) {
+	product, err := h.service.AssignBarcode(id)
+	switch {
+	case errors.Is(err, services.ErrProductNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, services.ErrBarcodeAssigned), errors.Is(err, services.ErrBarcodeRangeFull):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(product)
+}
+
+// RemoveBarcode - DELETE /api/product/{id}/barcode/{code}
+func (h *ProductHandler) RemoveBarcode(w http.ResponseWriter, r *http.Request, id int, code string) {
+	product, err := h.service.RemoveBarcode(id, code)
+	switch {
+	case errors.Is(err, services.ErrProductNotFound), errors.Is(err, services.ErrBarcodeNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, services.ErrInvalidBarcode):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(product)
+}
+
+// HandleBarcode - GET /api/barcodes?code=...&symbology=ean13|code128&format=svg|png
+func (h *ProductHandler) HandleBarcode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	data, contentType, err := services.RenderBarcode(q.Get("code"), q.Get("symbology"), q.Get("format"))
+	if errors.Is(err, services.ErrInvalidBarcode) || errors.Is(err, services.ErrInvalidBarcodeFormat) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(data)
+}
+
+// HandleLabels - GET /api/labels?product_ids=1,2,3&copies=2, lembar label rak
+// (HTML, dicetak dari browser)
+func (h *ProductHandler) HandleLabels(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	var ids []int
+	for _, s := range strings.Split(q.Get("product_ids"), ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		id, err := strconv.Atoi(s)
+		if err != nil {
+			http.Error(w, "Invalid product_ids", http.StatusBadRequest)
+			return
+		}
+		ids = append(ids, id)
+	}
+	copies := 0
+	if s := q.Get("copies"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			http.Error(w, "Invalid copies", http.StatusBadRequest)
+			return
+		}
+		copies = n
+	}
+
+	page, err := h.service.LabelSheet(ids, copies)
+	switch {
+	case errors.Is(err, services.ErrInvalidLabelRequest):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, services.ErrProductNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(page)
 }
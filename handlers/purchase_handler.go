@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type PurchaseHandler struct {
+	service *services.PurchaseService
+}
+
+func NewPurchaseHandler(service *services.PurchaseService) *PurchaseHandler {
+	return &PurchaseHandler{service: service}
+}
+
+// HandleSuppliers - GET/POST /api/suppliers
+func (h *PurchaseHandler) HandleSuppliers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetSuppliers(w, r)
+	case http.MethodPost:
+		h.CreateSupplier(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *PurchaseHandler) GetSuppliers(w http.ResponseWriter, r *http.Request) {
+	suppliers, err := h.service.GetSuppliers()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(suppliers)
+}
+
+func (h *PurchaseHandler) CreateSupplier(w http.ResponseWriter, r *http.Request) {
+	var supplier models.Supplier
+	if err := json.NewDecoder(r.Body).Decode(&supplier); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.CreateSupplier(&supplier); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(supplier)
+}
+
+// HandleSupplierByID - GET/PUT/DELETE /api/suppliers/{id}
+func (h *PurchaseHandler) HandleSupplierByID(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/suppliers/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid supplier ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.GetSupplier(w, r, id)
+	case http.MethodPut:
+		h.UpdateSupplier(w, r, id)
+	case http.MethodDelete:
+		h.DeleteSupplier(w, r, id)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *PurchaseHandler) GetSupplier(w http.ResponseWriter, r *http.Request, id int) {
+	supplier, err := h.service.GetSupplier(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(supplier)
+}
+
+func (h *PurchaseHandler) UpdateSupplier(w http.ResponseWriter, r *http.Request, id int) {
+	var supplier models.Supplier
+	if err := json.NewDecoder(r.Body).Decode(&supplier); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	supplier.ID = id
+	err := h.service.UpdateSupplier(&supplier)
+	if errors.Is(err, services.ErrSupplierNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(supplier)
+}
+
+func (h *PurchaseHandler) DeleteSupplier(w http.ResponseWriter, r *http.Request, id int) {
+	err := h.service.DeleteSupplier(id)
+	switch {
+	case errors.Is(err, services.ErrSupplierNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, services.ErrSupplierInUse):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Supplier deleted successfully",
+	})
+}
+
+// HandlePurchaseOrders - GET /api/purchase-orders?status=&supplier_id=, POST buat draft
+func (h *PurchaseHandler) HandlePurchaseOrders(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetPurchaseOrders(w, r)
+	case http.MethodPost:
+		h.CreatePurchaseOrder(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *PurchaseHandler) GetPurchaseOrders(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := models.PurchaseOrderFilter{Status: q.Get("status")}
+	if v := q.Get("supplier_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id <= 0 {
+			http.Error(w, "Invalid supplier_id", http.StatusBadRequest)
+			return
+		}
+		filter.SupplierID = id
+	}
+
+	orders, err := h.service.GetPurchaseOrders(filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(orders)
+}
+
+func (h *PurchaseHandler) CreatePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	var req models.PurchaseOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	po, err := h.service.CreatePurchaseOrder(req, requestUser(r))
+	if err != nil {
+		http.Error(w, err.Error(), purchaseErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(po)
+}
+
+// HandlePurchaseOrderByID - GET/PUT/DELETE /api/purchase-orders/{id},
+// POST .../send, GET/POST .../receipts
+func (h *PurchaseHandler) HandlePurchaseOrderByID(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/api/purchase-orders/")
+	idStr, action, _ := strings.Cut(rest, "/")
+	action = strings.TrimSuffix(action, "/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid purchase order ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetPurchaseOrder(w, r, id)
+	case action == "" && r.Method == http.MethodPut:
+		h.UpdatePurchaseOrder(w, r, id)
+	case action == "" && r.Method == http.MethodDelete:
+		h.DeletePurchaseOrder(w, r, id)
+	case action == "send" && r.Method == http.MethodPost:
+		h.SendPurchaseOrder(w, r, id)
+	case action == "receipts" && r.Method == http.MethodGet:
+		h.ListReceipts(w, r, id)
+	case action == "receipts" && r.Method == http.MethodPost:
+		h.ReceiveGoods(w, r, id)
+	case action != "" && action != "send" && action != "receipts":
+		http.NotFound(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *PurchaseHandler) GetPurchaseOrder(w http.ResponseWriter, r *http.Request, id int) {
+	po, err := h.service.GetPurchaseOrder(id)
+	if err != nil {
+		http.Error(w, err.Error(), purchaseErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(po)
+}
+
+func (h *PurchaseHandler) UpdatePurchaseOrder(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.PurchaseOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	po, err := h.service.UpdatePurchaseOrder(id, req)
+	if err != nil {
+		http.Error(w, err.Error(), purchaseErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(po)
+}
+
+func (h *PurchaseHandler) DeletePurchaseOrder(w http.ResponseWriter, r *http.Request, id int) {
+	if err := h.service.DeletePurchaseOrder(id); err != nil {
+		http.Error(w, err.Error(), purchaseErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Purchase order deleted successfully",
+	})
+}
+
+func (h *PurchaseHandler) SendPurchaseOrder(w http.ResponseWriter, r *http.Request, id int) {
+	po, err := h.service.SendPurchaseOrder(id)
+	if err != nil {
+		http.Error(w, err.Error(), purchaseErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(po)
+}
+
+// ReceiveGoods - POST /api/purchase-orders/{id}/receipts, items kosong = terima semua sisa
+func (h *PurchaseHandler) ReceiveGoods(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.GoodsReceiptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	receipt, err := h.service.ReceiveGoods(id, req, requestUser(r))
+	if err != nil {
+		http.Error(w, err.Error(), purchaseErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(receipt)
+}
+
+func (h *PurchaseHandler) ListReceipts(w http.ResponseWriter, r *http.Request, id int) {
+	receipts, err := h.service.ListReceipts(id)
+	if err != nil {
+		http.Error(w, err.Error(), purchaseErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(receipts)
+}
+
+func purchaseErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrPurchaseOrderNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidPurchaseOrder), errors.Is(err, services.ErrInvalidReceipt),
+		errors.Is(err, services.ErrSupplierNotFound), errors.Is(err, services.ErrProductNotFound),
+		errors.Is(err, services.ErrLocationNotFound), errors.Is(err, services.ErrSerialRequired),
+		errors.Is(err, services.ErrInvalidSerial):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrPurchaseOrderStatus), errors.Is(err, services.ErrSerialUnavailable):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
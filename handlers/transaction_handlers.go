@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"kasir-api/models"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"kasir-api/services"
@@ -34,17 +37,70 @@ func (h *TransactionHandler) Checkout(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
+	req.User = requestUser(r)
 
-	transaction, err := h.service.Checkout(req.Items, false)
+	key := r.Header.Get("Idempotency-Key")
+	if key == "" {
+		transaction, err := h.service.Checkout(req)
+		if err != nil {
+			http.Error(w, err.Error(), checkoutErrorStatus(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(transaction)
+		return
+	}
+
+	if len(key) > 255 {
+		http.Error(w, "Idempotency-Key terlalu panjang (max 255 karakter)", http.StatusBadRequest)
+		return
+	}
+
+	transaction, replayed, err := h.service.CheckoutIdempotent(key, req)
+	if errors.Is(err, services.ErrIdempotencyKeyReused) || errors.Is(err, services.ErrIdempotencyKeyInProgress) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), checkoutErrorStatus(err))
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	if replayed {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
 	json.NewEncoder(w).Encode(transaction)
 }
 
+// checkoutErrorStatus - kesalahan input checkout (item, pembayaran, diskon, promo, lokasi, serial, varian, barcode) = 400,
+// stok kurang / kalah balapan dengan checkout lain = 409, selain itu 500
+func checkoutErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidCheckout),
+		errors.Is(err, services.ErrProductNotFound),
+		errors.Is(err, services.ErrInvalidPayment),
+		errors.Is(err, services.ErrInvalidDiscount),
+		errors.Is(err, services.ErrPromotionNotApplicable),
+		errors.Is(err, services.ErrPromotionExhausted),
+		errors.Is(err, services.ErrLocationNotFound),
+		errors.Is(err, services.ErrSerialRequired),
+		errors.Is(err, services.ErrInvalidSerial),
+		errors.Is(err, services.ErrVariantRequired),
+		errors.Is(err, services.ErrInvalidVariant),
+		errors.Is(err, services.ErrInvalidBarcode),
+		errors.Is(err, services.ErrBarcodeNotFound):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrInsufficientStock),
+		errors.Is(err, services.ErrBatchExpired),
+		errors.Is(err, services.ErrSerialUnavailable):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 func (h *TransactionHandler) HandleReport(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -72,3 +128,277 @@ func (h *TransactionHandler) HandleReport(w http.ResponseWriter, r *http.Request
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(report)
 }
+
+// HandleTaxReport - GET /api/report/tax?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD,
+// default hari ini
+func (h *TransactionHandler) HandleTaxReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	startDate, endDate, err := reportDateRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.service.GetTaxSummary(startDate, endDate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// HandleProfitReport - GET /api/report/profit?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD,
+// laba kotor per produk dan per category, default hari ini
+func (h *TransactionHandler) HandleProfitReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	startDate, endDate, err := reportDateRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.service.GetProfitReport(startDate, endDate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// reportDateRange - start_date dan end_date (YYYY-MM-DD) laporan pajak / laba,
+// yang kosong default hari ini. Dikembalikan sebagai batas awal dan akhir hari.
+func reportDateRange(r *http.Request) (string, string, error) {
+	today := time.Now().Format("2006-01-02")
+	startDate, endDate := today, today
+	if v := r.URL.Query().Get("start_date"); v != "" {
+		startDate = v
+	}
+	if v := r.URL.Query().Get("end_date"); v != "" {
+		endDate = v
+	}
+	for _, v := range []string{startDate, endDate} {
+		if _, err := time.Parse("2006-01-02", v); err != nil {
+			return "", "", errors.New("Invalid date, format YYYY-MM-DD")
+		}
+	}
+	return startDate + " 00:00:00", endDate + " 23:59:59", nil
+}
+
+// HandleTransactions - GET /api/transactions
+func (h *TransactionHandler) HandleTransactions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.List(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// List - GET /api/transactions?start_date=&end_date=&min_amount=&max_amount=&product_id=&location_id=&page=&limit=
+func (h *TransactionHandler) List(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.TransactionFilter{Page: 1, Limit: 20}
+
+	if v := query.Get("start_date"); v != "" {
+		if _, err := time.Parse("2006-01-02", v); err != nil {
+			http.Error(w, "Invalid start_date, format YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		filter.StartDate = v + " 00:00:00"
+	}
+	if v := query.Get("end_date"); v != "" {
+		if _, err := time.Parse("2006-01-02", v); err != nil {
+			http.Error(w, "Invalid end_date, format YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		filter.EndDate = v + " 23:59:59"
+	}
+
+	intParams := []struct {
+		name  string
+		apply func(n int)
+		min   int
+	}{
+		{"min_amount", func(n int) { filter.MinAmount = &n }, 0},
+		{"max_amount", func(n int) { filter.MaxAmount = &n }, 0},
+		{"product_id", func(n int) { filter.ProductID = n }, 1},
+		{"location_id", func(n int) { filter.LocationID = n }, 1},
+		{"page", func(n int) { filter.Page = n }, 1},
+		{"limit", func(n int) { filter.Limit = n }, 1},
+	}
+	for _, p := range intParams {
+		v := query.Get(p.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < p.min {
+			http.Error(w, "Invalid "+p.name, http.StatusBadRequest)
+			return
+		}
+		p.apply(n)
+	}
+	if filter.Limit > 100 {
+		filter.Limit = 100
+	}
+
+	list, err := h.service.ListTransactions(filter)
+	if errors.Is(err, services.ErrInvalidPage) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// HandleTransactionByID - GET /api/transactions/{id}, GET/POST /api/transactions/{id}/returns,
+// POST /api/transactions/{id}/void
+func (h *TransactionHandler) HandleTransactionByID(w http.ResponseWriter, r *http.Request) {
+	id, action, err := parseTransactionPath(r.URL.Path)
+	if err != nil {
+		http.Error(w, "Invalid transaction ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetByID(w, r, id)
+	case action == "returns" && r.Method == http.MethodGet:
+		h.ListReturns(w, r, id)
+	case action == "returns" && r.Method == http.MethodPost:
+		h.CreateReturn(w, r, id)
+	case action == "void" && r.Method == http.MethodPost:
+		h.Void(w, r, id)
+	case action != "" && action != "returns" && action != "void":
+		http.NotFound(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// requestUser - operator yang melakukan aksi, dari header X-User (boleh kosong)
+func requestUser(r *http.Request) string {
+	return strings.TrimSpace(r.Header.Get("X-User"))
+}
+
+// parseTransactionPath - pecah /api/transactions/{id}[/{action}]
+func parseTransactionPath(path string) (int, string, error) {
+	rest := strings.TrimPrefix(path, "/api/transactions/")
+	idStr, action, _ := strings.Cut(rest, "/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return 0, "", err
+	}
+	return id, strings.TrimSuffix(action, "/"), nil
+}
+
+// GetByID - GET /api/transactions/{id}
+func (h *TransactionHandler) GetByID(w http.ResponseWriter, r *http.Request, id int) {
+	transaction, err := h.service.GetTransactionByID(id)
+	if errors.Is(err, services.ErrTransactionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transaction)
+}
+
+// CreateReturn - POST /api/transactions/{id}/returns, items kosong = retur penuh
+func (h *TransactionHandler) CreateReturn(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.ReturnRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.User = requestUser(r)
+	ret, err := h.service.CreateReturn(id, req)
+	if errors.Is(err, services.ErrTransactionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, services.ErrTransactionVoided) || errors.Is(err, services.ErrSerialUnavailable) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ret)
+}
+
+// ListReturns - GET /api/transactions/{id}/returns
+func (h *TransactionHandler) ListReturns(w http.ResponseWriter, r *http.Request, id int) {
+	returns, err := h.service.ListReturns(id)
+	if errors.Is(err, services.ErrTransactionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(returns)
+}
+
+// Void - POST /api/transactions/{id}/void, header X-Manager-Key untuk void di luar batas waktu
+func (h *TransactionHandler) Void(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.VoidRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	transaction, err := h.service.Void(id, req.Reason, r.Header.Get("X-Manager-Key"), requestUser(r))
+	switch {
+	case errors.Is(err, services.ErrVoidReasonRequired):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, services.ErrVoidNotAuthorized):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case errors.Is(err, services.ErrTransactionNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, services.ErrTransactionVoided), errors.Is(err, services.ErrTransactionHasReturns),
+		errors.Is(err, services.ErrSerialUnavailable):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transaction)
+}
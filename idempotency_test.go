@@ -0,0 +1,66 @@
+package main
+
+import (
+	"kasir-api/models"
+	"net/http"
+	"testing"
+)
+
+func TestCheckoutIdempotencyKey(t *testing.T) {
+	s := newTestServer(t)
+
+	category := s.createCategory("Makanan")
+	indomie := s.createProduct("Indomie Goreng", 3500, 10, category.ID)
+
+	headers := map[string]string{"X-Api-Key": testAPIKey, "Idempotency-Key": "tablet-1-0001"}
+	req := models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: indomie.ID, Quantity: 2}}}
+
+	rec := s.do(http.MethodPost, "/api/checkout", req, headers)
+	expectStatus(t, rec, http.StatusOK)
+	first := decodeJSON[models.Transaction](t, rec)
+
+	// retry setelah koneksi putus: transaksi yang sama, stok tidak berkurang lagi
+	rec = s.do(http.MethodPost, "/api/checkout", req, headers)
+	expectStatus(t, rec, http.StatusOK)
+	replayed := decodeJSON[models.Transaction](t, rec)
+	if replayed.ID != first.ID || replayed.TotalAmount != first.TotalAmount {
+		t.Errorf("replayed transaction = %+v, want %+v", replayed, first)
+	}
+	if rec.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("expected Idempotent-Replayed header on replay")
+	}
+	if stock := s.productStock(indomie.ID); stock != 8 {
+		t.Errorf("stock = %d, want 8", stock)
+	}
+
+	// key sama, body beda
+	other := models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: indomie.ID, Quantity: 3}}}
+	rec = s.do(http.MethodPost, "/api/checkout", other, headers)
+	expectStatus(t, rec, http.StatusConflict)
+	if stock := s.productStock(indomie.ID); stock != 8 {
+		t.Errorf("stock after conflict = %d, want 8", stock)
+	}
+}
+
+func TestCheckoutIdempotencyKeyReleasedOnFailure(t *testing.T) {
+	s := newTestServer(t)
+
+	category := s.createCategory("Makanan")
+	indomie := s.createProduct("Indomie Goreng", 3500, 1, category.ID)
+
+	headers := map[string]string{"X-Api-Key": testAPIKey, "Idempotency-Key": "tablet-1-0002"}
+	req := models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: indomie.ID, Quantity: 2}}}
+
+	rec := s.do(http.MethodPost, "/api/checkout", req, headers)
+	expectStatus(t, rec, http.StatusConflict)
+
+	// stok ditambah, retry dengan key yang sama harus diproses ulang
+	rec = s.doAuth(http.MethodPut, "/api/product/1", models.Product{Name: indomie.Name, Price: indomie.Price, Stock: 5, LocationID: models.DefaultLocationID})
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = s.do(http.MethodPost, "/api/checkout", req, headers)
+	expectStatus(t, rec, http.StatusOK)
+	if stock := s.productStock(indomie.ID); stock != 3 {
+		t.Errorf("stock = %d, want 3", stock)
+	}
+}
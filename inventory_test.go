@@ -0,0 +1,225 @@
+package main
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestStockAdjustmentBatch(t *testing.T) {
+	s := newTestServer(t)
+
+	category := s.createCategory("Makanan")
+	indomie := s.createProduct("Indomie Goreng", 3500, 10, category.ID)
+	roti := s.createProduct("Roti Tawar", 15000, 2, category.ID)
+
+	rec := s.doAuth(http.MethodPost, "/api/inventory/adjustments", models.StockAdjustmentRequest{Reason: "hilang"})
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	// roti akan minus, seluruh batch batal termasuk indomie
+	rec = s.doAuth(http.MethodPost, "/api/inventory/adjustments", models.StockAdjustmentRequest{
+		Reason: models.AdjustmentDamaged,
+		Items: []models.StockAdjustmentItem{
+			{ProductID: indomie.ID, Quantity: -2},
+			{ProductID: roti.ID, Quantity: -3},
+		},
+	})
+	expectStatus(t, rec, http.StatusConflict)
+	if stock := s.productStock(indomie.ID); stock != 10 {
+		t.Errorf("indomie stock = %d, want 10 after rejected batch", stock)
+	}
+
+	rec = s.do(http.MethodPost, "/api/inventory/adjustments", models.StockAdjustmentRequest{
+		Reason: models.AdjustmentDamaged,
+		Note:   "kemasan sobek",
+		Items: []models.StockAdjustmentItem{
+			{ProductID: roti.ID, Quantity: -2},
+			{ProductID: indomie.ID, Quantity: 5},
+		},
+	}, map[string]string{"X-Api-Key": testAPIKey, "X-User": "budi"})
+	expectStatus(t, rec, http.StatusCreated)
+	adj := decodeJSON[models.StockAdjustment](t, rec)
+	if adj.ID == 0 || adj.User != "budi" || adj.Items[0].StockAfter != 0 || adj.Items[1].StockAfter != 15 {
+		t.Errorf("adjustment = %+v", adj)
+	}
+
+	movements := s.stockMovements(roti.ID)
+	last := movements[len(movements)-1]
+	if last.Delta != -2 || last.ReferenceType != models.StockRefAdjustment || last.ReferenceID != adj.ID || last.Note != "damaged: kemasan sobek" {
+		t.Errorf("movement = %+v", last)
+	}
+
+	rec = s.doAuth(http.MethodPost, "/api/inventory/adjustments", models.StockAdjustmentRequest{
+		Reason: models.AdjustmentFound,
+		Items:  []models.StockAdjustmentItem{{ProductID: 999, Quantity: 1}},
+	})
+	expectStatus(t, rec, http.StatusBadRequest)
+}
+
+func TestStockAdjustmentAllowNegative(t *testing.T) {
+	s := newTestServerWithConfig(t, Config{
+		APIKey:             testAPIKey,
+		CheckoutLockMode:   string(repositories.LockPessimistic),
+		AllowNegativeStock: true,
+	})
+
+	category := s.createCategory("Makanan")
+	indomie := s.createProduct("Indomie Goreng", 3500, 1, category.ID)
+
+	rec := s.doAuth(http.MethodPost, "/api/inventory/adjustments", models.StockAdjustmentRequest{
+		Reason: models.AdjustmentCorrection,
+		Items:  []models.StockAdjustmentItem{{ProductID: indomie.ID, Quantity: -3}},
+	})
+	expectStatus(t, rec, http.StatusCreated)
+	if stock := s.productStock(indomie.ID); stock != -2 {
+		t.Errorf("stock = %d, want -2", stock)
+	}
+}
+
+// setReorder - PUT produk dengan reorder point, stok ikut dikirim supaya tidak berubah
+func (s *testServer) setReorder(p models.Product, point, quantity, supplierID int) {
+	s.t.Helper()
+
+	p.ReorderPoint, p.ReorderQuantity, p.SupplierID = point, quantity, supplierID
+	rec := s.doAuth(http.MethodPut, "/api/product/"+strconv.Itoa(p.ID), p)
+	expectStatus(s.t, rec, http.StatusOK)
+}
+
+func TestLowStockReport(t *testing.T) {
+	s := newTestServer(t)
+
+	makanan := s.createCategory("Makanan")
+	minuman := s.createCategory("Minuman")
+	indofood := s.createSupplier("PT Indofood")
+	indomie := s.createProduct("Indomie Goreng", 3500, 10, makanan.ID)
+	aqua := s.createProduct("Aqua 600ml", 4000, 3, minuman.ID)
+	roti := s.createProduct("Roti Tawar", 15000, 0, makanan.ID)
+
+	s.setReorder(indomie, 5, 40, indofood.ID)
+	s.setReorder(aqua, 5, 24, 0)
+
+	rec := s.doAuth(http.MethodPut, "/api/product/"+strconv.Itoa(roti.ID), models.Product{Name: roti.Name, ReorderPoint: -1})
+	expectStatus(t, rec, http.StatusBadRequest)
+	rec = s.doAuth(http.MethodPut, "/api/product/"+strconv.Itoa(roti.ID), models.Product{Name: roti.Name, SupplierID: 999})
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	// roti stok 0 tapi tanpa reorder point, tidak dipantau
+	rec = s.doAuth(http.MethodGet, "/api/inventory/low-stock", nil)
+	expectStatus(t, rec, http.StatusOK)
+	report := decodeJSON[models.LowStockReport](t, rec)
+	if report.GroupBy != models.LowStockByCategory || report.TotalProducts != 1 || len(report.Groups) != 1 ||
+		report.Groups[0].Name != "Minuman" || report.Groups[0].Products[0].ProductID != aqua.ID {
+		t.Fatalf("report = %+v", report)
+	}
+
+	s.checkout(models.CheckoutItem{ProductID: indomie.ID, Quantity: 5})
+
+	rec = s.doAuth(http.MethodGet, "/api/inventory/low-stock?group_by=supplier", nil)
+	expectStatus(t, rec, http.StatusOK)
+	report = decodeJSON[models.LowStockReport](t, rec)
+	if report.TotalProducts != 2 || len(report.Groups) != 2 {
+		t.Fatalf("report = %+v", report)
+	}
+	first, last := report.Groups[0], report.Groups[1]
+	if first.ID != indofood.ID || first.Name != "PT Indofood" || first.Products[0].ProductID != indomie.ID ||
+		first.Products[0].Stock != 5 || first.Products[0].ReorderQuantity != 40 {
+		t.Errorf("supplier group = %+v", first)
+	}
+	if last.ID != 0 || last.Products[0].ProductID != aqua.ID {
+		t.Errorf("tanpa supplier group = %+v", last)
+	}
+
+	rec = s.doAuth(http.MethodGet, "/api/inventory/low-stock?group_by=brand", nil)
+	expectStatus(t, rec, http.StatusBadRequest)
+}
+
+func TestProductUpdateKeepsOmittedFields(t *testing.T) {
+	s := newTestServer(t)
+
+	category := s.createCategory("Makanan")
+	indofood := s.createSupplier("PT Indofood")
+	indomie := s.createProduct("Indomie Goreng", 3500, 10, category.ID)
+	s.setReorder(indomie, 5, 40, indofood.ID)
+	path := "/api/product/" + strconv.Itoa(indomie.ID)
+
+	// stok, supplier dan reorder tidak dikirim: nilai lama tetap
+	rec := s.doAuth(http.MethodPut, path, map[string]any{"name": "Indomie Goreng Jumbo", "price": 4000})
+	expectStatus(t, rec, http.StatusOK)
+	if got := decodeJSON[models.Product](t, rec); got.SupplierID != indofood.ID || got.ReorderPoint != 5 || got.ReorderQuantity != 40 {
+		t.Errorf("update response = %+v", got)
+	}
+	got := s.productLocations(indomie.ID)
+	if got.Stock != 10 || len(s.stockMovements(indomie.ID)) != 1 {
+		t.Errorf("stock after update without stock = %d, movements %+v", got.Stock, s.stockMovements(indomie.ID))
+	}
+	if got.Name != "Indomie Goreng Jumbo" || got.SupplierID != indofood.ID || got.ReorderPoint != 5 || got.ReorderQuantity != 40 {
+		t.Errorf("product after update = %+v", got)
+	}
+
+	// dikirim eksplisit: 0 melepas supplier dan berhenti memantau stok
+	rec = s.doAuth(http.MethodPut, path, map[string]any{"name": "Indomie Goreng Jumbo", "price": 4000, "stock": 10, "supplier_id": 0, "reorder_point": 0})
+	expectStatus(t, rec, http.StatusOK)
+	got = s.productLocations(indomie.ID)
+	if got.SupplierID != 0 || got.ReorderPoint != 0 || got.ReorderQuantity != 40 {
+		t.Errorf("product after clearing = %+v", got)
+	}
+
+	rec = s.doAuth(http.MethodPut, path, map[string]any{"name": "Indomie Goreng Jumbo", "stock": 10, "reorder_quantity": -1})
+	expectStatus(t, rec, http.StatusBadRequest)
+}
+
+func TestReorderSuggestions(t *testing.T) {
+	s := newTestServer(t)
+
+	category := s.createCategory("Makanan")
+	rec := s.doAuth(http.MethodPost, "/api/suppliers", models.Supplier{Name: "PT Indofood", LeadTimeDays: -1})
+	expectStatus(t, rec, http.StatusBadRequest)
+	rec = s.doAuth(http.MethodPost, "/api/suppliers", models.Supplier{Name: "PT Indofood", LeadTimeDays: 7})
+	expectStatus(t, rec, http.StatusCreated)
+	indofood := decodeJSON[models.Supplier](t, rec)
+
+	indomie := s.createProduct("Indomie Goreng", 3500, 100, category.ID)
+	s.createProduct("Roti Tawar", 15000, 5, category.ID)
+	s.setReorder(indomie, 0, 0, indofood.ID)
+
+	s.checkout(models.CheckoutItem{ProductID: indomie.ID, Quantity: 30})
+
+	// window 1 hari: velocity 30/hari, stok 70 cukup 2,33 hari < lead time 7 hari.
+	// target 30 x (7 + 14) = 630, roti tidak terjual sehingga tidak disarankan
+	path := "/api/inventory/reorder-suggestions?window_days=1"
+	rec = s.doAuth(http.MethodGet, path, nil)
+	expectStatus(t, rec, http.StatusOK)
+	report := decodeJSON[models.ReorderSuggestionReport](t, rec)
+	if report.WindowDays != 1 || report.CoverDays != 14 || len(report.Suggestions) != 1 {
+		t.Fatalf("report = %+v", report)
+	}
+	got := report.Suggestions[0]
+	if got.ProductID != indomie.ID || got.SupplierName != "PT Indofood" || got.LeadTimeDays != 7 || got.SmoothedVelocity != 30 ||
+		got.DaysOfCover == nil || *got.DaysOfCover != 2.33 || !got.Urgent || got.TargetStock != 630 || got.SuggestedQuantity != 560 {
+		t.Errorf("suggestion = %+v", got)
+	}
+
+	// PO yang sudah dikirim ikut mengurangi saran
+	rec = s.doAuth(http.MethodPost, "/api/purchase-orders", models.PurchaseOrderRequest{
+		SupplierID: indofood.ID,
+		Lines:      []models.PurchaseOrderLineRequest{{ProductID: indomie.ID, Quantity: 200, UnitCost: 3000}},
+	})
+	expectStatus(t, rec, http.StatusCreated)
+	po := decodeJSON[models.PurchaseOrder](t, rec)
+	rec = s.doAuth(http.MethodPost, "/api/purchase-orders/"+strconv.Itoa(po.ID)+"/send", nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = s.doAuth(http.MethodGet, path, nil)
+	expectStatus(t, rec, http.StatusOK)
+	report = decodeJSON[models.ReorderSuggestionReport](t, rec)
+	if len(report.Suggestions) != 1 || report.Suggestions[0].OnOrder != 200 || report.Suggestions[0].SuggestedQuantity != 360 {
+		t.Errorf("suggestions = %+v", report.Suggestions)
+	}
+
+	for _, query := range []string{"?alpha=1.5", "?window_days=400", "?window_days=abc", "?cover_days=-3"} {
+		rec = s.doAuth(http.MethodGet, "/api/inventory/reorder-suggestions"+query, nil)
+		expectStatus(t, rec, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"kasir-api/models"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func (s *testServer) createLocation(name, locationType string) models.Location {
+	s.t.Helper()
+
+	rec := s.doAuth(http.MethodPost, "/api/locations", models.Location{Name: name, Type: locationType})
+	expectStatus(s.t, rec, http.StatusCreated)
+	return decodeJSON[models.Location](s.t, rec)
+}
+
+func (s *testServer) productLocations(id int) models.ProductResponse {
+	s.t.Helper()
+
+	rec := s.doAuth(http.MethodGet, "/api/product/"+strconv.Itoa(id), nil)
+	expectStatus(s.t, rec, http.StatusOK)
+	return decodeJSON[models.ProductResponse](s.t, rec)
+}
+
+// locationStock - stok dan in-transit satu lokasi dari response produk
+func locationStock(p models.ProductResponse, locationID int) (int, int) {
+	for _, l := range p.Locations {
+		if l.LocationID == locationID {
+			return l.Stock, l.InTransit
+		}
+	}
+	return 0, 0
+}
+
+func TestLocationCRUD(t *testing.T) {
+	s := newTestServer(t)
+
+	rec := s.doAuth(http.MethodPost, "/api/locations", models.Location{Name: "Gudang", Type: "kantor"})
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	gudang := s.createLocation("Gudang Pusat", models.LocationWarehouse)
+	rec = s.doAuth(http.MethodGet, "/api/locations", nil)
+	expectStatus(t, rec, http.StatusOK)
+	if locations := decodeJSON[[]models.Location](t, rec); len(locations) != 2 || locations[0].ID != models.DefaultLocationID {
+		t.Errorf("locations = %+v, want default location + gudang", locations)
+	}
+
+	path := "/api/locations/" + strconv.Itoa(gudang.ID)
+	rec = s.doAuth(http.MethodPut, path, models.Location{Name: "Gudang Cikarang", Type: models.LocationWarehouse})
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = s.doAuth(http.MethodDelete, "/api/locations/"+strconv.Itoa(models.DefaultLocationID), nil)
+	expectStatus(t, rec, http.StatusConflict)
+
+	// lokasi yang sudah punya stok tidak bisa dihapus
+	category := s.createCategory("Sembako")
+	gula := s.createProduct("Gula 1kg", 15000, 0, category.ID)
+	rec = s.doAuth(http.MethodPost, "/api/inventory/adjustments", models.StockAdjustmentRequest{
+		LocationID: gudang.ID,
+		Reason:     models.AdjustmentFound,
+		Items:      []models.StockAdjustmentItem{{ProductID: gula.ID, Quantity: 5}},
+	})
+	expectStatus(t, rec, http.StatusCreated)
+	rec = s.doAuth(http.MethodDelete, path, nil)
+	expectStatus(t, rec, http.StatusConflict)
+
+	kosong := s.createLocation("Outlet Lama", models.LocationOutlet)
+	rec = s.doAuth(http.MethodDelete, "/api/locations/"+strconv.Itoa(kosong.ID), nil)
+	expectStatus(t, rec, http.StatusOK)
+	rec = s.doAuth(http.MethodGet, "/api/locations/"+strconv.Itoa(kosong.ID), nil)
+	expectStatus(t, rec, http.StatusNotFound)
+}
+
+func TestCheckoutPerLocation(t *testing.T) {
+	s := newVoidTestServer(t, time.Hour)
+
+	outlet := s.createLocation("Outlet Kemang", models.LocationOutlet)
+	category := s.createCategory("Makanan")
+	indomie := s.createProduct("Indomie Goreng", 3500, 10, category.ID)
+
+	// stok hanya ada di lokasi bawaan, outlet baru belum punya stok
+	rec := s.doAuth(http.MethodPost, "/api/checkout", models.CheckoutRequest{
+		LocationID: outlet.ID,
+		Items:      []models.CheckoutItem{{ProductID: indomie.ID, Quantity: 1}},
+	})
+	expectStatus(t, rec, http.StatusConflict)
+
+	rec = s.doAuth(http.MethodPost, "/api/checkout", models.CheckoutRequest{
+		LocationID: 999,
+		Items:      []models.CheckoutItem{{ProductID: indomie.ID, Quantity: 1}},
+	})
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	trx := s.checkout(models.CheckoutItem{ProductID: indomie.ID, Quantity: 3})
+	if trx.LocationID != models.DefaultLocationID {
+		t.Errorf("transaction location = %d, want default", trx.LocationID)
+	}
+	product := s.productLocations(indomie.ID)
+	if stock, _ := locationStock(product, models.DefaultLocationID); product.Stock != 7 || stock != 7 {
+		t.Errorf("stock = %d (default location %d), want 7", product.Stock, stock)
+	}
+
+	// void mengembalikan stok ke lokasi penjualan
+	rec = s.doAuth(http.MethodPost, "/api/transactions/"+strconv.Itoa(trx.ID)+"/void", models.VoidRequest{Reason: "salah input"})
+	expectStatus(t, rec, http.StatusOK)
+	if stock, _ := locationStock(s.productLocations(indomie.ID), models.DefaultLocationID); stock != 10 {
+		t.Errorf("default location stock after void = %d, want 10", stock)
+	}
+
+	rec = s.doAuth(http.MethodGet, "/api/transactions?location_id="+strconv.Itoa(outlet.ID), nil)
+	expectStatus(t, rec, http.StatusOK)
+	if list := decodeJSON[models.TransactionList](t, rec); list.Total != 0 {
+		t.Errorf("outlet transactions = %d, want 0", list.Total)
+	}
+}
+
+func TestStockTransferLifecycle(t *testing.T) {
+	s := newTestServer(t)
+
+	gudang := s.createLocation("Gudang Pusat", models.LocationWarehouse)
+	outlet := s.createLocation("Outlet Kemang", models.LocationOutlet)
+	category := s.createCategory("Sembako")
+	gula := s.createProduct("Gula 1kg", 15000, 2, category.ID)
+
+	rec := s.doAuth(http.MethodPost, "/api/inventory/adjustments", models.StockAdjustmentRequest{
+		LocationID: gudang.ID,
+		Reason:     models.AdjustmentFound,
+		Items:      []models.StockAdjustmentItem{{ProductID: gula.ID, Quantity: 20}},
+	})
+	expectStatus(t, rec, http.StatusCreated)
+
+	rec = s.doAuth(http.MethodPost, "/api/inventory/transfers", models.StockTransferRequest{
+		FromLocationID: gudang.ID,
+		ToLocationID:   gudang.ID,
+		Items:          []models.StockTransferItem{{ProductID: gula.ID, Quantity: 1}},
+	})
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = s.doAuth(http.MethodPost, "/api/inventory/transfers", models.StockTransferRequest{
+		FromLocationID: gudang.ID,
+		ToLocationID:   outlet.ID,
+		Note:           "restock mingguan",
+		Items:          []models.StockTransferItem{{ProductID: gula.ID, Quantity: 15}},
+	})
+	expectStatus(t, rec, http.StatusCreated)
+	transfer := decodeJSON[models.StockTransfer](t, rec)
+	if transfer.Status != models.TransferRequested || transfer.ToLocationName != "Outlet Kemang" || transfer.Lines[0].ProductName != "Gula 1kg" {
+		t.Fatalf("transfer = %+v", transfer)
+	}
+	path := "/api/inventory/transfers/" + strconv.Itoa(transfer.ID)
+
+	rec = s.doAuth(http.MethodPost, path+"/receive", nil)
+	expectStatus(t, rec, http.StatusConflict)
+
+	rec = s.doAuth(http.MethodPost, path+"/ship", nil)
+	expectStatus(t, rec, http.StatusOK)
+	if shipped := decodeJSON[models.StockTransfer](t, rec); shipped.Status != models.TransferShipped || shipped.ShippedAt == nil {
+		t.Errorf("shipped transfer = %+v", shipped)
+	}
+
+	// dalam perjalanan: stok gudang sudah keluar, outlet belum bertambah
+	product := s.productLocations(gula.ID)
+	gudangStock, _ := locationStock(product, gudang.ID)
+	outletStock, outletInTransit := locationStock(product, outlet.ID)
+	if product.Stock != 7 || product.InTransit != 15 || gudangStock != 5 || outletStock != 0 || outletInTransit != 15 {
+		t.Errorf("in transit: total %d in_transit %d gudang %d outlet %d (+%d)",
+			product.Stock, product.InTransit, gudangStock, outletStock, outletInTransit)
+	}
+
+	rec = s.doAuth(http.MethodPost, path+"/cancel", nil)
+	expectStatus(t, rec, http.StatusConflict)
+
+	rec = s.doAuth(http.MethodPost, path+"/receive", nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	product = s.productLocations(gula.ID)
+	outletStock, outletInTransit = locationStock(product, outlet.ID)
+	if product.Stock != 22 || product.InTransit != 0 || outletStock != 15 || outletInTransit != 0 {
+		t.Errorf("after receive: total %d in_transit %d outlet %d (+%d)", product.Stock, product.InTransit, outletStock, outletInTransit)
+	}
+
+	// sekarang outlet bisa menjual
+	rec = s.doAuth(http.MethodPost, "/api/checkout", models.CheckoutRequest{
+		LocationID: outlet.ID,
+		Items:      []models.CheckoutItem{{ProductID: gula.ID, Quantity: 4}},
+	})
+	expectStatus(t, rec, http.StatusOK)
+	if outletStock, _ = locationStock(s.productLocations(gula.ID), outlet.ID); outletStock != 11 {
+		t.Errorf("outlet stock after sale = %d, want 11", outletStock)
+	}
+
+	// stok gudang tinggal 5, transfer 6 gagal saat dikirim
+	rec = s.doAuth(http.MethodPost, "/api/inventory/transfers", models.StockTransferRequest{
+		FromLocationID: gudang.ID,
+		ToLocationID:   outlet.ID,
+		Items:          []models.StockTransferItem{{ProductID: gula.ID, Quantity: 6}},
+	})
+	expectStatus(t, rec, http.StatusCreated)
+	second := decodeJSON[models.StockTransfer](t, rec)
+	rec = s.doAuth(http.MethodPost, "/api/inventory/transfers/"+strconv.Itoa(second.ID)+"/ship", nil)
+	expectStatus(t, rec, http.StatusConflict)
+	rec = s.doAuth(http.MethodPost, "/api/inventory/transfers/"+strconv.Itoa(second.ID)+"/cancel", nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	movements := s.stockMovements(gula.ID)
+	var transferMoves int
+	for _, m := range movements {
+		if m.Reason == models.StockReasonTransfer && m.ReferenceID == transfer.ID {
+			transferMoves++
+		}
+	}
+	if transferMoves != 2 {
+		t.Errorf("transfer movements = %d, want 2 (ship + receive)", transferMoves)
+	}
+
+	rec = s.doAuth(http.MethodGet, "/api/inventory/transfers?status=cancelled&location_id="+strconv.Itoa(outlet.ID), nil)
+	expectStatus(t, rec, http.StatusOK)
+	if list := decodeJSON[[]models.StockTransfer](t, rec); len(list) != 1 || list[0].ID != second.ID {
+		t.Errorf("cancelled transfers = %+v", list)
+	}
+}
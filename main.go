@@ -1,17 +1,17 @@
 package main
 
 import (
-	"encoding/json"
+	"database/sql"
 	"fmt"
 	"kasir-api/database"
-	"kasir-api/handlers"
-	"kasir-api/middleware"
 	"kasir-api/repositories"
 	"kasir-api/services"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -20,12 +20,39 @@ type Config struct {
 	Port   string `mapstructure:"PORT"`
 	DBConn string `mapstructure:"DB_CONN"`
 	APIKey string `mapstructure:"API_KEY"`
+
+	AutoMigrate bool `mapstructure:"AUTO_MIGRATE"`
+
+	// pessimistic (default) atau optimistic
+	CheckoutLockMode string `mapstructure:"CHECKOUT_LOCK_MODE"`
+
+	// Void transaksi: kasir dalam VOID_WINDOW, di luar itu pakai MANAGER_KEY
+	VoidWindow time.Duration `mapstructure:"VOID_WINDOW"`
+	ManagerKey string        `mapstructure:"MANAGER_KEY"`
+
+	// Harga jual termasuk PPN (inclusive, default) atau belum (exclusive)
+	TaxPriceMode string `mapstructure:"TAX_PRICE_MODE"`
+
+	// Stock adjustment boleh membuat stok minus (default false)
+	AllowNegativeStock bool `mapstructure:"ALLOW_NEGATIVE_STOCK"`
+
+	// Default saran reorder, 0 = default service (28 hari, alpha 0.3, cover 14 hari)
+	ReorderWindowDays int     `mapstructure:"REORDER_WINDOW_DAYS"`
+	ReorderAlpha      float64 `mapstructure:"REORDER_ALPHA"`
+	ReorderCoverDays  int     `mapstructure:"REORDER_COVER_DAYS"`
+
+	// Metode HPP stok keluar: average (moving weighted average, default) atau fifo
+	CostingMethod string `mapstructure:"COSTING_METHOD"`
+
+	// Prefix EAN-13 internal untuk produk tanpa barcode pabrik, kosong = 200
+	BarcodePrefix string `mapstructure:"BARCODE_PREFIX"`
 }
 
 func main() {
 	// Konfigurasi Environment
 	viper.AutomaticEnv()
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.SetDefault("VOID_WINDOW", "15m")
 
 	if _, err := os.Stat(".env"); err == nil {
 		viper.SetConfigFile(".env")
@@ -36,63 +63,146 @@ func main() {
 		Port:   viper.GetString("PORT"),
 		DBConn: viper.GetString("DB_CONN"),
 		APIKey: viper.GetString("API_KEY"),
-	}
 
-	//Init Database
-	db, err := database.InitDB(config.DBConn)
-	if err != nil {
-		log.Fatal("Failed to initialize database:", err)
-	}
-	defer db.Close()
+		AutoMigrate: viper.GetBool("AUTO_MIGRATE"),
+
+		CheckoutLockMode: viper.GetString("CHECKOUT_LOCK_MODE"),
+
+		VoidWindow: viper.GetDuration("VOID_WINDOW"),
+		ManagerKey: viper.GetString("MANAGER_KEY"),
+
+		TaxPriceMode: viper.GetString("TAX_PRICE_MODE"),
 
-	// Setup Middleware & Dependency Injection
-	apiKeyMiddleware := middleware.APIKey(config.APIKey)
+		AllowNegativeStock: viper.GetBool("ALLOW_NEGATIVE_STOCK"),
 
-	productRepo := repositories.NewProductRepository(db)
-	productService := services.NewProductService(productRepo)
-	productHandler := handlers.NewProductHandler(productService)
+		ReorderWindowDays: viper.GetInt("REORDER_WINDOW_DAYS"),
+		ReorderAlpha:      viper.GetFloat64("REORDER_ALPHA"),
+		ReorderCoverDays:  viper.GetInt("REORDER_COVER_DAYS"),
+
+		CostingMethod: viper.GetString("COSTING_METHOD"),
+
+		BarcodePrefix: viper.GetString("BARCODE_PREFIX"),
+	}
+
+	if config.ManagerKey != "" && config.ManagerKey == config.APIKey {
+		log.Fatal("Invalid config: MANAGER_KEY tidak boleh sama dengan API_KEY")
+	}
 
-	categoryRepo := repositories.NewCategoryRepository(db)
-	categoryService := services.NewCategoryService(categoryRepo)
-	categoryHandler := handlers.NewCategoryHandler(categoryService)
+	lockStrategy, err := repositories.ParseLockStrategy(config.CheckoutLockMode)
+	if err != nil {
+		log.Fatal("Invalid config:", err)
+	}
+	config.CheckoutLockMode = string(lockStrategy)
 
-	transactionRepo := repositories.NewTransactionRepository(db)
-	transactionService := services.NewTransactionService(transactionRepo)
-	transactionHandler := handlers.NewTransactionHandler(transactionService)
+	costingMethod, err := repositories.ParseCostingMethod(config.CostingMethod)
+	if err != nil {
+		log.Fatal("Invalid config:", err)
+	}
+	config.CostingMethod = string(costingMethod)
 
-	// Setup Routes
+	taxPriceMode, err := services.ParseTaxPriceMode(config.TaxPriceMode)
+	if err != nil {
+		log.Fatal("Invalid config:", err)
+	}
+	config.TaxPriceMode = taxPriceMode
 
-	// -- Product --
-	http.HandleFunc("/api/product", productHandler.HandleProducts)
-	http.HandleFunc("/api/product/", middleware.Logger(apiKeyMiddleware(productHandler.HandleProductByID)))
+	barcodePrefix, err := services.ParseBarcodePrefix(config.BarcodePrefix)
+	if err != nil {
+		log.Fatal("Invalid config:", err)
+	}
+	config.BarcodePrefix = barcodePrefix
 
-	// -- Category --
-	http.HandleFunc("/api/category", categoryHandler.HandleCategories)
-	http.HandleFunc("/api/category/", middleware.Logger(apiKeyMiddleware(categoryHandler.HandleCategoryByID)))
+	//Init Database
+	db, err := database.InitDB(config.DBConn)
+	if err != nil {
+		log.Fatal("Failed to initialize database:", err)
+	}
+	defer db.Close()
 
-	// -- Checkout --
-	http.HandleFunc("/api/checkout", middleware.Logger(apiKeyMiddleware(transactionHandler.HandleCheckout)))
+	// Mode migrasi: go run . migrate up|down [steps]|status
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(db, os.Args[2:]); err != nil {
+			log.Fatal("Migration failed:", err)
+		}
+		return
+	}
 
-	// -- Report --
-	http.HandleFunc("/api/report/hari-ini", transactionHandler.HandleReport)
-	http.HandleFunc("/api/report", transactionHandler.HandleReport)
+	if config.AutoMigrate {
+		applied, err := database.MigrateUp(db)
+		if err != nil {
+			log.Fatal("Auto migrate failed:", err)
+		}
+		log.Printf("Auto migrate: %d migration diterapkan", applied)
+	}
 
-	// -- Health Check --
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]string{
-			"status":  "OK",
-			"message": "API running",
-		})
-	})
+	// Setup Dependency Injection & Routes
+	router := newRouter(appRepositories{
+		product:     repositories.NewProductRepository(db),
+		category:    repositories.NewCategoryRepository(db),
+		transaction: repositories.NewTransactionRepository(db),
+		idempotency: repositories.NewIdempotencyRepository(db),
+		promotion:   repositories.NewPromotionRepository(db),
+		rule:        repositories.NewPromotionRuleRepository(db),
+		taxRate:     repositories.NewTaxRateRepository(db),
+		movement:    repositories.NewStockMovementRepository(db),
+		inventory:   repositories.NewInventoryRepository(db),
+		stockCount:  repositories.NewStockCountRepository(db),
+		supplier:    repositories.NewSupplierRepository(db),
+		purchase:    repositories.NewPurchaseOrderRepository(db),
+		location:    repositories.NewLocationRepository(db),
+		transfer:    repositories.NewStockTransferRepository(db),
+	}, config)
 
 	addr := "0.0.0.0:" + config.Port
 	fmt.Println("Server running di", addr)
 
-	globalHandler := middleware.CORS(http.DefaultServeMux)
-
-	err = http.ListenAndServe(addr, globalHandler)
+	err = http.ListenAndServe(addr, router)
 	if err != nil {
 		fmt.Println("gagal running server", err)
 	}
 }
+
+func runMigrate(db *sql.DB, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up|down [steps]|status")
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := database.MigrateUp(db)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d migration diterapkan\n", applied)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("steps harus angka >= 1")
+			}
+			steps = n
+		}
+		rolledBack, err := database.MigrateDown(db, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d migration di-rollback\n", rolledBack)
+	case "status":
+		statuses, err := database.GetMigrationStatus(db)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-40s %s\n", s.Version, s.Name, state)
+		}
+	default:
+		return fmt.Errorf("perintah migrate tidak dikenal: %s", args[0])
+	}
+
+	return nil
+}
@@ -0,0 +1,395 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testAPIKey = "test-key"
+
+type testServer struct {
+	t       *testing.T
+	handler http.Handler
+	store   *repositories.MemoryStore
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	return newTestServerWithConfig(t, Config{APIKey: testAPIKey, CheckoutLockMode: string(repositories.LockPessimistic)})
+}
+
+func newTestServerWithConfig(t *testing.T, config Config) *testServer {
+	t.Helper()
+
+	store := repositories.NewMemoryStore()
+	router := newRouter(appRepositories{
+		product:     repositories.NewMemoryProductRepository(store),
+		category:    repositories.NewMemoryCategoryRepository(store),
+		transaction: repositories.NewMemoryTransactionRepository(store),
+		idempotency: repositories.NewMemoryIdempotencyRepository(store),
+		promotion:   repositories.NewMemoryPromotionRepository(store),
+		rule:        repositories.NewMemoryPromotionRuleRepository(store),
+		taxRate:     repositories.NewMemoryTaxRateRepository(store),
+		movement:    repositories.NewMemoryStockMovementRepository(store),
+		inventory:   repositories.NewMemoryInventoryRepository(store),
+		stockCount:  repositories.NewMemoryStockCountRepository(store),
+		supplier:    repositories.NewMemorySupplierRepository(store),
+		purchase:    repositories.NewMemoryPurchaseOrderRepository(store),
+		location:    repositories.NewMemoryLocationRepository(store),
+		transfer:    repositories.NewMemoryStockTransferRepository(store),
+	}, config)
+
+	return &testServer{t: t, handler: router, store: store}
+}
+
+// do - kirim request ke router. body nil = tanpa body, string = raw, selain itu di-encode JSON
+func (s *testServer) do(method, path string, body any, headers map[string]string) *httptest.ResponseRecorder {
+	s.t.Helper()
+
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		reader = bytes.NewBufferString(b)
+	default:
+		payload, err := json.Marshal(b)
+		if err != nil {
+			s.t.Fatalf("marshal body: %v", err)
+		}
+		reader = bytes.NewBuffer(payload)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// doAuth - sama dengan do, plus header X-Api-Key yang valid
+func (s *testServer) doAuth(method, path string, body any) *httptest.ResponseRecorder {
+	s.t.Helper()
+	return s.do(method, path, body, map[string]string{"X-Api-Key": testAPIKey})
+}
+
+func decodeJSON[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+
+	var v T
+	if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+		t.Fatalf("decode response %q: %v", rec.Body.String(), err)
+	}
+	return v
+}
+
+func expectStatus(t *testing.T, rec *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if rec.Code != want {
+		t.Fatalf("status = %d, want %d, body: %s", rec.Code, want, rec.Body.String())
+	}
+}
+
+func (s *testServer) createCategory(name string) models.Category {
+	s.t.Helper()
+
+	rec := s.do(http.MethodPost, "/api/category", models.Category{Name: name}, nil)
+	expectStatus(s.t, rec, http.StatusCreated)
+	return decodeJSON[models.Category](s.t, rec)
+}
+
+func (s *testServer) createProduct(name string, price float64, stock, categoryID int) models.Product {
+	s.t.Helper()
+
+	rec := s.do(http.MethodPost, "/api/product", models.Product{
+		Name:       name,
+		Price:      price,
+		Stock:      stock,
+		CategoryID: categoryID,
+	}, nil)
+	expectStatus(s.t, rec, http.StatusCreated)
+	return decodeJSON[models.Product](s.t, rec)
+}
+
+func (s *testServer) productStock(id int) int {
+	s.t.Helper()
+
+	rec := s.doAuth(http.MethodGet, "/api/product/"+strconv.Itoa(id), nil)
+	expectStatus(s.t, rec, http.StatusOK)
+	return decodeJSON[models.ProductResponse](s.t, rec).Stock
+}
+
+func TestHealth(t *testing.T) {
+	s := newTestServer(t)
+
+	rec := s.do(http.MethodGet, "/health", nil, nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	body := decodeJSON[map[string]string](t, rec)
+	if body["status"] != "OK" {
+		t.Errorf("status = %q, want OK", body["status"])
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	s := newTestServer(t)
+
+	rec := s.do(http.MethodOptions, "/api/checkout", nil, nil)
+	expectStatus(t, rec, http.StatusOK)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want *", got)
+	}
+}
+
+func TestAPIKeyRequired(t *testing.T) {
+	s := newTestServer(t)
+
+	rec := s.do(http.MethodGet, "/api/product/1", nil, nil)
+	expectStatus(t, rec, http.StatusUnauthorized)
+
+	rec = s.do(http.MethodGet, "/api/product/1", nil, map[string]string{"X-Api-Key": "wrong"})
+	expectStatus(t, rec, http.StatusUnauthorized)
+}
+
+func TestCategoryCRUD(t *testing.T) {
+	s := newTestServer(t)
+
+	created := s.createCategory("Minuman")
+	if created.ID == 0 {
+		t.Fatal("expected category id to be set")
+	}
+
+	rec := s.do(http.MethodGet, "/api/category", nil, nil)
+	expectStatus(t, rec, http.StatusOK)
+	if list := decodeJSON[[]models.Category](t, rec); len(list) != 1 {
+		t.Fatalf("len(categories) = %d, want 1", len(list))
+	}
+
+	path := "/api/category/" + strconv.Itoa(created.ID)
+	rec = s.doAuth(http.MethodPut, path, models.Category{Name: "Minuman Dingin", Description: "es"})
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = s.doAuth(http.MethodGet, path, nil)
+	expectStatus(t, rec, http.StatusOK)
+	if got := decodeJSON[models.Category](t, rec); got.Name != "Minuman Dingin" || got.Description != "es" {
+		t.Errorf("category = %+v, want updated name and description", got)
+	}
+
+	rec = s.doAuth(http.MethodDelete, path, nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = s.doAuth(http.MethodGet, path, nil)
+	expectStatus(t, rec, http.StatusNotFound)
+
+	rec = s.doAuth(http.MethodGet, "/api/category/abc", nil)
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = s.do(http.MethodPatch, "/api/category", nil, nil)
+	expectStatus(t, rec, http.StatusMethodNotAllowed)
+}
+
+func TestProductCRUD(t *testing.T) {
+	s := newTestServer(t)
+
+	category := s.createCategory("Makanan")
+	indomie := s.createProduct("Indomie Goreng", 3500, 10, category.ID)
+	s.createProduct("Roti Tawar", 15000, 5, category.ID)
+
+	rec := s.do(http.MethodGet, "/api/product", nil, nil)
+	expectStatus(t, rec, http.StatusOK)
+	if list := decodeJSON[[]models.Product](t, rec); len(list) != 2 {
+		t.Fatalf("len(products) = %d, want 2", len(list))
+	}
+
+	rec = s.do(http.MethodGet, "/api/product?name=indo", nil, nil)
+	expectStatus(t, rec, http.StatusOK)
+	if list := decodeJSON[[]models.Product](t, rec); len(list) != 1 || list[0].ID != indomie.ID {
+		t.Fatalf("search result = %+v, want only Indomie", list)
+	}
+
+	path := "/api/product/" + strconv.Itoa(indomie.ID)
+	rec = s.doAuth(http.MethodGet, path, nil)
+	expectStatus(t, rec, http.StatusOK)
+	if got := decodeJSON[models.ProductResponse](t, rec); got.CategoryName != "Makanan" {
+		t.Errorf("category_name = %q, want Makanan", got.CategoryName)
+	}
+
+	// stok diubah tanpa lokasi ditolak, tidak ditebak masuk lokasi bawaan
+	rec = s.doAuth(http.MethodPut, path, models.Product{Name: "Indomie Soto", Price: 3600, Stock: 20})
+	expectStatus(t, rec, http.StatusBadRequest)
+	rec = s.doAuth(http.MethodPut, path, models.Product{Name: "Indomie Soto", Price: 3600, Stock: 20, LocationID: models.DefaultLocationID})
+	expectStatus(t, rec, http.StatusOK)
+	if stock := s.productStock(indomie.ID); stock != 20 {
+		t.Errorf("stock = %d, want 20", stock)
+	}
+
+	rec = s.doAuth(http.MethodDelete, path, nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = s.doAuth(http.MethodGet, path, nil)
+	expectStatus(t, rec, http.StatusNotFound)
+
+	rec = s.do(http.MethodPost, "/api/product", "{bad json", nil)
+	expectStatus(t, rec, http.StatusBadRequest)
+}
+
+func TestCheckout(t *testing.T) {
+	s := newTestServer(t)
+
+	category := s.createCategory("Makanan")
+	indomie := s.createProduct("Indomie Goreng", 3500, 10, category.ID)
+	roti := s.createProduct("Roti Tawar", 15000, 5, category.ID)
+
+	rec := s.doAuth(http.MethodPost, "/api/checkout", models.CheckoutRequest{Items: []models.CheckoutItem{
+		{ProductID: indomie.ID, Quantity: 3},
+		{ProductID: roti.ID, Quantity: 1},
+	}})
+	expectStatus(t, rec, http.StatusOK)
+
+	trx := decodeJSON[models.Transaction](t, rec)
+	if trx.TotalAmount != 3*3500+15000 {
+		t.Errorf("total_amount = %d, want %d", trx.TotalAmount, 3*3500+15000)
+	}
+	if len(trx.Details) != 2 {
+		t.Fatalf("len(details) = %d, want 2", len(trx.Details))
+	}
+	if stock := s.productStock(indomie.ID); stock != 7 {
+		t.Errorf("indomie stock = %d, want 7", stock)
+	}
+
+	// stok kurang: seluruh transaksi batal, stok item lain tidak ikut berkurang
+	rec = s.doAuth(http.MethodPost, "/api/checkout", models.CheckoutRequest{Items: []models.CheckoutItem{
+		{ProductID: indomie.ID, Quantity: 1},
+		{ProductID: roti.ID, Quantity: 99},
+	}})
+	expectStatus(t, rec, http.StatusConflict)
+	if stock := s.productStock(indomie.ID); stock != 7 {
+		t.Errorf("indomie stock after failed checkout = %d, want 7", stock)
+	}
+
+	rec = s.doAuth(http.MethodGet, "/api/checkout", nil)
+	expectStatus(t, rec, http.StatusMethodNotAllowed)
+}
+
+func TestReport(t *testing.T) {
+	s := newTestServer(t)
+
+	category := s.createCategory("Makanan")
+	indomie := s.createProduct("Indomie Goreng", 3500, 10, category.ID)
+	roti := s.createProduct("Roti Tawar", 15000, 5, category.ID)
+
+	for _, item := range []models.CheckoutItem{
+		{ProductID: indomie.ID, Quantity: 4},
+		{ProductID: roti.ID, Quantity: 1},
+	} {
+		rec := s.doAuth(http.MethodPost, "/api/checkout", models.CheckoutRequest{Items: []models.CheckoutItem{item}})
+		expectStatus(t, rec, http.StatusOK)
+	}
+
+	for _, path := range []string{"/api/report/hari-ini", "/api/report"} {
+		// laporan memuat laba kotor, tanpa API key ditolak
+		rec := s.do(http.MethodGet, path, nil, nil)
+		expectStatus(t, rec, http.StatusUnauthorized)
+
+		rec = s.doAuth(http.MethodGet, path, nil)
+		expectStatus(t, rec, http.StatusOK)
+
+		report := decodeJSON[models.SalesReport](t, rec)
+		if report.TotalRevenue != 4*3500+15000 || report.TotalTransaction != 2 {
+			t.Errorf("%s: report = %+v", path, report)
+		}
+		if report.TopProduct.Name != "Indomie Goreng" || report.TopProduct.TotalSold != 4 {
+			t.Errorf("%s: top product = %+v", path, report.TopProduct)
+		}
+	}
+
+	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	rec := s.doAuth(http.MethodGet, "/api/report?start_date="+yesterday+"&end_date="+yesterday, nil)
+	expectStatus(t, rec, http.StatusOK)
+	if report := decodeJSON[models.SalesReport](t, rec); report.TotalTransaction != 0 || report.TopProduct.Name != "-" {
+		t.Errorf("report for yesterday = %+v, want empty", report)
+	}
+
+	rec = s.doAuth(http.MethodPost, "/api/report", nil)
+	expectStatus(t, rec, http.StatusMethodNotAllowed)
+}
+
+func TestCheckoutRejectsInvalidQuantity(t *testing.T) {
+	s := newTestServer(t)
+
+	category := s.createCategory("Makanan")
+	indomie := s.createProduct("Indomie Goreng", 3500, 10, category.ID)
+
+	for _, qty := range []int{0, -5} {
+		rec := s.doAuth(http.MethodPost, "/api/checkout", models.CheckoutRequest{Items: []models.CheckoutItem{
+			{ProductID: indomie.ID, Quantity: qty},
+		}})
+		expectStatus(t, rec, http.StatusBadRequest)
+	}
+
+	// items kosong dan produk yang tidak ada juga kesalahan input, bukan 500
+	for _, items := range [][]models.CheckoutItem{nil, {{ProductID: 999, Quantity: 1}}} {
+		rec := s.doAuth(http.MethodPost, "/api/checkout", models.CheckoutRequest{Items: items})
+		expectStatus(t, rec, http.StatusBadRequest)
+	}
+	if stock := s.productStock(indomie.ID); stock != 10 {
+		t.Errorf("stock = %d, want 10", stock)
+	}
+}
+
+// TestCheckoutConcurrentNeverOversells - lewat HTTP dengan MemoryStore (satu
+// mutex). Row lock / UPDATE bersyarat Postgres diuji di
+// repositories.TestPostgresCheckoutConcurrentNeverOversells (butuh TEST_DB_CONN).
+func TestCheckoutConcurrentNeverOversells(t *testing.T) {
+	for _, strategy := range []repositories.LockStrategy{repositories.LockPessimistic, repositories.LockOptimistic} {
+		t.Run(string(strategy), func(t *testing.T) {
+			s := newTestServerWithConfig(t, Config{APIKey: testAPIKey, CheckoutLockMode: string(strategy)})
+
+			category := s.createCategory("Makanan")
+			a := s.createProduct("Produk A", 1000, 25, category.ID)
+			b := s.createProduct("Produk B", 2000, 25, category.ID)
+
+			// urutan item dibalik di setengah request, kasus klasik deadlock
+			const workers = 60
+			var success atomic.Int32
+			var wg sync.WaitGroup
+			for i := 0; i < workers; i++ {
+				items := []models.CheckoutItem{{ProductID: a.ID, Quantity: 1}, {ProductID: b.ID, Quantity: 1}}
+				if i%2 == 1 {
+					items[0], items[1] = items[1], items[0]
+				}
+
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					rec := s.doAuth(http.MethodPost, "/api/checkout", models.CheckoutRequest{Items: items})
+					if rec.Code == http.StatusOK {
+						success.Add(1)
+					}
+				}()
+			}
+			wg.Wait()
+
+			if got := success.Load(); got != 25 {
+				t.Errorf("successful checkouts = %d, want 25", got)
+			}
+			for _, p := range []models.Product{a, b} {
+				if stock := s.productStock(p.ID); stock != 0 {
+					t.Errorf("stock of %s = %d, want 0", p.Name, stock)
+				}
+			}
+		})
+	}
+}
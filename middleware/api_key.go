@@ -20,6 +20,7 @@ func APIKey(validApiKey string) func(http.HandlerFunc) http.HandlerFunc {
 
 			if apiKey != validApiKey {
 				http.Error(w, "Invalid API Key", http.StatusUnauthorized)
+				return
 			}
 
 			next(w, r)
@@ -7,7 +7,7 @@ func CORS(next http.Handler) http.Handler {
 		// 1. Set Header CORS
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, x-api-key")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, x-api-key, Idempotency-Key, X-Manager-Key, X-User")
 
 		// 2. Handle Preflight Request (OPTIONS)
 		if r.Method == "OPTIONS" {
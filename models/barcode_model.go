@@ -0,0 +1,18 @@
+package models
+
+// Jenis barcode produk. EAN-13 dan UPC-A divalidasi check digit-nya, kode
+// internal bebas (huruf, angka, - . _ /) untuk label cetakan sendiri.
+const (
+	BarcodeEAN13    = "ean13"
+	BarcodeUPC      = "upc"
+	BarcodeInternal = "internal"
+)
+
+// Barcode - Type boleh kosong saat input: 13 digit dianggap EAN-13, 12 digit
+// UPC-A, selain itu internal. Assigned = EAN-13 internal yang dibuatkan sistem
+// lewat POST /api/product/{id}/barcode, diabaikan kalau dikirim di body.
+type Barcode struct {
+	Code     string `json:"code"`
+	Type     string `json:"type"`
+	Assigned bool   `json:"assigned,omitempty"`
+}
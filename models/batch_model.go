@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// ExpiryDateLayout - format expiry_date di request dan response
+const ExpiryDateLayout = "2006-01-02"
+
+// Batch - stok satu lot produk di satu lokasi. ExpiryDate kosong = lot tanpa
+// tanggal kedaluwarsa.
+type Batch struct {
+	ID           int       `json:"id"`
+	ProductID    int       `json:"product_id"`
+	ProductName  string    `json:"product_name"`
+	LocationID   int       `json:"location_id"`
+	LocationName string    `json:"location_name"`
+	LotNumber    string    `json:"lot_number"`
+	ExpiryDate   string    `json:"expiry_date,omitempty"`
+	Quantity     int       `json:"quantity"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// BatchAllocation - quantity satu lot yang masuk / keluar lewat stock movement
+type BatchAllocation struct {
+	BatchID    int    `json:"batch_id"`
+	LotNumber  string `json:"lot_number"`
+	ExpiryDate string `json:"expiry_date,omitempty"`
+	Quantity   int    `json:"quantity"`
+}
+
+// BatchFilter - ExpiresBy diisi = hanya batch yang kedaluwarsa paling lambat tanggal itu
+type BatchFilter struct {
+	ProductID  int
+	LocationID int
+	ExpiresBy  string
+}
+
+type ExpiringBatch struct {
+	Batch
+	DaysLeft int  `json:"days_left"`
+	Expired  bool `json:"expired"`
+}
+
+// ExpiringReport - batch yang kedaluwarsa dalam Days hari dari Date, termasuk
+// yang sudah lewat tanggal
+type ExpiringReport struct {
+	Date             string          `json:"date"`
+	Days             int             `json:"days"`
+	ExpiredQuantity  int             `json:"expired_quantity"`
+	ExpiringQuantity int             `json:"expiring_quantity"`
+	Batches          []ExpiringBatch `json:"batches"`
+}
@@ -4,4 +4,5 @@ type Category struct {
 	ID          int    `json:"id"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	TaxRateID   int    `json:"tax_rate_id,omitempty"`
 }
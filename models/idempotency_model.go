@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+const (
+	IdempotencyProcessing = "processing"
+	IdempotencyCompleted  = "completed"
+)
+
+type IdempotencyRecord struct {
+	Scope        string
+	Key          string
+	Fingerprint  string
+	Status       string
+	ResponseCode int
+	ResponseBody []byte
+	CreatedAt    time.Time
+}
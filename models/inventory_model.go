@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Kode alasan stock adjustment
+const (
+	AdjustmentDamaged    = "damaged"
+	AdjustmentExpired    = "expired"
+	AdjustmentLost       = "lost"
+	AdjustmentFound      = "found"
+	AdjustmentCorrection = "correction"
+)
+
+// StockAdjustment - dokumen penyesuaian stok, satu atau beberapa produk sekaligus.
+// Setiap item menjadi satu baris stock_movements dengan reason adjustment.
+type StockAdjustment struct {
+	ID         int                   `json:"id"`
+	LocationID int                   `json:"location_id"`
+	Reason     string                `json:"reason"`
+	Note       string                `json:"note,omitempty"`
+	User       string                `json:"user,omitempty"`
+	CreatedAt  time.Time             `json:"created_at"`
+	Items      []StockAdjustmentItem `json:"items"`
+}
+
+// StockAdjustmentItem - Quantity relatif, positif = tambah stok, negatif = kurangi.
+// LotNumber diisi untuk menambah stok ke lot tertentu (dengan ExpiryDate) atau
+// mengurangi stok dari lot tertentu, misalnya menarik lot yang kedaluwarsa.
+type StockAdjustmentItem struct {
+	ProductID  int      `json:"product_id"`
+	Quantity   int      `json:"quantity"`
+	LotNumber  string   `json:"lot_number,omitempty"`
+	ExpiryDate string   `json:"expiry_date,omitempty"`
+	Serials    []string `json:"serials,omitempty"`
+	StockAfter int      `json:"stock_after"`
+	MovementID int      `json:"movement_id"`
+}
+
+// StockAdjustmentRequest - Items berisi satu produk atau lebih
+type StockAdjustmentRequest struct {
+	LocationID int                   `json:"location_id,omitempty"`
+	Reason     string                `json:"reason"`
+	Note       string                `json:"note"`
+	Items      []StockAdjustmentItem `json:"items"`
+}
@@ -0,0 +1,82 @@
+package models
+
+import "time"
+
+// Jenis lokasi stok
+const (
+	LocationOutlet    = "outlet"
+	LocationWarehouse = "warehouse"
+)
+
+// DefaultLocationID - lokasi bawaan hasil migrasi. Request yang tidak
+// menyebut location_id (checkout, adjustment, opname, PO) memakai lokasi ini.
+const DefaultLocationID = 1
+
+type Location struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Address string `json:"address"`
+}
+
+// LocationStock - stok produk di satu lokasi. InTransit = quantity transfer
+// yang sudah dikirim ke lokasi ini tapi belum diterima.
+type LocationStock struct {
+	LocationID   int    `json:"location_id"`
+	LocationName string `json:"location_name"`
+	Stock        int    `json:"stock"`
+	InTransit    int    `json:"in_transit"`
+}
+
+// Status transfer stok: requested -> shipped -> received, atau cancelled
+// selama belum dikirim
+const (
+	TransferRequested = "requested"
+	TransferShipped   = "shipped"
+	TransferReceived  = "received"
+	TransferCancelled = "cancelled"
+)
+
+// StockTransfer - perpindahan stok antar lokasi. Stok lokasi asal berkurang
+// saat shipped, stok lokasi tujuan bertambah saat received.
+type StockTransfer struct {
+	ID               int                 `json:"id"`
+	FromLocationID   int                 `json:"from_location_id"`
+	FromLocationName string              `json:"from_location_name"`
+	ToLocationID     int                 `json:"to_location_id"`
+	ToLocationName   string              `json:"to_location_name"`
+	Status           string              `json:"status"`
+	Note             string              `json:"note,omitempty"`
+	CreatedBy        string              `json:"created_by,omitempty"`
+	CreatedAt        time.Time           `json:"created_at"`
+	ShippedBy        string              `json:"shipped_by,omitempty"`
+	ShippedAt        *time.Time          `json:"shipped_at,omitempty"`
+	ReceivedBy       string              `json:"received_by,omitempty"`
+	ReceivedAt       *time.Time          `json:"received_at,omitempty"`
+	Lines            []StockTransferLine `json:"lines,omitempty"`
+}
+
+type StockTransferLine struct {
+	ID          int    `json:"id"`
+	ProductID   int    `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Quantity    int    `json:"quantity"`
+}
+
+type StockTransferRequest struct {
+	FromLocationID int                 `json:"from_location_id"`
+	ToLocationID   int                 `json:"to_location_id"`
+	Note           string              `json:"note"`
+	Items          []StockTransferItem `json:"items"`
+}
+
+type StockTransferItem struct {
+	ProductID int `json:"product_id"`
+	Quantity  int `json:"quantity"`
+}
+
+// StockTransferFilter - LocationID cocok dengan lokasi asal maupun tujuan
+type StockTransferFilter struct {
+	Status     string
+	LocationID int
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// Pengelompokan laporan low stock
+const (
+	LowStockByCategory = "category"
+	LowStockBySupplier = "supplier"
+)
+
+// LowStockItem - produk dengan stok total di atau di bawah reorder point
+type LowStockItem struct {
+	ProductID       int    `json:"product_id"`
+	ProductName     string `json:"product_name"`
+	CategoryID      int    `json:"category_id"`
+	CategoryName    string `json:"category_name"`
+	SupplierID      int    `json:"supplier_id,omitempty"`
+	SupplierName    string `json:"supplier_name,omitempty"`
+	Stock           int    `json:"stock"`
+	ReorderPoint    int    `json:"reorder_point"`
+	ReorderQuantity int    `json:"reorder_quantity"`
+}
+
+// LowStockGroup - ID 0 untuk produk tanpa supplier
+type LowStockGroup struct {
+	ID       int            `json:"id"`
+	Name     string         `json:"name"`
+	Products []LowStockItem `json:"products"`
+}
+
+type LowStockReport struct {
+	GroupBy       string          `json:"group_by"`
+	TotalProducts int             `json:"total_products"`
+	Groups        []LowStockGroup `json:"groups"`
+}
+
+// LowStockEvent - dikirim ke subscriber saat checkout membuat stok total
+// produk turun dari di atas reorder point menjadi sama dengan / di bawahnya
+type LowStockEvent struct {
+	ProductID       int       `json:"product_id"`
+	ProductName     string    `json:"product_name"`
+	LocationID      int       `json:"location_id"`
+	TransactionID   int       `json:"transaction_id"`
+	Stock           int       `json:"stock"`
+	ReorderPoint    int       `json:"reorder_point"`
+	ReorderQuantity int       `json:"reorder_quantity"`
+	CreatedAt       time.Time `json:"created_at"`
+}
@@ -0,0 +1,25 @@
+package models
+
+const (
+	PaymentCash     = "cash"
+	PaymentCard     = "card"
+	PaymentQRIS     = "qris"
+	PaymentTransfer = "transfer"
+)
+
+// Payment - satu pembayaran di transaksi. Untuk tunai, Amount adalah uang yang
+// diterima (sebelum kembalian).
+type Payment struct {
+	ID            int    `json:"id,omitempty"`
+	TransactionID int    `json:"transaction_id,omitempty"`
+	Method        string `json:"method"`
+	Amount        int    `json:"amount"`
+	Reference     string `json:"reference,omitempty"`
+}
+
+// PaymentSummary - total per metode pembayaran di laporan, tunai sudah dikurangi kembalian
+type PaymentSummary struct {
+	Method           string `json:"method"`
+	Total            int    `json:"total"`
+	TotalTransaction int    `json:"total_transaksi"`
+}
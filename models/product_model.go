@@ -4,6 +4,59 @@ type Product struct {
 	ID         int     `json:"id"`
 	Name       string  `json:"name"`
 	Price      float64 `json:"price"`
+	CostPrice  int     `json:"cost_price,omitempty"` // HPP rata-rata, dikelola ledger: diabaikan saat update
 	Stock      int     `json:"stock"`
 	CategoryID int     `json:"category_id"`
+	TaxRateID  int     `json:"tax_rate_id,omitempty"`
+	SupplierID int     `json:"supplier_id,omitempty"`
+
+	// Stok total <= ReorderPoint dianggap low stock, 0 = tidak dipantau
+	ReorderPoint    int `json:"reorder_point"`
+	ReorderQuantity int `json:"reorder_quantity"`
+
+	// TrackSerial - setiap unit dilacak per serial number (IMEI / S/N), wajib
+	// diisi saat penerimaan barang, checkout dan retur
+	TrackSerial bool `json:"track_serial"`
+
+	// Varian (ukuran, warna, ...) adalah produk dengan ParentID ke produk induk,
+	// stok dan SKU-nya sendiri. PriceOverride false = harga mengikuti induk.
+	// Variants hanya diisi di listing / detail produk induk.
+	ParentID      int               `json:"parent_id,omitempty"`
+	SKU           string            `json:"sku,omitempty"`
+	Attributes    map[string]string `json:"attributes,omitempty"`
+	PriceOverride bool              `json:"price_override,omitempty"`
+	Variants      []Product         `json:"variants,omitempty"`
+
+	// Barcodes - barcode yang bisa di-scan kasir, unik antar produk
+	Barcodes []Barcode `json:"barcodes,omitempty"`
+
+	// Stock adalah total semua lokasi, rinciannya di Locations. Saat create,
+	// stok awal dicatat di LocationID (kosong = lokasi bawaan). Update yang
+	// mengubah Stock wajib mengisi LocationID, selisihnya dicatat di sana.
+	LocationID int             `json:"location_id,omitempty"`
+	InTransit  int             `json:"in_transit"`
+	Locations  []LocationStock `json:"locations,omitempty"`
+
+	// User - operator dari header X-User, dicatat di stock_movements kalau stok berubah
+	User string `json:"-"`
+
+	// CostMethod - metode HPP kalau stok berkurang, diisi service dari konfigurasi
+	CostMethod string `json:"-"`
+}
+
+// ProductUpdate - body PUT /api/product/{id}. Field pointer yang tidak dikirim
+// (nil) tetap memakai nilai yang tersimpan, supplier_id 0 melepas supplier dan
+// stock yang tidak dikirim tidak mengubah stok.
+// track_serial hanya bisa diubah selama stok (termasuk dalam perjalanan) 0,
+// stok produk serial diubah lewat adjustment yang menyebut serial-nya.
+// barcodes menggantikan barcode lama, kecuali kode internal dan EAN-13 buatan
+// sistem yang hanya dihapus lewat DELETE /api/product/{id}/barcode/{code}.
+type ProductUpdate struct {
+	Product
+	Stock           *int       `json:"stock,omitempty"`
+	SupplierID      *int       `json:"supplier_id,omitempty"`
+	ReorderPoint    *int       `json:"reorder_point,omitempty"`
+	ReorderQuantity *int       `json:"reorder_quantity,omitempty"`
+	TrackSerial     *bool      `json:"track_serial,omitempty"`
+	Barcodes        *[]Barcode `json:"barcodes,omitempty"`
 }
@@ -4,7 +4,26 @@ type ProductResponse struct {
 	ID           int     `json:"id"`
 	Name         string  `json:"name"`
 	Price        float64 `json:"price"`
+	CostPrice    int     `json:"cost_price"`
 	Stock        int     `json:"stock"`
 	CategoryID   int     `json:"category_id"`
 	CategoryName string  `json:"category_name"`
+	TaxRateID    int     `json:"tax_rate_id,omitempty"`
+	SupplierID   int     `json:"supplier_id,omitempty"`
+
+	ReorderPoint    int `json:"reorder_point"`
+	ReorderQuantity int `json:"reorder_quantity"`
+
+	TrackSerial bool `json:"track_serial"`
+
+	ParentID      int               `json:"parent_id,omitempty"`
+	SKU           string            `json:"sku,omitempty"`
+	Attributes    map[string]string `json:"attributes,omitempty"`
+	PriceOverride bool              `json:"price_override,omitempty"`
+	Variants      []Product         `json:"variants,omitempty"`
+
+	Barcodes []Barcode `json:"barcodes,omitempty"`
+
+	InTransit int             `json:"in_transit"`
+	Locations []LocationStock `json:"locations"`
 }
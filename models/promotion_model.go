@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+const (
+	DiscountPercent = "percent"
+	DiscountFixed   = "fixed"
+)
+
+// Discount - diskon persen (Value 0-100) atau potongan nominal tetap
+type Discount struct {
+	Type  string `json:"type"`
+	Value int    `json:"value"`
+}
+
+// Promotion - kode promo. UsageLimit 0 = tanpa batas pemakaian.
+type Promotion struct {
+	ID          int       `json:"id"`
+	Code        string    `json:"code"`
+	Description string    `json:"description"`
+	Type        string    `json:"type"`
+	Value       int       `json:"value"`
+	MinSpend    int       `json:"min_spend"`
+	UsageLimit  int       `json:"usage_limit"`
+	UsedCount   int       `json:"used_count"`
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+	Active      bool      `json:"active"`
+}
+
+const (
+	RuleBuyXGetY = "buy_x_get_y"
+	RuleBundle   = "bundle"
+	RulePercent  = "percent"
+)
+
+type BundleItem struct {
+	ProductID int `json:"product_id"`
+	Quantity  int `json:"quantity"`
+}
+
+// PromotionRule - promo otomatis yang dievaluasi di setiap checkout tanpa kode.
+//   - buy_x_get_y: beli BuyQuantity, GetQuantity item termurah potongan Percent (0 = gratis)
+//   - bundle: BundleItems dijual seharga BundlePrice
+//   - percent: potongan Percent, untuk happy hour isi StartTime / EndTime ("14:00")
+//
+// Produk yang kena dipilih lewat ProductIDs atau CategoryID (bundle lewat BundleItems).
+type PromotionRule struct {
+	ID          int          `json:"id"`
+	Name        string       `json:"name"`
+	Type        string       `json:"type"`
+	ProductIDs  []int        `json:"product_ids,omitempty"`
+	CategoryID  int          `json:"category_id,omitempty"`
+	BuyQuantity int          `json:"buy_quantity,omitempty"`
+	GetQuantity int          `json:"get_quantity,omitempty"`
+	Percent     int          `json:"percent,omitempty"`
+	BundleItems []BundleItem `json:"bundle_items,omitempty"`
+	BundlePrice int          `json:"bundle_price,omitempty"`
+	StartTime   string       `json:"start_time,omitempty"`
+	EndTime     string       `json:"end_time,omitempty"`
+	StartsAt    *time.Time   `json:"starts_at,omitempty"`
+	EndsAt      *time.Time   `json:"ends_at,omitempty"`
+	Active      bool         `json:"active"`
+}
+
+// AppliedPromotion - penjelasan promo otomatis yang kena di satu baris transaksi
+type AppliedPromotion struct {
+	RuleID   int    `json:"rule_id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Quantity int    `json:"quantity"`
+	Discount int    `json:"discount"`
+}
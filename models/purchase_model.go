@@ -0,0 +1,111 @@
+package models
+
+import "time"
+
+type Supplier struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Phone   string `json:"phone"`
+	Email   string `json:"email"`
+	Address string `json:"address"`
+
+	// LeadTimeDays - hari dari PO dikirim sampai barang diterima, dipakai saran reorder
+	LeadTimeDays int `json:"lead_time_days"`
+}
+
+// Status purchase order: draft -> sent -> partially_received -> received
+const (
+	PurchaseOrderDraft             = "draft"
+	PurchaseOrderSent              = "sent"
+	PurchaseOrderPartiallyReceived = "partially_received"
+	PurchaseOrderReceived          = "received"
+)
+
+type PurchaseOrder struct {
+	ID           int                 `json:"id"`
+	SupplierID   int                 `json:"supplier_id"`
+	SupplierName string              `json:"supplier_name"`
+	LocationID   int                 `json:"location_id"`
+	Status       string              `json:"status"`
+	Note         string              `json:"note,omitempty"`
+	TotalCost    int                 `json:"total_cost"`
+	CreatedBy    string              `json:"created_by,omitempty"`
+	CreatedAt    time.Time           `json:"created_at"`
+	SentAt       *time.Time          `json:"sent_at,omitempty"`
+	ReceivedAt   *time.Time          `json:"received_at,omitempty"`
+	Lines        []PurchaseOrderLine `json:"lines,omitempty"`
+}
+
+// PurchaseOrderLine - UnitCost harga beli per unit yang disepakati di PO
+type PurchaseOrderLine struct {
+	ID               int    `json:"id"`
+	PurchaseOrderID  int    `json:"purchase_order_id"`
+	ProductID        int    `json:"product_id"`
+	ProductName      string `json:"product_name"`
+	Quantity         int    `json:"quantity"`
+	ReceivedQuantity int    `json:"received_quantity"`
+	UnitCost         int    `json:"unit_cost"`
+	Subtotal         int    `json:"subtotal"`
+}
+
+// PurchaseOrderRequest - buat / ubah PO (hanya selama draft)
+type PurchaseOrderRequest struct {
+	SupplierID int                        `json:"supplier_id"`
+	LocationID int                        `json:"location_id,omitempty"`
+	Note       string                     `json:"note"`
+	Lines      []PurchaseOrderLineRequest `json:"lines"`
+}
+
+type PurchaseOrderLineRequest struct {
+	ProductID int `json:"product_id"`
+	Quantity  int `json:"quantity"`
+	UnitCost  int `json:"unit_cost"`
+}
+
+type PurchaseOrderFilter struct {
+	Status     string
+	SupplierID int
+}
+
+// GoodsReceipt - penerimaan barang atas satu PO, boleh sebagian
+type GoodsReceipt struct {
+	ID              int                `json:"id"`
+	PurchaseOrderID int                `json:"purchase_order_id"`
+	Note            string             `json:"note,omitempty"`
+	User            string             `json:"user,omitempty"`
+	CreatedAt       time.Time          `json:"created_at"`
+	Items           []GoodsReceiptItem `json:"items"`
+}
+
+type GoodsReceiptItem struct {
+	ID                  int      `json:"id"`
+	GoodsReceiptID      int      `json:"goods_receipt_id"`
+	PurchaseOrderLineID int      `json:"purchase_order_line_id"`
+	ProductID           int      `json:"product_id"`
+	Quantity            int      `json:"quantity"`
+	UnitCost            int      `json:"unit_cost"`
+	LotNumber           string   `json:"lot_number,omitempty"`
+	ExpiryDate          string   `json:"expiry_date,omitempty"`
+	Serials             []string `json:"serials,omitempty"`
+	MovementID          int      `json:"movement_id"`
+}
+
+// GoodsReceiptRequest - Items kosong = terima semua sisa quantity PO.
+// UnitCost diisi kalau harga di faktur berbeda dengan harga di PO.
+type GoodsReceiptRequest struct {
+	Note  string                    `json:"note"`
+	Items []GoodsReceiptRequestItem `json:"items"`
+}
+
+type GoodsReceiptRequestItem struct {
+	PurchaseOrderLineID int  `json:"purchase_order_line_id"`
+	Quantity            int  `json:"quantity"`
+	UnitCost            *int `json:"unit_cost,omitempty"`
+
+	// LotNumber / ExpiryDate (YYYY-MM-DD) diisi untuk barang yang dilacak per batch
+	LotNumber  string `json:"lot_number,omitempty"`
+	ExpiryDate string `json:"expiry_date,omitempty"`
+
+	// Serials wajib untuk produk dengan track_serial, satu per unit diterima
+	Serials []string `json:"serials,omitempty"`
+}
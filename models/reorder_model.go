@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// ProductDemand - bahan saran reorder satu produk. DailySales berisi quantity
+// terjual per tanggal (2006-01-02), transaksi void tidak dihitung.
+type ProductDemand struct {
+	ProductID       int
+	ProductName     string
+	SupplierID      int
+	SupplierName    string
+	LeadTimeDays    int
+	Stock           int
+	OnOrder         int
+	ReorderQuantity int
+	DailySales      map[string]int
+}
+
+// ReorderSuggestion - velocity dalam unit per hari. DaysOfCover kosong kalau
+// produk tidak terjual selama window.
+type ReorderSuggestion struct {
+	ProductID         int      `json:"product_id"`
+	ProductName       string   `json:"product_name"`
+	SupplierID        int      `json:"supplier_id,omitempty"`
+	SupplierName      string   `json:"supplier_name,omitempty"`
+	LeadTimeDays      int      `json:"lead_time_days"`
+	Stock             int      `json:"stock"`
+	OnOrder           int      `json:"on_order"`
+	MovingAverage     float64  `json:"moving_average"`
+	SmoothedVelocity  float64  `json:"smoothed_velocity"`
+	DaysOfCover       *float64 `json:"days_of_cover,omitempty"`
+	TargetStock       int      `json:"target_stock"`
+	SuggestedQuantity int      `json:"suggested_quantity"`
+	Urgent            bool     `json:"urgent"`
+}
+
+type ReorderSuggestionReport struct {
+	GeneratedAt time.Time           `json:"generated_at"`
+	WindowDays  int                 `json:"window_days"`
+	Alpha       float64             `json:"alpha"`
+	CoverDays   int                 `json:"cover_days"`
+	Suggestions []ReorderSuggestion `json:"suggestions"`
+}
+
+// ReorderParams - kosong = pakai default dari konfigurasi
+type ReorderParams struct {
+	WindowDays int
+	Alpha      float64
+	CoverDays  int
+}
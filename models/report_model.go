@@ -5,8 +5,44 @@ type BestSellingProduct struct {
 	TotalSold int    `json:"qty_terjual"`
 }
 
+// SalesReport - TotalRevenue adalah pendapatan bersih (GrossRevenue + TotalReturns),
+// TotalReturns bernilai negatif. Laba kotor dihitung dari NetSales (DPP, tanpa
+// pajak dan setelah semua diskon) dikurangi HPP, Margin dalam persen.
 type SalesReport struct {
 	TotalRevenue     int                `json:"total_revenue"`
+	GrossRevenue     int                `json:"gross_revenue"`
+	TotalReturns     int                `json:"total_returns"`
+	TotalDiscount    int                `json:"total_discount"`
+	TotalTax         int                `json:"total_tax"`
 	TotalTransaction int                `json:"total_transaksi"`
+	NetSales         int                `json:"net_sales"`
+	TotalCost        int                `json:"total_cost"`
+	GrossProfit      int                `json:"gross_profit"`
+	Margin           float64            `json:"margin"`
 	TopProduct       BestSellingProduct `json:"produk_terlaris"`
+	PaymentBreakdown []PaymentSummary   `json:"payment_breakdown"`
+}
+
+// ProfitLine - laba kotor satu produk atau category. Quantity, NetSales dan
+// Cost sudah dikurangi retur di periode yang sama.
+type ProfitLine struct {
+	ID          int     `json:"id"`
+	Name        string  `json:"name"`
+	Quantity    int     `json:"quantity"`
+	NetSales    int     `json:"net_sales"`
+	Cost        int     `json:"cost"`
+	GrossProfit int     `json:"gross_profit"`
+	Margin      float64 `json:"margin"`
+}
+
+// ProfitReport - category mengikuti category produk saat ini
+type ProfitReport struct {
+	StartDate   string       `json:"start_date"`
+	EndDate     string       `json:"end_date"`
+	NetSales    int          `json:"net_sales"`
+	TotalCost   int          `json:"total_cost"`
+	GrossProfit int          `json:"gross_profit"`
+	Margin      float64      `json:"margin"`
+	Products    []ProfitLine `json:"products"`
+	Categories  []ProfitLine `json:"categories"`
 }
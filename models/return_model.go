@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Return - dokumen retur atas satu transaksi. TotalAmount, Amount dan TaxAmount negatif.
+type Return struct {
+	ID            int          `json:"id"`
+	TransactionID int          `json:"transaction_id"`
+	TotalAmount   int          `json:"total_amount"`
+	Reason        string       `json:"reason"`
+	CreatedAt     time.Time    `json:"created_at"`
+	Items         []ReturnItem `json:"items"`
+}
+
+type ReturnItem struct {
+	ID                  int      `json:"id"`
+	ReturnID            int      `json:"return_id"`
+	TransactionDetailID int      `json:"transaction_detail_id"`
+	ProductID           int      `json:"product_id"`
+	ProductName         string   `json:"product_name"`
+	Quantity            int      `json:"quantity"`
+	Amount              int      `json:"amount"`
+	TaxAmount           int      `json:"tax_amount"`
+	Serials             []string `json:"serials,omitempty"`
+}
+
+// ReturnRequest - Items kosong berarti retur penuh (semua sisa quantity)
+type ReturnRequest struct {
+	Reason string              `json:"reason"`
+	Items  []ReturnRequestItem `json:"items"`
+	User   string              `json:"-"`
+}
+
+// ReturnRequestItem - Serials unit yang dikembalikan, boleh kosong kalau
+// semua sisa unit baris itu diretur
+type ReturnRequestItem struct {
+	TransactionDetailID int      `json:"transaction_detail_id"`
+	Quantity            int      `json:"quantity"`
+	Serials             []string `json:"serials,omitempty"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Status unit yang dilacak per serial number (IMEI / S/N)
+const (
+	SerialInStock = "in_stock"
+	SerialSold    = "sold"
+	SerialRemoved = "removed"
+)
+
+// SerialNumber - satu unit produk yang dilacak per serial. Serial unik per
+// produk. History adalah stock movement yang melibatkan unit ini, urut dari
+// yang paling lama (penerimaan, penjualan, retur, void, adjustment).
+type SerialNumber struct {
+	ID          int             `json:"id"`
+	ProductID   int             `json:"product_id"`
+	ProductName string          `json:"product_name"`
+	Serial      string          `json:"serial"`
+	Status      string          `json:"status"`
+	CreatedAt   time.Time       `json:"created_at"`
+	History     []StockMovement `json:"history"`
+}
@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+// Status sesi stock opname
+const (
+	StockCountOpen      = "open"
+	StockCountFinalized = "finalized"
+	StockCountCancelled = "cancelled"
+)
+
+// StockCount - sesi stock opname. Stok sistem di-snapshot saat sesi dibuka,
+// hasil hitung bisa dikirim bertahap dari beberapa device.
+type StockCount struct {
+	ID           int              `json:"id"`
+	LocationID   int              `json:"location_id"`
+	Status       string           `json:"status"`
+	Note         string           `json:"note,omitempty"`
+	CreatedBy    string           `json:"created_by,omitempty"`
+	CreatedAt    time.Time        `json:"created_at"`
+	ClosedAt     *time.Time       `json:"closed_at,omitempty"`
+	ClosedBy     string           `json:"closed_by,omitempty"`
+	AdjustmentID int              `json:"adjustment_id,omitempty"`
+	Lines        []StockCountLine `json:"lines,omitempty"`
+}
+
+// StockCountLine - CountedQuantity nil = produk belum dihitung sama sekali.
+// Variance = CountedQuantity - SystemStock, VarianceValue = Variance * UnitCost
+// (HPP saat sesi dibuka). UnitPrice hanya info harga jual.
+type StockCountLine struct {
+	ProductID       int               `json:"product_id"`
+	ProductName     string            `json:"product_name"`
+	CategoryID      int               `json:"category_id"`
+	UnitPrice       int               `json:"unit_price"`
+	UnitCost        int               `json:"unit_cost"`
+	SystemStock     int               `json:"system_stock"`
+	CountedQuantity *int              `json:"counted_quantity"`
+	Variance        int               `json:"variance"`
+	VarianceValue   int               `json:"variance_value"`
+	Entries         []StockCountEntry `json:"entries,omitempty"`
+}
+
+// StockCountEntry - hasil hitung satu device untuk satu produk. Kiriman ulang
+// dari device yang sama menggantikan angka sebelumnya, antar device dijumlah.
+type StockCountEntry struct {
+	Device    string    `json:"device"`
+	Quantity  int       `json:"quantity"`
+	CountedBy string    `json:"counted_by,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// StockCountRequest - ProductIDs dan CategoryIDs kosong = semua produk.
+// Stok sistem diambil dari LocationID (kosong = lokasi bawaan).
+type StockCountRequest struct {
+	LocationID  int    `json:"location_id,omitempty"`
+	ProductIDs  []int  `json:"product_ids"`
+	CategoryIDs []int  `json:"category_ids"`
+	Note        string `json:"note"`
+}
+
+type StockCountSubmission struct {
+	Device string           `json:"device"`
+	Items  []StockCountItem `json:"items"`
+}
+
+type StockCountItem struct {
+	ProductID int `json:"product_id"`
+	Quantity  int `json:"quantity"`
+}
+
+// StockCountVariance - laporan selisih. Lines hanya berisi produk yang sudah
+// dihitung dan selisihnya tidak nol. Nilai dalam HPP, negatif = kekurangan.
+type StockCountVariance struct {
+	CountID            int              `json:"count_id"`
+	Status             string           `json:"status"`
+	TotalLines         int              `json:"total_lines"`
+	CountedLines       int              `json:"counted_lines"`
+	TotalVarianceQty   int              `json:"total_variance_qty"`
+	TotalVarianceValue int              `json:"total_variance_value"`
+	ShortageValue      int              `json:"shortage_value"`
+	SurplusValue       int              `json:"surplus_value"`
+	Lines              []StockCountLine `json:"lines"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Alasan perubahan stok di stock_movements
+const (
+	StockReasonSale       = "sale"
+	StockReasonReturn     = "return"
+	StockReasonVoid       = "void"
+	StockReasonAdjustment = "adjustment"
+	StockReasonReceipt    = "receipt"
+	StockReasonTransfer   = "transfer"
+)
+
+// Jenis dokumen yang jadi referensi perubahan stok
+const (
+	StockRefTransaction = "transaction"
+	StockRefReturn      = "return"
+	StockRefProduct     = "product"
+	StockRefAdjustment  = "adjustment"
+	StockRefReceipt     = "goods_receipt"
+	StockRefTransfer    = "stock_transfer"
+)
+
+// StockMovement - satu baris ledger stok (append-only). Delta positif = stok
+// masuk, negatif = stok keluar. StockAfter adalah stok produk di LocationID
+// setelah perubahan. CostAmount adalah nilai persediaan yang masuk/keluar
+// (bertanda sama dengan Delta), UnitCost nilainya per unit.
+type StockMovement struct {
+	ID            int       `json:"id"`
+	ProductID     int       `json:"product_id"`
+	LocationID    int       `json:"location_id"`
+	Delta         int       `json:"delta"`
+	StockAfter    int       `json:"stock_after"`
+	UnitCost      int       `json:"unit_cost"`
+	CostAmount    int       `json:"cost_amount"`
+	CostMethod    string    `json:"cost_method,omitempty"`
+	Reason        string    `json:"reason"`
+	ReferenceType string    `json:"reference_type,omitempty"`
+	ReferenceID   int       `json:"reference_id,omitempty"`
+	User          string    `json:"user,omitempty"`
+	Note          string    `json:"note,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	// Batches - lot yang masuk / keluar. Untuk stok masuk diisi caller (lot
+	// baru atau lot yang dikembalikan), untuk stok keluar diisi ledger (FEFO)
+	// kecuali caller menentukan lot tertentu.
+	Batches []BatchAllocation `json:"batches,omitempty"`
+
+	// Serials - serial number unit yang masuk / keluar, diisi caller. Jumlahnya
+	// harus sama dengan |Delta| kalau diisi.
+	Serials []string `json:"serials,omitempty"`
+}
@@ -0,0 +1,35 @@
+package models
+
+const (
+	TaxInclusive = "inclusive"
+	TaxExclusive = "exclusive"
+)
+
+// TaxRate - tarif pajak (PPN). Rate dalam persen, mis. 11 atau 12.
+type TaxRate struct {
+	ID   int     `json:"id"`
+	Name string  `json:"name"`
+	Rate float64 `json:"rate"`
+}
+
+// TaxSummaryLine - rekap DPP dan pajak per tarif. Nilai retur negatif,
+// Net = penjualan + retur.
+type TaxSummaryLine struct {
+	TaxRateID       int     `json:"tax_rate_id"`
+	Name            string  `json:"name"`
+	Rate            float64 `json:"rate"`
+	TaxBase         int     `json:"tax_base"`
+	TaxAmount       int     `json:"tax_amount"`
+	ReturnTaxBase   int     `json:"return_tax_base"`
+	ReturnTaxAmount int     `json:"return_tax_amount"`
+	NetTaxBase      int     `json:"net_tax_base"`
+	NetTaxAmount    int     `json:"net_tax_amount"`
+}
+
+type TaxSummaryReport struct {
+	StartDate      string           `json:"start_date"`
+	EndDate        string           `json:"end_date"`
+	Rates          []TaxSummaryLine `json:"rates"`
+	TotalTaxBase   int              `json:"total_tax_base"`
+	TotalTaxAmount int              `json:"total_tax_amount"`
+}
@@ -2,27 +2,116 @@ package models
 
 import "time"
 
+// Transaction - TotalAmount = TaxBase + TaxAmount. Harga inclusive: TotalAmount =
+// GrossAmount - semua diskon (pajak sudah termasuk). Harga exclusive: pajak ditambahkan
+// di atas GrossAmount - semua diskon.
 type Transaction struct {
-	ID          int                 `json:"id"`
-	TotalAmount int                 `json:"total_amount"`
-	CreatedAt   time.Time           `json:"created_at"`
-	Details     []TransactionDetail `json:"details"`
+	ID            int                 `json:"id"`
+	LocationID    int                 `json:"location_id"`
+	GrossAmount   int                 `json:"gross_amount"`
+	LineDiscount  int                 `json:"line_discount"`
+	RuleDiscount  int                 `json:"rule_discount"`
+	OrderDiscount int                 `json:"order_discount"`
+	PromoCode     string              `json:"promo_code,omitempty"`
+	PromotionID   int                 `json:"-"`
+	PromoDiscount int                 `json:"promo_discount"`
+	TaxInclusive  bool                `json:"tax_inclusive"`
+	TaxBase       int                 `json:"tax_base"`
+	TaxAmount     int                 `json:"tax_amount"`
+	TotalAmount   int                 `json:"total_amount"`
+	PaidAmount    int                 `json:"paid_amount"`
+	Change        int                 `json:"change"`
+	CreatedAt     time.Time           `json:"created_at"`
+	VoidedAt      *time.Time          `json:"voided_at,omitempty"`
+	VoidReason    string              `json:"void_reason,omitempty"`
+	VoidedBy      string              `json:"voided_by,omitempty"`
+	Details       []TransactionDetail `json:"details,omitempty"`
+	Payments      []Payment           `json:"payments,omitempty"`
+
+	// LowStock - produk yang turun melewati reorder point karena checkout ini
+	LowStock []LowStockEvent `json:"-"`
 }
 
+const (
+	VoidedByCashier = "cashier"
+	VoidedByManager = "manager"
+)
+
+type VoidRequest struct {
+	Reason string `json:"reason"`
+}
+
+// TransactionDetail - nilai setelah diskon = UnitPrice*Quantity - LineDiscount - RuleDiscount - OrderDiscount,
+// Subtotal = TaxBase + TaxAmount (yang dibayar pelanggan untuk baris ini).
+// RuleDiscount adalah potongan promo otomatis (rincian di Promotions). OrderDiscount
+// adalah bagian diskon transaksi + promo yang dialokasikan ke baris ini, supaya
+// nilai retur per baris sudah bersih dari semua diskon. UnitCost adalah snapshot
+// cost_price produk saat checkout.
 type TransactionDetail struct {
-	ID            int    `json:"id"`
-	TransactionID int    `json:"transaction_id"`
-	ProductID     int    `json:"product_id"`
-	ProductName   string `json:"product_name,omitempty"`
-	Quantity      int    `json:"quantity"`
-	Subtotal      int    `json:"subtotal"`
+	ID            int                `json:"id"`
+	TransactionID int                `json:"transaction_id"`
+	ProductID     int                `json:"product_id"`
+	ProductName   string             `json:"product_name,omitempty"`
+	CategoryID    int                `json:"-"`
+	UnitPrice     int                `json:"unit_price"`
+	UnitCost      int                `json:"unit_cost"`
+	Quantity      int                `json:"quantity"`
+	LineDiscount  int                `json:"line_discount"`
+	RuleDiscount  int                `json:"rule_discount"`
+	OrderDiscount int                `json:"order_discount"`
+	TaxRateID     int                `json:"tax_rate_id,omitempty"`
+	TaxName       string             `json:"tax_name,omitempty"`
+	TaxRate       float64            `json:"tax_rate"`
+	TaxBase       int                `json:"tax_base"`
+	TaxAmount     int                `json:"tax_amount"`
+	Subtotal      int                `json:"subtotal"`
+	Promotions    []AppliedPromotion `json:"promotions,omitempty"`
+	Serials       []string           `json:"serials,omitempty"`
 }
 
+// CheckoutItem - Serials wajib untuk produk dengan track_serial, satu per unit.
+// Produk yang punya varian harus dibeli lewat VariantID; kalau ProductID ikut
+// diisi, nilainya harus produk induk dari varian itu. Hasil scan bisa dikirim
+// lewat Barcode atau SKU sebagai ganti ID.
 type CheckoutItem struct {
-	ProductID int `json:"product_id"`
-	Quantity  int `json:"quantity"`
+	ProductID int       `json:"product_id"`
+	VariantID int       `json:"variant_id,omitempty"`
+	Barcode   string    `json:"barcode,omitempty"`
+	SKU       string    `json:"sku,omitempty"`
+	Quantity  int       `json:"quantity"`
+	Discount  *Discount `json:"discount,omitempty"`
+	Serials   []string  `json:"serials,omitempty"`
 }
 
+// CheckoutRequest - Payments kosong dianggap bayar tunai pas. LocationID adalah
+// outlet tempat penjualan (stok dikurangi di sini), kosong = lokasi bawaan.
 type CheckoutRequest struct {
-	Items []CheckoutItem `json:"items"`
+	LocationID int            `json:"location_id,omitempty"`
+	Items      []CheckoutItem `json:"items"`
+	Discount   *Discount      `json:"discount,omitempty"`
+	PromoCode  string         `json:"promo_code,omitempty"`
+	Payments   []Payment      `json:"payments,omitempty"`
+
+	// User - operator dari header X-User, dicatat di stock_movements
+	User string `json:"-"`
+}
+
+// TransactionFilter - filter GET /api/transactions. StartDate / EndDate format
+// "2006-01-02 15:04:05", amount nil = tidak difilter.
+type TransactionFilter struct {
+	StartDate  string
+	EndDate    string
+	MinAmount  *int
+	MaxAmount  *int
+	ProductID  int
+	LocationID int
+	Page       int
+	Limit      int
+}
+
+type TransactionList struct {
+	Data  []Transaction `json:"data"`
+	Page  int           `json:"page"`
+	Limit int           `json:"limit"`
+	Total int           `json:"total"`
 }
@@ -0,0 +1,32 @@
+package models
+
+// ValuationItem - stok dan nilai persediaan satu produk (jumlah ledger
+// stock_movements, transfer antar lokasi tidak dihitung)
+type ValuationItem struct {
+	ProductID    int    `json:"product_id"`
+	ProductName  string `json:"product_name"`
+	CategoryID   int    `json:"-"`
+	CategoryName string `json:"-"`
+	Quantity     int    `json:"quantity"`
+	Value        int    `json:"value"`
+
+	// UnitCost - Value / Quantity (dibulatkan), 0 kalau stok habis / minus
+	UnitCost int `json:"unit_cost"`
+}
+
+type ValuationCategory struct {
+	ID       int             `json:"id"`
+	Name     string          `json:"name"`
+	Quantity int             `json:"quantity"`
+	Value    int             `json:"value"`
+	Products []ValuationItem `json:"products"`
+}
+
+// ValuationReport - nilai persediaan saat ini, atau per akhir hari AsOf
+type ValuationReport struct {
+	AsOf          string              `json:"as_of,omitempty"`
+	Method        string              `json:"method"`
+	TotalQuantity int                 `json:"total_quantity"`
+	TotalValue    int                 `json:"total_value"`
+	Categories    []ValuationCategory `json:"categories"`
+}
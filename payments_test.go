@@ -0,0 +1,81 @@
+package main
+
+import (
+	"kasir-api/models"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestCheckoutWithPayments(t *testing.T) {
+	s := newTestServer(t)
+
+	category := s.createCategory("Makanan")
+	roti := s.createProduct("Roti Tawar", 15000, 10, category.ID)
+
+	rec := s.doAuth(http.MethodPost, "/api/checkout", models.CheckoutRequest{
+		Items: []models.CheckoutItem{{ProductID: roti.ID, Quantity: 2}},
+		Payments: []models.Payment{
+			{Method: models.PaymentCard, Amount: 20000, Reference: "APPR-1234"},
+			{Method: models.PaymentCash, Amount: 20000},
+		},
+	})
+	expectStatus(t, rec, http.StatusOK)
+	trx := decodeJSON[models.Transaction](t, rec)
+	if trx.PaidAmount != 40000 || trx.Change != 10000 || len(trx.Payments) != 2 {
+		t.Fatalf("transaction = %+v", trx)
+	}
+
+	rec = s.doAuth(http.MethodGet, "/api/transactions/"+strconv.Itoa(trx.ID), nil)
+	expectStatus(t, rec, http.StatusOK)
+	if stored := decodeJSON[models.Transaction](t, rec); len(stored.Payments) != 2 || stored.Payments[0].Reference != "APPR-1234" {
+		t.Errorf("stored payments = %+v", stored.Payments)
+	}
+
+	// pembayaran kurang: ditolak dan stok tidak berubah
+	rec = s.doAuth(http.MethodPost, "/api/checkout", models.CheckoutRequest{
+		Items:    []models.CheckoutItem{{ProductID: roti.ID, Quantity: 1}},
+		Payments: []models.Payment{{Method: models.PaymentCash, Amount: 10000}},
+	})
+	expectStatus(t, rec, http.StatusBadRequest)
+	if stock := s.productStock(roti.ID); stock != 8 {
+		t.Errorf("stock = %d, want 8", stock)
+	}
+}
+
+func TestReportPaymentBreakdown(t *testing.T) {
+	s := newTestServer(t)
+
+	category := s.createCategory("Makanan")
+	roti := s.createProduct("Roti Tawar", 15000, 10, category.ID)
+
+	for _, payments := range [][]models.Payment{
+		{{Method: models.PaymentCash, Amount: 20000}},
+		{{Method: models.PaymentQRIS, Amount: 15000}},
+		{{Method: models.PaymentCard, Amount: 5000}, {Method: models.PaymentCash, Amount: 10000}},
+	} {
+		rec := s.doAuth(http.MethodPost, "/api/checkout", models.CheckoutRequest{
+			Items:    []models.CheckoutItem{{ProductID: roti.ID, Quantity: 1}},
+			Payments: payments,
+		})
+		expectStatus(t, rec, http.StatusOK)
+	}
+
+	rec := s.doAuth(http.MethodGet, "/api/report", nil)
+	expectStatus(t, rec, http.StatusOK)
+	report := decodeJSON[models.SalesReport](t, rec)
+
+	want := map[string]models.PaymentSummary{
+		models.PaymentCard: {Method: models.PaymentCard, Total: 5000, TotalTransaction: 1},
+		models.PaymentCash: {Method: models.PaymentCash, Total: 25000, TotalTransaction: 2},
+		models.PaymentQRIS: {Method: models.PaymentQRIS, Total: 15000, TotalTransaction: 1},
+	}
+	if len(report.PaymentBreakdown) != len(want) {
+		t.Fatalf("breakdown = %+v", report.PaymentBreakdown)
+	}
+	for _, got := range report.PaymentBreakdown {
+		if got != want[got.Method] {
+			t.Errorf("breakdown %s = %+v, want %+v", got.Method, got, want[got.Method])
+		}
+	}
+}
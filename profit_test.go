@@ -0,0 +1,100 @@
+package main
+
+import (
+	"kasir-api/models"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func (s *testServer) createProductWithCost(name string, price float64, cost, stock, categoryID int) models.Product {
+	s.t.Helper()
+
+	rec := s.do(http.MethodPost, "/api/product", models.Product{
+		Name:       name,
+		Price:      price,
+		CostPrice:  cost,
+		Stock:      stock,
+		CategoryID: categoryID,
+	}, nil)
+	expectStatus(s.t, rec, http.StatusCreated)
+	return decodeJSON[models.Product](s.t, rec)
+}
+
+func TestProfitReport(t *testing.T) {
+	s := newTestServer(t)
+
+	makanan := s.createCategory("Makanan")
+	minuman := s.createCategory("Minuman")
+	indomie := s.createProductWithCost("Indomie Goreng", 3500, 2500, 100, makanan.ID)
+	aqua := s.createProductWithCost("Aqua 600ml", 4000, 3000, 100, minuman.ID)
+
+	// HPP dalam rupiah utuh, pecahan ditolak sebelum sampai ke database
+	rec := s.do(http.MethodPost, "/api/product", map[string]any{"name": "Teh Botol", "price": 5000, "cost_price": 3500.5, "category_id": minuman.ID}, nil)
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	// listing publik tanpa API key tidak membawa HPP
+	rec = s.do(http.MethodGet, "/api/product", nil, nil)
+	expectStatus(t, rec, http.StatusOK)
+	for _, p := range decodeJSON[[]map[string]any](t, rec) {
+		if _, ok := p["cost_price"]; ok {
+			t.Errorf("public product list exposes cost_price: %v", p)
+		}
+	}
+
+	trx := s.checkout(
+		models.CheckoutItem{ProductID: indomie.ID, Quantity: 10},
+		models.CheckoutItem{ProductID: aqua.ID, Quantity: 5},
+	)
+	if trx.Details[0].UnitCost != 2500 || trx.Details[1].UnitCost != 3000 {
+		t.Fatalf("details = %+v", trx.Details)
+	}
+
+	// HPP dikelola ledger: cost_price di body update diabaikan, snapshot
+	// transaksi lama juga tetap
+	indomie.CostPrice, indomie.Stock = 3000, 90
+	rec = s.doAuth(http.MethodPut, "/api/product/"+strconv.Itoa(indomie.ID), indomie)
+	expectStatus(t, rec, http.StatusOK)
+	if got := decodeJSON[models.Product](t, rec); got.CostPrice != 2500 {
+		t.Errorf("update response cost_price = %v, want 2500", got.CostPrice)
+	}
+	if got := s.productLocations(indomie.ID); got.CostPrice != 2500 {
+		t.Errorf("cost_price after update = %v, want 2500", got.CostPrice)
+	}
+	if details := s.transactionDetails(trx.ID); details[0].UnitCost != 2500 {
+		t.Errorf("unit cost after cost change = %d, want 2500", details[0].UnitCost)
+	}
+
+	// retur 2 indomie: penjualan bersih 8 x 3500, HPP 8 x 2500
+	rec = s.doAuth(http.MethodPost, "/api/transactions/"+strconv.Itoa(trx.ID)+"/returns", models.ReturnRequest{
+		Reason: "rusak",
+		Items:  []models.ReturnRequestItem{{TransactionDetailID: trx.Details[0].ID, Quantity: 2}},
+	})
+	expectStatus(t, rec, http.StatusCreated)
+
+	rec = s.doAuth(http.MethodGet, "/api/report/profit", nil)
+	expectStatus(t, rec, http.StatusOK)
+	report := decodeJSON[models.ProfitReport](t, rec)
+	if report.NetSales != 48000 || report.TotalCost != 35000 || report.GrossProfit != 13000 || report.Margin != 27.08 {
+		t.Errorf("totals = %+v", report)
+	}
+	if len(report.Products) != 2 || len(report.Categories) != 2 {
+		t.Fatalf("report = %+v", report)
+	}
+	want := models.ProfitLine{ID: indomie.ID, Name: "Indomie Goreng", Quantity: 8, NetSales: 28000, Cost: 20000, GrossProfit: 8000, Margin: 28.57}
+	if report.Products[0] != want {
+		t.Errorf("product line = %+v, want %+v", report.Products[0], want)
+	}
+	if c := report.Categories[1]; c.ID != minuman.ID || c.Name != "Minuman" || c.GrossProfit != 5000 || c.Margin != 25 {
+		t.Errorf("category line = %+v", c)
+	}
+
+	rec = s.doAuth(http.MethodGet, "/api/report", nil)
+	expectStatus(t, rec, http.StatusOK)
+	if sales := decodeJSON[models.SalesReport](t, rec); sales.GrossProfit != 13000 || sales.TotalCost != 35000 || sales.Margin != 27.08 {
+		t.Errorf("sales report = %+v", sales)
+	}
+
+	rec = s.doAuth(http.MethodGet, "/api/report/profit?start_date=kemarin", nil)
+	expectStatus(t, rec, http.StatusBadRequest)
+}
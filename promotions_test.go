@@ -0,0 +1,206 @@
+package main
+
+import (
+	"kasir-api/models"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func (s *testServer) createPromotion(p models.Promotion) models.Promotion {
+	s.t.Helper()
+
+	rec := s.doAuth(http.MethodPost, "/api/promotions", p)
+	expectStatus(s.t, rec, http.StatusCreated)
+	return decodeJSON[models.Promotion](s.t, rec)
+}
+
+func TestPromotionCRUD(t *testing.T) {
+	s := newTestServer(t)
+	now := time.Now()
+
+	promo := s.createPromotion(models.Promotion{
+		Code: "hemat10", Type: models.DiscountPercent, Value: 10,
+		StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour), Active: true,
+	})
+	if promo.ID == 0 || promo.Code != "HEMAT10" {
+		t.Fatalf("promotion = %+v", promo)
+	}
+	path := "/api/promotions/" + strconv.Itoa(promo.ID)
+
+	rec := s.doAuth(http.MethodPost, "/api/promotions", models.Promotion{Code: "HEMAT10", Type: models.DiscountPercent, Value: 10, StartsAt: now, EndsAt: now.Add(time.Hour)})
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = s.doAuth(http.MethodPost, "/api/promotions", models.Promotion{Code: "LEBIH", Type: models.DiscountPercent, Value: 150, StartsAt: now, EndsAt: now.Add(time.Hour)})
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	promo.Value = 15
+	rec = s.doAuth(http.MethodPut, path, promo)
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = s.doAuth(http.MethodGet, path, nil)
+	expectStatus(t, rec, http.StatusOK)
+	if got := decodeJSON[models.Promotion](t, rec); got.Value != 15 {
+		t.Errorf("value = %d, want 15", got.Value)
+	}
+
+	rec = s.doAuth(http.MethodGet, "/api/promotions", nil)
+	expectStatus(t, rec, http.StatusOK)
+	if list := decodeJSON[[]models.Promotion](t, rec); len(list) != 1 {
+		t.Errorf("promotions = %+v", list)
+	}
+
+	rec = s.doAuth(http.MethodDelete, path, nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = s.doAuth(http.MethodGet, path, nil)
+	expectStatus(t, rec, http.StatusNotFound)
+}
+
+func TestCheckoutWithDiscounts(t *testing.T) {
+	s := newTestServer(t)
+	now := time.Now()
+
+	category := s.createCategory("Minuman")
+	kopi := s.createProduct("Kopi Susu", 20000, 10, category.ID)
+	teh := s.createProduct("Es Teh", 5000, 10, category.ID)
+	s.createPromotion(models.Promotion{
+		Code: "NGOPI", Type: models.DiscountFixed, Value: 3000, MinSpend: 30000,
+		StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour), Active: true,
+	})
+
+	rec := s.doAuth(http.MethodPost, "/api/checkout", models.CheckoutRequest{
+		Items: []models.CheckoutItem{
+			{ProductID: kopi.ID, Quantity: 2, Discount: &models.Discount{Type: models.DiscountPercent, Value: 10}},
+			{ProductID: teh.ID, Quantity: 1},
+		},
+		Discount:  &models.Discount{Type: models.DiscountFixed, Value: 1000},
+		PromoCode: "ngopi",
+	})
+	expectStatus(t, rec, http.StatusOK)
+	trx := decodeJSON[models.Transaction](t, rec)
+
+	// 45000 - 4000 (baris) - 1000 (transaksi) - 3000 (promo)
+	if trx.GrossAmount != 45000 || trx.LineDiscount != 4000 || trx.OrderDiscount != 1000 || trx.PromoDiscount != 3000 || trx.TotalAmount != 37000 {
+		t.Fatalf("transaction = %+v", trx)
+	}
+	if trx.PromoCode != "NGOPI" || trx.PaidAmount != 37000 {
+		t.Errorf("promo code = %q, paid = %d", trx.PromoCode, trx.PaidAmount)
+	}
+
+	details := s.transactionDetails(trx.ID)
+	sum := 0
+	for _, d := range details {
+		sum += d.Subtotal
+	}
+	if len(details) != 2 || details[0].UnitPrice != 20000 || details[0].LineDiscount != 4000 || sum != 37000 {
+		t.Errorf("details = %+v", details)
+	}
+
+	// minimal belanja tidak terpenuhi, promo tidak dikenal, diskon tidak valid
+	for _, req := range []models.CheckoutRequest{
+		{Items: []models.CheckoutItem{{ProductID: teh.ID, Quantity: 1}}, PromoCode: "NGOPI"},
+		{Items: []models.CheckoutItem{{ProductID: teh.ID, Quantity: 1}}, PromoCode: "TIDAKADA"},
+		{Items: []models.CheckoutItem{{ProductID: teh.ID, Quantity: 1, Discount: &models.Discount{Type: models.DiscountFixed, Value: 6000}}}},
+	} {
+		rec = s.doAuth(http.MethodPost, "/api/checkout", req)
+		expectStatus(t, rec, http.StatusBadRequest)
+	}
+	if stock := s.productStock(teh.ID); stock != 9 {
+		t.Errorf("stock = %d, want 9", stock)
+	}
+}
+
+func TestPromotionUsageLimit(t *testing.T) {
+	s := newVoidTestServer(t, time.Hour)
+	now := time.Now()
+
+	category := s.createCategory("Makanan")
+	roti := s.createProduct("Roti Tawar", 15000, 10, category.ID)
+	promo := s.createPromotion(models.Promotion{
+		Code: "SEKALI", Type: models.DiscountPercent, Value: 20, UsageLimit: 1,
+		StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour), Active: true,
+	})
+	req := models.CheckoutRequest{
+		Items:     []models.CheckoutItem{{ProductID: roti.ID, Quantity: 1}},
+		PromoCode: "SEKALI",
+	}
+
+	rec := s.doAuth(http.MethodPost, "/api/checkout", req)
+	expectStatus(t, rec, http.StatusOK)
+	trx := decodeJSON[models.Transaction](t, rec)
+	if trx.TotalAmount != 12000 {
+		t.Fatalf("total = %d, want 12000", trx.TotalAmount)
+	}
+
+	rec = s.doAuth(http.MethodPost, "/api/checkout", req)
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	// void mengembalikan kuota promo
+	rec = s.doAuth(http.MethodPost, "/api/transactions/"+strconv.Itoa(trx.ID)+"/void", models.VoidRequest{Reason: "salah input"})
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = s.doAuth(http.MethodGet, "/api/promotions/"+strconv.Itoa(promo.ID), nil)
+	expectStatus(t, rec, http.StatusOK)
+	if got := decodeJSON[models.Promotion](t, rec); got.UsedCount != 0 {
+		t.Errorf("used count = %d, want 0", got.UsedCount)
+	}
+
+	rec = s.doAuth(http.MethodPost, "/api/checkout", req)
+	expectStatus(t, rec, http.StatusOK)
+}
+
+func TestPromotionRulesAtCheckout(t *testing.T) {
+	s := newTestServer(t)
+
+	category := s.createCategory("Minuman")
+	teh := s.createProduct("Es Teh", 5000, 20, category.ID)
+	kopi := s.createProduct("Kopi Susu", 20000, 20, category.ID)
+
+	rec := s.doAuth(http.MethodPost, "/api/promotion-rules", models.PromotionRule{Name: "Tanpa tipe", ProductIDs: []int{teh.ID}, Active: true})
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = s.doAuth(http.MethodPost, "/api/promotion-rules", models.PromotionRule{
+		Name: "Beli 2 Gratis 1 Es Teh", Type: models.RuleBuyXGetY, ProductIDs: []int{teh.ID},
+		BuyQuantity: 2, GetQuantity: 1, Active: true,
+	})
+	expectStatus(t, rec, http.StatusCreated)
+	rule := decodeJSON[models.PromotionRule](t, rec)
+
+	rec = s.doAuth(http.MethodGet, "/api/promotion-rules/"+strconv.Itoa(rule.ID), nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = s.doAuth(http.MethodPost, "/api/checkout", models.CheckoutRequest{
+		Items: []models.CheckoutItem{
+			{ProductID: teh.ID, Quantity: 3},
+			{ProductID: kopi.ID, Quantity: 1},
+		},
+	})
+	expectStatus(t, rec, http.StatusOK)
+	trx := decodeJSON[models.Transaction](t, rec)
+	if trx.RuleDiscount != 5000 || trx.TotalAmount != 30000 {
+		t.Fatalf("transaction = %+v", trx)
+	}
+	if p := trx.Details[0].Promotions; len(p) != 1 || p[0].RuleID != rule.ID || p[0].Quantity != 3 || p[0].Discount != 5000 {
+		t.Errorf("promotions = %+v", p)
+	}
+
+	details := s.transactionDetails(trx.ID)
+	if details[0].RuleDiscount != 5000 || len(details[0].Promotions) != 1 || len(details[1].Promotions) != 0 {
+		t.Errorf("details = %+v", details)
+	}
+
+	// rule nonaktif tidak dipakai lagi
+	rule.Active = false
+	rec = s.doAuth(http.MethodPut, "/api/promotion-rules/"+strconv.Itoa(rule.ID), rule)
+	expectStatus(t, rec, http.StatusOK)
+
+	trx = s.checkout(models.CheckoutItem{ProductID: teh.ID, Quantity: 3})
+	if trx.RuleDiscount != 0 || trx.TotalAmount != 15000 {
+		t.Errorf("transaction = %+v", trx)
+	}
+
+	rec = s.doAuth(http.MethodDelete, "/api/promotion-rules/"+strconv.Itoa(rule.ID), nil)
+	expectStatus(t, rec, http.StatusOK)
+}
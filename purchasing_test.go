@@ -0,0 +1,153 @@
+package main
+
+import (
+	"kasir-api/models"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func (s *testServer) createSupplier(name string) models.Supplier {
+	s.t.Helper()
+
+	rec := s.doAuth(http.MethodPost, "/api/suppliers", models.Supplier{Name: name})
+	expectStatus(s.t, rec, http.StatusCreated)
+	return decodeJSON[models.Supplier](s.t, rec)
+}
+
+func TestSupplierCRUD(t *testing.T) {
+	s := newTestServer(t)
+
+	rec := s.doAuth(http.MethodPost, "/api/suppliers", models.Supplier{Name: " "})
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	supplier := s.createSupplier("PT Sumber Rejeki")
+	path := "/api/suppliers/" + strconv.Itoa(supplier.ID)
+
+	rec = s.doAuth(http.MethodPut, path, models.Supplier{Name: "PT Sumber Rejeki Abadi", Phone: "0812"})
+	expectStatus(t, rec, http.StatusOK)
+	rec = s.doAuth(http.MethodGet, path, nil)
+	expectStatus(t, rec, http.StatusOK)
+	if got := decodeJSON[models.Supplier](t, rec); got.Name != "PT Sumber Rejeki Abadi" || got.Phone != "0812" {
+		t.Errorf("supplier = %+v", got)
+	}
+
+	// supplier yang sudah punya PO tidak bisa dihapus
+	category := s.createCategory("Sembako")
+	gula := s.createProduct("Gula 1kg", 15000, 0, category.ID)
+	rec = s.doAuth(http.MethodPost, "/api/purchase-orders", models.PurchaseOrderRequest{
+		SupplierID: supplier.ID,
+		Lines:      []models.PurchaseOrderLineRequest{{ProductID: gula.ID, Quantity: 1, UnitCost: 12000}},
+	})
+	expectStatus(t, rec, http.StatusCreated)
+	rec = s.doAuth(http.MethodDelete, path, nil)
+	expectStatus(t, rec, http.StatusConflict)
+
+	rec = s.doAuth(http.MethodDelete, "/api/suppliers/999", nil)
+	expectStatus(t, rec, http.StatusNotFound)
+}
+
+func TestPurchaseOrderLifecycle(t *testing.T) {
+	s := newTestServer(t)
+
+	category := s.createCategory("Sembako")
+	// harga pokok awal gula 10.000
+	gula := s.createProductWithCost("Gula 1kg", 15000, 10000, 10, category.ID)
+	kopi := s.createProduct("Kopi Bubuk", 35000, 0, category.ID)
+	supplier := s.createSupplier("PT Sumber Rejeki")
+
+	rec := s.doAuth(http.MethodPost, "/api/purchase-orders", models.PurchaseOrderRequest{
+		SupplierID: supplier.ID,
+		Lines:      []models.PurchaseOrderLineRequest{{ProductID: gula.ID, Quantity: 0, UnitCost: 12000}},
+	})
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = s.doAuth(http.MethodPost, "/api/purchase-orders", models.PurchaseOrderRequest{
+		SupplierID: supplier.ID,
+		Note:       "stok lebaran",
+		Lines: []models.PurchaseOrderLineRequest{
+			{ProductID: gula.ID, Quantity: 10, UnitCost: 12000},
+			{ProductID: kopi.ID, Quantity: 5, UnitCost: 25000},
+		},
+	})
+	expectStatus(t, rec, http.StatusCreated)
+	po := decodeJSON[models.PurchaseOrder](t, rec)
+	if po.Status != models.PurchaseOrderDraft || po.TotalCost != 10*12000+5*25000 || po.SupplierName != "PT Sumber Rejeki" {
+		t.Fatalf("po = %+v", po)
+	}
+	path := "/api/purchase-orders/" + strconv.Itoa(po.ID)
+
+	// belum dikirim, belum bisa diterima
+	rec = s.doAuth(http.MethodPost, path+"/receipts", models.GoodsReceiptRequest{})
+	expectStatus(t, rec, http.StatusConflict)
+
+	rec = s.doAuth(http.MethodPost, path+"/send", nil)
+	expectStatus(t, rec, http.StatusOK)
+	if sent := decodeJSON[models.PurchaseOrder](t, rec); sent.Status != models.PurchaseOrderSent || sent.SentAt == nil {
+		t.Errorf("sent po = %+v", sent)
+	}
+	rec = s.doAuth(http.MethodPut, path, models.PurchaseOrderRequest{
+		SupplierID: supplier.ID,
+		Lines:      []models.PurchaseOrderLineRequest{{ProductID: gula.ID, Quantity: 1, UnitCost: 1}},
+	})
+	expectStatus(t, rec, http.StatusConflict)
+
+	// kiriman pertama: 6 gula dengan harga faktur 13.000
+	invoiceCost := 13000
+	rec = s.doAuth(http.MethodPost, path+"/receipts", models.GoodsReceiptRequest{
+		Items: []models.GoodsReceiptRequestItem{{PurchaseOrderLineID: po.Lines[0].ID, Quantity: 6, UnitCost: &invoiceCost}},
+	})
+	expectStatus(t, rec, http.StatusCreated)
+
+	rec = s.doAuth(http.MethodGet, "/api/product/"+strconv.Itoa(gula.ID), nil)
+	product := decodeJSON[models.ProductResponse](t, rec)
+	// (10*10000 + 6*13000) / 16 = 11125
+	if product.Stock != 16 || product.CostPrice != 11125 {
+		t.Errorf("gula after partial receipt = stock %d cost %d, want 16 / 11125", product.Stock, product.CostPrice)
+	}
+
+	rec = s.doAuth(http.MethodGet, path, nil)
+	if partial := decodeJSON[models.PurchaseOrder](t, rec); partial.Status != models.PurchaseOrderPartiallyReceived || partial.Lines[0].ReceivedQuantity != 6 {
+		t.Errorf("po after partial receipt = %+v", partial)
+	}
+
+	rec = s.doAuth(http.MethodPost, path+"/receipts", models.GoodsReceiptRequest{
+		Items: []models.GoodsReceiptRequestItem{{PurchaseOrderLineID: po.Lines[0].ID, Quantity: 5}},
+	})
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	// sisanya diterima sekaligus
+	rec = s.doAuth(http.MethodPost, path+"/receipts", models.GoodsReceiptRequest{Note: "lunas"})
+	expectStatus(t, rec, http.StatusCreated)
+	if receipt := decodeJSON[models.GoodsReceipt](t, rec); len(receipt.Items) != 2 {
+		t.Errorf("final receipt = %+v", receipt)
+	}
+
+	rec = s.doAuth(http.MethodGet, path, nil)
+	if received := decodeJSON[models.PurchaseOrder](t, rec); received.Status != models.PurchaseOrderReceived || received.ReceivedAt == nil {
+		t.Errorf("po after full receipt = %+v", received)
+	}
+	if stock := s.productStock(kopi.ID); stock != 5 {
+		t.Errorf("kopi stock = %d, want 5", stock)
+	}
+
+	rec = s.doAuth(http.MethodGet, path+"/receipts", nil)
+	expectStatus(t, rec, http.StatusOK)
+	if receipts := decodeJSON[[]models.GoodsReceipt](t, rec); len(receipts) != 2 {
+		t.Errorf("receipts = %+v", receipts)
+	}
+
+	movements := s.stockMovements(kopi.ID)
+	if last := movements[len(movements)-1]; last.Reason != models.StockReasonReceipt || last.Delta != 5 || last.ReferenceType != models.StockRefReceipt {
+		t.Errorf("kopi movement = %+v", last)
+	}
+
+	rec = s.doAuth(http.MethodGet, "/api/purchase-orders?status=received", nil)
+	expectStatus(t, rec, http.StatusOK)
+	if list := decodeJSON[[]models.PurchaseOrder](t, rec); len(list) != 1 || list[0].ID != po.ID {
+		t.Errorf("received list = %+v", list)
+	}
+
+	rec = s.doAuth(http.MethodDelete, path, nil)
+	expectStatus(t, rec, http.StatusConflict)
+}
@@ -0,0 +1,174 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"kasir-api/models"
+
+	"github.com/lib/pq"
+)
+
+// GetByCode - lookup hasil scan: barcode dulu, kalau tidak ada dicoba sebagai SKU
+func (repo *PostgresProductRepository) GetByCode(code string) (*models.ProductResponse, error) {
+	id, err := findProductByCode(repo.db, code, "")
+	if errors.Is(err, ErrBarcodeNotFound) {
+		id, err = findProductByCode(repo.db, "", code)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetByID(id)
+}
+
+// AssignBarcode - buatkan EAN-13 internal dari prefix untuk produk yang belum
+// punya EAN-13 / UPC. Tabel dikunci supaya dua request tidak dapat nomor sama.
+func (repo *PostgresProductRepository) AssignBarcode(productID int, prefix string) (models.Barcode, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return models.Barcode{}, err
+	}
+	defer tx.Rollback()
+
+	// baris produk dikunci supaya tidak balapan dengan update barcode produk ini
+	err = tx.QueryRow("SELECT id FROM products WHERE id = $1 FOR UPDATE", productID).Scan(&productID)
+	if err == sql.ErrNoRows {
+		return models.Barcode{}, ErrProductNotFound
+	}
+	if err != nil {
+		return models.Barcode{}, err
+	}
+	if _, err := tx.Exec("LOCK TABLE product_barcodes IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return models.Barcode{}, err
+	}
+
+	barcodes, err := loadProductBarcodes(tx, []int{productID})
+	if err != nil {
+		return models.Barcode{}, err
+	}
+	if hasRetailBarcode(barcodes[productID]) {
+		return models.Barcode{}, ErrBarcodeAssigned
+	}
+
+	var last string
+	err = tx.QueryRow(`
+		SELECT COALESCE(MAX(code), '')
+		FROM product_barcodes
+		WHERE type = $1 AND LENGTH(code) = 13 AND code LIKE $2 || '%'`,
+		models.BarcodeEAN13, prefix,
+	).Scan(&last)
+	if err != nil {
+		return models.Barcode{}, err
+	}
+	code, err := nextInternalEAN13(prefix, last)
+	if err != nil {
+		return models.Barcode{}, err
+	}
+
+	barcode := models.Barcode{Code: code, Type: models.BarcodeEAN13, Assigned: true}
+	_, err = tx.Exec("INSERT INTO product_barcodes (product_id, code, type, assigned) VALUES ($1, $2, $3, TRUE)", productID, barcode.Code, barcode.Type)
+	if err != nil {
+		return models.Barcode{}, err
+	}
+
+	return barcode, tx.Commit()
+}
+
+// findProductByCode - product id dari barcode atau SKU (isi salah satu)
+func findProductByCode(q queryer, barcode, sku string) (int, error) {
+	var id int
+	var err error
+	if barcode != "" {
+		err = q.QueryRow("SELECT product_id FROM product_barcodes WHERE code = $1", barcode).Scan(&id)
+	} else {
+		err = q.QueryRow("SELECT id FROM products WHERE sku = $1", sku).Scan(&id)
+	}
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("%w: %s%s", ErrBarcodeNotFound, barcode, sku)
+	}
+	return id, err
+}
+
+// saveProductBarcodes - barcode produk disamakan dengan daftar: yang tidak ada
+// dihapus, yang baru ditambahkan, yang tetap tidak disentuh (assigned-nya aman)
+func saveProductBarcodes(tx *sql.Tx, productID int, barcodes []models.Barcode) error {
+	codes := make([]string, len(barcodes))
+	for i, b := range barcodes {
+		codes[i] = b.Code
+	}
+	_, err := tx.Exec("DELETE FROM product_barcodes WHERE product_id = $1 AND code <> ALL($2)", productID, pq.Array(codes))
+	if err != nil {
+		return err
+	}
+
+	// kode yang sudah dipakai produk lain tidak ter-update, 0 baris = bentrok
+	for _, b := range barcodes {
+		result, err := tx.Exec(`
+			INSERT INTO product_barcodes (product_id, code, type) VALUES ($1, $2, $3)
+			ON CONFLICT (code) DO UPDATE SET type = EXCLUDED.type
+			WHERE product_barcodes.product_id = EXCLUDED.product_id`,
+			productID, b.Code, b.Type)
+		if err != nil {
+			return err
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return fmt.Errorf("%w: %s", ErrDuplicateBarcode, b.Code)
+		}
+	}
+	return nil
+}
+
+// RemoveBarcode - hapus satu barcode produk, satu-satunya cara menghapus kode
+// internal dan EAN-13 buatan sistem
+func (repo *PostgresProductRepository) RemoveBarcode(productID int, code string) error {
+	result, err := repo.db.Exec("DELETE FROM product_barcodes WHERE product_id = $1 AND code = $2", productID, code)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows > 0 {
+		return nil
+	}
+
+	var exists bool
+	if err := repo.db.QueryRow("SELECT EXISTS (SELECT 1 FROM products WHERE id = $1)", productID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrProductNotFound
+	}
+	return fmt.Errorf("%w: %s", ErrBarcodeNotFound, code)
+}
+
+// loadProductBarcodes - barcode per product id, urut waktu didaftarkan
+func loadProductBarcodes(q queryer, productIDs []int) (map[int][]models.Barcode, error) {
+	rows, err := q.Query(`
+		SELECT product_id, code, type, assigned
+		FROM product_barcodes
+		WHERE product_id = ANY($1)
+		ORDER BY id`, pq.Array(productIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	barcodes := make(map[int][]models.Barcode)
+	for rows.Next() {
+		var productID int
+		var b models.Barcode
+		if err := rows.Scan(&productID, &b.Code, &b.Type, &b.Assigned); err != nil {
+			return nil, err
+		}
+		barcodes[productID] = append(barcodes[productID], b)
+	}
+
+	return barcodes, rows.Err()
+}
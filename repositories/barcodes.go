@@ -0,0 +1,235 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"kasir-api/models"
+)
+
+var (
+	ErrInvalidSKU       = errors.New("SKU tidak valid")
+	ErrDuplicateSKU     = errors.New("SKU sudah dipakai produk lain")
+	ErrInvalidBarcode   = errors.New("barcode tidak valid")
+	ErrDuplicateBarcode = errors.New("barcode sudah dipakai produk lain")
+	ErrBarcodeNotFound  = errors.New("barcode / SKU tidak ditemukan")
+	ErrBarcodeAssigned  = errors.New("produk sudah punya barcode EAN-13 / UPC")
+	ErrBarcodeRangeFull = errors.New("nomor EAN-13 internal untuk prefix ini sudah habis")
+)
+
+const (
+	maxSKULength     = 64
+	maxBarcodeLength = 64
+)
+
+// normalizeProductCodes - SKU memakai karakter yang sama dengan kode internal
+// supaya bisa dicetak sebagai barcode
+func normalizeProductCodes(p *models.Product) error {
+	p.SKU = strings.TrimSpace(p.SKU)
+	if len(p.SKU) > maxSKULength {
+		return fmt.Errorf("%w: sku maksimal %d karakter", ErrInvalidSKU, maxSKULength)
+	}
+	if p.SKU != "" && !isCodeCharset(p.SKU) {
+		return fmt.Errorf("%w: sku hanya boleh huruf, angka dan - . _ /", ErrInvalidSKU)
+	}
+
+	barcodes, err := NormalizeBarcodes(p.Barcodes)
+	if err != nil {
+		return err
+	}
+	p.Barcodes = barcodes
+	return nil
+}
+
+// NormalizeBarcodes - trim, tentukan jenis kalau kosong dan cek check digit
+// EAN-13 / UPC-A. Barcode yang sama tidak boleh muncul dua kali.
+func NormalizeBarcodes(barcodes []models.Barcode) ([]models.Barcode, error) {
+	if len(barcodes) == 0 {
+		return nil, nil
+	}
+
+	normalized := make([]models.Barcode, len(barcodes))
+	for i, b := range barcodes {
+		b.Code = strings.TrimSpace(b.Code)
+		b.Type = strings.ToLower(strings.TrimSpace(b.Type))
+		b.Assigned = false
+		if b.Type == "" {
+			b.Type = detectBarcodeType(b.Code)
+		}
+		if err := validateBarcode(b); err != nil {
+			return nil, err
+		}
+		for _, prev := range normalized[:i] {
+			if prev.Code == b.Code {
+				return nil, fmt.Errorf("%w: %s duplikat", ErrInvalidBarcode, b.Code)
+			}
+		}
+		normalized[i] = b
+	}
+	return normalized, nil
+}
+
+func detectBarcodeType(code string) string {
+	switch {
+	case len(code) == 13 && isDigits(code):
+		return models.BarcodeEAN13
+	case len(code) == 12 && isDigits(code):
+		return models.BarcodeUPC
+	default:
+		return models.BarcodeInternal
+	}
+}
+
+func validateBarcode(b models.Barcode) error {
+	switch b.Type {
+	case models.BarcodeEAN13, models.BarcodeUPC:
+		length := 13
+		if b.Type == models.BarcodeUPC {
+			length = 12
+		}
+		if len(b.Code) != length || !isDigits(b.Code) {
+			return fmt.Errorf("%w: %s harus %d digit angka", ErrInvalidBarcode, b.Type, length)
+		}
+		payload, check := b.Code[:length-1], int(b.Code[length-1]-'0')
+		if gs1CheckDigit(payload) != check {
+			return fmt.Errorf("%w: check digit %s salah", ErrInvalidBarcode, b.Code)
+		}
+	case models.BarcodeInternal:
+		if b.Code == "" || len(b.Code) > maxBarcodeLength {
+			return fmt.Errorf("%w: kode internal harus 1-%d karakter", ErrInvalidBarcode, maxBarcodeLength)
+		}
+		if !isCodeCharset(b.Code) {
+			return fmt.Errorf("%w: kode internal hanya boleh huruf, angka dan - . _ /", ErrInvalidBarcode)
+		}
+	default:
+		return fmt.Errorf("%w: jenis %q tidak dikenal", ErrInvalidBarcode, b.Type)
+	}
+	return nil
+}
+
+// nextInternalEAN13 - EAN-13 berikutnya setelah last (kode terbesar dengan
+// prefix yang sama, kosong = belum ada): prefix + nomor urut + check digit
+func nextInternalEAN13(prefix, last string) (string, error) {
+	width := 12 - len(prefix)
+	next := 1
+	if last != "" {
+		n, err := strconv.Atoi(last[len(prefix):12])
+		if err != nil {
+			return "", err
+		}
+		next = n + 1
+	}
+	if len(strconv.Itoa(next)) > width {
+		return "", fmt.Errorf("%w (prefix %s)", ErrBarcodeRangeFull, prefix)
+	}
+
+	payload := prefix + fmt.Sprintf("%0*d", width, next)
+	return payload + strconv.Itoa(gs1CheckDigit(payload)), nil
+}
+
+// mergeBarcodes - barcode hasil update: daftar baru, ditambah kode internal dan
+// EAN-13 buatan sistem yang tersimpan walaupun tidak ikut dikirim. Urutannya
+// sama dengan Postgres (urut id): barcode lama yang tetap, lalu yang baru.
+func mergeBarcodes(stored, barcodes []models.Barcode) []models.Barcode {
+	merged := make([]models.Barcode, 0, len(stored)+len(barcodes))
+	for _, s := range stored {
+		i := slices.IndexFunc(barcodes, func(b models.Barcode) bool { return b.Code == s.Code })
+		switch {
+		case i >= 0 && !s.Assigned:
+			merged = append(merged, barcodes[i])
+		case i >= 0 || s.Assigned || s.Type == models.BarcodeInternal:
+			merged = append(merged, s)
+		}
+	}
+	for _, b := range barcodes {
+		if !slices.ContainsFunc(stored, func(s models.Barcode) bool { return s.Code == b.Code }) {
+			merged = append(merged, b)
+		}
+	}
+	return merged
+}
+
+// hasRetailBarcode - EAN-13 / UPC sudah ada, tidak perlu dibuatkan lagi
+func hasRetailBarcode(barcodes []models.Barcode) bool {
+	for _, b := range barcodes {
+		if b.Type == models.BarcodeEAN13 || b.Type == models.BarcodeUPC {
+			return true
+		}
+	}
+	return false
+}
+
+// gs1CheckDigit - check digit GS1 (EAN / UPC): dari digit paling kanan bobotnya
+// 3, 1, 3, ... lalu dibulatkan ke kelipatan 10 berikutnya
+func gs1CheckDigit(payload string) int {
+	sum := 0
+	for i := len(payload) - 1; i >= 0; i-- {
+		d := int(payload[i] - '0')
+		if (len(payload)-1-i)%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return (10 - sum%10) % 10
+}
+
+func isCodeCharset(code string) bool {
+	for _, r := range code {
+		if !isDigit(r) && (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') && !strings.ContainsRune("-._/", r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if !isDigit(r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// checkoutCodeRef - barcode atau SKU dari item checkout, maksimal salah satu
+func checkoutCodeRef(item models.CheckoutItem) (string, error) {
+	barcode, sku := strings.TrimSpace(item.Barcode), strings.TrimSpace(item.SKU)
+	switch {
+	case barcode != "" && sku != "":
+		return "", fmt.Errorf("%w: isi barcode atau sku, tidak keduanya", ErrInvalidBarcode)
+	case barcode != "":
+		return barcode, nil
+	default:
+		return sku, nil
+	}
+}
+
+// resolveCheckoutCodes - item yang dikirim lewat barcode / SKU diisi product
+// id-nya. find mencari barcode dulu, lalu SKU. Kalau ID juga diisi, harus sama.
+func resolveCheckoutCodes(items []models.CheckoutItem, find func(barcode, sku string) (int, error)) error {
+	for i := range items {
+		item := &items[i]
+		if item.Barcode == "" && item.SKU == "" {
+			continue
+		}
+		id, err := find(strings.TrimSpace(item.Barcode), strings.TrimSpace(item.SKU))
+		if err != nil {
+			return err
+		}
+		switch itemProductID(*item) {
+		case 0:
+			item.ProductID = id
+		case id:
+		default:
+			return fmt.Errorf("%w: %s%s bukan milik product id %d", ErrInvalidBarcode, item.Barcode, item.SKU, itemProductID(*item))
+		}
+	}
+	return nil
+}
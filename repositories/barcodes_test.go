@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"kasir-api/models"
+)
+
+func TestGS1CheckDigit(t *testing.T) {
+	for _, code := range []string{"4006381333931", "8992761111106", "036000291452", "0000000000000"} {
+		payload, want := code[:len(code)-1], int(code[len(code)-1]-'0')
+		if got := gs1CheckDigit(payload); got != want {
+			t.Errorf("gs1CheckDigit(%s) = %d, want %d", payload, got, want)
+		}
+	}
+}
+
+func TestNormalizeBarcodes(t *testing.T) {
+	got, err := NormalizeBarcodes([]models.Barcode{{Code: " 4006381333931 "}, {Code: "036000291452"}, {Code: "SKU-1", Type: " INTERNAL "}})
+	if err != nil || got[0].Code != "4006381333931" || got[0].Type != models.BarcodeEAN13 ||
+		got[1].Type != models.BarcodeUPC || got[2].Type != models.BarcodeInternal {
+		t.Errorf("NormalizeBarcodes = %+v, %v", got, err)
+	}
+
+	for _, barcodes := range [][]models.Barcode{
+		{{Code: "4006381333932"}},
+		{{Code: "400638133393", Type: models.BarcodeEAN13}},
+		{{Code: "036000291452", Type: "qr"}},
+		{{Code: " "}},
+		{{Code: "KODE#1"}},
+		{{Code: "SKU-1"}, {Code: "SKU-1"}},
+	} {
+		if _, err := NormalizeBarcodes(barcodes); !errors.Is(err, ErrInvalidBarcode) {
+			t.Errorf("NormalizeBarcodes(%+v) err = %v, want ErrInvalidBarcode", barcodes, err)
+		}
+	}
+}
+
+func TestMergeBarcodes(t *testing.T) {
+	stored := []models.Barcode{
+		{Code: "8992761111106", Type: models.BarcodeEAN13},
+		{Code: "2000000000015", Type: models.BarcodeEAN13, Assigned: true},
+		{Code: "RAK-A1", Type: models.BarcodeInternal},
+		{Code: "036000291452", Type: models.BarcodeUPC},
+	}
+
+	// barcode pabrik yang tidak dikirim dilepas, internal / buatan sistem tetap
+	got := mergeBarcodes(stored, []models.Barcode{{Code: "4006381333931", Type: models.BarcodeEAN13}, {Code: "036000291452", Type: models.BarcodeUPC}})
+	want := []models.Barcode{stored[1], stored[2], stored[3], {Code: "4006381333931", Type: models.BarcodeEAN13}}
+	if !slices.Equal(got, want) {
+		t.Errorf("mergeBarcodes = %+v, want %+v", got, want)
+	}
+
+	// dikirim ulang tanpa flag assigned: flag dari yang tersimpan
+	if got := mergeBarcodes(stored, []models.Barcode{{Code: "2000000000015", Type: models.BarcodeEAN13}}); !slices.Equal(got, stored[1:3]) {
+		t.Errorf("mergeBarcodes resend = %+v", got)
+	}
+}
+
+func TestNextInternalEAN13(t *testing.T) {
+	for _, c := range []struct{ prefix, last, want string }{
+		{"200", "", "2000000000015"},
+		{"200", "2000000000015", "2000000000022"},
+		{"299123456", "2991234560997", "2991234561000"},
+	} {
+		if got, err := nextInternalEAN13(c.prefix, c.last); err != nil || got != c.want {
+			t.Errorf("nextInternalEAN13(%s, %s) = %s, %v, want %s", c.prefix, c.last, got, err, c.want)
+		}
+	}
+	if _, err := nextInternalEAN13("299123456", "2991234569990"); !errors.Is(err, ErrBarcodeRangeFull) {
+		t.Errorf("range penuh err = %v", err)
+	}
+}
+
+// TestPostgresUpdateKeepsInternalBarcodes - update hanya menyentuh barcode kalau
+// barcodes dikirim, kode internal / buatan sistem hanya hilang lewat RemoveBarcode
+func TestPostgresUpdateKeepsInternalBarcodes(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewProductRepository(db)
+	roti := createTestProduct(t, db, models.Product{Name: "Roti Tawar", Price: 16500, Barcodes: []models.Barcode{{Code: "RAK-A1"}}})
+	createTestProduct(t, db, models.Product{Name: "Susu", Price: 8000, Barcodes: []models.Barcode{{Code: "036000291452"}}})
+
+	assigned, err := repo.AssignBarcode(roti.ID, "200")
+	if err != nil || !assigned.Assigned {
+		t.Fatalf("assign = %+v, %v", assigned, err)
+	}
+	barcodes := func() []models.Barcode {
+		p, err := repo.GetByID(roti.ID)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		return p.Barcodes
+	}
+	update := func(list *[]models.Barcode) error {
+		return repo.Update(&models.ProductUpdate{Product: models.Product{ID: roti.ID, Name: roti.Name, Price: roti.Price}, Barcodes: list})
+	}
+
+	want := []models.Barcode{{Code: "RAK-A1", Type: models.BarcodeInternal}, assigned}
+	if err := update(nil); err != nil || !slices.Equal(barcodes(), want) {
+		t.Errorf("update without barcodes = %+v, %v", barcodes(), err)
+	}
+
+	want = append(want, models.Barcode{Code: "4006381333931", Type: models.BarcodeEAN13})
+	if err := update(&[]models.Barcode{{Code: "4006381333931"}}); err != nil || !slices.Equal(barcodes(), want) {
+		t.Errorf("update with barcodes = %+v, %v, want %+v", barcodes(), err, want)
+	}
+	if err := update(&[]models.Barcode{{Code: "036000291452"}}); !errors.Is(err, ErrDuplicateBarcode) {
+		t.Errorf("barcode of another product: err = %v, want ErrDuplicateBarcode", err)
+	}
+
+	if err := repo.RemoveBarcode(roti.ID, assigned.Code); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := repo.RemoveBarcode(roti.ID, assigned.Code); !errors.Is(err, ErrBarcodeNotFound) {
+		t.Errorf("remove twice: err = %v, want ErrBarcodeNotFound", err)
+	}
+	if got := barcodes(); len(got) != 2 || got[0].Code != "RAK-A1" {
+		t.Errorf("barcodes after remove = %+v", got)
+	}
+}
@@ -0,0 +1,188 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"kasir-api/models"
+)
+
+// ListBatches - batch dengan stok, urut FEFO
+func (repo *PostgresInventoryRepository) ListBatches(filter models.BatchFilter) ([]models.Batch, error) {
+	return loadBatches(repo.db, filter, false)
+}
+
+// loadBatches - batch dengan stok > 0, forUpdate mengunci baris batch
+func loadBatches(q queryer, filter models.BatchFilter, forUpdate bool) ([]models.Batch, error) {
+	query := `
+		SELECT b.id, b.product_id, p.name, b.location_id, l.name, b.lot_number,
+			COALESCE(TO_CHAR(b.expiry_date, 'YYYY-MM-DD'), ''), b.quantity, b.created_at
+		FROM batches b
+		JOIN products p ON p.id = b.product_id
+		JOIN locations l ON l.id = b.location_id
+		WHERE b.quantity > 0
+			AND ($1 = 0 OR b.product_id = $1)
+			AND ($2 = 0 OR b.location_id = $2)
+			AND ($3 = '' OR b.expiry_date <= NULLIF($3, '')::date)
+		ORDER BY b.expiry_date NULLS LAST, b.id`
+	if forUpdate {
+		query += " FOR UPDATE OF b"
+	}
+
+	rows, err := q.Query(query, filter.ProductID, filter.LocationID, filter.ExpiresBy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	batches := make([]models.Batch, 0)
+	for rows.Next() {
+		var b models.Batch
+		if err := rows.Scan(&b.ID, &b.ProductID, &b.ProductName, &b.LocationID, &b.LocationName, &b.LotNumber, &b.ExpiryDate, &b.Quantity, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		batches = append(batches, b)
+	}
+
+	return batches, rows.Err()
+}
+
+// moveBatches - stok masuk ditambahkan ke lot di m.Batches (lot baru dibuat),
+// stok keluar diambil FEFO (atau dari lot yang diminta) dan dicatat ke m.Batches
+func moveBatches(tx *sql.Tx, m *models.StockMovement) error {
+	if m.Delta > 0 {
+		for i := range m.Batches {
+			b := &m.Batches[i]
+			err := tx.QueryRow(`
+				INSERT INTO batches (product_id, location_id, lot_number, expiry_date, quantity)
+				VALUES ($1, $2, $3, NULLIF($4, '')::date, $5)
+				ON CONFLICT (product_id, location_id, lot_number, COALESCE(expiry_date, 'infinity'::date))
+				DO UPDATE SET quantity = batches.quantity + EXCLUDED.quantity
+				RETURNING id`,
+				m.ProductID, m.LocationID, b.LotNumber, b.ExpiryDate, b.Quantity,
+			).Scan(&b.BatchID)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if m.Delta == 0 {
+		return nil
+	}
+
+	batches, err := loadBatches(tx, models.BatchFilter{ProductID: m.ProductID, LocationID: m.LocationID}, true)
+	if err != nil {
+		return err
+	}
+	lot := ""
+	if len(m.Batches) > 0 {
+		lot = m.Batches[0].LotNumber
+	}
+	sale := m.Reason == models.StockReasonSale
+	allocations, err := pickBatches(batches, lot, -m.Delta, unbatchedStock(m.StockAfter-m.Delta, batches), sale, today())
+	if err != nil {
+		return err
+	}
+	for _, a := range allocations {
+		if _, err := tx.Exec("UPDATE batches SET quantity = quantity - $1 WHERE id = $2", a.Quantity, a.BatchID); err != nil {
+			return err
+		}
+	}
+	m.Batches = allocations
+	return nil
+}
+
+// insertBatchMovements - catat batch yang dipakai movement m (setelah m.ID ada)
+func insertBatchMovements(tx *sql.Tx, m *models.StockMovement) error {
+	sign := 1
+	if m.Delta < 0 {
+		sign = -1
+	}
+	for _, a := range m.Batches {
+		_, err := tx.Exec(`
+			INSERT INTO batch_movements (movement_id, batch_id, quantity) VALUES ($1, $2, $3)
+			ON CONFLICT (movement_id, batch_id) DO UPDATE SET quantity = batch_movements.quantity + EXCLUDED.quantity`,
+			m.ID, a.BatchID, sign*a.Quantity,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// soldLots - lot yang terjual di transaksi untuk satu produk dan belum diretur
+func soldLots(q queryer, transactionID, productID int) ([]models.BatchAllocation, error) {
+	return queryLots(q, `
+		SELECT b.id, b.lot_number, COALESCE(TO_CHAR(b.expiry_date, 'YYYY-MM-DD'), ''), -SUM(bm.quantity)
+		FROM batch_movements bm
+		JOIN stock_movements sm ON sm.id = bm.movement_id
+		JOIN batches b ON b.id = bm.batch_id
+		WHERE sm.product_id = $1
+			AND ((sm.reference_type = $2 AND sm.reference_id = $3)
+				OR (sm.reference_type = $4 AND sm.reference_id IN (SELECT id FROM returns WHERE transaction_id = $3)))
+		GROUP BY b.id
+		HAVING -SUM(bm.quantity) > 0
+		ORDER BY b.id`,
+		productID, models.StockRefTransaction, transactionID, models.StockRefReturn,
+	)
+}
+
+// shippedLots - lot yang dikirim dari lokasi asal lewat transfer
+func shippedLots(q queryer, transferID, productID int) ([]models.BatchAllocation, error) {
+	return queryLots(q, `
+		SELECT b.id, b.lot_number, COALESCE(TO_CHAR(b.expiry_date, 'YYYY-MM-DD'), ''), -SUM(bm.quantity)
+		FROM batch_movements bm
+		JOIN stock_movements sm ON sm.id = bm.movement_id
+		JOIN batches b ON b.id = bm.batch_id
+		WHERE sm.product_id = $1 AND sm.reference_type = $2 AND sm.reference_id = $3 AND sm.delta < 0
+		GROUP BY b.id
+		ORDER BY b.id`,
+		productID, models.StockRefTransfer, transferID,
+	)
+}
+
+func queryLots(q queryer, query string, args ...any) ([]models.BatchAllocation, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lots := make([]models.BatchAllocation, 0)
+	for rows.Next() {
+		var a models.BatchAllocation
+		if err := rows.Scan(&a.BatchID, &a.LotNumber, &a.ExpiryDate, &a.Quantity); err != nil {
+			return nil, err
+		}
+		lots = append(lots, a)
+	}
+
+	return lots, rows.Err()
+}
+
+// loadMovementBatches - batch per movement id untuk ledger satu produk
+func loadMovementBatches(q queryer, productID int) (map[int][]models.BatchAllocation, error) {
+	rows, err := q.Query(`
+		SELECT bm.movement_id, b.id, b.lot_number, COALESCE(TO_CHAR(b.expiry_date, 'YYYY-MM-DD'), ''), ABS(bm.quantity)
+		FROM batch_movements bm
+		JOIN batches b ON b.id = bm.batch_id
+		WHERE b.product_id = $1
+		ORDER BY bm.movement_id, b.id`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byMovement := make(map[int][]models.BatchAllocation)
+	for rows.Next() {
+		var movementID int
+		var a models.BatchAllocation
+		if err := rows.Scan(&movementID, &a.BatchID, &a.LotNumber, &a.ExpiryDate, &a.Quantity); err != nil {
+			return nil, err
+		}
+		byMovement[movementID] = append(byMovement[movementID], a)
+	}
+
+	return byMovement, rows.Err()
+}
@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"kasir-api/models"
+)
+
+var (
+	ErrInvalidBatch      = errors.New("lot tidak valid")
+	ErrBatchExpired      = errors.New("stok yang tersisa sudah kedaluwarsa")
+	ErrInsufficientBatch = errors.New("stok lot tidak mencukupi")
+)
+
+const maxLotNumberLength = 50
+
+// ValidateLot - lot number maksimal 50 karakter, expiry_date format YYYY-MM-DD
+// dan hanya boleh diisi bersama lot number
+func ValidateLot(lot, expiry string) error {
+	if len(lot) > maxLotNumberLength {
+		return fmt.Errorf("%w: lot_number maksimal %d karakter", ErrInvalidBatch, maxLotNumberLength)
+	}
+	if expiry == "" {
+		return nil
+	}
+	if lot == "" {
+		return fmt.Errorf("%w: expiry_date butuh lot_number", ErrInvalidBatch)
+	}
+	if _, err := time.Parse(models.ExpiryDateLayout, expiry); err != nil {
+		return fmt.Errorf("%w: expiry_date harus format YYYY-MM-DD", ErrInvalidBatch)
+	}
+	return nil
+}
+
+// batchLots - alokasi lot untuk satu item adjustment / receipt, kosong kalau tanpa lot
+func batchLots(lot, expiry string, quantity int) []models.BatchAllocation {
+	lot = strings.TrimSpace(lot)
+	if lot == "" {
+		return nil
+	}
+	return []models.BatchAllocation{{LotNumber: lot, ExpiryDate: expiry, Quantity: max(quantity, -quantity)}}
+}
+
+// today - tanggal hari ini untuk dibandingkan dengan expiry_date
+func today() string {
+	return time.Now().Format(models.ExpiryDateLayout)
+}
+
+// isExpired - batch masih boleh dijual sampai akhir hari expiry_date
+func isExpired(expiry, today string) bool {
+	return expiry != "" && expiry < today
+}
+
+// sortFEFO - yang paling cepat kedaluwarsa duluan, lot tanpa tanggal paling akhir
+func sortFEFO(batches []models.Batch) {
+	sort.SliceStable(batches, func(i, j int) bool {
+		a, b := batches[i].ExpiryDate, batches[j].ExpiryDate
+		if a != b {
+			return b == "" || (a != "" && a < b)
+		}
+		return batches[i].ID < batches[j].ID
+	})
+}
+
+// pickBatches - batch untuk stok keluar sebanyak quantity dari batches (stok
+// lokasi, quantity > 0). Kalau lot diisi hanya lot itu yang dipakai. Tanpa lot,
+// batch diambil FEFO lalu sisanya dari stok tanpa lot (unbatched). Penjualan
+// tidak boleh mengambil batch kedaluwarsa.
+func pickBatches(batches []models.Batch, lot string, quantity, unbatched int, sale bool, today string) ([]models.BatchAllocation, error) {
+	sorted := append([]models.Batch(nil), batches...)
+	sortFEFO(sorted)
+
+	allocations := make([]models.BatchAllocation, 0)
+	for _, b := range sorted {
+		if quantity == 0 {
+			break
+		}
+		if lot != "" && b.LotNumber != lot {
+			continue
+		}
+		if sale && isExpired(b.ExpiryDate, today) {
+			continue
+		}
+		n := min(b.Quantity, quantity)
+		allocations = append(allocations, models.BatchAllocation{BatchID: b.ID, LotNumber: b.LotNumber, ExpiryDate: b.ExpiryDate, Quantity: n})
+		quantity -= n
+	}
+
+	switch {
+	case quantity == 0:
+	case lot != "":
+		return nil, fmt.Errorf("%w: lot %s", ErrInsufficientBatch, lot)
+	case sale && quantity > unbatched:
+		return nil, ErrBatchExpired
+	}
+	return allocations, nil
+}
+
+// unbatchedStock - stok lokasi yang tidak tercakup batch mana pun
+func unbatchedStock(locationStock int, batches []models.Batch) int {
+	for _, b := range batches {
+		locationStock -= b.Quantity
+	}
+	return max(locationStock, 0)
+}
+
+// takeLots - ambil lot untuk dikembalikan ke stok sampai quantity, sisanya
+// masuk sebagai stok tanpa lot
+func takeLots(lots []models.BatchAllocation, quantity int) []models.BatchAllocation {
+	taken := make([]models.BatchAllocation, 0, len(lots))
+	for _, lot := range lots {
+		if quantity == 0 {
+			break
+		}
+		lot.Quantity = min(lot.Quantity, quantity)
+		quantity -= lot.Quantity
+		taken = append(taken, lot)
+	}
+	return taken
+}
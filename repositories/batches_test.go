@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+
+	"kasir-api/models"
+)
+
+func TestPickBatchesFEFO(t *testing.T) {
+	batches := []models.Batch{
+		{ID: 1, LotNumber: "TANPA", Quantity: 5},
+		{ID: 2, LotNumber: "B2", ExpiryDate: "2026-03-01", Quantity: 4},
+		{ID: 3, LotNumber: "B1", ExpiryDate: "2026-02-01", Quantity: 3},
+		{ID: 4, LotNumber: "LAMA", ExpiryDate: "2026-01-01", Quantity: 2},
+	}
+
+	picked, err := pickBatches(batches, "", 6, 0, true, "2026-01-15")
+	if err != nil || len(picked) != 2 || picked[0].LotNumber != "B1" || picked[0].Quantity != 3 ||
+		picked[1].LotNumber != "B2" || picked[1].Quantity != 3 {
+		t.Errorf("sale = %+v, %v", picked, err)
+	}
+
+	// bukan penjualan: yang kedaluwarsa keluar duluan
+	picked, err = pickBatches(batches, "", 3, 0, false, "2026-01-15")
+	if err != nil || picked[0].LotNumber != "LAMA" || picked[0].Quantity != 2 || picked[1].LotNumber != "B1" {
+		t.Errorf("write-off = %+v, %v", picked, err)
+	}
+
+	// sisa di luar batch diambil dari stok tanpa lot
+	picked, err = pickBatches(batches, "", 14, 2, true, "2026-01-15")
+	if err != nil || len(picked) != 3 || picked[2].LotNumber != "TANPA" {
+		t.Errorf("with unbatched = %+v, %v", picked, err)
+	}
+	if _, err := pickBatches(batches, "", 15, 2, true, "2026-01-15"); !errors.Is(err, ErrBatchExpired) {
+		t.Errorf("expired shortfall err = %v", err)
+	}
+	if _, err := pickBatches(batches, "B1", 4, 10, false, "2026-01-15"); !errors.Is(err, ErrInsufficientBatch) {
+		t.Errorf("lot shortfall err = %v", err)
+	}
+}
+
+func TestValidateLot(t *testing.T) {
+	valid := [][2]string{{"", ""}, {"L-01", ""}, {"L-01", "2026-12-31"}}
+	for _, c := range valid {
+		if err := ValidateLot(c[0], c[1]); err != nil {
+			t.Errorf("ValidateLot(%q, %q) = %v", c[0], c[1], err)
+		}
+	}
+	invalid := [][2]string{{"", "2026-12-31"}, {"L-01", "31-12-2026"}, {string(make([]byte, 51)), ""}}
+	for _, c := range invalid {
+		if err := ValidateLot(c[0], c[1]); !errors.Is(err, ErrInvalidBatch) {
+			t.Errorf("ValidateLot(%q, %q) = %v, want ErrInvalidBatch", c[0], c[1], err)
+		}
+	}
+}
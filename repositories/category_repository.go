@@ -6,16 +6,16 @@ import (
 	"kasir-api/models"
 )
 
-type CategoryRepository struct {
+type PostgresCategoryRepository struct {
 	db *sql.DB
 }
 
-func NewCategoryRepository(db *sql.DB) *CategoryRepository {
-	return &CategoryRepository{db: db}
+func NewCategoryRepository(db *sql.DB) *PostgresCategoryRepository {
+	return &PostgresCategoryRepository{db: db}
 }
 
-func (repo *CategoryRepository) GetAll() ([]models.Category, error) {
-	query := "SELECT id, name, description FROM categories"
+func (repo *PostgresCategoryRepository) GetAll() ([]models.Category, error) {
+	query := "SELECT id, name, description, COALESCE(tax_rate_id, 0) FROM categories"
 	rows, err := repo.db.Query(query)
 	if err != nil {
 		return nil, err
@@ -25,7 +25,7 @@ func (repo *CategoryRepository) GetAll() ([]models.Category, error) {
 	categories := make([]models.Category, 0)
 	for rows.Next() {
 		var p models.Category
-		err := rows.Scan(&p.ID, &p.Name, &p.Description)
+		err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.TaxRateID)
 		if err != nil {
 			return nil, err
 		}
@@ -35,18 +35,18 @@ func (repo *CategoryRepository) GetAll() ([]models.Category, error) {
 	return categories, nil
 }
 
-func (repo *CategoryRepository) Create(category *models.Category) error {
-	query := "INSERT INTO categories (name, description) VALUES ($1, $2) RETURNING id"
-	err := repo.db.QueryRow(query, category.Name, category.Description).Scan(&category.ID)
+func (repo *PostgresCategoryRepository) Create(category *models.Category) error {
+	query := "INSERT INTO categories (name, description, tax_rate_id) VALUES ($1, $2, $3) RETURNING id"
+	err := repo.db.QueryRow(query, category.Name, category.Description, nullableID(category.TaxRateID)).Scan(&category.ID)
 	return err
 }
 
 // GetByID - ambil category by ID
-func (repo *CategoryRepository) GetByID(id int) (*models.Category, error) {
-	query := "SELECT id, name, description, stock FROM cateories WHERE id = $1"
+func (repo *PostgresCategoryRepository) GetByID(id int) (*models.Category, error) {
+	query := "SELECT id, name, description, COALESCE(tax_rate_id, 0) FROM categories WHERE id = $1"
 
 	var c models.Category
-	err := repo.db.QueryRow(query, id).Scan(&c.ID, &c.Name, &c.Description)
+	err := repo.db.QueryRow(query, id).Scan(&c.ID, &c.Name, &c.Description, &c.TaxRateID)
 	if err == sql.ErrNoRows {
 		return nil, errors.New("category tidak ditemukan")
 	}
@@ -57,9 +57,9 @@ func (repo *CategoryRepository) GetByID(id int) (*models.Category, error) {
 	return &c, nil
 }
 
-func (repo *CategoryRepository) Update(category *models.Category) error {
-	query := "UPDATE products SET name = $1, description = $2 WHERE id = $4"
-	result, err := repo.db.Exec(query, category.Name, category.Description)
+func (repo *PostgresCategoryRepository) Update(category *models.Category) error {
+	query := "UPDATE categories SET name = $1, description = $2, tax_rate_id = $3 WHERE id = $4"
+	result, err := repo.db.Exec(query, category.Name, category.Description, nullableID(category.TaxRateID), category.ID)
 	if err != nil {
 		return err
 	}
@@ -76,7 +76,7 @@ func (repo *CategoryRepository) Update(category *models.Category) error {
 	return nil
 }
 
-func (repo *CategoryRepository) Delete(id int) error {
+func (repo *PostgresCategoryRepository) Delete(id int) error {
 	query := "DELETE FROM categories WHERE id = $1"
 	result, err := repo.db.Exec(query, id)
 	if err != nil {
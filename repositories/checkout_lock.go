@@ -0,0 +1,188 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"kasir-api/models"
+)
+
+// LockStrategy - cara checkout mengunci stok supaya dua checkout bersamaan tidak oversell
+type LockStrategy string
+
+const (
+	// LockPessimistic - SELECT ... FOR UPDATE, baris produk dikunci urut product id
+	// supaya dua transaksi dengan item yang sama tidak saling deadlock
+	LockPessimistic LockStrategy = "pessimistic"
+
+	// LockOptimistic - tanpa row lock di awal, stok dikurangi dengan
+	// UPDATE ... WHERE stock >= qty, transaksi diulang kalau kena konflik
+	LockOptimistic LockStrategy = "optimistic"
+)
+
+// ErrInvalidCheckout - item checkout kosong atau quantity tidak valid
+var ErrInvalidCheckout = errors.New("checkout tidak valid")
+
+// maxCheckoutRetries - berapa kali checkout diulang kalau Postgres
+// mengembalikan serialization failure / deadlock
+const maxCheckoutRetries = 3
+
+func ParseLockStrategy(s string) (LockStrategy, error) {
+	switch LockStrategy(strings.ToLower(strings.TrimSpace(s))) {
+	case "", LockPessimistic:
+		return LockPessimistic, nil
+	case LockOptimistic:
+		return LockOptimistic, nil
+	default:
+		return "", fmt.Errorf("lock strategy tidak dikenal: %s", s)
+	}
+}
+
+func (s LockStrategy) validate() error {
+	if s != LockPessimistic && s != LockOptimistic {
+		return fmt.Errorf("lock strategy tidak dikenal: %s", s)
+	}
+	return nil
+}
+
+// productSnapshot - data produk yang dibaca di dalam transaksi checkout,
+// stock adalah stok di lokasi checkout
+type productSnapshot struct {
+	name       string
+	price      int
+	cost       int
+	stock      int
+	categoryID int
+	taxRateID  int
+	taxName    string
+	taxRate    float64
+
+	// parentID - induk kalau produk ini varian, hasVariants - produk induk
+	// yang tidak bisa dijual langsung
+	parentID    int
+	hasVariants bool
+}
+
+// checkoutProductIDs - product id unik, terurut. Urutan ini yang dipakai untuk
+// mengunci / meng-update baris produk.
+func checkoutProductIDs(items []models.CheckoutItem) []int {
+	seen := make(map[int]bool)
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		if id := itemProductID(item); !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// checkoutQuantities - total quantity per product id (item yang sama bisa muncul dua kali)
+func checkoutQuantities(items []models.CheckoutItem) map[int]int {
+	quantities := make(map[int]int)
+	for _, item := range items {
+		quantities[itemProductID(item)] += item.Quantity
+	}
+	return quantities
+}
+
+func validateCheckoutItems(items []models.CheckoutItem) error {
+	if len(items) == 0 {
+		return fmt.Errorf("%w: items tidak boleh kosong", ErrInvalidCheckout)
+	}
+	for i := range items {
+		item := &items[i]
+		if item.Quantity <= 0 {
+			return fmt.Errorf("%w: quantity for product id %d must be greater than 0", ErrInvalidCheckout, itemProductID(*item))
+		}
+		if _, err := checkoutCodeRef(*item); err != nil {
+			return err
+		}
+		serials, err := NormalizeSerials(item.Serials, item.Quantity)
+		if err != nil {
+			return fmt.Errorf("%w (product id %d)", err, itemProductID(*item))
+		}
+		item.Serials = serials
+	}
+	return nil
+}
+
+// checkoutStockError - stok kurang saat checkout, termasuk kalah balapan dengan
+// checkout lain di mode optimistic
+func checkoutStockError(name string) error {
+	return fmt.Errorf("%w: insufficient stock for product %s", ErrInsufficientStock, name)
+}
+
+// checkoutProductColumns - kolom yang dibaca loadCheckoutProducts, urutannya
+// harus sama persis dengan checkoutProductDest. Tarif pajak produk, kalau
+// kosong pakai tarif category.
+const checkoutProductColumns = `p.id, p.name, p.price, p.cost_price, COALESCE(ps.stock, 0), COALESCE(p.category_id, 0),
+	COALESCE(t.id, 0), COALESCE(t.name, ''), COALESCE(t.rate, 0),
+	COALESCE(p.parent_id, 0), EXISTS (SELECT 1 FROM products v WHERE v.parent_id = p.id)`
+
+func checkoutProductDest(id *int, p *productSnapshot) []any {
+	return []any{id, &p.name, &p.price, &p.cost, &p.stock, &p.categoryID, &p.taxRateID, &p.taxName, &p.taxRate, &p.parentID, &p.hasVariants}
+}
+
+func loadCheckoutProducts(tx *sql.Tx, ids []int, locationID int, forUpdate bool) (map[int]productSnapshot, error) {
+	query := `
+		SELECT ` + checkoutProductColumns + `
+		FROM products p
+		LEFT JOIN product_stocks ps ON ps.product_id = p.id AND ps.location_id = $2
+		LEFT JOIN categories c ON c.id = p.category_id
+		LEFT JOIN tax_rates t ON t.id = COALESCE(p.tax_rate_id, c.tax_rate_id)
+		WHERE p.id = ANY($1)
+		ORDER BY p.id`
+	if forUpdate {
+		query += " FOR UPDATE OF p"
+	}
+
+	rows, err := tx.Query(query, pq.Array(ids), locationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make(map[int]productSnapshot)
+	for rows.Next() {
+		var id int
+		var p productSnapshot
+		if err := rows.Scan(checkoutProductDest(&id, &p)...); err != nil {
+			return nil, err
+		}
+		products[id] = p
+	}
+
+	return products, rows.Err()
+}
+
+// isRetryableError - serialization_failure (40001) dan deadlock_detected (40P01)
+// aman untuk diulang dari awal transaksi
+func isRetryableError(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "40001" || pqErr.Code == "40P01"
+	}
+	return false
+}
+
+func withRetry(fn func() error) error {
+	var err error
+	for attempt := 0; attempt <= maxCheckoutRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt*attempt) * 10 * time.Millisecond)
+		}
+
+		err = fn()
+		if err == nil || !isRetryableError(err) {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,167 @@
+package repositories
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/lib/pq"
+	"kasir-api/models"
+)
+
+// selectColumnCount - jumlah kolom di daftar SELECT, koma di dalam kurung
+// (fungsi / subquery) tidak dihitung
+func selectColumnCount(columns string) int {
+	count, depth := 1, 0
+	for _, r := range columns {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func TestCheckoutProductColumnsMatchScan(t *testing.T) {
+	var id int
+	var p productSnapshot
+	dest := checkoutProductDest(&id, &p)
+	if got := selectColumnCount(checkoutProductColumns); got != len(dest) {
+		t.Errorf("SELECT has %d columns, Scan has %d targets", got, len(dest))
+	}
+	if !strings.Contains(checkoutProductColumns, "p.cost_price") {
+		t.Error("checkout must read p.cost_price for the unit cost snapshot")
+	}
+}
+
+// TestPostgresCheckoutSnapshotsUnitCost - checkout lewat SQL sungguhan, HPP
+// per unit ikut tersimpan di detail
+func TestPostgresCheckoutSnapshotsUnitCost(t *testing.T) {
+	db := openTestDB(t)
+	product := createTestProduct(t, db, models.Product{Name: "Indomie Goreng", Price: 3500, CostPrice: 2800, Stock: 10})
+
+	for _, strategy := range []LockStrategy{LockPessimistic, LockOptimistic} {
+		req := models.CheckoutRequest{LocationID: models.DefaultLocationID, Items: []models.CheckoutItem{{ProductID: product.ID, Quantity: 2}}}
+		trx, err := NewTransactionRepository(db).CreateTransaction(req, CheckoutOptions{Strategy: strategy})
+		if err != nil {
+			t.Fatalf("%s checkout: %v", strategy, err)
+		}
+		if d := trx.Details[0]; d.UnitPrice != 3500 || d.UnitCost != 2800 {
+			t.Errorf("%s detail = %+v, want unit price 3500 and unit cost 2800", strategy, d)
+		}
+	}
+	if stock := testProductStock(t, db, product.ID); stock != 6 {
+		t.Errorf("stock = %d, want 6", stock)
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	// serialization failure / deadlock diulang sampai maxCheckoutRetries
+	for _, code := range []pq.ErrorCode{"40001", "40P01"} {
+		calls := 0
+		err := withRetry(func() error {
+			calls++
+			return &pq.Error{Code: code}
+		})
+		if calls != maxCheckoutRetries+1 || !isRetryableError(err) {
+			t.Errorf("code %s: calls = %d, err = %v", code, calls, err)
+		}
+	}
+
+	// berhasil di percobaan kedua
+	calls := 0
+	err := withRetry(func() error {
+		calls++
+		if calls == 1 {
+			return &pq.Error{Code: "40001"}
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("retry then success: calls = %d, err = %v", calls, err)
+	}
+
+	// error lain (termasuk stok kurang) langsung dikembalikan
+	for _, want := range []error{ErrInsufficientStock, &pq.Error{Code: "23505"}} {
+		calls := 0
+		err := withRetry(func() error {
+			calls++
+			return want
+		})
+		if calls != 1 || !errors.Is(err, want) {
+			t.Errorf("%v: calls = %d, err = %v", want, calls, err)
+		}
+	}
+}
+
+func TestCheckoutProductIDsSorted(t *testing.T) {
+	items := []models.CheckoutItem{{ProductID: 9, Quantity: 1}, {ProductID: 3, Quantity: 2}, {ProductID: 9, Quantity: 1}, {VariantID: 5, ProductID: 1, Quantity: 1}}
+
+	ids := checkoutProductIDs(items)
+	if len(ids) != 3 || ids[0] != 3 || ids[1] != 5 || ids[2] != 9 {
+		t.Errorf("ids = %v, want [3 5 9]", ids)
+	}
+	if q := checkoutQuantities(items); q[9] != 2 || q[3] != 2 || q[5] != 1 {
+		t.Errorf("quantities = %v", q)
+	}
+}
+
+// TestPostgresCheckoutConcurrentNeverOversells - checkout paralel dengan urutan
+// item dibalik terhadap Postgres, untuk kedua lock strategy
+func TestPostgresCheckoutConcurrentNeverOversells(t *testing.T) {
+	for _, strategy := range []LockStrategy{LockPessimistic, LockOptimistic} {
+		t.Run(string(strategy), func(t *testing.T) {
+			db := openTestDB(t)
+			repo := NewTransactionRepository(db)
+
+			a := createTestProduct(t, db, models.Product{Name: "Produk A", Price: 1000, Stock: 25})
+			b := createTestProduct(t, db, models.Product{Name: "Produk B", Price: 2000, Stock: 25})
+
+			const workers = 60
+			var success, conflict atomic.Int32
+			var wg sync.WaitGroup
+			for i := 0; i < workers; i++ {
+				items := []models.CheckoutItem{{ProductID: a.ID, Quantity: 1}, {ProductID: b.ID, Quantity: 1}}
+				if i%2 == 1 {
+					items[0], items[1] = items[1], items[0]
+				}
+
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					req := models.CheckoutRequest{LocationID: models.DefaultLocationID, Items: items}
+					_, err := repo.CreateTransaction(req, CheckoutOptions{Strategy: strategy})
+					switch {
+					case err == nil:
+						success.Add(1)
+					case errors.Is(err, ErrInsufficientStock):
+						conflict.Add(1)
+					default:
+						t.Errorf("checkout: %v", err)
+					}
+				}()
+			}
+			wg.Wait()
+
+			if got := success.Load(); got != 25 {
+				t.Errorf("successful checkouts = %d, want 25", got)
+			}
+			if got := conflict.Load(); got != workers-25 {
+				t.Errorf("insufficient stock = %d, want %d", got, workers-25)
+			}
+			for _, p := range []models.Product{a, b} {
+				if stock := testProductStock(t, db, p.ID); stock != 0 {
+					t.Errorf("stock of %s = %d, want 0", p.Name, stock)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package repositories
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"kasir-api/models"
+)
+
+// CostingMethod - cara menghitung HPP stok keluar
+type CostingMethod string
+
+const (
+	// CostAverage - moving weighted average, HPP = quantity x cost_price produk
+	CostAverage CostingMethod = "average"
+
+	// CostFIFO - HPP dari cost layer paling lama yang masih tersisa
+	CostFIFO CostingMethod = "fifo"
+)
+
+func ParseCostingMethod(s string) (CostingMethod, error) {
+	switch CostingMethod(strings.ToLower(strings.TrimSpace(s))) {
+	case "", CostAverage:
+		return CostAverage, nil
+	case CostFIFO:
+		return CostFIFO, nil
+	default:
+		return "", fmt.Errorf("costing method tidak dikenal: %s", s)
+	}
+}
+
+func costingMethodOrDefault(method string) CostingMethod {
+	if method == "" {
+		return CostAverage
+	}
+	return CostingMethod(method)
+}
+
+// StockPolicy - kebijakan stok keluar dari konfigurasi service
+type StockPolicy struct {
+	AllowNegative bool
+	Costing       CostingMethod
+}
+
+// costLayer - satu lot stok masuk dengan harga pokoknya. Layer dibuat untuk
+// setiap stok masuk dan selalu dipakai urut FIFO supaya quantity-nya sama
+// dengan stok, apa pun metode HPP-nya.
+type costLayer struct {
+	id        int
+	unitCost  int
+	remaining int
+}
+
+// costedMovement - movement transfer tidak mengubah nilai persediaan, barang
+// masih milik toko selama dalam perjalanan. Pengiriman mengurangi
+// products.stock tanpa mengambil layer, jadi stok masuk menghitung harga
+// rata-rata (dan stok minus yang ditutup layer) dari stok + dalam perjalanan.
+func costedMovement(m *models.StockMovement) bool {
+	return m.Reason != models.StockReasonTransfer
+}
+
+// inboundUnitCost - receipt memakai harga faktur, retur dan void memakai HPP
+// snapshot penjualan asal, selain itu (adjustment, stok awal) pakai cost_price
+func inboundUnitCost(m *models.StockMovement, averageCost int) int {
+	switch m.Reason {
+	case models.StockReasonReceipt, models.StockReasonReturn, models.StockReasonVoid:
+		return m.UnitCost
+	default:
+		return averageCost
+	}
+}
+
+// layerRemaining - quantity layer baru. Stok minus ditutup dulu, karena
+// barang yang sudah keluar tanpa layer sudah dibebankan di harga rata-rata.
+func layerRemaining(stockBefore, delta int) int {
+	return max(delta-max(-stockBefore, 0), 0)
+}
+
+// consumeLayers - ambil quantity dari layer urut terlama. taken[i] adalah
+// jumlah yang diambil dari layers[i], shortfall sisa yang tidak tertutup layer.
+func consumeLayers(layers []costLayer, quantity int) (taken []int, fifoCost, shortfall int) {
+	taken = make([]int, len(layers))
+	for i, layer := range layers {
+		if quantity == 0 {
+			break
+		}
+		n := min(layer.remaining, quantity)
+		taken[i] = n
+		fifoCost += n * layer.unitCost
+		quantity -= n
+	}
+	return taken, fifoCost, quantity
+}
+
+// outboundCost - HPP total stok keluar. Bagian yang tidak tertutup layer
+// (stok minus) selalu dinilai dengan harga rata-rata.
+func outboundCost(method CostingMethod, quantity, fifoCost, shortfall, averageCost int) int {
+	if method == CostFIFO {
+		return fifoCost + shortfall*averageCost
+	}
+	return quantity * averageCost
+}
+
+// valuationItem - lengkapi UnitCost dan label produk tanpa category
+func valuationItem(item models.ValuationItem) models.ValuationItem {
+	if item.CategoryID == 0 {
+		item.CategoryName = noCategoryName
+	}
+	if item.Quantity > 0 {
+		item.UnitCost = int(math.Round(float64(item.Value) / float64(item.Quantity)))
+	}
+	return item
+}
+
+// setDetailUnitCost - HPP per unit dari movement penjualan ke semua baris produk itu
+func setDetailUnitCost(details []models.TransactionDetail, productID, unitCost int) {
+	for i := range details {
+		if details[i].ProductID == productID {
+			details[i].UnitCost = unitCost
+		}
+	}
+}
+
+// setMovementCost - isi UnitCost (dibulatkan) dan CostAmount dari total nilai
+// (positif). CostAmount bertanda sama dengan Delta.
+func setMovementCost(m *models.StockMovement, total int) {
+	quantity := m.Delta
+	m.CostAmount = total
+	if quantity < 0 {
+		quantity, m.CostAmount = -quantity, -total
+	}
+	m.UnitCost = int(math.Round(float64(total) / float64(quantity)))
+}
@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"testing"
+
+	"kasir-api/models"
+)
+
+func TestParseCostingMethod(t *testing.T) {
+	for input, want := range map[string]CostingMethod{"": CostAverage, "average": CostAverage, " FIFO ": CostFIFO} {
+		if got, err := ParseCostingMethod(input); err != nil || got != want {
+			t.Errorf("ParseCostingMethod(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseCostingMethod("lifo"); err == nil {
+		t.Error("lifo should be rejected")
+	}
+}
+
+func TestConsumeLayers(t *testing.T) {
+	layers := []costLayer{{id: 1, unitCost: 1000, remaining: 3}, {id: 2, unitCost: 1500, remaining: 4}}
+
+	taken, fifoCost, shortfall := consumeLayers(layers, 5)
+	if taken[0] != 3 || taken[1] != 2 || fifoCost != 3*1000+2*1500 || shortfall != 0 {
+		t.Errorf("consume 5 = %v, %d, %d", taken, fifoCost, shortfall)
+	}
+
+	// stok minus: sisa yang tidak tertutup layer dinilai harga rata-rata
+	taken, fifoCost, shortfall = consumeLayers(layers, 9)
+	if taken[1] != 4 || shortfall != 2 {
+		t.Errorf("consume 9 = %v, %d", taken, shortfall)
+	}
+	if got := outboundCost(CostFIFO, 9, fifoCost, shortfall, 1300); got != 9000+2*1300 {
+		t.Errorf("fifo cost = %d", got)
+	}
+	if got := outboundCost(CostAverage, 9, fifoCost, shortfall, 1300); got != 9*1300 {
+		t.Errorf("average cost = %d", got)
+	}
+}
+
+func TestMovementCost(t *testing.T) {
+	m := models.StockMovement{Delta: -3}
+	setMovementCost(&m, 3500)
+	if m.CostAmount != -3500 || m.UnitCost != 1167 {
+		t.Errorf("outbound = %+v", m)
+	}
+
+	if got := layerRemaining(-2, 5); got != 3 {
+		t.Errorf("layerRemaining after negative stock = %d, want 3", got)
+	}
+	if got := layerRemaining(-8, 5); got != 0 {
+		t.Errorf("layerRemaining deficit = %d, want 0", got)
+	}
+}
+
+// TestPostgresInTransitKeepsAverageCost - stok masuk selama ada transfer dalam
+// perjalanan tetap merata-rata dengan harga unit yang sedang dikirim
+func TestPostgresInTransitKeepsAverageCost(t *testing.T) {
+	db := openTestDB(t)
+	gula := createTestProduct(t, db, models.Product{Name: "Gula 1kg", Price: 15000, CostPrice: 10000, Stock: 10})
+
+	outlet := models.Location{Name: "Outlet Kemang", Type: models.LocationOutlet}
+	if err := NewLocationRepository(db).Create(&outlet); err != nil {
+		t.Fatalf("create location: %v", err)
+	}
+	transfers := NewStockTransferRepository(db)
+	transfer := models.StockTransfer{
+		FromLocationID: models.DefaultLocationID,
+		ToLocationID:   outlet.ID,
+		Lines:          []models.StockTransferLine{{ProductID: gula.ID, Quantity: 10}},
+	}
+	if err := transfers.Create(&transfer); err != nil {
+		t.Fatalf("create transfer: %v", err)
+	}
+	if _, err := transfers.Ship(transfer.ID, "gudang"); err != nil {
+		t.Fatalf("ship: %v", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	err = moveStock(tx, &models.StockMovement{
+		ProductID: gula.ID, Delta: 10, UnitCost: 12000,
+		Reason: models.StockReasonReceipt, ReferenceType: models.StockRefReceipt,
+	})
+	if err != nil {
+		t.Fatalf("receipt: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	// (10 x 10.000 dalam perjalanan + 10 x 12.000) / 20
+	var cost float64
+	if err := db.QueryRow("SELECT cost_price FROM products WHERE id = $1", gula.ID).Scan(&cost); err != nil {
+		t.Fatal(err)
+	}
+	if cost != 11000 {
+		t.Errorf("cost_price = %v, want 11000", cost)
+	}
+}
@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"kasir-api/models"
+	"net/http"
+	"time"
+)
+
+// ErrIdempotencyKeyLost - key sudah tidak processing saat checkout mau commit:
+// sudah diselesaikan / dilepas oleh request lain yang me-reclaim key basi
+var ErrIdempotencyKeyLost = errors.New("idempotency key sudah diproses request lain")
+
+type PostgresIdempotencyRepository struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepository(db *sql.DB) *PostgresIdempotencyRepository {
+	return &PostgresIdempotencyRepository{db: db}
+}
+
+// Reserve - klaim key dengan status processing. Kalau key sudah pernah dipakai,
+// record lama yang dikembalikan dan created = false. Key processing dengan
+// fingerprint sama yang lebih tua dari staleAfter diklaim ulang: response
+// checkout disimpan di transaksi DB yang sama, jadi key basi berarti
+// checkout-nya tidak pernah commit.
+func (repo *PostgresIdempotencyRepository) Reserve(scope, key, fingerprint string, staleAfter time.Duration) (*models.IdempotencyRecord, bool, error) {
+	query := `
+		INSERT INTO idempotency_keys (scope, key, fingerprint, status)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (scope, key) DO UPDATE SET created_at = NOW()
+		WHERE idempotency_keys.status = EXCLUDED.status
+			AND idempotency_keys.fingerprint = EXCLUDED.fingerprint
+			AND idempotency_keys.created_at < NOW() - $5 * INTERVAL '1 second'
+		RETURNING created_at
+	`
+
+	record := models.IdempotencyRecord{
+		Scope:       scope,
+		Key:         key,
+		Fingerprint: fingerprint,
+		Status:      models.IdempotencyProcessing,
+	}
+	err := repo.db.QueryRow(query, scope, key, fingerprint, models.IdempotencyProcessing, staleAfter.Seconds()).Scan(&record.CreatedAt)
+	if err == nil {
+		return &record, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, err
+	}
+
+	existing, err := repo.get(scope, key)
+	if err != nil {
+		return nil, false, err
+	}
+	return existing, false, nil
+}
+
+func (repo *PostgresIdempotencyRepository) get(scope, key string) (*models.IdempotencyRecord, error) {
+	query := `
+		SELECT scope, key, fingerprint, status, COALESCE(response_code, 0), response_body, created_at
+		FROM idempotency_keys
+		WHERE scope = $1 AND key = $2
+	`
+
+	var r models.IdempotencyRecord
+	err := repo.db.QueryRow(query, scope, key).Scan(
+		&r.Scope,
+		&r.Key,
+		&r.Fingerprint,
+		&r.Status,
+		&r.ResponseCode,
+		&r.ResponseBody,
+		&r.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		// key baru saja di-release oleh request lain
+		return nil, errors.New("idempotency key tidak ditemukan")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// completeIdempotencyKey - simpan response di dalam tx checkout. UPDATE ini
+// menunggu row lock request lain dengan key yang sama, jadi hanya satu
+// checkout yang bisa commit untuk satu key.
+func completeIdempotencyKey(tx *sql.Tx, scope, key string, response any) error {
+	body, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(`
+		UPDATE idempotency_keys
+		SET status = $1, response_code = $2, response_body = $3, completed_at = NOW()
+		WHERE scope = $4 AND key = $5 AND status = $6`,
+		models.IdempotencyCompleted, http.StatusOK, body, scope, key, models.IdempotencyProcessing,
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrIdempotencyKeyLost
+	}
+	return nil
+}
+
+// Release - hapus key yang masih processing, dipakai kalau request gagal
+// supaya client bisa retry dengan key yang sama
+func (repo *PostgresIdempotencyRepository) Release(scope, key string) error {
+	query := "DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2 AND status = $3"
+	_, err := repo.db.Exec(query, scope, key, models.IdempotencyProcessing)
+	return err
+}
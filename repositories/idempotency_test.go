@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"kasir-api/models"
+)
+
+func TestMemoryIdempotencyReclaimStaleKey(t *testing.T) {
+	repo := NewMemoryIdempotencyRepository(NewMemoryStore())
+
+	if _, created, err := repo.Reserve("checkout", "k1", "fp", time.Hour); err != nil || !created {
+		t.Fatalf("first reserve: created = %v, err = %v", created, err)
+	}
+	if record, created, _ := repo.Reserve("checkout", "k1", "fp", time.Hour); created || record.Status != models.IdempotencyProcessing {
+		t.Errorf("fresh processing key must not be reclaimed: %+v, %v", record, created)
+	}
+
+	// body beda tidak pernah mengambil alih key, walaupun sudah basi
+	if _, created, _ := repo.Reserve("checkout", "k1", "other", 0); created {
+		t.Error("stale key reclaimed with a different fingerprint")
+	}
+	if _, created, _ := repo.Reserve("checkout", "k1", "fp", 0); !created {
+		t.Error("stale processing key should be reclaimed")
+	}
+}
+
+func TestMemoryCheckoutCompletesIdempotencyKey(t *testing.T) {
+	store := NewMemoryStore()
+	category := models.Category{Name: "Makanan"}
+	if err := NewMemoryCategoryRepository(store).Create(&category); err != nil {
+		t.Fatal(err)
+	}
+	product := models.Product{Name: "Indomie Goreng", Price: 3500, Stock: 10, CategoryID: category.ID}
+	if err := NewMemoryProductRepository(store).Create(&product); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := NewMemoryIdempotencyRepository(store)
+	transactions := NewMemoryTransactionRepository(store)
+	checkout := func(key string) (*models.Transaction, error) {
+		req := models.CheckoutRequest{LocationID: models.DefaultLocationID, Items: []models.CheckoutItem{{ProductID: product.ID, Quantity: 1}}}
+		return transactions.CreateTransaction(req, CheckoutOptions{Strategy: LockPessimistic, IdempotencyScope: "checkout", IdempotencyKey: key})
+	}
+
+	if _, _, err := keys.Reserve("checkout", "k1", "fp", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	trx, err := checkout("k1")
+	if err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+
+	record, created, _ := keys.Reserve("checkout", "k1", "fp", 0)
+	if created || record.Status != models.IdempotencyCompleted {
+		t.Fatalf("record after checkout = %+v, created = %v", record, created)
+	}
+	var saved models.Transaction
+	if err := json.Unmarshal(record.ResponseBody, &saved); err != nil || saved.ID != trx.ID {
+		t.Errorf("saved response = %+v, %v, want transaction %d", saved, err, trx.ID)
+	}
+
+	// key yang sudah completed / tidak pernah di-reserve: checkout batal, stok utuh
+	for _, key := range []string{"k1", "missing"} {
+		if _, err := checkout(key); !errors.Is(err, ErrIdempotencyKeyLost) {
+			t.Errorf("checkout with key %s: err = %v, want ErrIdempotencyKeyLost", key, err)
+		}
+	}
+	if p := store.products[product.ID]; p.Stock != 9 {
+		t.Errorf("stock = %d, want 9", p.Stock)
+	}
+}
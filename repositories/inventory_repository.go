@@ -0,0 +1,228 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"kasir-api/models"
+)
+
+type PostgresInventoryRepository struct {
+	db *sql.DB
+}
+
+func NewInventoryRepository(db *sql.DB) *PostgresInventoryRepository {
+	return &PostgresInventoryRepository{db: db}
+}
+
+// CreateAdjustment - simpan dokumen adjustment dan ubah stok semua item dalam
+// satu transaksi DB. Satu item gagal = seluruh adjustment batal.
+func (repo *PostgresInventoryRepository) CreateAdjustment(adj *models.StockAdjustment, policy StockPolicy) error {
+	return withRetry(func() error {
+		return repo.createAdjustment(adj, policy)
+	})
+}
+
+func (repo *PostgresInventoryRepository) createAdjustment(adj *models.StockAdjustment, policy StockPolicy) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertAdjustment(tx, adj, policy); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertAdjustment - header adjustment + satu stock movement per item, dipakai
+// juga oleh finalize stock opname
+func insertAdjustment(tx *sql.Tx, adj *models.StockAdjustment, policy StockPolicy) error {
+	if err := checkLocationExists(tx, adj.LocationID); err != nil {
+		return err
+	}
+
+	err := tx.QueryRow(
+		"INSERT INTO stock_adjustments (location_id, reason, note, created_by) VALUES ($1, $2, $3, $4) RETURNING id, created_at",
+		adj.LocationID, adj.Reason, adj.Note, adj.User,
+	).Scan(&adj.ID, &adj.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range adjustmentOrder(adj.Items) {
+		m := adjustmentMovement(adj, adj.Items[i])
+		m.CostMethod = string(policy.Costing)
+		if err := applyStockMovement(tx, &m, policy.AllowNegative); err != nil {
+			return adjustmentError(err, m.ProductID)
+		}
+		adj.Items[i].StockAfter, adj.Items[i].MovementID = m.StockAfter, m.ID
+	}
+
+	return nil
+}
+
+// adjustmentOrder - index item urut product id, sama dengan urutan lock di checkout
+func adjustmentOrder(items []models.StockAdjustmentItem) []int {
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return items[order[a]].ProductID < items[order[b]].ProductID })
+	return order
+}
+
+func adjustmentMovement(adj *models.StockAdjustment, item models.StockAdjustmentItem) models.StockMovement {
+	note := adj.Reason
+	if adj.Note != "" {
+		note += ": " + adj.Note
+	}
+	return models.StockMovement{
+		ProductID:     item.ProductID,
+		LocationID:    adj.LocationID,
+		Delta:         item.Quantity,
+		Reason:        models.StockReasonAdjustment,
+		ReferenceType: models.StockRefAdjustment,
+		ReferenceID:   adj.ID,
+		User:          adj.User,
+		Note:          note,
+		Batches:       batchLots(item.LotNumber, item.ExpiryDate, item.Quantity),
+		Serials:       item.Serials,
+	}
+}
+
+// adjustmentError - sebutkan produk yang gagal supaya client tahu item mana di batch
+func adjustmentError(err error, productID int) error {
+	if errors.Is(err, ErrInsufficientStock) || errors.Is(err, ErrProductNotFound) || errors.Is(err, ErrInsufficientBatch) || isSerialError(err) {
+		return fmt.Errorf("%w (product id %d)", err, productID)
+	}
+	return err
+}
+
+// ListLowStock - produk dengan reorder point yang stok totalnya sudah mencapai
+// reorder point, urut product id
+func (repo *PostgresInventoryRepository) ListLowStock() ([]models.LowStockItem, error) {
+	rows, err := repo.db.Query(`
+		SELECT p.id, p.name, COALESCE(p.category_id, 0), COALESCE(c.name, ''), COALESCE(p.supplier_id, 0), COALESCE(s.name, ''),
+			p.stock, p.reorder_point, p.reorder_quantity
+		FROM products p
+		LEFT JOIN categories c ON c.id = p.category_id
+		LEFT JOIN suppliers s ON s.id = p.supplier_id
+		WHERE p.reorder_point > 0 AND p.stock <= p.reorder_point
+		ORDER BY p.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]models.LowStockItem, 0)
+	for rows.Next() {
+		var i models.LowStockItem
+		err := rows.Scan(&i.ProductID, &i.ProductName, &i.CategoryID, &i.CategoryName, &i.SupplierID, &i.SupplierName,
+			&i.Stock, &i.ReorderPoint, &i.ReorderQuantity)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+
+	return items, rows.Err()
+}
+
+// ListProductDemand - semua produk dengan supplier, quantity PO yang belum
+// diterima dan penjualan harian sejak since
+func (repo *PostgresInventoryRepository) ListProductDemand(since time.Time) ([]models.ProductDemand, error) {
+	rows, err := repo.db.Query(`
+		SELECT p.id, p.name, COALESCE(p.supplier_id, 0), COALESCE(s.name, ''), COALESCE(s.lead_time_days, 0),
+			p.stock, p.reorder_quantity, COALESCE(o.on_order, 0)
+		FROM products p
+		LEFT JOIN suppliers s ON s.id = p.supplier_id
+		LEFT JOIN (
+			SELECT l.product_id, SUM(l.quantity - l.received_quantity) AS on_order
+			FROM purchase_order_lines l
+			JOIN purchase_orders po ON po.id = l.purchase_order_id
+			WHERE po.status IN ('sent', 'partially_received')
+			GROUP BY l.product_id
+		) o ON o.product_id = p.id
+		ORDER BY p.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	demand := make([]models.ProductDemand, 0)
+	index := make(map[int]int)
+	for rows.Next() {
+		d := models.ProductDemand{DailySales: make(map[string]int)}
+		err := rows.Scan(&d.ProductID, &d.ProductName, &d.SupplierID, &d.SupplierName, &d.LeadTimeDays,
+			&d.Stock, &d.ReorderQuantity, &d.OnOrder)
+		if err != nil {
+			return nil, err
+		}
+		index[d.ProductID] = len(demand)
+		demand = append(demand, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sales, err := repo.db.Query(`
+		SELECT td.product_id, TO_CHAR(t.created_at, 'YYYY-MM-DD'), SUM(td.quantity)
+		FROM transaction_details td
+		JOIN transactions t ON t.id = td.transaction_id
+		WHERE t.created_at >= $1 AND t.voided_at IS NULL
+		GROUP BY 1, 2`, since.Format(reportTimeLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer sales.Close()
+
+	for sales.Next() {
+		var productID, quantity int
+		var day string
+		if err := sales.Scan(&productID, &day, &quantity); err != nil {
+			return nil, err
+		}
+		if i, ok := index[productID]; ok {
+			demand[i].DailySales[day] = quantity
+		}
+	}
+
+	return demand, sales.Err()
+}
+
+// ListValuation - jumlah delta dan cost_amount ledger per produk. before nil =
+// saat ini, selain itu hanya movement sebelum waktu tersebut.
+func (repo *PostgresInventoryRepository) ListValuation(before *time.Time) ([]models.ValuationItem, error) {
+	rows, err := repo.db.Query(`
+		SELECT p.id, p.name, COALESCE(c.id, 0), COALESCE(c.name, ''), SUM(sm.delta), SUM(sm.cost_amount)
+		FROM stock_movements sm
+		JOIN products p ON p.id = sm.product_id
+		LEFT JOIN categories c ON c.id = p.category_id
+		WHERE sm.reason <> $1 AND ($2::timestamp IS NULL OR sm.created_at < $2)
+		GROUP BY p.id, p.name, c.id, c.name
+		HAVING SUM(sm.delta) <> 0 OR SUM(sm.cost_amount) <> 0
+		ORDER BY p.id`,
+		models.StockReasonTransfer, before,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]models.ValuationItem, 0)
+	for rows.Next() {
+		var item models.ValuationItem
+		if err := rows.Scan(&item.ProductID, &item.ProductName, &item.CategoryID, &item.CategoryName, &item.Quantity, &item.Value); err != nil {
+			return nil, err
+		}
+		items = append(items, valuationItem(item))
+	}
+
+	return items, rows.Err()
+}
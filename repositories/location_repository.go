@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+
+	"kasir-api/models"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrLocationNotFound = errors.New("lokasi tidak ditemukan")
+	ErrLocationInUse    = errors.New("lokasi masih dipakai")
+
+	ErrStockLocationRequired = errors.New("location_id wajib diisi kalau stok diubah")
+)
+
+type PostgresLocationRepository struct {
+	db *sql.DB
+}
+
+func NewLocationRepository(db *sql.DB) *PostgresLocationRepository {
+	return &PostgresLocationRepository{db: db}
+}
+
+func (repo *PostgresLocationRepository) GetAll() ([]models.Location, error) {
+	rows, err := repo.db.Query("SELECT id, name, type, address FROM locations ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	locations := make([]models.Location, 0)
+	for rows.Next() {
+		var l models.Location
+		if err := rows.Scan(&l.ID, &l.Name, &l.Type, &l.Address); err != nil {
+			return nil, err
+		}
+		locations = append(locations, l)
+	}
+
+	return locations, rows.Err()
+}
+
+func (repo *PostgresLocationRepository) Create(location *models.Location) error {
+	return repo.db.QueryRow(
+		"INSERT INTO locations (name, type, address) VALUES ($1, $2, $3) RETURNING id",
+		location.Name, location.Type, location.Address,
+	).Scan(&location.ID)
+}
+
+func (repo *PostgresLocationRepository) GetByID(id int) (*models.Location, error) {
+	var l models.Location
+	err := repo.db.QueryRow("SELECT id, name, type, address FROM locations WHERE id = $1", id).
+		Scan(&l.ID, &l.Name, &l.Type, &l.Address)
+	if err == sql.ErrNoRows {
+		return nil, ErrLocationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &l, nil
+}
+
+func (repo *PostgresLocationRepository) Update(location *models.Location) error {
+	result, err := repo.db.Exec(
+		"UPDATE locations SET name = $1, type = $2, address = $3 WHERE id = $4",
+		location.Name, location.Type, location.Address, location.ID,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrLocationNotFound
+	}
+
+	return nil
+}
+
+// Delete - lokasi yang sudah punya stok, transaksi atau dokumen tidak bisa dihapus
+func (repo *PostgresLocationRepository) Delete(id int) error {
+	result, err := repo.db.Exec("DELETE FROM locations WHERE id = $1", id)
+	if isForeignKeyViolation(err) {
+		return ErrLocationInUse
+	}
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrLocationNotFound
+	}
+
+	return nil
+}
+
+// checkLocationExists - dipakai sebelum menulis dokumen yang menyimpan location_id
+func checkLocationExists(q queryer, id int) error {
+	var exists bool
+	if err := q.QueryRow("SELECT EXISTS (SELECT 1 FROM locations WHERE id = $1)", id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrLocationNotFound
+	}
+	return nil
+}
+
+// loadProductLocations - stok per lokasi untuk produk-produk yang diminta,
+// termasuk quantity transfer yang sedang dalam perjalanan ke lokasi tersebut
+func loadProductLocations(q queryer, productIDs []int) (map[int][]models.LocationStock, error) {
+	rows, err := q.Query(`
+		SELECT s.product_id, s.location_id, l.name, SUM(s.stock), SUM(s.in_transit)
+		FROM (
+			SELECT product_id, location_id, stock, 0 AS in_transit
+			FROM product_stocks
+			UNION ALL
+			SELECT tl.product_id, t.to_location_id, 0, tl.quantity
+			FROM stock_transfer_lines tl
+			JOIN stock_transfers t ON t.id = tl.transfer_id
+			WHERE t.status = $2
+		) s
+		JOIN locations l ON l.id = s.location_id
+		WHERE s.product_id = ANY($1)
+		GROUP BY s.product_id, s.location_id, l.name
+		ORDER BY s.product_id, s.location_id`, pq.Array(productIDs), models.TransferShipped)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[int][]models.LocationStock)
+	for rows.Next() {
+		var productID int
+		var ls models.LocationStock
+		if err := rows.Scan(&productID, &ls.LocationID, &ls.LocationName, &ls.Stock, &ls.InTransit); err != nil {
+			return nil, err
+		}
+		result[productID] = append(result[productID], ls)
+	}
+
+	return result, rows.Err()
+}
+
+// sumInTransit - total quantity dalam perjalanan semua lokasi
+func sumInTransit(locations []models.LocationStock) int {
+	total := 0
+	for _, l := range locations {
+		total += l.InTransit
+	}
+	return total
+}
@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"kasir-api/models"
+
+	"github.com/lib/pq"
+)
+
+// crossedReorderPoint - stok turun dari di atas reorder point menjadi sama
+// dengan / di bawahnya. Produk yang sudah low stock sebelum checkout tidak
+// memicu event lagi.
+func crossedReorderPoint(stockAfter, sold, reorderPoint int) bool {
+	return reorderPoint > 0 && stockAfter <= reorderPoint && stockAfter+sold > reorderPoint
+}
+
+func newLowStockEvent(trx *models.Transaction, p models.Product) models.LowStockEvent {
+	return models.LowStockEvent{
+		ProductID:       p.ID,
+		ProductName:     p.Name,
+		LocationID:      trx.LocationID,
+		TransactionID:   trx.ID,
+		Stock:           p.Stock,
+		ReorderPoint:    p.ReorderPoint,
+		ReorderQuantity: p.ReorderQuantity,
+		CreatedAt:       trx.CreatedAt,
+	}
+}
+
+// loadLowStockEvents - dipanggil setelah stok checkout dikurangi, baris produk
+// sudah terkunci oleh UPDATE sehingga stok yang dibaca adalah stok akhir
+func loadLowStockEvents(q queryer, trx *models.Transaction, ids []int, quantities map[int]int) ([]models.LowStockEvent, error) {
+	rows, err := q.Query(`
+		SELECT id, name, stock, reorder_point, reorder_quantity
+		FROM products
+		WHERE id = ANY($1) AND reorder_point > 0 AND stock <= reorder_point
+		ORDER BY id`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []models.LowStockEvent
+	for rows.Next() {
+		var p models.Product
+		if err := rows.Scan(&p.ID, &p.Name, &p.Stock, &p.ReorderPoint, &p.ReorderQuantity); err != nil {
+			return nil, err
+		}
+		if crossedReorderPoint(p.Stock, quantities[p.ID], p.ReorderPoint) {
+			events = append(events, newLowStockEvent(trx, p))
+		}
+	}
+
+	return events, rows.Err()
+}
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"kasir-api/models"
+)
+
+func (repo *MemoryProductRepository) GetByCode(code string) (*models.ProductResponse, error) {
+	repo.store.mu.Lock()
+	id, err := repo.store.findProductByCode(code, "")
+	if errors.Is(err, ErrBarcodeNotFound) {
+		id, err = repo.store.findProductByCode("", code)
+	}
+	repo.store.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetByID(id)
+}
+
+func (repo *MemoryProductRepository) AssignBarcode(productID int, prefix string) (models.Barcode, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	product, ok := repo.store.products[productID]
+	if !ok {
+		return models.Barcode{}, ErrProductNotFound
+	}
+	if hasRetailBarcode(product.Barcodes) {
+		return models.Barcode{}, ErrBarcodeAssigned
+	}
+
+	last := ""
+	for _, p := range repo.store.products {
+		for _, b := range p.Barcodes {
+			if b.Type == models.BarcodeEAN13 && strings.HasPrefix(b.Code, prefix) && b.Code > last {
+				last = b.Code
+			}
+		}
+	}
+	code, err := nextInternalEAN13(prefix, last)
+	if err != nil {
+		return models.Barcode{}, err
+	}
+
+	barcode := models.Barcode{Code: code, Type: models.BarcodeEAN13, Assigned: true}
+	product.Barcodes = append(slices.Clone(product.Barcodes), barcode)
+	repo.store.products[productID] = product
+	return barcode, nil
+}
+
+func (repo *MemoryProductRepository) RemoveBarcode(productID int, code string) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	product, ok := repo.store.products[productID]
+	if !ok {
+		return ErrProductNotFound
+	}
+	i := slices.IndexFunc(product.Barcodes, func(b models.Barcode) bool { return b.Code == code })
+	if i < 0 {
+		return fmt.Errorf("%w: %s", ErrBarcodeNotFound, code)
+	}
+	product.Barcodes = slices.Delete(slices.Clone(product.Barcodes), i, i+1)
+	repo.store.products[productID] = product
+	return nil
+}
+
+// findProductByCode - sama dengan versi Postgres, caller memegang store.mu
+func (s *MemoryStore) findProductByCode(barcode, sku string) (int, error) {
+	for _, p := range s.products {
+		if barcode != "" && slices.ContainsFunc(p.Barcodes, func(b models.Barcode) bool { return b.Code == barcode }) {
+			return p.ID, nil
+		}
+		if barcode == "" && sku != "" && p.SKU == sku {
+			return p.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s%s", ErrBarcodeNotFound, barcode, sku)
+}
@@ -0,0 +1,161 @@
+package repositories
+
+import (
+	"time"
+
+	"kasir-api/models"
+)
+
+func (repo *MemoryInventoryRepository) ListBatches(filter models.BatchFilter) ([]models.Batch, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	return repo.store.listBatches(filter), nil
+}
+
+// listBatches - sama dengan loadBatches versi Postgres, caller memegang store.mu
+func (s *MemoryStore) listBatches(filter models.BatchFilter) []models.Batch {
+	batches := make([]models.Batch, 0)
+	for _, b := range s.batches {
+		if b.Quantity <= 0 ||
+			(filter.ProductID != 0 && b.ProductID != filter.ProductID) ||
+			(filter.LocationID != 0 && b.LocationID != filter.LocationID) ||
+			(filter.ExpiresBy != "" && (b.ExpiryDate == "" || b.ExpiryDate > filter.ExpiresBy)) {
+			continue
+		}
+		b.ProductName = s.products[b.ProductID].Name
+		b.LocationName = s.locations[b.LocationID].Name
+		batches = append(batches, b)
+	}
+	sortFEFO(batches)
+	return batches
+}
+
+// expiredStock - quantity batch kedaluwarsa di satu lokasi (tidak boleh dijual)
+func (s *MemoryStore) expiredStock(productID, locationID int) int {
+	if locationID == 0 {
+		locationID = models.DefaultLocationID
+	}
+	expired, now := 0, today()
+	for _, b := range s.batches {
+		if b.ProductID == productID && b.LocationID == locationID && isExpired(b.ExpiryDate, now) {
+			expired += b.Quantity
+		}
+	}
+	return expired
+}
+
+// lotStock - stok satu lot (semua tanggal kedaluwarsa) di satu lokasi
+func (s *MemoryStore) lotStock(productID, locationID int, lot string) int {
+	stock := 0
+	for _, b := range s.listBatches(models.BatchFilter{ProductID: productID, LocationID: locationID}) {
+		if b.LotNumber == lot {
+			stock += b.Quantity
+		}
+	}
+	return stock
+}
+
+// moveBatches - sama dengan versi Postgres. locationStock adalah stok lokasi
+// sebelum movement diterapkan.
+func (s *MemoryStore) moveBatches(m *models.StockMovement, locationStock int) error {
+	if m.Delta > 0 {
+		for i := range m.Batches {
+			m.Batches[i].BatchID = s.addToBatch(m.ProductID, m.LocationID, m.Batches[i])
+		}
+		return nil
+	}
+	if m.Delta == 0 {
+		return nil
+	}
+
+	batches := s.listBatches(models.BatchFilter{ProductID: m.ProductID, LocationID: m.LocationID})
+	lot := ""
+	if len(m.Batches) > 0 {
+		lot = m.Batches[0].LotNumber
+	}
+	sale := m.Reason == models.StockReasonSale
+	allocations, err := pickBatches(batches, lot, -m.Delta, unbatchedStock(locationStock, batches), sale, today())
+	if err != nil {
+		return err
+	}
+	for _, a := range allocations {
+		b := s.batches[a.BatchID]
+		b.Quantity -= a.Quantity
+		s.batches[a.BatchID] = b
+	}
+	m.Batches = allocations
+	return nil
+}
+
+// addToBatch - tambah ke batch (product, lokasi, lot, expiry) atau buat baru
+func (s *MemoryStore) addToBatch(productID, locationID int, lot models.BatchAllocation) int {
+	for id, b := range s.batches {
+		if b.ProductID == productID && b.LocationID == locationID && b.LotNumber == lot.LotNumber && b.ExpiryDate == lot.ExpiryDate {
+			b.Quantity += lot.Quantity
+			s.batches[id] = b
+			return id
+		}
+	}
+
+	id := s.nextBatchID
+	s.nextBatchID++
+	s.batches[id] = models.Batch{
+		ID:         id,
+		ProductID:  productID,
+		LocationID: locationID,
+		LotNumber:  lot.LotNumber,
+		ExpiryDate: lot.ExpiryDate,
+		Quantity:   lot.Quantity,
+		CreatedAt:  time.Now(),
+	}
+	return id
+}
+
+// movementLots - jumlah lot dari movement produk yang cocok dengan match,
+// positif = keluar (belum kembali), urut batch id
+func (s *MemoryStore) movementLots(productID int, match func(m models.StockMovement) bool) []models.BatchAllocation {
+	outstanding := make(map[int]int)
+	for _, m := range s.stockMovements {
+		if m.ProductID != productID || !match(m) {
+			continue
+		}
+		for _, a := range m.Batches {
+			if m.Delta < 0 {
+				outstanding[a.BatchID] += a.Quantity
+			} else {
+				outstanding[a.BatchID] -= a.Quantity
+			}
+		}
+	}
+
+	lots := make([]models.BatchAllocation, 0)
+	for id := 1; id < s.nextBatchID; id++ {
+		if n := outstanding[id]; n > 0 {
+			b := s.batches[id]
+			lots = append(lots, models.BatchAllocation{BatchID: id, LotNumber: b.LotNumber, ExpiryDate: b.ExpiryDate, Quantity: n})
+		}
+	}
+	return lots
+}
+
+// soldLots - sama dengan versi Postgres
+func (s *MemoryStore) soldLots(transactionID, productID int) []models.BatchAllocation {
+	returns := make(map[int]bool)
+	for _, r := range s.returns {
+		if r.TransactionID == transactionID {
+			returns[r.ID] = true
+		}
+	}
+	return s.movementLots(productID, func(m models.StockMovement) bool {
+		return (m.ReferenceType == models.StockRefTransaction && m.ReferenceID == transactionID) ||
+			(m.ReferenceType == models.StockRefReturn && returns[m.ReferenceID])
+	})
+}
+
+// shippedLots - sama dengan versi Postgres
+func (s *MemoryStore) shippedLots(transferID, productID int) []models.BatchAllocation {
+	return s.movementLots(productID, func(m models.StockMovement) bool {
+		return m.ReferenceType == models.StockRefTransfer && m.ReferenceID == transferID && m.Delta < 0
+	})
+}
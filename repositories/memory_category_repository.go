@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"errors"
+	"sort"
+
+	"kasir-api/models"
+)
+
+type MemoryCategoryRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryCategoryRepository(store *MemoryStore) *MemoryCategoryRepository {
+	return &MemoryCategoryRepository{store: store}
+}
+
+func (repo *MemoryCategoryRepository) GetAll() ([]models.Category, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	categories := make([]models.Category, 0, len(repo.store.categories))
+	for _, c := range repo.store.categories {
+		categories = append(categories, c)
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].ID < categories[j].ID })
+
+	return categories, nil
+}
+
+func (repo *MemoryCategoryRepository) Create(category *models.Category) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if err := repo.store.checkTaxRateExists(category.TaxRateID); err != nil {
+		return err
+	}
+
+	category.ID = repo.store.nextCategoryID
+	repo.store.nextCategoryID++
+	repo.store.categories[category.ID] = *category
+
+	return nil
+}
+
+func (repo *MemoryCategoryRepository) GetByID(id int) (*models.Category, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	c, ok := repo.store.categories[id]
+	if !ok {
+		return nil, errors.New("category tidak ditemukan")
+	}
+
+	return &c, nil
+}
+
+func (repo *MemoryCategoryRepository) Update(category *models.Category) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if _, ok := repo.store.categories[category.ID]; !ok {
+		return errors.New("category tidak ditemukan")
+	}
+	if err := repo.store.checkTaxRateExists(category.TaxRateID); err != nil {
+		return err
+	}
+	repo.store.categories[category.ID] = *category
+
+	return nil
+}
+
+func (repo *MemoryCategoryRepository) Delete(id int) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if _, ok := repo.store.categories[id]; !ok {
+		return errors.New("category tidak ditemukan")
+	}
+	delete(repo.store.categories, id)
+
+	return nil
+}
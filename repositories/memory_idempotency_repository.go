@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"kasir-api/models"
+)
+
+type MemoryIdempotencyRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryIdempotencyRepository(store *MemoryStore) *MemoryIdempotencyRepository {
+	return &MemoryIdempotencyRepository{store: store}
+}
+
+func idempotencyMapKey(scope, key string) string {
+	return scope + "\x00" + key
+}
+
+func (repo *MemoryIdempotencyRepository) Reserve(scope, key, fingerprint string, staleAfter time.Duration) (*models.IdempotencyRecord, bool, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	existing, ok := repo.store.idempotencyKeys[idempotencyMapKey(scope, key)]
+	stale := ok && existing.Status == models.IdempotencyProcessing && existing.Fingerprint == fingerprint &&
+		time.Since(existing.CreatedAt) > staleAfter
+	if ok && !stale {
+		return &existing, false, nil
+	}
+
+	record := models.IdempotencyRecord{
+		Scope:       scope,
+		Key:         key,
+		Fingerprint: fingerprint,
+		Status:      models.IdempotencyProcessing,
+		CreatedAt:   time.Now(),
+	}
+	repo.store.idempotencyKeys[idempotencyMapKey(scope, key)] = record
+
+	return &record, true, nil
+}
+
+func (repo *MemoryIdempotencyRepository) Release(scope, key string) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if record, ok := repo.store.idempotencyKeys[idempotencyMapKey(scope, key)]; ok && record.Status == models.IdempotencyProcessing {
+		delete(repo.store.idempotencyKeys, idempotencyMapKey(scope, key))
+	}
+
+	return nil
+}
+
+// checkIdempotencyKey - key harus masih processing sebelum checkout mengubah store
+func (s *MemoryStore) checkIdempotencyKey(scope, key string) error {
+	if key == "" {
+		return nil
+	}
+	if record, ok := s.idempotencyKeys[idempotencyMapKey(scope, key)]; !ok || record.Status != models.IdempotencyProcessing {
+		return ErrIdempotencyKeyLost
+	}
+	return nil
+}
+
+// completeIdempotencyKey - simpan response checkout, key sudah dicek dengan
+// checkIdempotencyKey di bawah lock yang sama
+func (s *MemoryStore) completeIdempotencyKey(scope, key string, response any) error {
+	if key == "" {
+		return nil
+	}
+	body, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+
+	record := s.idempotencyKeys[idempotencyMapKey(scope, key)]
+	record.Status = models.IdempotencyCompleted
+	record.ResponseCode = http.StatusOK
+	record.ResponseBody = body
+	s.idempotencyKeys[idempotencyMapKey(scope, key)] = record
+	return nil
+}
@@ -0,0 +1,191 @@
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"kasir-api/models"
+)
+
+type MemoryInventoryRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryInventoryRepository(store *MemoryStore) *MemoryInventoryRepository {
+	return &MemoryInventoryRepository{store: store}
+}
+
+func (repo *MemoryInventoryRepository) CreateAdjustment(adj *models.StockAdjustment, policy StockPolicy) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	return repo.store.insertAdjustment(adj, policy)
+}
+
+// insertAdjustment - semua item dicek dulu sebelum store diubah, supaya
+// atomic seperti versi Postgres. Caller harus sudah memegang store.mu.
+func (s *MemoryStore) insertAdjustment(adj *models.StockAdjustment, policy StockPolicy) error {
+	if _, ok := s.locations[adj.LocationID]; !ok {
+		return ErrLocationNotFound
+	}
+	if err := s.checkAdjustment(adj.Items, adj.LocationID, policy.AllowNegative); err != nil {
+		return err
+	}
+
+	adj.ID = s.nextAdjustmentID
+	adj.CreatedAt = time.Now()
+	s.nextAdjustmentID++
+
+	for _, i := range adjustmentOrder(adj.Items) {
+		m := adjustmentMovement(adj, adj.Items[i])
+		m.CostMethod = string(policy.Costing)
+		if err := s.applyStockMovement(&m, policy.AllowNegative); err != nil {
+			return err
+		}
+		adj.Items[i].StockAfter, adj.Items[i].MovementID = m.StockAfter, m.ID
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) checkAdjustment(items []models.StockAdjustmentItem, locationID int, allowNegative bool) error {
+	stock := make(map[int]int)
+	for _, i := range adjustmentOrder(items) {
+		item := items[i]
+		if _, ok := s.products[item.ProductID]; !ok {
+			return adjustmentError(ErrProductNotFound, item.ProductID)
+		}
+		if _, seen := stock[item.ProductID]; !seen {
+			stock[item.ProductID] = s.locationStock(item.ProductID, locationID)
+		}
+		stock[item.ProductID] += item.Quantity
+		if item.Quantity < 0 && !allowNegative && stock[item.ProductID] < 0 {
+			return adjustmentError(ErrInsufficientStock, item.ProductID)
+		}
+		if item.Quantity < 0 && item.LotNumber != "" && s.lotStock(item.ProductID, locationID, item.LotNumber) < -item.Quantity {
+			return adjustmentError(fmt.Errorf("%w: lot %s", ErrInsufficientBatch, item.LotNumber), item.ProductID)
+		}
+		m := models.StockMovement{ProductID: item.ProductID, Delta: item.Quantity, Reason: models.StockReasonAdjustment, Serials: item.Serials}
+		if err := s.checkSerials(&m); err != nil {
+			return adjustmentError(err, item.ProductID)
+		}
+	}
+	return nil
+}
+
+func (repo *MemoryInventoryRepository) ListLowStock() ([]models.LowStockItem, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	items := make([]models.LowStockItem, 0)
+	for _, p := range repo.store.products {
+		if p.ReorderPoint <= 0 || p.Stock > p.ReorderPoint {
+			continue
+		}
+		items = append(items, models.LowStockItem{
+			ProductID:       p.ID,
+			ProductName:     p.Name,
+			CategoryID:      p.CategoryID,
+			CategoryName:    repo.store.categories[p.CategoryID].Name,
+			SupplierID:      p.SupplierID,
+			SupplierName:    repo.store.suppliers[p.SupplierID].Name,
+			Stock:           p.Stock,
+			ReorderPoint:    p.ReorderPoint,
+			ReorderQuantity: p.ReorderQuantity,
+		})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ProductID < items[j].ProductID })
+
+	return items, nil
+}
+
+func (repo *MemoryInventoryRepository) ListProductDemand(since time.Time) ([]models.ProductDemand, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	onOrder := make(map[int]int)
+	for _, po := range repo.store.purchaseOrders {
+		if po.Status != models.PurchaseOrderSent && po.Status != models.PurchaseOrderPartiallyReceived {
+			continue
+		}
+		for _, line := range po.Lines {
+			onOrder[line.ProductID] += line.Quantity - line.ReceivedQuantity
+		}
+	}
+
+	days := make(map[int]string)
+	for _, t := range repo.store.transactions {
+		if t.VoidedAt == nil && !t.CreatedAt.Before(since) {
+			days[t.ID] = t.CreatedAt.Format("2006-01-02")
+		}
+	}
+	sales := make(map[int]map[string]int)
+	for _, d := range repo.store.details {
+		day, ok := days[d.TransactionID]
+		if !ok {
+			continue
+		}
+		if sales[d.ProductID] == nil {
+			sales[d.ProductID] = make(map[string]int)
+		}
+		sales[d.ProductID][day] += d.Quantity
+	}
+
+	demand := make([]models.ProductDemand, 0, len(repo.store.products))
+	for _, p := range repo.store.products {
+		supplier := repo.store.suppliers[p.SupplierID]
+		d := models.ProductDemand{
+			ProductID:       p.ID,
+			ProductName:     p.Name,
+			SupplierID:      p.SupplierID,
+			SupplierName:    supplier.Name,
+			LeadTimeDays:    supplier.LeadTimeDays,
+			Stock:           p.Stock,
+			OnOrder:         onOrder[p.ID],
+			ReorderQuantity: p.ReorderQuantity,
+			DailySales:      sales[p.ID],
+		}
+		if d.DailySales == nil {
+			d.DailySales = make(map[string]int)
+		}
+		demand = append(demand, d)
+	}
+	sort.Slice(demand, func(i, j int) bool { return demand[i].ProductID < demand[j].ProductID })
+
+	return demand, nil
+}
+
+func (repo *MemoryInventoryRepository) ListValuation(before *time.Time) ([]models.ValuationItem, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	byProduct := make(map[int]*models.ValuationItem)
+	for _, m := range repo.store.stockMovements {
+		if !costedMovement(&m) || (before != nil && !m.CreatedAt.Before(*before)) {
+			continue
+		}
+		p, ok := repo.store.products[m.ProductID]
+		if !ok {
+			continue
+		}
+		item, ok := byProduct[p.ID]
+		if !ok {
+			category := repo.store.categories[p.CategoryID]
+			item = &models.ValuationItem{ProductID: p.ID, ProductName: p.Name, CategoryID: category.ID, CategoryName: category.Name}
+			byProduct[p.ID] = item
+		}
+		item.Quantity += m.Delta
+		item.Value += m.CostAmount
+	}
+
+	items := make([]models.ValuationItem, 0, len(byProduct))
+	for _, item := range byProduct {
+		if item.Quantity != 0 || item.Value != 0 {
+			items = append(items, valuationItem(*item))
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ProductID < items[j].ProductID })
+
+	return items, nil
+}
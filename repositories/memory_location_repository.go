@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"sort"
+
+	"kasir-api/models"
+)
+
+type productLocationKey struct {
+	productID  int
+	locationID int
+}
+
+type MemoryLocationRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryLocationRepository(store *MemoryStore) *MemoryLocationRepository {
+	return &MemoryLocationRepository{store: store}
+}
+
+func (repo *MemoryLocationRepository) GetAll() ([]models.Location, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	locations := make([]models.Location, 0, len(repo.store.locations))
+	for _, l := range repo.store.locations {
+		locations = append(locations, l)
+	}
+	sort.Slice(locations, func(i, j int) bool { return locations[i].ID < locations[j].ID })
+
+	return locations, nil
+}
+
+func (repo *MemoryLocationRepository) Create(location *models.Location) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	location.ID = repo.store.nextLocationID
+	repo.store.nextLocationID++
+	repo.store.locations[location.ID] = *location
+
+	return nil
+}
+
+func (repo *MemoryLocationRepository) GetByID(id int) (*models.Location, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	l, ok := repo.store.locations[id]
+	if !ok {
+		return nil, ErrLocationNotFound
+	}
+
+	return &l, nil
+}
+
+func (repo *MemoryLocationRepository) Update(location *models.Location) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if _, ok := repo.store.locations[location.ID]; !ok {
+		return ErrLocationNotFound
+	}
+	repo.store.locations[location.ID] = *location
+
+	return nil
+}
+
+// Delete - sama dengan foreign key di Postgres, lokasi yang sudah dipakai
+// ledger, transaksi atau dokumen lain ditolak
+func (repo *MemoryLocationRepository) Delete(id int) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if _, ok := repo.store.locations[id]; !ok {
+		return ErrLocationNotFound
+	}
+	if repo.store.locationInUse(id) {
+		return ErrLocationInUse
+	}
+	delete(repo.store.locations, id)
+
+	return nil
+}
+
+func (s *MemoryStore) locationInUse(id int) bool {
+	for key := range s.productStocks {
+		if key.locationID == id {
+			return true
+		}
+	}
+	for _, t := range s.transactions {
+		if t.LocationID == id {
+			return true
+		}
+	}
+	for _, t := range s.stockTransfers {
+		if t.FromLocationID == id || t.ToLocationID == id {
+			return true
+		}
+	}
+	for _, po := range s.purchaseOrders {
+		if po.LocationID == id {
+			return true
+		}
+	}
+	for _, c := range s.stockCounts {
+		if c.LocationID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// productLocations - sama dengan loadProductLocations versi Postgres
+func (s *MemoryStore) productLocations(productID int) []models.LocationStock {
+	byLocation := make(map[int]*models.LocationStock)
+	add := func(locationID int) *models.LocationStock {
+		if ls, ok := byLocation[locationID]; ok {
+			return ls
+		}
+		ls := &models.LocationStock{LocationID: locationID, LocationName: s.locations[locationID].Name}
+		byLocation[locationID] = ls
+		return ls
+	}
+
+	for key, stock := range s.productStocks {
+		if key.productID == productID {
+			add(key.locationID).Stock += stock
+		}
+	}
+	for _, t := range s.stockTransfers {
+		if t.Status != models.TransferShipped {
+			continue
+		}
+		for _, line := range t.Lines {
+			if line.ProductID == productID {
+				add(t.ToLocationID).InTransit += line.Quantity
+			}
+		}
+	}
+
+	locations := make([]models.LocationStock, 0, len(byLocation))
+	for _, ls := range byLocation {
+		locations = append(locations, *ls)
+	}
+	sort.Slice(locations, func(i, j int) bool { return locations[i].LocationID < locations[j].LocationID })
+	return locations
+}
@@ -0,0 +1,342 @@
+package repositories
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"kasir-api/models"
+)
+
+type MemoryProductRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryProductRepository(store *MemoryStore) *MemoryProductRepository {
+	return &MemoryProductRepository{store: store}
+}
+
+// GetAll - sama dengan versi Postgres, varian dikelompokkan di bawah induknya
+func (repo *MemoryProductRepository) GetAll(name string) ([]models.Product, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	products := []models.Product{}
+	for _, p := range repo.store.products {
+		if p.ParentID != 0 {
+			continue
+		}
+		p.Locations = repo.store.productLocations(p.ID)
+		p.InTransit = sumInTransit(p.Locations)
+		p.Variants = repo.store.variants(p.ID)
+
+		// sama dengan ILIKE '%name%' di nama induk, nama varian atau SKU varian
+		if name != "" && !containsFold(p.Name, name) && !slices.ContainsFunc(p.Variants, func(v models.Product) bool {
+			return containsFold(v.Name, name) || containsFold(v.SKU, name)
+		}) {
+			continue
+		}
+		products = append(products, p)
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+
+	return products, nil
+}
+
+func (repo *MemoryProductRepository) GetVariants(parentID int) ([]models.Product, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if _, ok := repo.store.products[parentID]; !ok {
+		return nil, ErrProductNotFound
+	}
+	return repo.store.variants(parentID), nil
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func (repo *MemoryProductRepository) Create(product *models.Product) error {
+	if err := normalizeProductFields(product, product.ParentID != 0); err != nil {
+		return err
+	}
+
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if product.ParentID != 0 {
+		parent, ok := repo.store.products[product.ParentID]
+		if !ok {
+			return fmt.Errorf("%w: produk induk id %d tidak ditemukan", ErrInvalidVariant, product.ParentID)
+		}
+		if err := checkVariantParent(parent); err != nil {
+			return err
+		}
+		inheritFromParent(product, parent)
+	}
+	if err := repo.store.checkCodes(0, product.SKU, product.Barcodes); err != nil {
+		return err
+	}
+
+	if err := repo.store.checkTaxRateExists(product.TaxRateID); err != nil {
+		return err
+	}
+	if err := repo.store.checkSupplierExists(product.SupplierID); err != nil {
+		return err
+	}
+	if product.Stock < 0 {
+		return ErrInsufficientStock
+	}
+	if err := repo.store.checkStockLocation(0, product.LocationID, product.Stock); err != nil {
+		return err
+	}
+
+	product.ID = repo.store.nextProductID
+	repo.store.nextProductID++
+	stored := *product
+	stored.Stock, stored.User, stored.LocationID = 0, "", 0
+	repo.store.products[product.ID] = stored
+
+	if product.Stock != 0 {
+		return repo.store.moveStock(&models.StockMovement{
+			ProductID:     product.ID,
+			LocationID:    product.LocationID,
+			Delta:         product.Stock,
+			Reason:        models.StockReasonAdjustment,
+			ReferenceType: models.StockRefProduct,
+			ReferenceID:   product.ID,
+			User:          product.User,
+			Note:          "stok awal",
+			CostMethod:    product.CostMethod,
+		})
+	}
+
+	return nil
+}
+
+// GetByID - sama seperti versi Postgres (JOIN categories), produk tanpa
+// category yang valid dianggap tidak ditemukan
+func (repo *MemoryProductRepository) GetByID(id int) (*models.ProductResponse, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	p, ok := repo.store.products[id]
+	if !ok {
+		return nil, ErrProductNotFound
+	}
+	c, ok := repo.store.categories[p.CategoryID]
+	if !ok {
+		return nil, ErrProductNotFound
+	}
+	locations := repo.store.productLocations(id)
+
+	var variants []models.Product
+	if p.ParentID == 0 {
+		variants = repo.store.variants(id)
+	}
+
+	return &models.ProductResponse{
+		ID:           p.ID,
+		Name:         p.Name,
+		Price:        p.Price,
+		CostPrice:    p.CostPrice,
+		Stock:        p.Stock,
+		CategoryID:   p.CategoryID,
+		CategoryName: c.Name,
+		TaxRateID:    p.TaxRateID,
+		SupplierID:   p.SupplierID,
+		InTransit:    sumInTransit(locations),
+		Locations:    locations,
+
+		ReorderPoint:    p.ReorderPoint,
+		ReorderQuantity: p.ReorderQuantity,
+		TrackSerial:     p.TrackSerial,
+
+		ParentID:      p.ParentID,
+		SKU:           p.SKU,
+		Attributes:    p.Attributes,
+		PriceOverride: p.PriceOverride,
+		Variants:      variants,
+		Barcodes:      p.Barcodes,
+	}, nil
+}
+
+func (repo *MemoryProductRepository) Update(update *models.ProductUpdate) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	product := &update.Product
+	existing, ok := repo.store.products[product.ID]
+	if !ok {
+		return ErrProductNotFound
+	}
+	product.ParentID = existing.ParentID
+	if err := normalizeProductFields(product, existing.ParentID != 0); err != nil {
+		return err
+	}
+	stored := existing
+	stored.InTransit = repo.store.productInTransit(product.ID)
+	if err := mergeProductUpdate(update, stored); err != nil {
+		return err
+	}
+	if err := repo.store.checkTaxRateExists(product.TaxRateID); err != nil {
+		return err
+	}
+	if err := repo.store.checkSupplierExists(product.SupplierID); err != nil {
+		return err
+	}
+	if existing.ParentID != 0 {
+		inheritFromParent(product, repo.store.products[existing.ParentID])
+	}
+	if err := repo.store.checkCodes(product.ID, product.SKU, product.Barcodes); err != nil {
+		return err
+	}
+	hasVariants := repo.store.hasVariants(product.ID)
+
+	// category_id tidak ikut di-update, sama dengan query UPDATE di Postgres
+	if product.Stock < 0 {
+		return ErrInsufficientStock
+	}
+	if hasVariants && product.Stock != existing.Stock {
+		return fmt.Errorf("%w: stok %s dikelola per varian", ErrInvalidVariant, product.Name)
+	}
+	delta := product.Stock - existing.Stock
+	if err := repo.store.checkStockLocation(product.ID, product.LocationID, delta); err != nil {
+		return err
+	}
+	existing.Name = product.Name
+	existing.Price = product.Price
+	existing.TaxRateID = product.TaxRateID
+	existing.SupplierID = product.SupplierID
+	existing.ReorderPoint = product.ReorderPoint
+	existing.ReorderQuantity = product.ReorderQuantity
+	existing.TrackSerial = product.TrackSerial
+	existing.SKU = product.SKU
+	existing.Attributes = product.Attributes
+	existing.PriceOverride = product.PriceOverride
+	existing.Barcodes = product.Barcodes
+	repo.store.products[product.ID] = existing
+
+	if hasVariants {
+		for id, v := range repo.store.products {
+			if v.ParentID == product.ID && !v.PriceOverride {
+				v.Price = product.Price
+				repo.store.products[id] = v
+			}
+		}
+	}
+
+	if delta != 0 {
+		return repo.store.moveStock(&models.StockMovement{
+			ProductID:     product.ID,
+			LocationID:    product.LocationID,
+			Delta:         delta,
+			Reason:        models.StockReasonAdjustment,
+			ReferenceType: models.StockRefProduct,
+			ReferenceID:   product.ID,
+			User:          product.User,
+			Note:          "update produk",
+			CostMethod:    product.CostMethod,
+		})
+	}
+
+	return nil
+}
+
+func (repo *MemoryProductRepository) Delete(id int) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if _, ok := repo.store.products[id]; !ok {
+		return ErrProductNotFound
+	}
+	// varian ikut terhapus, sama dengan ON DELETE CASCADE di Postgres
+	for _, v := range repo.store.variants(id) {
+		repo.store.deleteProduct(v.ID)
+	}
+	repo.store.deleteProduct(id)
+
+	return nil
+}
+
+// deleteProduct - hapus produk beserta stok, layer HPP, lot dan serial-nya
+func (s *MemoryStore) deleteProduct(id int) {
+	delete(s.products, id)
+	delete(s.costLayers, id)
+	for batchID, b := range s.batches {
+		if b.ProductID == id {
+			delete(s.batches, batchID)
+		}
+	}
+	for serialID, sn := range s.serials {
+		if sn.ProductID == id {
+			delete(s.serials, serialID)
+		}
+	}
+	for key := range s.productStocks {
+		if key.productID == id {
+			delete(s.productStocks, key)
+		}
+	}
+}
+
+// variants - varian satu produk lengkap dengan stok per lokasi, urut id
+func (s *MemoryStore) variants(parentID int) []models.Product {
+	variants := []models.Product{}
+	for _, v := range s.products {
+		if v.ParentID == parentID {
+			v.Locations = s.productLocations(v.ID)
+			v.InTransit = sumInTransit(v.Locations)
+			variants = append(variants, v)
+		}
+	}
+	sort.Slice(variants, func(i, j int) bool { return variants[i].ID < variants[j].ID })
+	return variants
+}
+
+func (s *MemoryStore) hasVariants(productID int) bool {
+	for _, p := range s.products {
+		if p.ParentID == productID {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCodes - SKU dan barcode unik antar produk, sama dengan unique index di Postgres
+func (s *MemoryStore) checkCodes(productID int, sku string, barcodes []models.Barcode) error {
+	for _, p := range s.products {
+		if p.ID == productID {
+			continue
+		}
+		if sku != "" && p.SKU == sku {
+			return ErrDuplicateSKU
+		}
+		for _, b := range barcodes {
+			if slices.ContainsFunc(p.Barcodes, func(other models.Barcode) bool { return other.Code == b.Code }) {
+				return fmt.Errorf("%w: %s", ErrDuplicateBarcode, b.Code)
+			}
+		}
+	}
+	return nil
+}
+
+// checkStockLocation - perubahan stok produk lewat create / update dicek dulu
+// supaya produk tidak tersimpan setengah jalan, sama dengan rollback di Postgres
+func (s *MemoryStore) checkStockLocation(productID, locationID, delta int) error {
+	if delta == 0 {
+		return nil
+	}
+	if locationID == 0 {
+		locationID = models.DefaultLocationID
+	}
+	if _, ok := s.locations[locationID]; !ok {
+		return ErrLocationNotFound
+	}
+	if delta < 0 && s.locationStock(productID, locationID)+delta < 0 {
+		return ErrInsufficientStock
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"sort"
+
+	"kasir-api/models"
+)
+
+type MemoryPromotionRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryPromotionRepository(store *MemoryStore) *MemoryPromotionRepository {
+	return &MemoryPromotionRepository{store: store}
+}
+
+func (repo *MemoryPromotionRepository) GetAll() ([]models.Promotion, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	promotions := make([]models.Promotion, 0, len(repo.store.promotions))
+	for _, p := range repo.store.promotions {
+		promotions = append(promotions, p)
+	}
+	sort.Slice(promotions, func(i, j int) bool { return promotions[i].ID < promotions[j].ID })
+
+	return promotions, nil
+}
+
+func (repo *MemoryPromotionRepository) Create(promotion *models.Promotion) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if err := repo.store.checkPromotionCodeUnique(promotion.Code, 0); err != nil {
+		return err
+	}
+
+	promotion.ID = repo.store.nextPromotionID
+	promotion.UsedCount = 0
+	repo.store.nextPromotionID++
+	repo.store.promotions[promotion.ID] = *promotion
+
+	return nil
+}
+
+func (repo *MemoryPromotionRepository) GetByID(id int) (*models.Promotion, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	p, ok := repo.store.promotions[id]
+	if !ok {
+		return nil, ErrPromotionNotFound
+	}
+	return &p, nil
+}
+
+func (repo *MemoryPromotionRepository) GetByCode(code string) (*models.Promotion, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	for _, p := range repo.store.promotions {
+		if p.Code == code {
+			return &p, nil
+		}
+	}
+	return nil, ErrPromotionNotFound
+}
+
+func (repo *MemoryPromotionRepository) Update(promotion *models.Promotion) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	existing, ok := repo.store.promotions[promotion.ID]
+	if !ok {
+		return ErrPromotionNotFound
+	}
+	if err := repo.store.checkPromotionCodeUnique(promotion.Code, promotion.ID); err != nil {
+		return err
+	}
+
+	promotion.UsedCount = existing.UsedCount
+	repo.store.promotions[promotion.ID] = *promotion
+
+	return nil
+}
+
+func (repo *MemoryPromotionRepository) Delete(id int) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if _, ok := repo.store.promotions[id]; !ok {
+		return ErrPromotionNotFound
+	}
+	delete(repo.store.promotions, id)
+
+	return nil
+}
+
+// checkPromotionCodeUnique - pengganti UNIQUE constraint di Postgres
+func (s *MemoryStore) checkPromotionCodeUnique(code string, exceptID int) error {
+	for _, p := range s.promotions {
+		if p.Code == code && p.ID != exceptID {
+			return errDuplicatePromotionCode
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"sort"
+
+	"kasir-api/models"
+)
+
+type MemoryPromotionRuleRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryPromotionRuleRepository(store *MemoryStore) *MemoryPromotionRuleRepository {
+	return &MemoryPromotionRuleRepository{store: store}
+}
+
+func (repo *MemoryPromotionRuleRepository) GetAll() ([]models.PromotionRule, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	rules := make([]models.PromotionRule, 0, len(repo.store.promotionRules))
+	for _, r := range repo.store.promotionRules {
+		rules = append(rules, r)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+
+	return rules, nil
+}
+
+func (repo *MemoryPromotionRuleRepository) Create(rule *models.PromotionRule) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	rule.ID = repo.store.nextPromotionRuleID
+	repo.store.nextPromotionRuleID++
+	repo.store.promotionRules[rule.ID] = *rule
+
+	return nil
+}
+
+func (repo *MemoryPromotionRuleRepository) GetByID(id int) (*models.PromotionRule, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	r, ok := repo.store.promotionRules[id]
+	if !ok {
+		return nil, ErrPromotionRuleNotFound
+	}
+	return &r, nil
+}
+
+func (repo *MemoryPromotionRuleRepository) Update(rule *models.PromotionRule) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if _, ok := repo.store.promotionRules[rule.ID]; !ok {
+		return ErrPromotionRuleNotFound
+	}
+	repo.store.promotionRules[rule.ID] = *rule
+
+	return nil
+}
+
+func (repo *MemoryPromotionRuleRepository) Delete(id int) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if _, ok := repo.store.promotionRules[id]; !ok {
+		return ErrPromotionRuleNotFound
+	}
+	delete(repo.store.promotionRules, id)
+
+	return nil
+}
@@ -0,0 +1,257 @@
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"kasir-api/models"
+)
+
+type MemoryPurchaseOrderRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryPurchaseOrderRepository(store *MemoryStore) *MemoryPurchaseOrderRepository {
+	return &MemoryPurchaseOrderRepository{store: store}
+}
+
+func (repo *MemoryPurchaseOrderRepository) GetAll(filter models.PurchaseOrderFilter) ([]models.PurchaseOrder, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	orders := make([]models.PurchaseOrder, 0)
+	for _, po := range repo.store.purchaseOrders {
+		if filter.Status != "" && po.Status != filter.Status {
+			continue
+		}
+		if filter.SupplierID != 0 && po.SupplierID != filter.SupplierID {
+			continue
+		}
+		po = repo.store.purchaseOrderCopy(po)
+		po.Lines = nil
+		orders = append(orders, po)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID > orders[j].ID })
+
+	return orders, nil
+}
+
+// purchaseOrderCopy - salinan PO dengan nama supplier dan total terbaru
+func (s *MemoryStore) purchaseOrderCopy(po models.PurchaseOrder) models.PurchaseOrder {
+	po.SupplierName = s.suppliers[po.SupplierID].Name
+	po.Lines = append([]models.PurchaseOrderLine(nil), po.Lines...)
+	fillPurchaseOrderTotals(&po)
+	return po
+}
+
+func (repo *MemoryPurchaseOrderRepository) Create(po *models.PurchaseOrder) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if err := repo.store.preparePurchaseOrder(po); err != nil {
+		return err
+	}
+
+	po.ID = repo.store.nextPurchaseOrderID
+	po.Status = models.PurchaseOrderDraft
+	po.CreatedAt = time.Now()
+	repo.store.nextPurchaseOrderID++
+	repo.store.assignPurchaseOrderLines(po)
+
+	repo.store.purchaseOrders[po.ID] = repo.store.purchaseOrderCopy(*po)
+
+	return nil
+}
+
+// preparePurchaseOrder - cek supplier, lokasi dan produk, isi nama untuk snapshot
+func (s *MemoryStore) preparePurchaseOrder(po *models.PurchaseOrder) error {
+	supplier, ok := s.suppliers[po.SupplierID]
+	if !ok {
+		return ErrSupplierNotFound
+	}
+	po.SupplierName = supplier.Name
+	if _, ok := s.locations[po.LocationID]; !ok {
+		return ErrLocationNotFound
+	}
+
+	for i := range po.Lines {
+		product, ok := s.products[po.Lines[i].ProductID]
+		if !ok {
+			return fmt.Errorf("%w (product id %d)", ErrProductNotFound, po.Lines[i].ProductID)
+		}
+		po.Lines[i].ProductName = product.Name
+	}
+	return nil
+}
+
+func (s *MemoryStore) assignPurchaseOrderLines(po *models.PurchaseOrder) {
+	for i := range po.Lines {
+		po.Lines[i].ID = s.nextPurchaseOrderLineID
+		po.Lines[i].PurchaseOrderID = po.ID
+		s.nextPurchaseOrderLineID++
+	}
+	fillPurchaseOrderTotals(po)
+}
+
+func (repo *MemoryPurchaseOrderRepository) GetByID(id int) (*models.PurchaseOrder, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	po, ok := repo.store.purchaseOrders[id]
+	if !ok {
+		return nil, ErrPurchaseOrderNotFound
+	}
+	po = repo.store.purchaseOrderCopy(po)
+
+	return &po, nil
+}
+
+func (repo *MemoryPurchaseOrderRepository) Update(po *models.PurchaseOrder) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	current, ok := repo.store.purchaseOrders[po.ID]
+	if !ok {
+		return ErrPurchaseOrderNotFound
+	}
+	if current.Status != models.PurchaseOrderDraft {
+		return ErrPurchaseOrderStatus
+	}
+	if err := repo.store.preparePurchaseOrder(po); err != nil {
+		return err
+	}
+
+	repo.store.assignPurchaseOrderLines(po)
+	current.SupplierID, current.LocationID, current.Note, current.Lines = po.SupplierID, po.LocationID, po.Note, po.Lines
+	repo.store.purchaseOrders[po.ID] = repo.store.purchaseOrderCopy(current)
+
+	return nil
+}
+
+func (repo *MemoryPurchaseOrderRepository) Delete(id int) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	po, ok := repo.store.purchaseOrders[id]
+	if !ok {
+		return ErrPurchaseOrderNotFound
+	}
+	if po.Status != models.PurchaseOrderDraft {
+		return ErrPurchaseOrderStatus
+	}
+	delete(repo.store.purchaseOrders, id)
+
+	return nil
+}
+
+func (repo *MemoryPurchaseOrderRepository) Send(id int) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	po, ok := repo.store.purchaseOrders[id]
+	if !ok {
+		return ErrPurchaseOrderNotFound
+	}
+	if po.Status != models.PurchaseOrderDraft {
+		return ErrPurchaseOrderStatus
+	}
+
+	now := time.Now()
+	po.Status, po.SentAt = models.PurchaseOrderSent, &now
+	repo.store.purchaseOrders[id] = po
+
+	return nil
+}
+
+func (repo *MemoryPurchaseOrderRepository) Receive(id int, req models.GoodsReceiptRequest, user string) (*models.GoodsReceipt, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	stored, ok := repo.store.purchaseOrders[id]
+	if !ok {
+		return nil, ErrPurchaseOrderNotFound
+	}
+	if !canReceive(stored.Status) {
+		return nil, ErrPurchaseOrderStatus
+	}
+	po := repo.store.purchaseOrderCopy(stored)
+
+	items, err := planReceipt(po.Lines, req)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if _, ok := repo.store.products[item.ProductID]; !ok {
+			return nil, fmt.Errorf("%w (product id %d)", ErrProductNotFound, item.ProductID)
+		}
+		m := models.StockMovement{ProductID: item.ProductID, Delta: item.Quantity, Reason: models.StockReasonReceipt, Serials: item.Serials}
+		if err := repo.store.checkSerials(&m); err != nil {
+			return nil, fmt.Errorf("%w (product id %d)", err, item.ProductID)
+		}
+	}
+
+	receipt := models.GoodsReceipt{
+		ID:              repo.store.nextGoodsReceiptID,
+		PurchaseOrderID: id,
+		Note:            req.Note,
+		User:            user,
+		CreatedAt:       time.Now(),
+	}
+	repo.store.nextGoodsReceiptID++
+
+	for i := range items {
+		item := &items[i]
+		item.ID = repo.store.nextGoodsReceiptItemID
+		item.GoodsReceiptID = receipt.ID
+		repo.store.nextGoodsReceiptItemID++
+
+		m := models.StockMovement{
+			ProductID:     item.ProductID,
+			LocationID:    po.LocationID,
+			Delta:         item.Quantity,
+			UnitCost:      item.UnitCost,
+			Batches:       batchLots(item.LotNumber, item.ExpiryDate, item.Quantity),
+			Serials:       item.Serials,
+			Reason:        models.StockReasonReceipt,
+			ReferenceType: models.StockRefReceipt,
+			ReferenceID:   receipt.ID,
+			User:          user,
+			Note:          receiptNote(&po, req.Note),
+		}
+		if err := repo.store.moveStock(&m); err != nil {
+			return nil, err
+		}
+		item.MovementID = m.ID
+
+		for j := range po.Lines {
+			if po.Lines[j].ID == item.PurchaseOrderLineID {
+				po.Lines[j].ReceivedQuantity += item.Quantity
+			}
+		}
+	}
+	receipt.Items = items
+
+	po.Status = statusAfterReceipt(po.Lines)
+	if po.Status == models.PurchaseOrderReceived {
+		po.ReceivedAt = &receipt.CreatedAt
+	}
+	repo.store.purchaseOrders[id] = po
+	repo.store.goodsReceipts = append(repo.store.goodsReceipts, receipt)
+
+	return &receipt, nil
+}
+
+func (repo *MemoryPurchaseOrderRepository) ListReceipts(id int) ([]models.GoodsReceipt, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	receipts := make([]models.GoodsReceipt, 0)
+	for _, r := range repo.store.goodsReceipts {
+		if r.PurchaseOrderID == id {
+			receipts = append(receipts, r)
+		}
+	}
+
+	return receipts, nil
+}
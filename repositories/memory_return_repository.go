@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"time"
+
+	"kasir-api/models"
+)
+
+func (repo *MemoryTransactionRepository) CreateReturn(transactionID int, req models.ReturnRequest) (*models.Return, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	transaction, ok := repo.store.findTransaction(transactionID)
+	if !ok {
+		return nil, ErrTransactionNotFound
+	}
+	if transaction.VoidedAt != nil {
+		return nil, ErrTransactionVoided
+	}
+
+	lines := repo.store.returnableLines(transactionID)
+	items, err := planReturn(lines, req)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := models.Return{
+		ID:            repo.store.nextReturnID,
+		TransactionID: transactionID,
+		TotalAmount:   sumReturnAmount(items),
+		Reason:        req.Reason,
+		CreatedAt:     time.Now(),
+	}
+
+	productIDs, restock := restockQuantities(items)
+	costs := restockCosts(lines)
+	serials := restockSerials(items)
+	movements := make([]models.StockMovement, len(productIDs))
+	for i, productID := range productIDs {
+		movements[i] = models.StockMovement{
+			ProductID:     productID,
+			LocationID:    transaction.LocationID,
+			Delta:         restock[productID],
+			UnitCost:      costs[productID],
+			Serials:       serials[productID],
+			Reason:        models.StockReasonReturn,
+			ReferenceType: models.StockRefReturn,
+			ReferenceID:   ret.ID,
+			User:          req.User,
+			Note:          req.Reason,
+		}
+		if err := repo.store.checkSerials(&movements[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	repo.store.nextReturnID++
+	for i := range items {
+		items[i].ID = repo.store.nextReturnItemID
+		items[i].ReturnID = ret.ID
+		repo.store.nextReturnItemID++
+	}
+	for i := range movements {
+		m := &movements[i]
+		m.Batches = takeLots(repo.store.soldLots(transactionID, m.ProductID), m.Delta)
+		if err := repo.store.moveStock(m); err != nil {
+			return nil, err
+		}
+	}
+	ret.Items = items
+	repo.store.returns = append(repo.store.returns, ret)
+
+	return &ret, nil
+}
+
+func (repo *MemoryTransactionRepository) ListReturns(transactionID int) ([]models.Return, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	returns := make([]models.Return, 0)
+	for _, r := range repo.store.returns {
+		if r.TransactionID == transactionID {
+			returns = append(returns, r)
+		}
+	}
+
+	return returns, nil
+}
+
+func (s *MemoryStore) findTransaction(id int) (models.Transaction, bool) {
+	for _, t := range s.transactions {
+		if t.ID == id {
+			return t, true
+		}
+	}
+	return models.Transaction{}, false
+}
+
+func (s *MemoryStore) returnableLines(transactionID int) []returnableLine {
+	returned := make(map[int]returnableLine)
+	for _, r := range s.returns {
+		if r.TransactionID != transactionID {
+			continue
+		}
+		for _, item := range r.Items {
+			line := returned[item.TransactionDetailID]
+			line.returnedQty += item.Quantity
+			line.returnedAmount -= item.Amount
+			line.returnedTax -= item.TaxAmount
+			line.returnedSerials = append(line.returnedSerials, item.Serials...)
+			returned[item.TransactionDetailID] = line
+		}
+	}
+
+	var lines []returnableLine
+	for _, d := range s.details {
+		if d.TransactionID == transactionID {
+			line := returned[d.ID]
+			line.detail = d
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
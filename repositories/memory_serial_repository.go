@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"kasir-api/models"
+)
+
+func (repo *MemoryInventoryRepository) FindSerials(serial string) ([]models.SerialNumber, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	units := make([]models.SerialNumber, 0)
+	for _, sn := range repo.store.serials {
+		if sn.Serial != serial {
+			continue
+		}
+		sn.ProductName = repo.store.products[sn.ProductID].Name
+		sn.History = make([]models.StockMovement, 0)
+		for _, m := range repo.store.stockMovements {
+			if m.ProductID == sn.ProductID && slices.Contains(m.Serials, serial) {
+				m.Batches, m.Serials = nil, nil
+				sn.History = append(sn.History, m)
+			}
+		}
+		units = append(units, sn)
+	}
+	if len(units) == 0 {
+		return nil, ErrSerialNotFound
+	}
+	sort.Slice(units, func(i, j int) bool { return units[i].ID < units[j].ID })
+
+	return units, nil
+}
+
+// findSerial - unit (product, serial), caller memegang store.mu
+func (s *MemoryStore) findSerial(productID int, serial string) (models.SerialNumber, bool) {
+	for _, sn := range s.serials {
+		if sn.ProductID == productID && sn.Serial == serial {
+			return sn, true
+		}
+	}
+	return models.SerialNumber{}, false
+}
+
+// checkSerials - sama dengan pengecekan moveSerials versi Postgres tapi tanpa
+// mengubah store, dipakai sebelum operasi yang terdiri dari beberapa movement
+func (s *MemoryStore) checkSerials(m *models.StockMovement) error {
+	product, ok := s.products[m.ProductID]
+	if !ok {
+		return ErrProductNotFound
+	}
+	if err := checkSerialMovement(m, product.TrackSerial); err != nil {
+		return err
+	}
+
+	for _, serial := range m.Serials {
+		sn, ok := s.findSerial(m.ProductID, serial)
+		if (m.Delta > 0 && ok && sn.Status == models.SerialInStock) ||
+			(m.Delta < 0 && (!ok || sn.Status != models.SerialInStock)) {
+			return fmt.Errorf("%w: %s", ErrSerialUnavailable, serial)
+		}
+	}
+	return nil
+}
+
+// moveSerials - ubah status unit setelah checkSerials lolos
+func (s *MemoryStore) moveSerials(m *models.StockMovement) {
+	for _, serial := range m.Serials {
+		sn, ok := s.findSerial(m.ProductID, serial)
+		if !ok {
+			sn = models.SerialNumber{
+				ID:        s.nextSerialID,
+				ProductID: m.ProductID,
+				Serial:    serial,
+				CreatedAt: time.Now(),
+			}
+			s.nextSerialID++
+		}
+		sn.Status = serialStatusAfter(m)
+		s.serials[sn.ID] = sn
+	}
+}
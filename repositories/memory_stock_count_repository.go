@@ -0,0 +1,191 @@
+package repositories
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"kasir-api/models"
+)
+
+type stockCountEntryKey struct {
+	countID   int
+	productID int
+	device    string
+}
+
+type MemoryStockCountRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryStockCountRepository(store *MemoryStore) *MemoryStockCountRepository {
+	return &MemoryStockCountRepository{store: store}
+}
+
+func (repo *MemoryStockCountRepository) Create(count *models.StockCount, productIDs, categoryIDs []int) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if _, ok := repo.store.locations[count.LocationID]; !ok {
+		return ErrLocationNotFound
+	}
+
+	all := len(productIDs) == 0 && len(categoryIDs) == 0
+	lines := make([]models.StockCountLine, 0)
+	for _, p := range repo.store.products {
+		if !all && !slices.Contains(productIDs, p.ID) && !slices.Contains(categoryIDs, p.CategoryID) {
+			continue
+		}
+		lines = append(lines, models.StockCountLine{
+			ProductID:   p.ID,
+			ProductName: p.Name,
+			CategoryID:  p.CategoryID,
+			UnitPrice:   int(p.Price),
+			UnitCost:    p.CostPrice,
+			SystemStock: repo.store.locationStock(p.ID, count.LocationID),
+		})
+	}
+	if len(lines) == 0 {
+		return ErrStockCountEmpty
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i].ProductID < lines[j].ProductID })
+
+	count.ID = repo.store.nextStockCountID
+	count.Status = models.StockCountOpen
+	count.CreatedAt = time.Now()
+	repo.store.nextStockCountID++
+
+	stored := *count
+	stored.Lines = lines
+	repo.store.stockCounts[count.ID] = stored
+	count.Lines = repo.store.stockCountLines(stored)
+
+	return nil
+}
+
+func (repo *MemoryStockCountRepository) GetAll() ([]models.StockCount, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	counts := make([]models.StockCount, 0, len(repo.store.stockCounts))
+	for _, c := range repo.store.stockCounts {
+		c.Lines = nil
+		counts = append(counts, c)
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i].ID > counts[j].ID })
+
+	return counts, nil
+}
+
+func (repo *MemoryStockCountRepository) GetByID(id int) (*models.StockCount, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	c, ok := repo.store.stockCounts[id]
+	if !ok {
+		return nil, ErrStockCountNotFound
+	}
+	c.Lines = repo.store.stockCountLines(c)
+
+	return &c, nil
+}
+
+// stockCountLines - salinan lines sesi lengkap dengan entries dan selisihnya
+func (s *MemoryStore) stockCountLines(c models.StockCount) []models.StockCountLine {
+	lines := make([]models.StockCountLine, len(c.Lines))
+	for i, line := range c.Lines {
+		line.Entries = nil
+		for key, e := range s.stockCountEntries {
+			if key.countID == c.ID && key.productID == line.ProductID {
+				line.Entries = append(line.Entries, e)
+			}
+		}
+		sort.Slice(line.Entries, func(a, b int) bool { return line.Entries[a].Device < line.Entries[b].Device })
+		fillCountVariance(&line)
+		lines[i] = line
+	}
+	return lines
+}
+
+func (repo *MemoryStockCountRepository) Submit(id int, sub models.StockCountSubmission, user string) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	c, ok := repo.store.stockCounts[id]
+	if !ok {
+		return ErrStockCountNotFound
+	}
+	if c.Status != models.StockCountOpen {
+		return ErrStockCountClosed
+	}
+
+	inCount := make(map[int]bool, len(c.Lines))
+	for _, line := range c.Lines {
+		inCount[line.ProductID] = true
+	}
+	for _, item := range sub.Items {
+		if !inCount[item.ProductID] {
+			return ErrStockCountUnknownProduct
+		}
+	}
+
+	now := time.Now()
+	for _, item := range sub.Items {
+		repo.store.stockCountEntries[stockCountEntryKey{id, item.ProductID, sub.Device}] = models.StockCountEntry{
+			Device:    sub.Device,
+			Quantity:  item.Quantity,
+			CountedBy: user,
+			UpdatedAt: now,
+		}
+	}
+
+	return nil
+}
+
+func (repo *MemoryStockCountRepository) Finalize(id int, user string, policy StockPolicy) (*models.StockCount, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	c, ok := repo.store.stockCounts[id]
+	if !ok {
+		return nil, ErrStockCountNotFound
+	}
+	if c.Status != models.StockCountOpen {
+		return nil, ErrStockCountClosed
+	}
+
+	count := c
+	count.Lines = repo.store.stockCountLines(c)
+	if adj := countAdjustment(&count, user); len(adj.Items) > 0 {
+		if err := repo.store.insertAdjustment(adj, policy); err != nil {
+			return nil, err
+		}
+		c.AdjustmentID = adj.ID
+	}
+
+	now := time.Now()
+	c.Status, c.ClosedAt, c.ClosedBy = models.StockCountFinalized, &now, user
+	repo.store.stockCounts[id] = c
+
+	count.Status, count.ClosedAt, count.ClosedBy, count.AdjustmentID = c.Status, c.ClosedAt, c.ClosedBy, c.AdjustmentID
+	return &count, nil
+}
+
+func (repo *MemoryStockCountRepository) Cancel(id int, user string) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	c, ok := repo.store.stockCounts[id]
+	if !ok {
+		return ErrStockCountNotFound
+	}
+	if c.Status != models.StockCountOpen {
+		return ErrStockCountClosed
+	}
+
+	now := time.Now()
+	c.Status, c.ClosedAt, c.ClosedBy = models.StockCountCancelled, &now, user
+	repo.store.stockCounts[id] = c
+
+	return nil
+}
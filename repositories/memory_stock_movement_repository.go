@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"time"
+
+	"kasir-api/models"
+)
+
+type MemoryStockMovementRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryStockMovementRepository(store *MemoryStore) *MemoryStockMovementRepository {
+	return &MemoryStockMovementRepository{store: store}
+}
+
+func (repo *MemoryStockMovementRepository) ListByProduct(productID int) ([]models.StockMovement, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	movements := make([]models.StockMovement, 0)
+	for _, m := range repo.store.stockMovements {
+		if m.ProductID == productID {
+			movements = append(movements, m)
+		}
+	}
+
+	return movements, nil
+}
+
+// moveStock - sama dengan versi Postgres, caller harus sudah memegang store.mu
+func (s *MemoryStore) moveStock(m *models.StockMovement) error {
+	return s.applyStockMovement(m, false)
+}
+
+func (s *MemoryStore) applyStockMovement(m *models.StockMovement, allowNegative bool) error {
+	if m.LocationID == 0 {
+		m.LocationID = models.DefaultLocationID
+	}
+	product, ok := s.products[m.ProductID]
+	if !ok {
+		return ErrProductNotFound
+	}
+	if _, ok := s.locations[m.LocationID]; !ok {
+		return ErrLocationNotFound
+	}
+	key := productLocationKey{m.ProductID, m.LocationID}
+	if m.Delta < 0 && !allowNegative && s.productStocks[key]+m.Delta < 0 {
+		return ErrInsufficientStock
+	}
+	if err := s.checkSerials(m); err != nil {
+		return err
+	}
+	if err := s.moveBatches(m, s.productStocks[key]); err != nil {
+		return err
+	}
+	s.moveSerials(m)
+	stockBefore := product.Stock
+	product.Stock += m.Delta
+	if costedMovement(m) && m.Delta > 0 {
+		stockBefore += s.productInTransit(m.ProductID)
+	}
+	if costedMovement(m) {
+		product.CostPrice = s.costStockMovement(m, stockBefore, product.CostPrice)
+	}
+	s.products[m.ProductID] = product
+	s.productStocks[key] += m.Delta
+
+	m.ID = s.nextStockMovementID
+	m.StockAfter = s.productStocks[key]
+	m.CreatedAt = time.Now()
+	s.nextStockMovementID++
+	s.stockMovements = append(s.stockMovements, *m)
+
+	if costedMovement(m) && m.Delta > 0 {
+		s.costLayers[m.ProductID] = append(s.costLayers[m.ProductID], costLayer{
+			id:        s.nextCostLayerID,
+			unitCost:  m.UnitCost,
+			remaining: layerRemaining(stockBefore, m.Delta),
+		})
+		s.nextCostLayerID++
+	}
+
+	return nil
+}
+
+// costStockMovement - sama dengan versi Postgres, mengembalikan cost_price baru
+func (s *MemoryStore) costStockMovement(m *models.StockMovement, stockBefore, averageCost int) int {
+	if m.Delta > 0 {
+		unitCost := inboundUnitCost(m, averageCost)
+		m.CostMethod = ""
+		setMovementCost(m, m.Delta*unitCost)
+		return weightedCost(stockBefore, averageCost, m.Delta, unitCost)
+	}
+	if m.Delta == 0 {
+		return averageCost
+	}
+
+	layers := s.costLayers[m.ProductID]
+	taken, fifoCost, shortfall := consumeLayers(layers, -m.Delta)
+	open := layers[:0]
+	for i, layer := range layers {
+		layer.remaining -= taken[i]
+		if layer.remaining > 0 {
+			open = append(open, layer)
+		}
+	}
+	s.costLayers[m.ProductID] = open
+
+	method := costingMethodOrDefault(m.CostMethod)
+	m.CostMethod = string(method)
+	setMovementCost(m, outboundCost(method, -m.Delta, fifoCost, shortfall, averageCost))
+	return averageCost
+}
+
+// locationStock - stok produk di satu lokasi (0 kalau belum pernah ada)
+func (s *MemoryStore) locationStock(productID, locationID int) int {
+	if locationID == 0 {
+		locationID = models.DefaultLocationID
+	}
+	return s.productStocks[productLocationKey{productID, locationID}]
+}
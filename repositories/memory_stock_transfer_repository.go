@@ -0,0 +1,171 @@
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"kasir-api/models"
+)
+
+type MemoryStockTransferRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryStockTransferRepository(store *MemoryStore) *MemoryStockTransferRepository {
+	return &MemoryStockTransferRepository{store: store}
+}
+
+// stockTransferCopy - salinan dengan slice lines sendiri, nama lokasi terbaru
+func (s *MemoryStore) stockTransferCopy(t models.StockTransfer) models.StockTransfer {
+	t.FromLocationName = s.locations[t.FromLocationID].Name
+	t.ToLocationName = s.locations[t.ToLocationID].Name
+	t.Lines = append([]models.StockTransferLine(nil), t.Lines...)
+	return t
+}
+
+func (repo *MemoryStockTransferRepository) GetAll(filter models.StockTransferFilter) ([]models.StockTransfer, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	transfers := make([]models.StockTransfer, 0)
+	for _, t := range repo.store.stockTransfers {
+		if filter.Status != "" && t.Status != filter.Status {
+			continue
+		}
+		if filter.LocationID != 0 && t.FromLocationID != filter.LocationID && t.ToLocationID != filter.LocationID {
+			continue
+		}
+		t = repo.store.stockTransferCopy(t)
+		t.Lines = nil
+		transfers = append(transfers, t)
+	}
+	sort.Slice(transfers, func(i, j int) bool { return transfers[i].ID > transfers[j].ID })
+
+	return transfers, nil
+}
+
+func (repo *MemoryStockTransferRepository) Create(transfer *models.StockTransfer) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	for _, id := range []int{transfer.FromLocationID, transfer.ToLocationID} {
+		if _, ok := repo.store.locations[id]; !ok {
+			return ErrLocationNotFound
+		}
+	}
+	lines := make([]models.StockTransferLine, len(transfer.Lines))
+	for i, line := range transfer.Lines {
+		product, ok := repo.store.products[line.ProductID]
+		if !ok {
+			return fmt.Errorf("%w (product id %d)", ErrProductNotFound, line.ProductID)
+		}
+		line.ProductName = product.Name
+		lines[i] = line
+	}
+
+	transfer.ID = repo.store.nextStockTransferID
+	transfer.Status = models.TransferRequested
+	transfer.CreatedAt = time.Now()
+	repo.store.nextStockTransferID++
+	for i := range lines {
+		lines[i].ID = repo.store.nextStockTransferLineID
+		repo.store.nextStockTransferLineID++
+	}
+	transfer.Lines = lines
+
+	repo.store.stockTransfers[transfer.ID] = repo.store.stockTransferCopy(*transfer)
+	*transfer = repo.store.stockTransferCopy(*transfer)
+
+	return nil
+}
+
+func (repo *MemoryStockTransferRepository) GetByID(id int) (*models.StockTransfer, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	t, ok := repo.store.stockTransfers[id]
+	if !ok {
+		return nil, ErrStockTransferNotFound
+	}
+	t = repo.store.stockTransferCopy(t)
+
+	return &t, nil
+}
+
+func (repo *MemoryStockTransferRepository) Ship(id int, user string) (*models.StockTransfer, error) {
+	return repo.advance(id, user, models.TransferRequested, models.TransferShipped)
+}
+
+func (repo *MemoryStockTransferRepository) Receive(id int, user string) (*models.StockTransfer, error) {
+	return repo.advance(id, user, models.TransferShipped, models.TransferReceived)
+}
+
+func (repo *MemoryStockTransferRepository) Cancel(id int, user string) (*models.StockTransfer, error) {
+	return repo.advance(id, user, models.TransferRequested, models.TransferCancelled)
+}
+
+// advance - semua baris dicek dulu sebelum stok diubah, supaya atomic seperti
+// rollback di versi Postgres
+func (repo *MemoryStockTransferRepository) advance(id int, user, from, to string) (*models.StockTransfer, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	t, ok := repo.store.stockTransfers[id]
+	if !ok {
+		return nil, ErrStockTransferNotFound
+	}
+	if t.Status != from {
+		return nil, ErrStockTransferStatus
+	}
+	t = repo.store.stockTransferCopy(t)
+
+	if to != models.TransferCancelled {
+		movements := transferMovements(&t, to == models.TransferReceived, user)
+		for _, m := range movements {
+			if _, ok := repo.store.products[m.ProductID]; !ok {
+				return nil, fmt.Errorf("%w (product id %d)", ErrProductNotFound, m.ProductID)
+			}
+			if m.Delta < 0 && repo.store.locationStock(m.ProductID, m.LocationID)+m.Delta < 0 {
+				return nil, transferStockError(ErrInsufficientStock, m.ProductID)
+			}
+		}
+		for i := range movements {
+			// lot yang dikirim ikut pindah ke lokasi tujuan
+			if m := &movements[i]; m.Delta > 0 {
+				m.Batches = repo.store.shippedLots(t.ID, m.ProductID)
+			}
+			if err := repo.store.moveStock(&movements[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	now := time.Now()
+	switch to {
+	case models.TransferShipped:
+		t.ShippedAt, t.ShippedBy = &now, user
+	case models.TransferReceived:
+		t.ReceivedAt, t.ReceivedBy = &now, user
+	}
+	t.Status = to
+	repo.store.stockTransfers[id] = repo.store.stockTransferCopy(t)
+
+	return &t, nil
+}
+
+// productInTransit - sama dengan versi Postgres, caller memegang store.mu
+func (s *MemoryStore) productInTransit(productID int) int {
+	quantity := 0
+	for _, t := range s.stockTransfers {
+		if t.Status != models.TransferShipped {
+			continue
+		}
+		for _, line := range t.Lines {
+			if line.ProductID == productID {
+				quantity += line.Quantity
+			}
+		}
+	}
+	return quantity
+}
@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"sync"
+
+	"kasir-api/models"
+)
+
+// MemoryStore - penyimpanan in-memory yang dipakai bersama oleh semua
+// Memory*Repository. Satu mutex untuk semua tabel, jadi operasi checkout
+// (baca stok, kurangi stok, simpan transaksi) atomic seperti transaksi DB.
+type MemoryStore struct {
+	mu sync.Mutex
+
+	categories     map[int]models.Category
+	products       map[int]models.Product
+	transactions   []models.Transaction
+	details        []models.TransactionDetail
+	returns        []models.Return
+	payments       []models.Payment
+	stockMovements []models.StockMovement
+
+	idempotencyKeys   map[string]models.IdempotencyRecord
+	promotions        map[int]models.Promotion
+	promotionRules    map[int]models.PromotionRule
+	taxRates          map[int]models.TaxRate
+	stockCounts       map[int]models.StockCount
+	stockCountEntries map[stockCountEntryKey]models.StockCountEntry
+	suppliers         map[int]models.Supplier
+	purchaseOrders    map[int]models.PurchaseOrder
+	goodsReceipts     []models.GoodsReceipt
+	locations         map[int]models.Location
+	productStocks     map[productLocationKey]int
+	stockTransfers    map[int]models.StockTransfer
+	costLayers        map[int][]costLayer
+	batches           map[int]models.Batch
+	serials           map[int]models.SerialNumber
+
+	nextCategoryID          int
+	nextProductID           int
+	nextTransactionID       int
+	nextDetailID            int
+	nextReturnID            int
+	nextReturnItemID        int
+	nextPaymentID           int
+	nextPromotionID         int
+	nextPromotionRuleID     int
+	nextTaxRateID           int
+	nextStockMovementID     int
+	nextAdjustmentID        int
+	nextStockCountID        int
+	nextSupplierID          int
+	nextPurchaseOrderID     int
+	nextPurchaseOrderLineID int
+	nextGoodsReceiptID      int
+	nextGoodsReceiptItemID  int
+	nextLocationID          int
+	nextStockTransferID     int
+	nextStockTransferLineID int
+	nextCostLayerID         int
+	nextBatchID             int
+	nextSerialID            int
+}
+
+// NewMemoryStore - lokasi bawaan sudah ada, sama dengan hasil migrasi
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		categories:        make(map[int]models.Category),
+		products:          make(map[int]models.Product),
+		idempotencyKeys:   make(map[string]models.IdempotencyRecord),
+		promotions:        make(map[int]models.Promotion),
+		promotionRules:    make(map[int]models.PromotionRule),
+		taxRates:          make(map[int]models.TaxRate),
+		stockCounts:       make(map[int]models.StockCount),
+		stockCountEntries: make(map[stockCountEntryKey]models.StockCountEntry),
+		suppliers:         make(map[int]models.Supplier),
+		purchaseOrders:    make(map[int]models.PurchaseOrder),
+		locations: map[int]models.Location{
+			models.DefaultLocationID: {ID: models.DefaultLocationID, Name: "Toko Utama", Type: models.LocationOutlet},
+		},
+		productStocks:           make(map[productLocationKey]int),
+		stockTransfers:          make(map[int]models.StockTransfer),
+		costLayers:              make(map[int][]costLayer),
+		batches:                 make(map[int]models.Batch),
+		serials:                 make(map[int]models.SerialNumber),
+		nextCategoryID:          1,
+		nextProductID:           1,
+		nextTransactionID:       1,
+		nextDetailID:            1,
+		nextReturnID:            1,
+		nextReturnItemID:        1,
+		nextPaymentID:           1,
+		nextPromotionID:         1,
+		nextPromotionRuleID:     1,
+		nextTaxRateID:           1,
+		nextStockMovementID:     1,
+		nextAdjustmentID:        1,
+		nextStockCountID:        1,
+		nextSupplierID:          1,
+		nextPurchaseOrderID:     1,
+		nextPurchaseOrderLineID: 1,
+		nextGoodsReceiptID:      1,
+		nextGoodsReceiptItemID:  1,
+		nextLocationID:          models.DefaultLocationID + 1,
+		nextStockTransferID:     1,
+		nextStockTransferLineID: 1,
+		nextCostLayerID:         1,
+		nextBatchID:             1,
+		nextSerialID:            1,
+	}
+}
@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"sort"
+
+	"kasir-api/models"
+)
+
+type MemorySupplierRepository struct {
+	store *MemoryStore
+}
+
+func NewMemorySupplierRepository(store *MemoryStore) *MemorySupplierRepository {
+	return &MemorySupplierRepository{store: store}
+}
+
+func (repo *MemorySupplierRepository) GetAll() ([]models.Supplier, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	suppliers := make([]models.Supplier, 0, len(repo.store.suppliers))
+	for _, s := range repo.store.suppliers {
+		suppliers = append(suppliers, s)
+	}
+	sort.Slice(suppliers, func(i, j int) bool { return suppliers[i].ID < suppliers[j].ID })
+
+	return suppliers, nil
+}
+
+func (repo *MemorySupplierRepository) Create(supplier *models.Supplier) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	supplier.ID = repo.store.nextSupplierID
+	repo.store.nextSupplierID++
+	repo.store.suppliers[supplier.ID] = *supplier
+
+	return nil
+}
+
+func (repo *MemorySupplierRepository) GetByID(id int) (*models.Supplier, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	s, ok := repo.store.suppliers[id]
+	if !ok {
+		return nil, ErrSupplierNotFound
+	}
+
+	return &s, nil
+}
+
+func (repo *MemorySupplierRepository) Update(supplier *models.Supplier) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if _, ok := repo.store.suppliers[supplier.ID]; !ok {
+		return ErrSupplierNotFound
+	}
+	repo.store.suppliers[supplier.ID] = *supplier
+
+	return nil
+}
+
+func (repo *MemorySupplierRepository) Delete(id int) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if _, ok := repo.store.suppliers[id]; !ok {
+		return ErrSupplierNotFound
+	}
+	for _, po := range repo.store.purchaseOrders {
+		if po.SupplierID == id {
+			return ErrSupplierInUse
+		}
+	}
+	delete(repo.store.suppliers, id)
+	// sama dengan ON DELETE SET NULL di products.supplier_id
+	for pid, p := range repo.store.products {
+		if p.SupplierID == id {
+			p.SupplierID = 0
+			repo.store.products[pid] = p
+		}
+	}
+
+	return nil
+}
+
+// checkSupplierExists - pengganti foreign key products.supplier_id di Postgres
+func (s *MemoryStore) checkSupplierExists(id int) error {
+	if id == 0 {
+		return nil
+	}
+	if _, ok := s.suppliers[id]; !ok {
+		return ErrSupplierNotFound
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"sort"
+
+	"kasir-api/models"
+)
+
+type MemoryTaxRateRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryTaxRateRepository(store *MemoryStore) *MemoryTaxRateRepository {
+	return &MemoryTaxRateRepository{store: store}
+}
+
+func (repo *MemoryTaxRateRepository) GetAll() ([]models.TaxRate, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	rates := make([]models.TaxRate, 0, len(repo.store.taxRates))
+	for _, t := range repo.store.taxRates {
+		rates = append(rates, t)
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].ID < rates[j].ID })
+
+	return rates, nil
+}
+
+func (repo *MemoryTaxRateRepository) Create(rate *models.TaxRate) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	rate.ID = repo.store.nextTaxRateID
+	repo.store.nextTaxRateID++
+	repo.store.taxRates[rate.ID] = *rate
+
+	return nil
+}
+
+func (repo *MemoryTaxRateRepository) GetByID(id int) (*models.TaxRate, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	t, ok := repo.store.taxRates[id]
+	if !ok {
+		return nil, ErrTaxRateNotFound
+	}
+	return &t, nil
+}
+
+func (repo *MemoryTaxRateRepository) Update(rate *models.TaxRate) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if _, ok := repo.store.taxRates[rate.ID]; !ok {
+		return ErrTaxRateNotFound
+	}
+	repo.store.taxRates[rate.ID] = *rate
+
+	return nil
+}
+
+// Delete - sama dengan ON DELETE SET NULL di Postgres
+func (repo *MemoryTaxRateRepository) Delete(id int) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if _, ok := repo.store.taxRates[id]; !ok {
+		return ErrTaxRateNotFound
+	}
+	delete(repo.store.taxRates, id)
+
+	for _, p := range repo.store.products {
+		if p.TaxRateID == id {
+			p.TaxRateID = 0
+			repo.store.products[p.ID] = p
+		}
+	}
+	for _, c := range repo.store.categories {
+		if c.TaxRateID == id {
+			c.TaxRateID = 0
+			repo.store.categories[c.ID] = c
+		}
+	}
+
+	return nil
+}
+
+// checkTaxRateExists - pengganti foreign key tax_rate_id di Postgres
+func (s *MemoryStore) checkTaxRateExists(id int) error {
+	if id == 0 {
+		return nil
+	}
+	if _, ok := s.taxRates[id]; !ok {
+		return ErrTaxRateNotFound
+	}
+	return nil
+}
+
+// productTaxRate - tarif produk, kalau kosong pakai tarif category
+func (s *MemoryStore) productTaxRate(p models.Product) (models.TaxRate, bool) {
+	id := p.TaxRateID
+	if id == 0 {
+		id = s.categories[p.CategoryID].TaxRateID
+	}
+	t, ok := s.taxRates[id]
+	return t, ok
+}
@@ -0,0 +1,532 @@
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"kasir-api/models"
+)
+
+const reportTimeLayout = "2006-01-02 15:04:05"
+
+type MemoryTransactionRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryTransactionRepository(store *MemoryStore) *MemoryTransactionRepository {
+	return &MemoryTransactionRepository{store: store}
+}
+
+// CreateTransaction - semua strategy lock setara di sini karena store dikunci
+// satu mutex, tapi urutan validasi dan pesan error sama dengan versi Postgres
+func (repo *MemoryTransactionRepository) CreateTransaction(req models.CheckoutRequest, opts CheckoutOptions) (*models.Transaction, error) {
+	items := req.Items
+	if err := opts.Strategy.validate(); err != nil {
+		return nil, err
+	}
+	if err := validateCheckoutItems(items); err != nil {
+		return nil, err
+	}
+
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	if _, ok := repo.store.locations[req.LocationID]; !ok {
+		return nil, ErrLocationNotFound
+	}
+	if err := resolveCheckoutCodes(items, repo.store.findProductByCode); err != nil {
+		return nil, err
+	}
+
+	totalAmount := 0
+	details := make([]models.TransactionDetail, 0)
+
+	for _, item := range items {
+		id := itemProductID(item)
+		product, ok := repo.store.products[id]
+		if !ok {
+			return nil, fmt.Errorf("%w (product id %d)", ErrProductNotFound, id)
+		}
+		if err := checkVariantItem(item, product.Name, product.ParentID, repo.store.hasVariants(id)); err != nil {
+			return nil, err
+		}
+
+		subtotal := int(product.Price) * item.Quantity
+		totalAmount += subtotal
+
+		detail := models.TransactionDetail{
+			ProductID:   id,
+			ProductName: product.Name,
+			CategoryID:  product.CategoryID,
+			UnitPrice:   int(product.Price),
+			UnitCost:    product.CostPrice,
+			Quantity:    item.Quantity,
+			Subtotal:    subtotal,
+			Serials:     item.Serials,
+		}
+		if rate, ok := repo.store.productTaxRate(product); ok {
+			detail.TaxRateID, detail.TaxName, detail.TaxRate = rate.ID, rate.Name, rate.Rate
+		}
+		details = append(details, detail)
+	}
+
+	ids := checkoutProductIDs(items)
+	quantities := checkoutQuantities(items)
+	serials := checkoutSerials(items)
+	for _, id := range ids {
+		product := repo.store.products[id]
+		stock := repo.store.locationStock(id, req.LocationID)
+		if stock < quantities[id] {
+			return nil, checkoutStockError(product.Name)
+		}
+		if stock-repo.store.expiredStock(id, req.LocationID) < quantities[id] {
+			return nil, fmt.Errorf("%w: product %s", ErrBatchExpired, product.Name)
+		}
+		sale := models.StockMovement{ProductID: id, Delta: -quantities[id], Reason: models.StockReasonSale, Serials: serials[id]}
+		if err := repo.store.checkSerials(&sale); err != nil {
+			return nil, checkoutSerialError(err, product.Name)
+		}
+	}
+
+	trx := &models.Transaction{
+		LocationID:  req.LocationID,
+		GrossAmount: totalAmount,
+		TotalAmount: totalAmount,
+		Details:     details,
+		Payments:    append([]models.Payment(nil), req.Payments...),
+	}
+	if opts.Finalize != nil {
+		if err := opts.Finalize(trx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := repo.store.checkIdempotencyKey(opts.IdempotencyScope, opts.IdempotencyKey); err != nil {
+		return nil, err
+	}
+
+	if trx.PromotionID != 0 {
+		promo, ok := repo.store.promotions[trx.PromotionID]
+		if !ok || (promo.UsageLimit > 0 && promo.UsedCount >= promo.UsageLimit) {
+			return nil, ErrPromotionExhausted
+		}
+		promo.UsedCount++
+		repo.store.promotions[promo.ID] = promo
+	}
+
+	// mulai dari sini tidak ada lagi yang bisa gagal, aman mengubah store
+	trx.ID = repo.store.nextTransactionID
+	trx.CreatedAt = time.Now()
+	repo.store.nextTransactionID++
+
+	for _, id := range ids {
+		// stok sudah dicek di atas dengan store terkunci, moveStock tidak akan gagal
+		m := models.StockMovement{
+			ProductID:     id,
+			LocationID:    req.LocationID,
+			Delta:         -quantities[id],
+			Reason:        models.StockReasonSale,
+			ReferenceType: models.StockRefTransaction,
+			ReferenceID:   trx.ID,
+			User:          req.User,
+			CostMethod:    string(opts.Costing),
+			Serials:       serials[id],
+		}
+		_ = repo.store.moveStock(&m)
+		setDetailUnitCost(trx.Details, id, m.UnitCost)
+		if product := repo.store.products[id]; crossedReorderPoint(product.Stock, quantities[id], product.ReorderPoint) {
+			trx.LowStock = append(trx.LowStock, newLowStockEvent(trx, product))
+		}
+	}
+
+	for i := range trx.Details {
+		trx.Details[i].ID = repo.store.nextDetailID
+		trx.Details[i].TransactionID = trx.ID
+		repo.store.nextDetailID++
+	}
+	for i := range trx.Payments {
+		trx.Payments[i].ID = repo.store.nextPaymentID
+		trx.Payments[i].TransactionID = trx.ID
+		repo.store.nextPaymentID++
+	}
+
+	header := *trx
+	header.Details, header.Payments = nil, nil
+	repo.store.transactions = append(repo.store.transactions, header)
+	repo.store.details = append(repo.store.details, trx.Details...)
+	repo.store.payments = append(repo.store.payments, trx.Payments...)
+
+	if err := repo.store.completeIdempotencyKey(opts.IdempotencyScope, opts.IdempotencyKey, trx); err != nil {
+		return nil, err
+	}
+	return trx, nil
+}
+
+func (repo *MemoryTransactionRepository) GetSalesReport(startDate, endDate string) (*models.SalesReport, error) {
+	start, err := time.ParseInLocation(reportTimeLayout, startDate, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date: %w", err)
+	}
+	end, err := time.ParseInLocation(reportTimeLayout, endDate, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date: %w", err)
+	}
+
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	report := &models.SalesReport{}
+	inRange := make(map[int]bool)
+	for _, t := range repo.store.transactions {
+		if t.CreatedAt.Before(start) || t.CreatedAt.After(end) || t.VoidedAt != nil {
+			continue
+		}
+		inRange[t.ID] = true
+		report.GrossRevenue += t.TotalAmount
+		report.TotalDiscount += t.LineDiscount + t.RuleDiscount + t.OrderDiscount + t.PromoDiscount
+		report.TotalTax += t.TaxAmount
+		report.TotalTransaction++
+	}
+
+	sold := make(map[string]int)
+	for _, d := range repo.store.details {
+		if inRange[d.TransactionID] {
+			sold[d.ProductName] += d.Quantity
+		}
+	}
+
+	for _, r := range repo.store.returns {
+		if r.CreatedAt.Before(start) || r.CreatedAt.After(end) {
+			continue
+		}
+		report.TotalReturns += r.TotalAmount
+		for _, item := range r.Items {
+			sold[item.ProductName] -= item.Quantity
+			report.TotalTax += item.TaxAmount
+		}
+	}
+	report.TotalRevenue = report.GrossRevenue + report.TotalReturns
+
+	byMethod := make(map[string]*models.PaymentSummary)
+	counted := make(map[string]map[int]bool)
+	for _, p := range repo.store.payments {
+		if !inRange[p.TransactionID] {
+			continue
+		}
+		summary, ok := byMethod[p.Method]
+		if !ok {
+			summary = &models.PaymentSummary{Method: p.Method}
+			byMethod[p.Method] = summary
+			counted[p.Method] = make(map[int]bool)
+		}
+		summary.Total += p.Amount
+		if !counted[p.Method][p.TransactionID] {
+			counted[p.Method][p.TransactionID] = true
+			summary.TotalTransaction++
+		}
+	}
+	changeTotal := 0
+	for _, t := range repo.store.transactions {
+		if inRange[t.ID] {
+			changeTotal += t.Change
+		}
+	}
+	report.PaymentBreakdown = paymentBreakdown(byMethod, changeTotal)
+
+	report.TopProduct = models.BestSellingProduct{Name: "-", TotalSold: 0}
+	names := make([]string, 0, len(sold))
+	for name := range sold {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if sold[name] > report.TopProduct.TotalSold {
+			report.TopProduct = models.BestSellingProduct{Name: name, TotalSold: sold[name]}
+		}
+	}
+	fillSalesProfit(report, repo.store.profitReport(start, end, startDate, endDate))
+
+	return report, nil
+}
+
+func (repo *MemoryTransactionRepository) GetProfitReport(startDate, endDate string) (*models.ProfitReport, error) {
+	start, err := time.ParseInLocation(reportTimeLayout, startDate, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date: %w", err)
+	}
+	end, err := time.ParseInLocation(reportTimeLayout, endDate, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date: %w", err)
+	}
+
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	return repo.store.profitReport(start, end, startDate, endDate), nil
+}
+
+// profitReport - caller memegang store.mu
+func (s *MemoryStore) profitReport(start, end time.Time, startDate, endDate string) *models.ProfitReport {
+	inRange := make(map[int]bool)
+	for _, t := range s.transactions {
+		if !t.CreatedAt.Before(start) && !t.CreatedAt.After(end) && t.VoidedAt == nil {
+			inRange[t.ID] = true
+		}
+	}
+
+	profit := make(profitBuilder)
+	add := func(d models.TransactionDetail, quantity, netSales int) {
+		name, categoryID, categoryName := d.ProductName, 0, ""
+		if p, ok := s.products[d.ProductID]; ok {
+			name, categoryID = p.Name, p.CategoryID
+			categoryName = s.categories[p.CategoryID].Name
+		}
+		profit.add(d.ProductID, name, categoryID, categoryName, quantity, netSales, d.UnitCost*quantity)
+	}
+
+	details := make(map[int]models.TransactionDetail, len(s.details))
+	for _, d := range s.details {
+		details[d.ID] = d
+		if inRange[d.TransactionID] {
+			add(d, d.Quantity, d.TaxBase)
+		}
+	}
+	for _, r := range s.returns {
+		if r.CreatedAt.Before(start) || r.CreatedAt.After(end) {
+			continue
+		}
+		for _, item := range r.Items {
+			add(details[item.TransactionDetailID], -item.Quantity, item.Amount-item.TaxAmount)
+		}
+	}
+
+	return profit.report(startDate, endDate)
+}
+
+func (repo *MemoryTransactionRepository) GetTaxSummary(startDate, endDate string) (*models.TaxSummaryReport, error) {
+	start, err := time.ParseInLocation(reportTimeLayout, startDate, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date: %w", err)
+	}
+	end, err := time.ParseInLocation(reportTimeLayout, endDate, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date: %w", err)
+	}
+
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	inRange := make(map[int]bool)
+	for _, t := range repo.store.transactions {
+		if !t.CreatedAt.Before(start) && !t.CreatedAt.After(end) && t.VoidedAt == nil {
+			inRange[t.ID] = true
+		}
+	}
+
+	summary := make(taxSummaryBuilder)
+	details := make(map[int]models.TransactionDetail, len(repo.store.details))
+	for _, d := range repo.store.details {
+		details[d.ID] = d
+		if inRange[d.TransactionID] {
+			line := summary.line(d.TaxRateID, d.TaxName, d.TaxRate)
+			line.TaxBase += d.TaxBase
+			line.TaxAmount += d.TaxAmount
+		}
+	}
+
+	for _, r := range repo.store.returns {
+		if r.CreatedAt.Before(start) || r.CreatedAt.After(end) {
+			continue
+		}
+		for _, item := range r.Items {
+			d := details[item.TransactionDetailID]
+			line := summary.line(d.TaxRateID, d.TaxName, d.TaxRate)
+			line.ReturnTaxBase += item.Amount - item.TaxAmount
+			line.ReturnTaxAmount += item.TaxAmount
+		}
+	}
+
+	return summary.report(startDate, endDate), nil
+}
+
+func (repo *MemoryTransactionRepository) ListTransactions(filter models.TransactionFilter) (*models.TransactionList, error) {
+	offset, err := pageOffset(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var start, end time.Time
+	if filter.StartDate != "" {
+		if start, err = time.ParseInLocation(reportTimeLayout, filter.StartDate, time.Local); err != nil {
+			return nil, fmt.Errorf("invalid start date: %w", err)
+		}
+	}
+	if filter.EndDate != "" {
+		if end, err = time.ParseInLocation(reportTimeLayout, filter.EndDate, time.Local); err != nil {
+			return nil, fmt.Errorf("invalid end date: %w", err)
+		}
+	}
+
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	hasProduct := make(map[int]bool)
+	if filter.ProductID != 0 {
+		for _, d := range repo.store.details {
+			if d.ProductID == filter.ProductID {
+				hasProduct[d.TransactionID] = true
+			}
+		}
+	}
+
+	matched := make([]models.Transaction, 0)
+	for _, t := range repo.store.transactions {
+		if filter.StartDate != "" && t.CreatedAt.Before(start) {
+			continue
+		}
+		if filter.EndDate != "" && t.CreatedAt.After(end) {
+			continue
+		}
+		if filter.MinAmount != nil && t.TotalAmount < *filter.MinAmount {
+			continue
+		}
+		if filter.MaxAmount != nil && t.TotalAmount > *filter.MaxAmount {
+			continue
+		}
+		if filter.LocationID != 0 && t.LocationID != filter.LocationID {
+			continue
+		}
+		if filter.ProductID != 0 && !hasProduct[t.ID] {
+			continue
+		}
+		matched = append(matched, t)
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].CreatedAt.After(matched[j].CreatedAt)
+		}
+		return matched[i].ID > matched[j].ID
+	})
+
+	list := &models.TransactionList{Data: []models.Transaction{}, Page: filter.Page, Limit: filter.Limit, Total: len(matched)}
+	if offset < len(matched) {
+		list.Data = matched[offset:min(offset+filter.Limit, len(matched))]
+	}
+
+	return list, nil
+}
+
+func (repo *MemoryTransactionRepository) GetTransactionByID(id int) (*models.Transaction, error) {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	for _, t := range repo.store.transactions {
+		if t.ID != id {
+			continue
+		}
+
+		t.Details = make([]models.TransactionDetail, 0)
+		for _, d := range repo.store.details {
+			if d.TransactionID == id {
+				t.Details = append(t.Details, d)
+			}
+		}
+		for _, p := range repo.store.payments {
+			if p.TransactionID == id {
+				t.Payments = append(t.Payments, p)
+			}
+		}
+		return &t, nil
+	}
+
+	return nil, ErrTransactionNotFound
+}
+
+func (repo *MemoryTransactionRepository) VoidTransaction(id int, reason, voidedBy, user string, window time.Duration) (*models.Transaction, error) {
+	if err := repo.voidTransaction(id, reason, voidedBy, user, window); err != nil {
+		return nil, err
+	}
+	return repo.GetTransactionByID(id)
+}
+
+func (repo *MemoryTransactionRepository) voidTransaction(id int, reason, voidedBy, user string, window time.Duration) error {
+	repo.store.mu.Lock()
+	defer repo.store.mu.Unlock()
+
+	index := -1
+	for i, t := range repo.store.transactions {
+		if t.ID == id {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return ErrTransactionNotFound
+	}
+	if repo.store.transactions[index].VoidedAt != nil {
+		return ErrTransactionVoided
+	}
+	if window >= 0 && time.Since(repo.store.transactions[index].CreatedAt) > window {
+		return ErrVoidWindowExpired
+	}
+	for _, r := range repo.store.returns {
+		if r.TransactionID == id {
+			return ErrTransactionHasReturns
+		}
+	}
+
+	restock, costs, serials := make(map[int]int), make(map[int]int), make(map[int][]string)
+	for _, d := range repo.store.details {
+		if d.TransactionID == id {
+			restock[d.ProductID] += d.Quantity
+			costs[d.ProductID] = d.UnitCost
+			serials[d.ProductID] = append(serials[d.ProductID], d.Serials...)
+		}
+	}
+	productIDs := make([]int, 0, len(restock))
+	for productID := range restock {
+		productIDs = append(productIDs, productID)
+	}
+	sort.Ints(productIDs)
+
+	movements := make([]models.StockMovement, len(productIDs))
+	for i, productID := range productIDs {
+		movements[i] = models.StockMovement{
+			ProductID:     productID,
+			LocationID:    repo.store.transactions[index].LocationID,
+			Delta:         restock[productID],
+			UnitCost:      costs[productID],
+			Serials:       serials[productID],
+			Reason:        models.StockReasonVoid,
+			ReferenceType: models.StockRefTransaction,
+			ReferenceID:   id,
+			User:          user,
+			Note:          reason,
+		}
+		// serial dicek semua dulu supaya void tidak berhenti di tengah
+		if err := repo.store.checkSerials(&movements[i]); err != nil {
+			return err
+		}
+	}
+	for i := range movements {
+		m := &movements[i]
+		m.Batches = takeLots(repo.store.soldLots(id, m.ProductID), m.Delta)
+		if err := repo.store.moveStock(m); err != nil {
+			return err
+		}
+	}
+
+	if promo, ok := repo.store.promotions[repo.store.transactions[index].PromotionID]; ok && promo.UsedCount > 0 {
+		promo.UsedCount--
+		repo.store.promotions[promo.ID] = promo
+	}
+
+	now := time.Now()
+	repo.store.transactions[index].VoidedAt = &now
+	repo.store.transactions[index].VoidReason = reason
+	repo.store.transactions[index].VoidedBy = voidedBy
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"sort"
+
+	"kasir-api/models"
+)
+
+// paymentBreakdown - urutkan ringkasan per metode dan kurangi kembalian dari tunai,
+// supaya total tunai = uang yang benar-benar masuk laci
+func paymentBreakdown(byMethod map[string]*models.PaymentSummary, changeTotal int) []models.PaymentSummary {
+	if cash, ok := byMethod[models.PaymentCash]; ok {
+		cash.Total -= changeTotal
+	}
+
+	breakdown := make([]models.PaymentSummary, 0, len(byMethod))
+	for _, summary := range byMethod {
+		breakdown = append(breakdown, *summary)
+	}
+	sort.Slice(breakdown, func(i, j int) bool { return breakdown[i].Method < breakdown[j].Method })
+
+	return breakdown
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"kasir-api/database"
+	"kasir-api/models"
+)
+
+// openTestDB - Postgres sungguhan untuk test yang menguji SQL dan locking.
+// Di-skip kalau TEST_DB_CONN kosong. Tiap test dapat schema baru yang sudah
+// dimigrasi dan dihapus lagi di akhir test.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	conn := os.Getenv("TEST_DB_CONN")
+	if conn == "" {
+		t.Skip("TEST_DB_CONN kosong, test Postgres dilewati")
+	}
+
+	admin, err := database.InitDB(conn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			t.Errorf("drop schema: %v", err)
+		}
+	})
+
+	db, err := database.InitDB(withSearchPath(t, conn, schema))
+	if err != nil {
+		t.Fatalf("connect %s: %v", schema, err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := database.MigrateUp(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+// withSearchPath - connection string yang sama, tabel dibuat di schema
+func withSearchPath(t *testing.T, conn, schema string) string {
+	t.Helper()
+
+	if !strings.HasPrefix(conn, "postgres://") && !strings.HasPrefix(conn, "postgresql://") {
+		return conn + " search_path=" + schema
+	}
+	u, err := url.Parse(conn)
+	if err != nil {
+		t.Fatalf("TEST_DB_CONN: %v", err)
+	}
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// createTestProduct - category baru + produk dengan stok awal di lokasi bawaan
+func createTestProduct(t *testing.T, db *sql.DB, product models.Product) models.Product {
+	t.Helper()
+
+	category := models.Category{Name: "Test " + product.Name}
+	if err := NewCategoryRepository(db).Create(&category); err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+	product.CategoryID = category.ID
+	if err := NewProductRepository(db).Create(&product); err != nil {
+		t.Fatalf("create product %s: %v", product.Name, err)
+	}
+	return product
+}
+
+func testProductStock(t *testing.T, db *sql.DB, id int) int {
+	t.Helper()
+
+	var stock int
+	if err := db.QueryRow("SELECT stock FROM products WHERE id = $1", id).Scan(&stock); err != nil {
+		t.Fatalf("stock of product %d: %v", id, err)
+	}
+	return stock
+}
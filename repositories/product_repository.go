@@ -2,31 +2,121 @@ package repositories
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"kasir-api/models"
+
+	"github.com/lib/pq"
 )
 
-type ProductRepository struct {
+var ErrProductNotFound = errors.New("produk tidak ditemukan")
+
+type PostgresProductRepository struct {
 	db *sql.DB
 }
 
-func NewProductRepository(db *sql.DB) *ProductRepository {
-	return &ProductRepository{db: db}
+func NewProductRepository(db *sql.DB) *PostgresProductRepository {
+	return &PostgresProductRepository{db: db}
 }
 
-func (repo *ProductRepository) GetAll(name string) ([]models.Product, error) {
-	query := `
-		SELECT id, name, price, stock, category_id
-		FROM products
-	`
+// GetAll - katalog produk, varian dikelompokkan di bawah induknya. Filter nama
+// juga cocok kalau salah satu varian (nama / SKU) cocok.
+func (repo *PostgresProductRepository) GetAll(name string) ([]models.Product, error) {
+	query := "SELECT " + productColumns + " FROM products p WHERE p.parent_id IS NULL"
 
 	var args []interface{}
 	if name != "" {
-		query += " WHERE name ILIKE $1"
+		query += ` AND (p.name ILIKE $1 OR EXISTS (
+			SELECT 1 FROM products v WHERE v.parent_id = p.id AND (v.name ILIKE $1 OR v.sku ILIKE $1)))`
 		args = append(args, "%"+name+"%")
 	}
+	query += " ORDER BY p.id"
+
+	products, err := queryProducts(repo.db, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+	variants, err := queryProducts(repo.db, "SELECT "+productColumns+" FROM products p WHERE p.parent_id = ANY($1) ORDER BY p.id", pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range variants {
+		ids = append(ids, v.ID)
+	}
+
+	locations, err := loadProductLocations(repo.db, ids)
+	if err != nil {
+		return nil, err
+	}
+	barcodes, err := loadProductBarcodes(repo.db, ids)
+	if err != nil {
+		return nil, err
+	}
+	byParent := make(map[int][]models.Product)
+	for _, v := range variants {
+		v.Locations = locations[v.ID]
+		v.InTransit = sumInTransit(v.Locations)
+		v.Barcodes = barcodes[v.ID]
+		byParent[v.ParentID] = append(byParent[v.ParentID], v)
+	}
+	for i := range products {
+		products[i].Locations = locations[products[i].ID]
+		products[i].InTransit = sumInTransit(products[i].Locations)
+		products[i].Barcodes = barcodes[products[i].ID]
+		products[i].Variants = byParent[products[i].ID]
+	}
+
+	return products, nil
+}
+
+// GetVariants - varian satu produk induk, urut id
+func (repo *PostgresProductRepository) GetVariants(parentID int) ([]models.Product, error) {
+	var exists bool
+	if err := repo.db.QueryRow("SELECT EXISTS (SELECT 1 FROM products WHERE id = $1)", parentID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrProductNotFound
+	}
+
+	variants, err := queryProducts(repo.db, "SELECT "+productColumns+" FROM products p WHERE p.parent_id = $1 ORDER BY p.id", parentID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, len(variants))
+	for i, v := range variants {
+		ids[i] = v.ID
+	}
+	locations, err := loadProductLocations(repo.db, ids)
+	if err != nil {
+		return nil, err
+	}
+	barcodes, err := loadProductBarcodes(repo.db, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range variants {
+		variants[i].Locations = locations[variants[i].ID]
+		variants[i].InTransit = sumInTransit(variants[i].Locations)
+		variants[i].Barcodes = barcodes[variants[i].ID]
+	}
+
+	return variants, nil
+}
 
-	rows, err := repo.db.Query(query, args...)
+const productColumns = `p.id, p.name, p.price, p.cost_price, p.stock, p.category_id, COALESCE(p.tax_rate_id, 0),
+	COALESCE(p.supplier_id, 0), p.reorder_point, p.reorder_quantity, p.track_serial,
+	COALESCE(p.parent_id, 0), COALESCE(p.sku, ''), p.attributes, p.price_override`
+
+func queryProducts(q queryer, query string, args ...any) ([]models.Product, error) {
+	rows, err := q.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -35,94 +125,361 @@ func (repo *ProductRepository) GetAll(name string) ([]models.Product, error) {
 	products := []models.Product{}
 	for rows.Next() {
 		var p models.Product
+		var attributes []byte
 		if err := rows.Scan(
 			&p.ID,
 			&p.Name,
 			&p.Price,
+			&p.CostPrice,
 			&p.Stock,
 			&p.CategoryID,
+			&p.TaxRateID,
+			&p.SupplierID,
+			&p.ReorderPoint,
+			&p.ReorderQuantity,
+			&p.TrackSerial,
+			&p.ParentID,
+			&p.SKU,
+			&attributes,
+			&p.PriceOverride,
 		); err != nil {
 			return nil, err
 		}
+		if p.Attributes, err = decodeAttributes(attributes); err != nil {
+			return nil, err
+		}
 		products = append(products, p)
 	}
 
-	return products, nil
+	return products, rows.Err()
 }
 
-func (repo *ProductRepository) Create(product *models.Product) error {
+// Create - produk disimpan dengan stok 0, stok awal masuk lewat ledger
+// (varian: ParentID diisi, category / pajak / supplier / harga diambil dari induk)
+func (repo *PostgresProductRepository) Create(product *models.Product) error {
+	if err := normalizeProductFields(product, product.ParentID != 0); err != nil {
+		return err
+	}
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if product.ParentID != 0 {
+		parent, err := lockVariantParent(tx, product.ParentID)
+		if err == ErrProductNotFound {
+			return fmt.Errorf("%w: produk induk id %d tidak ditemukan", ErrInvalidVariant, product.ParentID)
+		}
+		if err != nil {
+			return err
+		}
+		if err := checkVariantParent(parent); err != nil {
+			return err
+		}
+		inheritFromParent(product, parent)
+	}
+
 	query := `
-		INSERT INTO products (name, price, stock, category_id)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO products (name, price, cost_price, stock, category_id, tax_rate_id, supplier_id, reorder_point, reorder_quantity, track_serial,
+			parent_id, sku, attributes, price_override)
+		VALUES ($1, $2, $3, 0, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		RETURNING id
 	`
 
-	err := repo.db.QueryRow(
+	err = tx.QueryRow(
 		query,
 		product.Name,
 		product.Price,
-		product.Stock,
+		product.CostPrice,
 		product.CategoryID,
+		nullableID(product.TaxRateID),
+		nullableID(product.SupplierID),
+		product.ReorderPoint,
+		product.ReorderQuantity,
+		product.TrackSerial,
+		nullableID(product.ParentID),
+		nullableSKU(product.SKU),
+		encodeAttributes(product.Attributes),
+		product.PriceOverride,
 	).Scan(&product.ID)
+	if isUniqueViolation(err) {
+		return ErrDuplicateSKU
+	}
+	if err != nil {
+		return err
+	}
+	if err := saveProductBarcodes(tx, product.ID, product.Barcodes); err != nil {
+		return err
+	}
 
-	return err
+	if product.Stock != 0 {
+		err := moveStock(tx, &models.StockMovement{
+			ProductID:     product.ID,
+			LocationID:    product.LocationID,
+			Delta:         product.Stock,
+			Reason:        models.StockReasonAdjustment,
+			ReferenceType: models.StockRefProduct,
+			ReferenceID:   product.ID,
+			User:          product.User,
+			Note:          "stok awal",
+			CostMethod:    product.CostMethod,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
 // GetByID - ambil produk by ID
-func (repo *ProductRepository) GetByID(id int) (*models.ProductResponse, error) {
+func (repo *PostgresProductRepository) GetByID(id int) (*models.ProductResponse, error) {
 	query := `
 		SELECT
 			p.id,
 			p.name,
 			p.price,
+			p.cost_price,
 			p.stock,
 			p.category_id,
-			c.name AS category_name
+			c.name AS category_name,
+			COALESCE(p.tax_rate_id, 0),
+			COALESCE(p.supplier_id, 0),
+			p.reorder_point,
+			p.reorder_quantity,
+			p.track_serial,
+			COALESCE(p.parent_id, 0),
+			COALESCE(p.sku, ''),
+			p.attributes,
+			p.price_override
 		FROM products p
 		JOIN categories c ON c.id = p.category_id
 		WHERE p.id = $1
 	`
 
 	var p models.ProductResponse
+	var attributes []byte
 	err := repo.db.QueryRow(query, id).Scan(
 		&p.ID,
 		&p.Name,
 		&p.Price,
+		&p.CostPrice,
 		&p.Stock,
 		&p.CategoryID,
 		&p.CategoryName,
+		&p.TaxRateID,
+		&p.SupplierID,
+		&p.ReorderPoint,
+		&p.ReorderQuantity,
+		&p.TrackSerial,
+		&p.ParentID,
+		&p.SKU,
+		&attributes,
+		&p.PriceOverride,
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, errors.New("produk tidak ditemukan")
+		return nil, ErrProductNotFound
 	}
 	if err != nil {
 		return nil, err
 	}
+	if p.Attributes, err = decodeAttributes(attributes); err != nil {
+		return nil, err
+	}
+	if p.ParentID == 0 {
+		if p.Variants, err = repo.GetVariants(id); err != nil {
+			return nil, err
+		}
+	}
+
+	locations, err := loadProductLocations(repo.db, []int{id})
+	if err != nil {
+		return nil, err
+	}
+	p.Locations = append([]models.LocationStock{}, locations[id]...)
+	p.InTransit = sumInTransit(p.Locations)
+
+	barcodes, err := loadProductBarcodes(repo.db, []int{id})
+	if err != nil {
+		return nil, err
+	}
+	p.Barcodes = barcodes[id]
 
 	return &p, nil
 }
 
-func (repo *ProductRepository) Update(product *models.Product) error {
-	query := "UPDATE products SET name = $1, price = $2, stock = $3, WHERE id = $4"
-	result, err := repo.db.Exec(query, product.Name, product.Price, product.Stock, product.ID)
+// Update - perubahan stok (total) dicatat sebagai adjustment sebesar selisihnya
+// di product.LocationID. Harga induk ikut ke varian yang tidak override harga.
+func (repo *PostgresProductRepository) Update(update *models.ProductUpdate) error {
+	product := &update.Product
+	tx, err := repo.db.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	rows, err := result.RowsAffected()
+	// parent_id tidak pernah berubah, jadi aman dibaca sebelum lock. Induk
+	// dikunci lebih dulu, urutannya sama dengan update induk -> varian.
+	var parentID int
+	err = tx.QueryRow("SELECT COALESCE(parent_id, 0) FROM products WHERE id = $1", product.ID).Scan(&parentID)
+	if err == sql.ErrNoRows {
+		return ErrProductNotFound
+	}
 	if err != nil {
 		return err
 	}
+	product.ParentID = parentID
+	var parent models.Product
+	if parentID != 0 {
+		parent, err = lockVariantParent(tx, parentID)
+		if err != nil {
+			return err
+		}
+	}
 
-	if rows == 0 {
-		return errors.New("produk tidak ditemukan")
+	// cost_price dikelola ledger (penerimaan barang / layer HPP), bukan dari body update
+	var stored models.Product
+	var hasVariants bool
+	err = tx.QueryRow(`
+		SELECT stock, cost_price, COALESCE(supplier_id, 0), reorder_point, reorder_quantity, track_serial,
+			EXISTS (SELECT 1 FROM products v WHERE v.parent_id = p.id)
+		FROM products p WHERE id = $1 FOR UPDATE`,
+		product.ID,
+	).Scan(&stored.Stock, &stored.CostPrice, &stored.SupplierID, &stored.ReorderPoint, &stored.ReorderQuantity,
+		&stored.TrackSerial, &hasVariants)
+	if err == sql.ErrNoRows {
+		return ErrProductNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if update.TrackSerial != nil && *update.TrackSerial != stored.TrackSerial {
+		if stored.InTransit, err = productInTransit(tx, product.ID); err != nil {
+			return err
+		}
+	}
+	barcodes, err := loadProductBarcodes(tx, []int{product.ID})
+	if err != nil {
+		return err
+	}
+	stored.Barcodes = barcodes[product.ID]
+	if err := normalizeProductFields(product, parentID != 0); err != nil {
+		return err
+	}
+	if err := mergeProductUpdate(update, stored); err != nil {
+		return err
+	}
+	if parentID != 0 {
+		inheritFromParent(product, parent)
 	}
 
+	stock := stored.Stock
+	if hasVariants && product.Stock != stock {
+		return fmt.Errorf("%w: stok %s dikelola per varian", ErrInvalidVariant, product.Name)
+	}
+
+	query := `
+		UPDATE products
+		SET name = $1, price = $2, tax_rate_id = $3, supplier_id = $4, reorder_point = $5, reorder_quantity = $6,
+			track_serial = $7, sku = $8, attributes = $9, price_override = $10
+		WHERE id = $11`
+	_, err = tx.Exec(query, product.Name, product.Price, nullableID(product.TaxRateID),
+		nullableID(product.SupplierID), product.ReorderPoint, product.ReorderQuantity, product.TrackSerial,
+		nullableSKU(product.SKU), encodeAttributes(product.Attributes), product.PriceOverride, product.ID)
+	if isUniqueViolation(err) {
+		return ErrDuplicateSKU
+	}
+	if err != nil {
+		return err
+	}
+	if update.Barcodes != nil {
+		if err := saveProductBarcodes(tx, product.ID, product.Barcodes); err != nil {
+			return err
+		}
+	}
+
+	if hasVariants {
+		_, err = tx.Exec("UPDATE products SET price = $1 WHERE parent_id = $2 AND NOT price_override", product.Price, product.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if delta := product.Stock - stock; delta != 0 {
+		err := moveStock(tx, &models.StockMovement{
+			ProductID:     product.ID,
+			LocationID:    product.LocationID,
+			Delta:         delta,
+			Reason:        models.StockReasonAdjustment,
+			ReferenceType: models.StockRefProduct,
+			ReferenceID:   product.ID,
+			User:          product.User,
+			Note:          "update produk",
+			CostMethod:    product.CostMethod,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// mergeProductUpdate - field yang tidak dikirim di body update diisi nilai
+// tersimpan, cost_price selalu dari yang tersimpan. Dipanggil setelah
+// normalizeProductFields supaya flag assigned barcode tidak hilang. track_serial ditolak
+// berubah selama masih ada stok, unit lama tidak punya (atau masih punya) serial.
+// Perubahan stok wajib menyebut location_id dan ditolak untuk produk serial.
+func mergeProductUpdate(update *models.ProductUpdate, stored models.Product) error {
+	p := &update.Product
+	p.TrackSerial = stored.TrackSerial
+	if update.TrackSerial != nil && *update.TrackSerial != stored.TrackSerial {
+		if stored.Stock != 0 || stored.InTransit != 0 {
+			return fmt.Errorf("%w: stok %d, dalam perjalanan %d", ErrTrackSerialInUse, stored.Stock, stored.InTransit)
+		}
+		p.TrackSerial = *update.TrackSerial
+	}
+	p.CostPrice = stored.CostPrice
+	p.Stock = stored.Stock
+	if update.Stock != nil {
+		p.Stock = *update.Stock
+	}
+	// unit produk serial harus tercatat satu per satu, update produk tidak membawa serial
+	if p.Stock != stored.Stock && p.TrackSerial {
+		return fmt.Errorf("%w: ubah stok lewat POST /api/inventory/adjustments", ErrSerialRequired)
+	}
+	// stok bisa tersebar di beberapa lokasi, selisihnya tidak boleh ditebak
+	// masuk ke lokasi bawaan
+	if p.Stock != stored.Stock && p.LocationID == 0 {
+		return ErrStockLocationRequired
+	}
+	p.SupplierID = stored.SupplierID
+	if update.SupplierID != nil {
+		p.SupplierID = *update.SupplierID
+	}
+	p.ReorderPoint = stored.ReorderPoint
+	if update.ReorderPoint != nil {
+		p.ReorderPoint = *update.ReorderPoint
+	}
+	p.ReorderQuantity = stored.ReorderQuantity
+	if update.ReorderQuantity != nil {
+		p.ReorderQuantity = *update.ReorderQuantity
+	}
+	p.Barcodes = stored.Barcodes
+	if update.Barcodes != nil {
+		barcodes, err := NormalizeBarcodes(*update.Barcodes)
+		if err != nil {
+			return err
+		}
+		p.Barcodes = mergeBarcodes(stored.Barcodes, barcodes)
+	}
 	return nil
 }
 
-func (repo *ProductRepository) Delete(id int) error {
+func (repo *PostgresProductRepository) Delete(id int) error {
 	query := "DELETE FROM products WHERE id = $1"
 	result, err := repo.db.Exec(query, id)
 	if err != nil {
@@ -134,8 +491,48 @@ func (repo *ProductRepository) Delete(id int) error {
 	}
 
 	if rows == 0 {
-		return errors.New("produk tidak ditemukan")
+		return ErrProductNotFound
 	}
 
 	return err
 }
+
+// lockVariantParent - kunci baris produk induk di dalam transaksi
+func lockVariantParent(tx *sql.Tx, id int) (models.Product, error) {
+	p := models.Product{ID: id}
+	err := tx.QueryRow(`
+		SELECT name, price, stock, category_id, COALESCE(tax_rate_id, 0), COALESCE(supplier_id, 0), COALESCE(parent_id, 0)
+		FROM products WHERE id = $1 FOR UPDATE`, id,
+	).Scan(&p.Name, &p.Price, &p.Stock, &p.CategoryID, &p.TaxRateID, &p.SupplierID, &p.ParentID)
+	if err == sql.ErrNoRows {
+		return p, ErrProductNotFound
+	}
+	return p, err
+}
+
+// nullableSKU - SKU kosong disimpan NULL supaya tidak bentrok di unique index
+func nullableSKU(sku string) *string {
+	if sku == "" {
+		return nil
+	}
+	return &sku
+}
+
+func encodeAttributes(attributes map[string]string) string {
+	if len(attributes) == 0 {
+		return "{}"
+	}
+	data, _ := json.Marshal(attributes)
+	return string(data)
+}
+
+func decodeAttributes(data []byte) (map[string]string, error) {
+	var attributes map[string]string
+	if err := json.Unmarshal(data, &attributes); err != nil {
+		return nil, err
+	}
+	if len(attributes) == 0 {
+		return nil, nil
+	}
+	return attributes, nil
+}
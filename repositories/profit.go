@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"math"
+	"sort"
+
+	"kasir-api/models"
+)
+
+// noCategoryName - label produk yang category-nya sudah tidak ada
+const noCategoryName = "Tanpa kategori"
+
+type profitEntry struct {
+	line       models.ProfitLine
+	categoryID int
+	category   string
+}
+
+// profitBuilder - kumpulkan DPP dan HPP per produk. Penjualan dihitung per
+// tanggal transaksi (tanpa void), retur per tanggal retur dengan HPP dari
+// snapshot unit_cost baris asal.
+type profitBuilder map[int]*profitEntry
+
+func (b profitBuilder) add(productID int, productName string, categoryID int, categoryName string, quantity, netSales, cost int) {
+	entry, ok := b[productID]
+	if !ok {
+		if categoryName == "" {
+			categoryID, categoryName = 0, noCategoryName
+		}
+		entry = &profitEntry{line: models.ProfitLine{ID: productID, Name: productName}, categoryID: categoryID, category: categoryName}
+		b[productID] = entry
+	}
+	entry.line.Quantity += quantity
+	entry.line.NetSales += netSales
+	entry.line.Cost += cost
+}
+
+func (b profitBuilder) report(startDate, endDate string) *models.ProfitReport {
+	report := &models.ProfitReport{
+		StartDate:  startDate,
+		EndDate:    endDate,
+		Products:   make([]models.ProfitLine, 0, len(b)),
+		Categories: make([]models.ProfitLine, 0),
+	}
+
+	categories := make(map[int]*models.ProfitLine)
+	for _, entry := range b {
+		line := entry.line
+		finishProfitLine(&line)
+		report.Products = append(report.Products, line)
+		report.NetSales += line.NetSales
+		report.TotalCost += line.Cost
+
+		category, ok := categories[entry.categoryID]
+		if !ok {
+			category = &models.ProfitLine{ID: entry.categoryID, Name: entry.category}
+			categories[entry.categoryID] = category
+		}
+		category.Quantity += line.Quantity
+		category.NetSales += line.NetSales
+		category.Cost += line.Cost
+	}
+	for _, category := range categories {
+		finishProfitLine(category)
+		report.Categories = append(report.Categories, *category)
+	}
+
+	report.GrossProfit = report.NetSales - report.TotalCost
+	report.Margin = profitMargin(report.GrossProfit, report.NetSales)
+	sortProfitLines(report.Products)
+	sortProfitLines(report.Categories)
+
+	return report
+}
+
+func finishProfitLine(line *models.ProfitLine) {
+	line.GrossProfit = line.NetSales - line.Cost
+	line.Margin = profitMargin(line.GrossProfit, line.NetSales)
+}
+
+// profitMargin - laba kotor / penjualan bersih dalam persen, 2 desimal
+func profitMargin(profit, netSales int) float64 {
+	if netSales == 0 {
+		return 0
+	}
+	return math.Round(float64(profit)/float64(netSales)*10000) / 100
+}
+
+// sortProfitLines - laba kotor terbesar dulu
+func sortProfitLines(lines []models.ProfitLine) {
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].GrossProfit != lines[j].GrossProfit {
+			return lines[i].GrossProfit > lines[j].GrossProfit
+		}
+		return lines[i].ID < lines[j].ID
+	})
+}
+
+// fillSalesProfit - ringkasan laba kotor untuk laporan penjualan
+func fillSalesProfit(report *models.SalesReport, profit *models.ProfitReport) {
+	report.NetSales = profit.NetSales
+	report.TotalCost = profit.TotalCost
+	report.GrossProfit = profit.GrossProfit
+	report.Margin = profit.Margin
+}
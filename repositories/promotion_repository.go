@@ -0,0 +1,140 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"kasir-api/models"
+)
+
+var (
+	ErrPromotionNotFound  = errors.New("promo tidak ditemukan")
+	ErrPromotionExhausted = errors.New("kuota pemakaian promo sudah habis")
+
+	errDuplicatePromotionCode = errors.New("kode promo sudah dipakai")
+)
+
+type PostgresPromotionRepository struct {
+	db *sql.DB
+}
+
+func NewPromotionRepository(db *sql.DB) *PostgresPromotionRepository {
+	return &PostgresPromotionRepository{db: db}
+}
+
+const promotionColumns = "id, code, description, type, value, min_spend, usage_limit, used_count, starts_at, ends_at, active"
+
+func scanPromotion(row interface{ Scan(...any) error }) (*models.Promotion, error) {
+	var p models.Promotion
+	err := row.Scan(
+		&p.ID,
+		&p.Code,
+		&p.Description,
+		&p.Type,
+		&p.Value,
+		&p.MinSpend,
+		&p.UsageLimit,
+		&p.UsedCount,
+		&p.StartsAt,
+		&p.EndsAt,
+		&p.Active,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrPromotionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (repo *PostgresPromotionRepository) GetAll() ([]models.Promotion, error) {
+	rows, err := repo.db.Query("SELECT " + promotionColumns + " FROM promotions ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	promotions := make([]models.Promotion, 0)
+	for rows.Next() {
+		p, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, *p)
+	}
+
+	return promotions, rows.Err()
+}
+
+func (repo *PostgresPromotionRepository) Create(promotion *models.Promotion) error {
+	query := `
+		INSERT INTO promotions (code, description, type, value, min_spend, usage_limit, starts_at, ends_at, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, used_count
+	`
+	return repo.db.QueryRow(
+		query,
+		promotion.Code,
+		promotion.Description,
+		promotion.Type,
+		promotion.Value,
+		promotion.MinSpend,
+		promotion.UsageLimit,
+		promotion.StartsAt,
+		promotion.EndsAt,
+		promotion.Active,
+	).Scan(&promotion.ID, &promotion.UsedCount)
+}
+
+func (repo *PostgresPromotionRepository) GetByID(id int) (*models.Promotion, error) {
+	return scanPromotion(repo.db.QueryRow("SELECT "+promotionColumns+" FROM promotions WHERE id = $1", id))
+}
+
+func (repo *PostgresPromotionRepository) GetByCode(code string) (*models.Promotion, error) {
+	return scanPromotion(repo.db.QueryRow("SELECT "+promotionColumns+" FROM promotions WHERE code = $1", code))
+}
+
+// Update - used_count tidak ikut di-update, hanya berubah lewat checkout / void
+func (repo *PostgresPromotionRepository) Update(promotion *models.Promotion) error {
+	query := `
+		UPDATE promotions
+		SET code = $1, description = $2, type = $3, value = $4, min_spend = $5,
+			usage_limit = $6, starts_at = $7, ends_at = $8, active = $9
+		WHERE id = $10
+		RETURNING used_count
+	`
+	err := repo.db.QueryRow(
+		query,
+		promotion.Code,
+		promotion.Description,
+		promotion.Type,
+		promotion.Value,
+		promotion.MinSpend,
+		promotion.UsageLimit,
+		promotion.StartsAt,
+		promotion.EndsAt,
+		promotion.Active,
+		promotion.ID,
+	).Scan(&promotion.UsedCount)
+	if err == sql.ErrNoRows {
+		return ErrPromotionNotFound
+	}
+	return err
+}
+
+func (repo *PostgresPromotionRepository) Delete(id int) error {
+	result, err := repo.db.Exec("DELETE FROM promotions WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrPromotionNotFound
+	}
+
+	return nil
+}
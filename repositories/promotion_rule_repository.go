@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"kasir-api/models"
+
+	"github.com/lib/pq"
+)
+
+var ErrPromotionRuleNotFound = errors.New("aturan promo tidak ditemukan")
+
+type PostgresPromotionRuleRepository struct {
+	db *sql.DB
+}
+
+func NewPromotionRuleRepository(db *sql.DB) *PostgresPromotionRuleRepository {
+	return &PostgresPromotionRuleRepository{db: db}
+}
+
+const promotionRuleColumns = `id, name, type, product_ids, COALESCE(category_id, 0), buy_quantity, get_quantity,
+	percent, bundle_items, bundle_price, start_time, end_time, starts_at, ends_at, active`
+
+func scanPromotionRule(row interface{ Scan(...any) error }) (*models.PromotionRule, error) {
+	var r models.PromotionRule
+	var productIDs pq.Int64Array
+	var bundleItems []byte
+	err := row.Scan(
+		&r.ID,
+		&r.Name,
+		&r.Type,
+		&productIDs,
+		&r.CategoryID,
+		&r.BuyQuantity,
+		&r.GetQuantity,
+		&r.Percent,
+		&bundleItems,
+		&r.BundlePrice,
+		&r.StartTime,
+		&r.EndTime,
+		&r.StartsAt,
+		&r.EndsAt,
+		&r.Active,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrPromotionRuleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range productIDs {
+		r.ProductIDs = append(r.ProductIDs, int(id))
+	}
+	if err := json.Unmarshal(bundleItems, &r.BundleItems); err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// promotionRuleArgs - kolom yang bisa diubah, urutan sama dengan query insert/update
+func promotionRuleArgs(rule *models.PromotionRule) ([]any, error) {
+	bundleItems, err := json.Marshal(rule.BundleItems)
+	if err != nil {
+		return nil, err
+	}
+	if rule.BundleItems == nil {
+		bundleItems = []byte("[]")
+	}
+
+	productIDs := rule.ProductIDs
+	if productIDs == nil {
+		productIDs = []int{}
+	}
+
+	var categoryID *int
+	if rule.CategoryID != 0 {
+		categoryID = &rule.CategoryID
+	}
+
+	return []any{
+		rule.Name, rule.Type, pq.Array(productIDs), categoryID, rule.BuyQuantity, rule.GetQuantity,
+		rule.Percent, bundleItems, rule.BundlePrice, rule.StartTime, rule.EndTime,
+		rule.StartsAt, rule.EndsAt, rule.Active,
+	}, nil
+}
+
+func (repo *PostgresPromotionRuleRepository) GetAll() ([]models.PromotionRule, error) {
+	rows, err := repo.db.Query("SELECT " + promotionRuleColumns + " FROM promotion_rules ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := make([]models.PromotionRule, 0)
+	for rows.Next() {
+		r, err := scanPromotionRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *r)
+	}
+
+	return rules, rows.Err()
+}
+
+func (repo *PostgresPromotionRuleRepository) Create(rule *models.PromotionRule) error {
+	args, err := promotionRuleArgs(rule)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO promotion_rules (name, type, product_ids, category_id, buy_quantity, get_quantity,
+			percent, bundle_items, bundle_price, start_time, end_time, starts_at, ends_at, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id
+	`
+	return repo.db.QueryRow(query, args...).Scan(&rule.ID)
+}
+
+func (repo *PostgresPromotionRuleRepository) GetByID(id int) (*models.PromotionRule, error) {
+	return scanPromotionRule(repo.db.QueryRow("SELECT "+promotionRuleColumns+" FROM promotion_rules WHERE id = $1", id))
+}
+
+func (repo *PostgresPromotionRuleRepository) Update(rule *models.PromotionRule) error {
+	args, err := promotionRuleArgs(rule)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE promotion_rules
+		SET name = $1, type = $2, product_ids = $3, category_id = $4, buy_quantity = $5, get_quantity = $6,
+			percent = $7, bundle_items = $8, bundle_price = $9, start_time = $10, end_time = $11,
+			starts_at = $12, ends_at = $13, active = $14
+		WHERE id = $15
+	`
+	result, err := repo.db.Exec(query, append(args, rule.ID)...)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrPromotionRuleNotFound
+	}
+
+	return nil
+}
+
+func (repo *PostgresPromotionRuleRepository) Delete(id int) error {
+	result, err := repo.db.Exec("DELETE FROM promotion_rules WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrPromotionRuleNotFound
+	}
+
+	return nil
+}
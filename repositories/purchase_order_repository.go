@@ -0,0 +1,387 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"kasir-api/models"
+
+	"github.com/lib/pq"
+)
+
+type PostgresPurchaseOrderRepository struct {
+	db *sql.DB
+}
+
+func NewPurchaseOrderRepository(db *sql.DB) *PostgresPurchaseOrderRepository {
+	return &PostgresPurchaseOrderRepository{db: db}
+}
+
+func (repo *PostgresPurchaseOrderRepository) GetAll(filter models.PurchaseOrderFilter) ([]models.PurchaseOrder, error) {
+	rows, err := repo.db.Query(`
+		SELECT po.id, po.supplier_id, s.name, po.location_id, po.status, po.note, po.created_by, po.created_at, po.sent_at, po.received_at,
+			COALESCE((SELECT SUM(l.quantity * l.unit_cost) FROM purchase_order_lines l WHERE l.purchase_order_id = po.id), 0)
+		FROM purchase_orders po
+		JOIN suppliers s ON s.id = po.supplier_id
+		WHERE ($1 = '' OR po.status = $1) AND ($2 = 0 OR po.supplier_id = $2)
+		ORDER BY po.id DESC`, filter.Status, filter.SupplierID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := make([]models.PurchaseOrder, 0)
+	for rows.Next() {
+		var po models.PurchaseOrder
+		if err := rows.Scan(
+			&po.ID, &po.SupplierID, &po.SupplierName, &po.LocationID, &po.Status, &po.Note, &po.CreatedBy, &po.CreatedAt, &po.SentAt, &po.ReceivedAt,
+			&po.TotalCost,
+		); err != nil {
+			return nil, err
+		}
+		orders = append(orders, po)
+	}
+
+	return orders, rows.Err()
+}
+
+func (repo *PostgresPurchaseOrderRepository) Create(po *models.PurchaseOrder) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if po.SupplierName, err = supplierName(tx, po.SupplierID); err != nil {
+		return err
+	}
+	if err := checkLocationExists(tx, po.LocationID); err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(
+		"INSERT INTO purchase_orders (supplier_id, location_id, note, created_by) VALUES ($1, $2, $3, $4) RETURNING id, status, created_at",
+		po.SupplierID, po.LocationID, po.Note, po.CreatedBy,
+	).Scan(&po.ID, &po.Status, &po.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := insertPurchaseOrderLines(tx, po); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func supplierName(tx *sql.Tx, supplierID int) (string, error) {
+	var name string
+	err := tx.QueryRow("SELECT name FROM suppliers WHERE id = $1", supplierID).Scan(&name)
+	if err == sql.ErrNoRows {
+		return "", ErrSupplierNotFound
+	}
+	return name, err
+}
+
+func insertPurchaseOrderLines(tx *sql.Tx, po *models.PurchaseOrder) error {
+	for i := range po.Lines {
+		line := &po.Lines[i]
+		line.PurchaseOrderID = po.ID
+
+		err := tx.QueryRow("SELECT name FROM products WHERE id = $1", line.ProductID).Scan(&line.ProductName)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w (product id %d)", ErrProductNotFound, line.ProductID)
+		}
+		if err != nil {
+			return err
+		}
+
+		err = tx.QueryRow(`
+			INSERT INTO purchase_order_lines (purchase_order_id, product_id, product_name, quantity, unit_cost)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id`,
+			po.ID, line.ProductID, line.ProductName, line.Quantity, line.UnitCost,
+		).Scan(&line.ID)
+		if err != nil {
+			return err
+		}
+	}
+	fillPurchaseOrderTotals(po)
+
+	return nil
+}
+
+func (repo *PostgresPurchaseOrderRepository) GetByID(id int) (*models.PurchaseOrder, error) {
+	return loadPurchaseOrder(repo.db, id, false)
+}
+
+// loadPurchaseOrder - header + baris PO. forUpdate mengunci header.
+func loadPurchaseOrder(q queryer, id int, forUpdate bool) (*models.PurchaseOrder, error) {
+	query := `
+		SELECT po.id, po.supplier_id, s.name, po.location_id, po.status, po.note, po.created_by, po.created_at, po.sent_at, po.received_at
+		FROM purchase_orders po
+		JOIN suppliers s ON s.id = po.supplier_id
+		WHERE po.id = $1`
+	if forUpdate {
+		query += " FOR UPDATE OF po"
+	}
+
+	var po models.PurchaseOrder
+	err := q.QueryRow(query, id).Scan(&po.ID, &po.SupplierID, &po.SupplierName, &po.LocationID, &po.Status, &po.Note, &po.CreatedBy, &po.CreatedAt, &po.SentAt, &po.ReceivedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrPurchaseOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.Query(`
+		SELECT id, purchase_order_id, product_id, product_name, quantity, received_quantity, unit_cost
+		FROM purchase_order_lines
+		WHERE purchase_order_id = $1
+		ORDER BY id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line models.PurchaseOrderLine
+		if err := rows.Scan(&line.ID, &line.PurchaseOrderID, &line.ProductID, &line.ProductName, &line.Quantity, &line.ReceivedQuantity, &line.UnitCost); err != nil {
+			return nil, err
+		}
+		po.Lines = append(po.Lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	fillPurchaseOrderTotals(&po)
+
+	return &po, nil
+}
+
+// Update - ganti supplier, catatan dan semua baris PO. Hanya selama draft.
+func (repo *PostgresPurchaseOrderRepository) Update(po *models.PurchaseOrder) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	current, err := loadPurchaseOrder(tx, po.ID, true)
+	if err != nil {
+		return err
+	}
+	if current.Status != models.PurchaseOrderDraft {
+		return ErrPurchaseOrderStatus
+	}
+	if po.SupplierName, err = supplierName(tx, po.SupplierID); err != nil {
+		return err
+	}
+	if err := checkLocationExists(tx, po.LocationID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("UPDATE purchase_orders SET supplier_id = $1, location_id = $2, note = $3 WHERE id = $4", po.SupplierID, po.LocationID, po.Note, po.ID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM purchase_order_lines WHERE purchase_order_id = $1", po.ID); err != nil {
+		return err
+	}
+	if err := insertPurchaseOrderLines(tx, po); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete - hanya PO draft yang boleh dihapus
+func (repo *PostgresPurchaseOrderRepository) Delete(id int) error {
+	result, err := repo.db.Exec("DELETE FROM purchase_orders WHERE id = $1 AND status = $2", id, models.PurchaseOrderDraft)
+	if err != nil {
+		return err
+	}
+	return repo.checkStatusChange(result, id)
+}
+
+// Send - draft -> sent
+func (repo *PostgresPurchaseOrderRepository) Send(id int) error {
+	result, err := repo.db.Exec(
+		"UPDATE purchase_orders SET status = $1, sent_at = NOW() WHERE id = $2 AND status = $3",
+		models.PurchaseOrderSent, id, models.PurchaseOrderDraft,
+	)
+	if err != nil {
+		return err
+	}
+	return repo.checkStatusChange(result, id)
+}
+
+// checkStatusChange - 0 baris terpengaruh bisa karena PO tidak ada atau statusnya salah
+func (repo *PostgresPurchaseOrderRepository) checkStatusChange(result sql.Result, id int) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	var exists bool
+	if err := repo.db.QueryRow("SELECT EXISTS (SELECT 1 FROM purchase_orders WHERE id = $1)", id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrPurchaseOrderNotFound
+	}
+	return ErrPurchaseOrderStatus
+}
+
+// Receive - penerimaan barang: stok bertambah lewat ledger, harga pokok
+// diperbarui rata-rata tertimbang, status PO ikut maju
+func (repo *PostgresPurchaseOrderRepository) Receive(id int, req models.GoodsReceiptRequest, user string) (*models.GoodsReceipt, error) {
+	var receipt *models.GoodsReceipt
+	err := withRetry(func() error {
+		var err error
+		receipt, err = repo.receive(id, req, user)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return receipt, nil
+}
+
+func (repo *PostgresPurchaseOrderRepository) receive(id int, req models.GoodsReceiptRequest, user string) (*models.GoodsReceipt, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	po, err := loadPurchaseOrder(tx, id, true)
+	if err != nil {
+		return nil, err
+	}
+	if !canReceive(po.Status) {
+		return nil, ErrPurchaseOrderStatus
+	}
+
+	items, err := planReceipt(po.Lines, req)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt := &models.GoodsReceipt{PurchaseOrderID: id, Note: req.Note, User: user}
+	err = tx.QueryRow(
+		"INSERT INTO goods_receipts (purchase_order_id, note, created_by) VALUES ($1, $2, $3) RETURNING id, created_at",
+		id, receipt.Note, user,
+	).Scan(&receipt.ID, &receipt.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range items {
+		item := &items[i]
+		item.GoodsReceiptID = receipt.ID
+
+		// cost_price rata-rata dan cost layer diperbarui di moveStock
+		m := models.StockMovement{
+			ProductID:     item.ProductID,
+			LocationID:    po.LocationID,
+			Delta:         item.Quantity,
+			UnitCost:      item.UnitCost,
+			Batches:       batchLots(item.LotNumber, item.ExpiryDate, item.Quantity),
+			Serials:       item.Serials,
+			Reason:        models.StockReasonReceipt,
+			ReferenceType: models.StockRefReceipt,
+			ReferenceID:   receipt.ID,
+			User:          user,
+			Note:          receiptNote(po, req.Note),
+		}
+		if err := moveStock(tx, &m); isSerialError(err) {
+			return nil, fmt.Errorf("%w (product id %d)", err, item.ProductID)
+		} else if err != nil {
+			return nil, err
+		}
+		item.MovementID = m.ID
+
+		if _, err := tx.Exec(
+			"UPDATE purchase_order_lines SET received_quantity = received_quantity + $1 WHERE id = $2",
+			item.Quantity, item.PurchaseOrderLineID,
+		); err != nil {
+			return nil, err
+		}
+
+		err := tx.QueryRow(`
+			INSERT INTO goods_receipt_items (goods_receipt_id, purchase_order_line_id, product_id, quantity, unit_cost, lot_number, expiry_date, serials, movement_id)
+			VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, '')::date, $8, $9)
+			RETURNING id`,
+			receipt.ID, item.PurchaseOrderLineID, item.ProductID, item.Quantity, item.UnitCost, item.LotNumber, item.ExpiryDate,
+			pq.Array(item.Serials), item.MovementID,
+		).Scan(&item.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for j := range po.Lines {
+			if po.Lines[j].ID == item.PurchaseOrderLineID {
+				po.Lines[j].ReceivedQuantity += item.Quantity
+			}
+		}
+	}
+	receipt.Items = items
+
+	status := statusAfterReceipt(po.Lines)
+	var receivedAt *time.Time
+	if status == models.PurchaseOrderReceived {
+		now := time.Now()
+		receivedAt = &now
+	}
+	if _, err := tx.Exec("UPDATE purchase_orders SET status = $1, received_at = $2 WHERE id = $3", status, receivedAt, id); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return receipt, nil
+}
+
+func (repo *PostgresPurchaseOrderRepository) ListReceipts(id int) ([]models.GoodsReceipt, error) {
+	rows, err := repo.db.Query(`
+		SELECT r.id, r.purchase_order_id, r.note, r.created_by, r.created_at,
+			i.id, i.purchase_order_line_id, i.product_id, i.quantity, i.unit_cost,
+			i.lot_number, COALESCE(TO_CHAR(i.expiry_date, 'YYYY-MM-DD'), ''), i.serials, COALESCE(i.movement_id, 0)
+		FROM goods_receipts r
+		JOIN goods_receipt_items i ON i.goods_receipt_id = r.id
+		WHERE r.purchase_order_id = $1
+		ORDER BY r.id, i.id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	receipts := make([]models.GoodsReceipt, 0)
+	for rows.Next() {
+		var r models.GoodsReceipt
+		var item models.GoodsReceiptItem
+		if err := rows.Scan(
+			&r.ID, &r.PurchaseOrderID, &r.Note, &r.User, &r.CreatedAt,
+			&item.ID, &item.PurchaseOrderLineID, &item.ProductID, &item.Quantity, &item.UnitCost,
+			&item.LotNumber, &item.ExpiryDate, pq.Array(&item.Serials), &item.MovementID,
+		); err != nil {
+			return nil, err
+		}
+		item.GoodsReceiptID = r.ID
+
+		if n := len(receipts); n == 0 || receipts[n-1].ID != r.ID {
+			receipts = append(receipts, r)
+		}
+		receipts[len(receipts)-1].Items = append(receipts[len(receipts)-1].Items, item)
+	}
+
+	return receipts, rows.Err()
+}
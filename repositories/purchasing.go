@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"kasir-api/models"
+)
+
+var (
+	ErrPurchaseOrderNotFound = errors.New("purchase order tidak ditemukan")
+	ErrPurchaseOrderStatus   = errors.New("status purchase order tidak mengizinkan aksi ini")
+	ErrInvalidReceipt        = errors.New("penerimaan barang tidak valid")
+)
+
+// fillPurchaseOrderTotals - subtotal per baris dan total PO dari harga di PO
+func fillPurchaseOrderTotals(po *models.PurchaseOrder) {
+	po.TotalCost = 0
+	for i := range po.Lines {
+		po.Lines[i].Subtotal = po.Lines[i].Quantity * po.Lines[i].UnitCost
+		po.TotalCost += po.Lines[i].Subtotal
+	}
+}
+
+// canReceive - barang hanya bisa diterima setelah PO dikirim ke supplier
+func canReceive(status string) bool {
+	return status == models.PurchaseOrderSent || status == models.PurchaseOrderPartiallyReceived
+}
+
+// planReceipt - validasi penerimaan terhadap sisa quantity tiap baris PO.
+// Items kosong berarti terima semua sisa. Hasilnya urut product id (urutan lock).
+func planReceipt(lines []models.PurchaseOrderLine, req models.GoodsReceiptRequest) ([]models.GoodsReceiptItem, error) {
+	byID := make(map[int]models.PurchaseOrderLine, len(lines))
+	for _, line := range lines {
+		byID[line.ID] = line
+	}
+
+	var items []models.GoodsReceiptItem
+	if len(req.Items) == 0 {
+		for _, line := range lines {
+			if remaining := line.Quantity - line.ReceivedQuantity; remaining > 0 {
+				items = append(items, models.GoodsReceiptItem{
+					PurchaseOrderLineID: line.ID,
+					ProductID:           line.ProductID,
+					Quantity:            remaining,
+					UnitCost:            line.UnitCost,
+				})
+			}
+		}
+		if len(items) == 0 {
+			return nil, fmt.Errorf("%w: semua barang sudah diterima", ErrInvalidReceipt)
+		}
+	}
+
+	received := make(map[int]int)
+	serialsByProduct := make(map[int][]string)
+	for _, reqItem := range req.Items {
+		line, ok := byID[reqItem.PurchaseOrderLineID]
+		if !ok {
+			return nil, fmt.Errorf("%w: purchase_order_line_id %d bukan bagian dari PO ini", ErrInvalidReceipt, reqItem.PurchaseOrderLineID)
+		}
+		if reqItem.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: quantity harus lebih dari 0", ErrInvalidReceipt)
+		}
+		received[line.ID] += reqItem.Quantity
+		if line.ReceivedQuantity+received[line.ID] > line.Quantity {
+			return nil, fmt.Errorf("%w: quantity %s melebihi sisa PO (%d)", ErrInvalidReceipt, line.ProductName, line.Quantity-line.ReceivedQuantity)
+		}
+
+		unitCost := line.UnitCost
+		if reqItem.UnitCost != nil {
+			if *reqItem.UnitCost < 0 {
+				return nil, fmt.Errorf("%w: unit_cost tidak boleh negatif", ErrInvalidReceipt)
+			}
+			unitCost = *reqItem.UnitCost
+		}
+		lot := strings.TrimSpace(reqItem.LotNumber)
+		if err := ValidateLot(lot, reqItem.ExpiryDate); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidReceipt, err)
+		}
+		serials, err := NormalizeSerials(reqItem.Serials, reqItem.Quantity)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w (product %s)", ErrInvalidReceipt, err, line.ProductName)
+		}
+		for _, serial := range serials {
+			if slices.Contains(serialsByProduct[line.ProductID], serial) {
+				return nil, fmt.Errorf("%w: serial %s duplikat", ErrInvalidReceipt, serial)
+			}
+			serialsByProduct[line.ProductID] = append(serialsByProduct[line.ProductID], serial)
+		}
+		items = append(items, models.GoodsReceiptItem{
+			PurchaseOrderLineID: line.ID,
+			ProductID:           line.ProductID,
+			Quantity:            reqItem.Quantity,
+			UnitCost:            unitCost,
+			LotNumber:           lot,
+			ExpiryDate:          reqItem.ExpiryDate,
+			Serials:             serials,
+		})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].ProductID < items[j].ProductID })
+	return items, nil
+}
+
+// statusAfterReceipt - received kalau semua baris sudah diterima penuh
+func statusAfterReceipt(lines []models.PurchaseOrderLine) string {
+	for _, line := range lines {
+		if line.ReceivedQuantity < line.Quantity {
+			return models.PurchaseOrderPartiallyReceived
+		}
+	}
+	return models.PurchaseOrderReceived
+}
+
+// weightedCost - harga pokok rata-rata tertimbang setelah menerima quantity unit
+// seharga unitCost. Stok minus dianggap 0 supaya harga pokok tidak melenceng.
+func weightedCost(stock, cost, quantity, unitCost int) int {
+	stock = max(stock, 0)
+	if stock+quantity == 0 {
+		return cost
+	}
+	return int(math.Round(float64(stock*cost+quantity*unitCost) / float64(stock+quantity)))
+}
+
+func receiptNote(po *models.PurchaseOrder, note string) string {
+	ref := fmt.Sprintf("PO #%d", po.ID)
+	if note != "" {
+		ref += ": " + note
+	}
+	return ref
+}
@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+
+	"kasir-api/models"
+)
+
+func TestWeightedCost(t *testing.T) {
+	tests := []struct {
+		name                            string
+		stock, cost, quantity, unitCost int
+		want                            int
+	}{
+		{"stok kosong pakai harga baru", 0, 0, 10, 2500, 2500},
+		{"rata-rata tertimbang", 10, 2000, 10, 3000, 2500},
+		{"dibulatkan", 2, 1000, 1, 1001, 1000},
+		{"stok minus dianggap nol", -5, 9999, 4, 1200, 1200},
+	}
+	for _, tt := range tests {
+		if got := weightedCost(tt.stock, tt.cost, tt.quantity, tt.unitCost); got != tt.want {
+			t.Errorf("%s: weightedCost = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPlanReceipt(t *testing.T) {
+	lines := []models.PurchaseOrderLine{
+		{ID: 1, ProductID: 9, ProductName: "Gula", Quantity: 10, ReceivedQuantity: 4, UnitCost: 12000},
+		{ID: 2, ProductID: 3, ProductName: "Kopi", Quantity: 5, ReceivedQuantity: 5, UnitCost: 30000},
+	}
+
+	items, err := planReceipt(lines, models.GoodsReceiptRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].PurchaseOrderLineID != 1 || items[0].Quantity != 6 || items[0].UnitCost != 12000 {
+		t.Errorf("receive remaining = %+v", items)
+	}
+
+	invoiceCost := 12500
+	items, err = planReceipt(lines, models.GoodsReceiptRequest{
+		Items: []models.GoodsReceiptRequestItem{{PurchaseOrderLineID: 1, Quantity: 2, UnitCost: &invoiceCost}},
+	})
+	if err != nil || items[0].UnitCost != 12500 {
+		t.Errorf("unit cost override = %+v, %v", items, err)
+	}
+
+	for _, req := range []models.GoodsReceiptRequest{
+		{Items: []models.GoodsReceiptRequestItem{{PurchaseOrderLineID: 1, Quantity: 7}}},
+		{Items: []models.GoodsReceiptRequestItem{{PurchaseOrderLineID: 1, Quantity: 4}, {PurchaseOrderLineID: 1, Quantity: 3}}},
+		{Items: []models.GoodsReceiptRequestItem{{PurchaseOrderLineID: 2, Quantity: 1}}},
+		{Items: []models.GoodsReceiptRequestItem{{PurchaseOrderLineID: 99, Quantity: 1}}},
+		{Items: []models.GoodsReceiptRequestItem{{PurchaseOrderLineID: 1, Quantity: 0}}},
+	} {
+		if _, err := planReceipt(lines, req); !errors.Is(err, ErrInvalidReceipt) {
+			t.Errorf("planReceipt(%+v) err = %v, want ErrInvalidReceipt", req.Items, err)
+		}
+	}
+}
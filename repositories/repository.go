@@ -0,0 +1,183 @@
+package repositories
+
+import (
+	"time"
+
+	"kasir-api/models"
+)
+
+// Interface repository dipakai oleh layer service, supaya service dan handler
+// bisa jalan di atas Postgres maupun backend in-memory (untuk test).
+
+type ProductRepository interface {
+	GetAll(name string) ([]models.Product, error)
+	Create(product *models.Product) error
+	GetByID(id int) (*models.ProductResponse, error)
+	GetVariants(parentID int) ([]models.Product, error)
+	GetByCode(code string) (*models.ProductResponse, error)
+	AssignBarcode(productID int, prefix string) (models.Barcode, error)
+	RemoveBarcode(productID int, code string) error
+	Update(update *models.ProductUpdate) error
+	Delete(id int) error
+}
+
+type CategoryRepository interface {
+	GetAll() ([]models.Category, error)
+	Create(category *models.Category) error
+	GetByID(id int) (*models.Category, error)
+	Update(category *models.Category) error
+	Delete(id int) error
+}
+
+// CheckoutOptions - pengaturan CreateTransaction
+type CheckoutOptions struct {
+	Strategy LockStrategy
+
+	// Costing - metode HPP barang terjual, kosong = average
+	Costing CostingMethod
+
+	// Finalize dipanggil di dalam transaksi DB setelah harga dan stok dibaca,
+	// sebelum transaksi disimpan. Service memakainya untuk aturan bisnis yang
+	// butuh total final (pembayaran, kembalian). Error di sini membatalkan checkout.
+	Finalize func(trx *models.Transaction) error
+
+	// IdempotencyScope / IdempotencyKey - kalau diisi, key yang sudah di-Reserve
+	// ditandai completed beserta response transaksi di transaksi DB yang sama.
+	// Key yang sudah tidak processing membatalkan checkout (ErrIdempotencyKeyLost).
+	IdempotencyScope string
+	IdempotencyKey   string
+}
+
+type TransactionRepository interface {
+	CreateTransaction(req models.CheckoutRequest, opts CheckoutOptions) (*models.Transaction, error)
+	GetSalesReport(startDate, endDate string) (*models.SalesReport, error)
+	GetTaxSummary(startDate, endDate string) (*models.TaxSummaryReport, error)
+	GetProfitReport(startDate, endDate string) (*models.ProfitReport, error)
+	ListTransactions(filter models.TransactionFilter) (*models.TransactionList, error)
+	GetTransactionByID(id int) (*models.Transaction, error)
+	CreateReturn(transactionID int, req models.ReturnRequest) (*models.Return, error)
+	ListReturns(transactionID int) ([]models.Return, error)
+	VoidTransaction(id int, reason, voidedBy, user string, window time.Duration) (*models.Transaction, error)
+}
+
+type PromotionRepository interface {
+	GetAll() ([]models.Promotion, error)
+	Create(promotion *models.Promotion) error
+	GetByID(id int) (*models.Promotion, error)
+	GetByCode(code string) (*models.Promotion, error)
+	Update(promotion *models.Promotion) error
+	Delete(id int) error
+}
+
+type PromotionRuleRepository interface {
+	GetAll() ([]models.PromotionRule, error)
+	Create(rule *models.PromotionRule) error
+	GetByID(id int) (*models.PromotionRule, error)
+	Update(rule *models.PromotionRule) error
+	Delete(id int) error
+}
+
+type TaxRateRepository interface {
+	GetAll() ([]models.TaxRate, error)
+	Create(rate *models.TaxRate) error
+	GetByID(id int) (*models.TaxRate, error)
+	Update(rate *models.TaxRate) error
+	Delete(id int) error
+}
+
+type StockMovementRepository interface {
+	ListByProduct(productID int) ([]models.StockMovement, error)
+}
+
+type InventoryRepository interface {
+	CreateAdjustment(adj *models.StockAdjustment, policy StockPolicy) error
+	ListLowStock() ([]models.LowStockItem, error)
+	ListProductDemand(since time.Time) ([]models.ProductDemand, error)
+	ListValuation(before *time.Time) ([]models.ValuationItem, error)
+	ListBatches(filter models.BatchFilter) ([]models.Batch, error)
+	FindSerials(serial string) ([]models.SerialNumber, error)
+}
+
+type StockCountRepository interface {
+	Create(count *models.StockCount, productIDs, categoryIDs []int) error
+	GetAll() ([]models.StockCount, error)
+	GetByID(id int) (*models.StockCount, error)
+	Submit(id int, sub models.StockCountSubmission, user string) error
+	Finalize(id int, user string, policy StockPolicy) (*models.StockCount, error)
+	Cancel(id int, user string) error
+}
+
+type SupplierRepository interface {
+	GetAll() ([]models.Supplier, error)
+	Create(supplier *models.Supplier) error
+	GetByID(id int) (*models.Supplier, error)
+	Update(supplier *models.Supplier) error
+	Delete(id int) error
+}
+
+type PurchaseOrderRepository interface {
+	GetAll(filter models.PurchaseOrderFilter) ([]models.PurchaseOrder, error)
+	Create(po *models.PurchaseOrder) error
+	GetByID(id int) (*models.PurchaseOrder, error)
+	Update(po *models.PurchaseOrder) error
+	Delete(id int) error
+	Send(id int) error
+	Receive(id int, req models.GoodsReceiptRequest, user string) (*models.GoodsReceipt, error)
+	ListReceipts(id int) ([]models.GoodsReceipt, error)
+}
+
+type LocationRepository interface {
+	GetAll() ([]models.Location, error)
+	Create(location *models.Location) error
+	GetByID(id int) (*models.Location, error)
+	Update(location *models.Location) error
+	Delete(id int) error
+}
+
+// StockTransferRepository - Ship mengurangi stok lokasi asal, Receive menambah
+// stok lokasi tujuan, keduanya lewat ledger
+type StockTransferRepository interface {
+	GetAll(filter models.StockTransferFilter) ([]models.StockTransfer, error)
+	Create(transfer *models.StockTransfer) error
+	GetByID(id int) (*models.StockTransfer, error)
+	Ship(id int, user string) (*models.StockTransfer, error)
+	Receive(id int, user string) (*models.StockTransfer, error)
+	Cancel(id int, user string) (*models.StockTransfer, error)
+}
+
+type IdempotencyRepository interface {
+	Reserve(scope, key, fingerprint string, staleAfter time.Duration) (*models.IdempotencyRecord, bool, error)
+	Release(scope, key string) error
+}
+
+var (
+	_ ProductRepository       = (*PostgresProductRepository)(nil)
+	_ CategoryRepository      = (*PostgresCategoryRepository)(nil)
+	_ TransactionRepository   = (*PostgresTransactionRepository)(nil)
+	_ IdempotencyRepository   = (*PostgresIdempotencyRepository)(nil)
+	_ PromotionRepository     = (*PostgresPromotionRepository)(nil)
+	_ PromotionRuleRepository = (*PostgresPromotionRuleRepository)(nil)
+	_ TaxRateRepository       = (*PostgresTaxRateRepository)(nil)
+	_ StockMovementRepository = (*PostgresStockMovementRepository)(nil)
+	_ InventoryRepository     = (*PostgresInventoryRepository)(nil)
+	_ StockCountRepository    = (*PostgresStockCountRepository)(nil)
+	_ SupplierRepository      = (*PostgresSupplierRepository)(nil)
+	_ PurchaseOrderRepository = (*PostgresPurchaseOrderRepository)(nil)
+	_ LocationRepository      = (*PostgresLocationRepository)(nil)
+	_ StockTransferRepository = (*PostgresStockTransferRepository)(nil)
+
+	_ ProductRepository       = (*MemoryProductRepository)(nil)
+	_ CategoryRepository      = (*MemoryCategoryRepository)(nil)
+	_ TransactionRepository   = (*MemoryTransactionRepository)(nil)
+	_ IdempotencyRepository   = (*MemoryIdempotencyRepository)(nil)
+	_ PromotionRepository     = (*MemoryPromotionRepository)(nil)
+	_ PromotionRuleRepository = (*MemoryPromotionRuleRepository)(nil)
+	_ TaxRateRepository       = (*MemoryTaxRateRepository)(nil)
+	_ StockMovementRepository = (*MemoryStockMovementRepository)(nil)
+	_ InventoryRepository     = (*MemoryInventoryRepository)(nil)
+	_ StockCountRepository    = (*MemoryStockCountRepository)(nil)
+	_ SupplierRepository      = (*MemorySupplierRepository)(nil)
+	_ PurchaseOrderRepository = (*MemoryPurchaseOrderRepository)(nil)
+	_ LocationRepository      = (*MemoryLocationRepository)(nil)
+	_ StockTransferRepository = (*MemoryStockTransferRepository)(nil)
+)
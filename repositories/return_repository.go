@@ -0,0 +1,197 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"kasir-api/models"
+
+	"github.com/lib/pq"
+)
+
+// CreateReturn - retur penuh / sebagian atas transaksi. Stok dikembalikan dan
+// dokumen retur disimpan dalam satu transaksi DB.
+func (repo *PostgresTransactionRepository) CreateReturn(transactionID int, req models.ReturnRequest) (*models.Return, error) {
+	var result *models.Return
+	err := withRetry(func() error {
+		var err error
+		result, err = repo.createReturn(transactionID, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (repo *PostgresTransactionRepository) createReturn(transactionID int, req models.ReturnRequest) (*models.Return, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// kunci header transaksi supaya dua retur bersamaan tidak melebihi quantity terjual
+	var voided bool
+	var locationID int
+	err = tx.QueryRow("SELECT voided_at IS NOT NULL, location_id FROM transactions WHERE id = $1 FOR UPDATE", transactionID).Scan(&voided, &locationID)
+	if err == sql.ErrNoRows {
+		return nil, ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if voided {
+		return nil, ErrTransactionVoided
+	}
+
+	lines, err := loadReturnableLines(tx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := planReturn(lines, req)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &models.Return{
+		TransactionID: transactionID,
+		TotalAmount:   sumReturnAmount(items),
+		Reason:        req.Reason,
+	}
+	err = tx.QueryRow(
+		"INSERT INTO returns (transaction_id, total_amount, reason) VALUES ($1, $2, $3) RETURNING id, created_at",
+		transactionID, ret.TotalAmount, ret.Reason,
+	).Scan(&ret.ID, &ret.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range items {
+		items[i].ReturnID = ret.ID
+		err := tx.QueryRow(`
+			INSERT INTO return_items (return_id, transaction_detail_id, product_id, product_name, quantity, amount, tax_amount, serials)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id`,
+			ret.ID, items[i].TransactionDetailID, items[i].ProductID, items[i].ProductName, items[i].Quantity, items[i].Amount, items[i].TaxAmount,
+			pq.Array(items[i].Serials),
+		).Scan(&items[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	ret.Items = items
+
+	// barang retur masuk lagi ke stok lokasi penjualan
+	productIDs, restock := restockQuantities(items)
+	costs := restockCosts(lines)
+	serials := restockSerials(items)
+	for _, productID := range productIDs {
+		lots, err := soldLots(tx, transactionID, productID)
+		if err != nil {
+			return nil, err
+		}
+		err = moveStock(tx, &models.StockMovement{
+			ProductID:     productID,
+			LocationID:    locationID,
+			Delta:         restock[productID],
+			UnitCost:      costs[productID],
+			Batches:       takeLots(lots, restock[productID]),
+			Serials:       serials[productID],
+			Reason:        models.StockReasonReturn,
+			ReferenceType: models.StockRefReturn,
+			ReferenceID:   ret.ID,
+			User:          req.User,
+			Note:          req.Reason,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func loadReturnableLines(tx *sql.Tx, transactionID int) ([]returnableLine, error) {
+	query := `
+		SELECT
+			td.id,
+			td.transaction_id,
+			td.product_id,
+			td.product_name,
+			td.quantity,
+			td.subtotal,
+			td.tax_amount,
+			td.unit_cost,
+			td.serials,
+			COALESCE(SUM(ri.quantity), 0),
+			COALESCE(-SUM(ri.amount), 0),
+			COALESCE(-SUM(ri.tax_amount), 0),
+			ARRAY(SELECT UNNEST(r.serials) FROM return_items r WHERE r.transaction_detail_id = td.id)
+		FROM transaction_details td
+		LEFT JOIN return_items ri ON ri.transaction_detail_id = td.id
+		WHERE td.transaction_id = $1
+		GROUP BY td.id
+		ORDER BY td.id
+	`
+	rows, err := tx.Query(query, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []returnableLine
+	for rows.Next() {
+		var line returnableLine
+		d := &line.detail
+		if err := rows.Scan(&d.ID, &d.TransactionID, &d.ProductID, &d.ProductName, &d.Quantity, &d.Subtotal, &d.TaxAmount, &d.UnitCost, pq.Array(&d.Serials),
+			&line.returnedQty, &line.returnedAmount, &line.returnedTax, pq.Array(&line.returnedSerials)); err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, rows.Err()
+}
+
+func (repo *PostgresTransactionRepository) ListReturns(transactionID int) ([]models.Return, error) {
+	rows, err := repo.db.Query(`
+		SELECT r.id, r.transaction_id, r.total_amount, r.reason, r.created_at,
+			ri.id, ri.transaction_detail_id, ri.product_id, ri.product_name, ri.quantity, ri.amount, ri.tax_amount, ri.serials
+		FROM returns r
+		JOIN return_items ri ON ri.return_id = r.id
+		WHERE r.transaction_id = $1
+		ORDER BY r.id, ri.id`, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	returns := make([]models.Return, 0)
+	for rows.Next() {
+		var r models.Return
+		var item models.ReturnItem
+		if err := rows.Scan(
+			&r.ID, &r.TransactionID, &r.TotalAmount, &r.Reason, &r.CreatedAt,
+			&item.ID, &item.TransactionDetailID, &item.ProductID, &item.ProductName, &item.Quantity, &item.Amount, &item.TaxAmount, pq.Array(&item.Serials),
+		); err != nil {
+			return nil, err
+		}
+		item.ReturnID = r.ID
+
+		if n := len(returns); n == 0 || returns[n-1].ID != r.ID {
+			returns = append(returns, r)
+		}
+		returns[len(returns)-1].Items = append(returns[len(returns)-1].Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return returns, nil
+}
@@ -0,0 +1,185 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"kasir-api/models"
+)
+
+var (
+	ErrTransactionNotFound   = errors.New("transaksi tidak ditemukan")
+	ErrTransactionVoided     = errors.New("transaksi sudah di-void")
+	ErrTransactionHasReturns = errors.New("transaksi sudah punya retur, tidak bisa di-void")
+	ErrVoidWindowExpired     = errors.New("batas waktu void transaksi sudah lewat")
+)
+
+// NoVoidWindow - window untuk VoidTransaction tanpa batas umur transaksi
+// (void dengan otorisasi manager)
+const NoVoidWindow time.Duration = -1
+
+// returnableLine - satu baris transaksi beserta quantity / amount yang sudah diretur
+type returnableLine struct {
+	detail         models.TransactionDetail
+	returnedQty    int
+	returnedAmount int // positif
+	returnedTax    int // positif
+
+	// returnedSerials - serial dari baris ini yang sudah diretur
+	returnedSerials []string
+}
+
+// planReturn - validasi request retur dan hitung item retur. Quantity dibatasi sisa
+// yang belum diretur; kalau sisa diretur semua, amount = sisa subtotal (dan pajak =
+// sisa pajak) supaya pembulatan tidak membuat total retur berbeda dengan subtotal.
+func planReturn(lines []returnableLine, req models.ReturnRequest) ([]models.ReturnItem, error) {
+	byDetail := make(map[int]*returnableLine, len(lines))
+	for i := range lines {
+		byDetail[lines[i].detail.ID] = &lines[i]
+	}
+
+	requested := make(map[int]int)
+	requestedSerials := make(map[int][]string)
+	order := make([]int, 0)
+	if len(req.Items) == 0 {
+		for _, line := range lines {
+			if remaining := line.detail.Quantity - line.returnedQty; remaining > 0 {
+				requested[line.detail.ID] = remaining
+				order = append(order, line.detail.ID)
+			}
+		}
+		if len(order) == 0 {
+			return nil, errors.New("semua item transaksi ini sudah diretur")
+		}
+	}
+	for _, item := range req.Items {
+		if _, ok := byDetail[item.TransactionDetailID]; !ok {
+			return nil, fmt.Errorf("transaction detail id %d bukan bagian dari transaksi ini", item.TransactionDetailID)
+		}
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("quantity retur untuk detail id %d harus lebih dari 0", item.TransactionDetailID)
+		}
+		if _, seen := requested[item.TransactionDetailID]; !seen {
+			order = append(order, item.TransactionDetailID)
+		}
+		requested[item.TransactionDetailID] += item.Quantity
+		requestedSerials[item.TransactionDetailID] = append(requestedSerials[item.TransactionDetailID], item.Serials...)
+	}
+
+	items := make([]models.ReturnItem, 0, len(order))
+	for _, detailID := range order {
+		line := byDetail[detailID]
+		qty := requested[detailID]
+
+		remaining := line.detail.Quantity - line.returnedQty
+		if qty > remaining {
+			return nil, fmt.Errorf("quantity retur %s melebihi sisa yang bisa diretur (%d)", line.detail.ProductName, remaining)
+		}
+
+		serials, err := returnSerials(line, qty, requestedSerials[detailID])
+		if err != nil {
+			return nil, err
+		}
+
+		amount := line.detail.Subtotal * qty / line.detail.Quantity
+		tax := line.detail.TaxAmount * qty / line.detail.Quantity
+		if qty == remaining {
+			amount = line.detail.Subtotal - line.returnedAmount
+			tax = line.detail.TaxAmount - line.returnedTax
+		}
+
+		items = append(items, models.ReturnItem{
+			TransactionDetailID: detailID,
+			ProductID:           line.detail.ProductID,
+			ProductName:         line.detail.ProductName,
+			Quantity:            qty,
+			Amount:              -amount,
+			TaxAmount:           -tax,
+			Serials:             serials,
+		})
+	}
+
+	return items, nil
+}
+
+// returnSerials - serial unit yang diretur dari satu baris. Baris yang dijual
+// dengan serial boleh tanpa serial di request hanya kalau semua sisa unitnya
+// diretur; serial yang diminta harus unit dari baris itu yang belum diretur.
+func returnSerials(line *returnableLine, qty int, requested []string) ([]string, error) {
+	if len(line.detail.Serials) == 0 {
+		if len(requested) > 0 {
+			return nil, fmt.Errorf("%w: %s tidak dijual dengan serial", ErrInvalidSerial, line.detail.ProductName)
+		}
+		return nil, nil
+	}
+
+	remaining := make([]string, 0, len(line.detail.Serials))
+	for _, serial := range line.detail.Serials {
+		if !slices.Contains(line.returnedSerials, serial) {
+			remaining = append(remaining, serial)
+		}
+	}
+	if len(requested) == 0 {
+		if qty == len(remaining) {
+			return remaining, nil
+		}
+		return nil, fmt.Errorf("%w (product %s)", ErrSerialRequired, line.detail.ProductName)
+	}
+
+	serials, err := NormalizeSerials(requested, qty)
+	if err != nil {
+		return nil, fmt.Errorf("%w (product %s)", err, line.detail.ProductName)
+	}
+	for _, serial := range serials {
+		if !slices.Contains(remaining, serial) {
+			return nil, fmt.Errorf("%w: %s bukan unit %s yang belum diretur", ErrInvalidSerial, serial, line.detail.ProductName)
+		}
+	}
+	return serials, nil
+}
+
+func sumReturnAmount(items []models.ReturnItem) int {
+	total := 0
+	for _, item := range items {
+		total += item.Amount
+	}
+	return total
+}
+
+// restockQuantities - quantity yang dikembalikan per produk, id urut naik
+// (sama dengan urutan lock di checkout)
+func restockQuantities(items []models.ReturnItem) ([]int, map[int]int) {
+	restock := make(map[int]int)
+	for _, item := range items {
+		restock[item.ProductID] += item.Quantity
+	}
+	productIDs := make([]int, 0, len(restock))
+	for productID := range restock {
+		productIDs = append(productIDs, productID)
+	}
+	sort.Ints(productIDs)
+
+	return productIDs, restock
+}
+
+// restockSerials - serial yang dikembalikan per produk
+func restockSerials(items []models.ReturnItem) map[int][]string {
+	serials := make(map[int][]string)
+	for _, item := range items {
+		serials[item.ProductID] = append(serials[item.ProductID], item.Serials...)
+	}
+	return serials
+}
+
+// restockCosts - HPP per unit penjualan asal per produk, barang retur masuk
+// lagi ke persediaan dengan nilai yang sama saat keluar
+func restockCosts(lines []returnableLine) map[int]int {
+	costs := make(map[int]int, len(lines))
+	for _, line := range lines {
+		costs[line.detail.ProductID] = line.detail.UnitCost
+	}
+	return costs
+}
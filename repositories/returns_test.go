@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"testing"
+
+	"kasir-api/models"
+)
+
+func TestPlanReturnLastReturnTakesRemainingSubtotal(t *testing.T) {
+	lines := []returnableLine{{
+		detail: models.TransactionDetail{ID: 1, ProductID: 7, ProductName: "Permen", Quantity: 3, Subtotal: 1000, TaxAmount: 100},
+	}}
+
+	total, totalTax := 0, 0
+	for i := 0; i < 3; i++ {
+		items, err := planReturn(lines, models.ReturnRequest{
+			Items: []models.ReturnRequestItem{{TransactionDetailID: 1, Quantity: 1}},
+		})
+		if err != nil {
+			t.Fatalf("return %d: %v", i+1, err)
+		}
+		lines[0].returnedQty += items[0].Quantity
+		lines[0].returnedAmount -= items[0].Amount
+		lines[0].returnedTax -= items[0].TaxAmount
+		total += items[0].Amount
+		totalTax += items[0].TaxAmount
+	}
+
+	if total != -1000 || totalTax != -100 {
+		t.Errorf("sum of partial returns = %d (tax %d), want -1000 (tax -100)", total, totalTax)
+	}
+
+	if _, err := planReturn(lines, models.ReturnRequest{}); err == nil {
+		t.Error("expected error when everything has been returned")
+	}
+}
+
+func TestPlanReturnRejectsInvalidItems(t *testing.T) {
+	lines := []returnableLine{{
+		detail: models.TransactionDetail{ID: 1, ProductID: 7, ProductName: "Permen", Quantity: 2, Subtotal: 200},
+	}}
+
+	tests := []models.ReturnRequestItem{
+		{TransactionDetailID: 99, Quantity: 1},
+		{TransactionDetailID: 1, Quantity: 0},
+		{TransactionDetailID: 1, Quantity: 3},
+	}
+	for _, item := range tests {
+		if _, err := planReturn(lines, models.ReturnRequest{Items: []models.ReturnRequestItem{item}}); err == nil {
+			t.Errorf("planReturn(%+v) = nil error, want error", item)
+		}
+	}
+}
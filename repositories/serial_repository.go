@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	"kasir-api/models"
+
+	"github.com/lib/pq"
+)
+
+// FindSerials - unit dengan serial itu (bisa lebih dari satu produk) beserta
+// riwayat movement-nya
+func (repo *PostgresInventoryRepository) FindSerials(serial string) ([]models.SerialNumber, error) {
+	rows, err := repo.db.Query(`
+		SELECT sn.id, sn.product_id, p.name, sn.serial, sn.status, sn.created_at
+		FROM serial_numbers sn
+		JOIN products p ON p.id = sn.product_id
+		WHERE sn.serial = $1
+		ORDER BY sn.id`, serial)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	units := make([]models.SerialNumber, 0)
+	for rows.Next() {
+		var sn models.SerialNumber
+		if err := rows.Scan(&sn.ID, &sn.ProductID, &sn.ProductName, &sn.Serial, &sn.Status, &sn.CreatedAt); err != nil {
+			return nil, err
+		}
+		units = append(units, sn)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(units) == 0 {
+		return nil, ErrSerialNotFound
+	}
+
+	for i := range units {
+		if units[i].History, err = repo.serialHistory(units[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return units, nil
+}
+
+func (repo *PostgresInventoryRepository) serialHistory(serialID int) ([]models.StockMovement, error) {
+	rows, err := repo.db.Query(`
+		SELECT sm.id, sm.product_id, sm.location_id, sm.delta, sm.stock_after, sm.unit_cost, sm.cost_amount, sm.cost_method,
+			sm.reason, sm.reference_type, COALESCE(sm.reference_id, 0), sm.created_by, sm.note, sm.created_at
+		FROM serial_movements x
+		JOIN stock_movements sm ON sm.id = x.movement_id
+		WHERE x.serial_id = $1
+		ORDER BY sm.id`, serialID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := make([]models.StockMovement, 0)
+	for rows.Next() {
+		var m models.StockMovement
+		if err := rows.Scan(&m.ID, &m.ProductID, &m.LocationID, &m.Delta, &m.StockAfter, &m.UnitCost, &m.CostAmount, &m.CostMethod, &m.Reason, &m.ReferenceType, &m.ReferenceID, &m.User, &m.Note, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, m)
+	}
+
+	return history, rows.Err()
+}
+
+// moveSerials - stok masuk mendaftarkan serial (atau mengembalikan unit yang
+// sudah terjual / dikeluarkan ke stok), stok keluar hanya boleh memakai unit
+// yang masih in_stock. tracked = products.track_serial.
+func moveSerials(tx *sql.Tx, m *models.StockMovement, tracked bool) error {
+	if err := checkSerialMovement(m, tracked); err != nil {
+		return err
+	}
+
+	for _, serial := range m.Serials {
+		var id int
+		var err error
+		if m.Delta > 0 {
+			err = tx.QueryRow(`
+				INSERT INTO serial_numbers (product_id, serial, status) VALUES ($1, $2, $3)
+				ON CONFLICT (product_id, serial) DO UPDATE SET status = EXCLUDED.status
+				WHERE serial_numbers.status <> EXCLUDED.status
+				RETURNING id`,
+				m.ProductID, serial, models.SerialInStock,
+			).Scan(&id)
+		} else {
+			err = tx.QueryRow(
+				"UPDATE serial_numbers SET status = $1 WHERE product_id = $2 AND serial = $3 AND status = $4 RETURNING id",
+				serialStatusAfter(m), m.ProductID, serial, models.SerialInStock,
+			).Scan(&id)
+		}
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: %s", ErrSerialUnavailable, serial)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertSerialMovements - catat serial yang ikut movement m (setelah m.ID ada)
+func insertSerialMovements(tx *sql.Tx, m *models.StockMovement) error {
+	if len(m.Serials) == 0 {
+		return nil
+	}
+	_, err := tx.Exec(`
+		INSERT INTO serial_movements (movement_id, serial_id)
+		SELECT $1, id FROM serial_numbers WHERE product_id = $2 AND serial = ANY($3)`,
+		m.ID, m.ProductID, pq.Array(m.Serials),
+	)
+	return err
+}
+
+// loadMovementSerials - serial per movement id untuk ledger satu produk
+func loadMovementSerials(q queryer, productID int) (map[int][]string, error) {
+	rows, err := q.Query(`
+		SELECT x.movement_id, sn.serial
+		FROM serial_movements x
+		JOIN serial_numbers sn ON sn.id = x.serial_id
+		WHERE sn.product_id = $1
+		ORDER BY x.movement_id, sn.serial`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byMovement := make(map[int][]string)
+	for rows.Next() {
+		var movementID int
+		var serial string
+		if err := rows.Scan(&movementID, &serial); err != nil {
+			return nil, err
+		}
+		byMovement[movementID] = append(byMovement[movementID], serial)
+	}
+
+	return byMovement, rows.Err()
+}
@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"kasir-api/models"
+)
+
+var (
+	ErrInvalidSerial     = errors.New("serial number tidak valid")
+	ErrSerialRequired    = errors.New("serial number wajib diisi untuk produk ini")
+	ErrSerialUnavailable = errors.New("serial number tidak tersedia")
+	ErrSerialNotFound    = errors.New("serial number tidak ditemukan")
+	ErrTrackSerialInUse  = errors.New("track_serial hanya bisa diubah kalau stok produk kosong")
+)
+
+const maxSerialLength = 100
+
+// NormalizeSerials - trim setiap serial, tolak yang kosong, terlalu panjang
+// atau duplikat. Kalau diisi, jumlahnya harus sama dengan quantity unit.
+func NormalizeSerials(serials []string, quantity int) ([]string, error) {
+	if len(serials) == 0 {
+		return nil, nil
+	}
+	if len(serials) != quantity {
+		return nil, fmt.Errorf("%w: jumlah serial (%d) harus sama dengan quantity (%d)", ErrInvalidSerial, len(serials), quantity)
+	}
+
+	normalized := make([]string, len(serials))
+	for i, serial := range serials {
+		serial = strings.TrimSpace(serial)
+		switch {
+		case serial == "":
+			return nil, fmt.Errorf("%w: serial tidak boleh kosong", ErrInvalidSerial)
+		case len(serial) > maxSerialLength:
+			return nil, fmt.Errorf("%w: serial maksimal %d karakter", ErrInvalidSerial, maxSerialLength)
+		case slices.Contains(normalized[:i], serial):
+			return nil, fmt.Errorf("%w: serial %s duplikat", ErrInvalidSerial, serial)
+		}
+		normalized[i] = serial
+	}
+	return normalized, nil
+}
+
+// checkSerialMovement - aturan serial di ledger, sama untuk kedua backend.
+// Produk dengan track_serial wajib menyertakan serial saat penerimaan barang
+// dan penjualan; movement lain (adjustment, transfer, stock opname) boleh tanpa
+// serial dan tidak mengubah status unit.
+func checkSerialMovement(m *models.StockMovement, tracked bool) error {
+	if len(m.Serials) == 0 {
+		if tracked && (m.Reason == models.StockReasonSale || m.Reason == models.StockReasonReceipt) {
+			return ErrSerialRequired
+		}
+		return nil
+	}
+	if !tracked {
+		return fmt.Errorf("%w: produk id %d tidak dilacak per serial", ErrInvalidSerial, m.ProductID)
+	}
+	_, err := NormalizeSerials(m.Serials, max(m.Delta, -m.Delta))
+	return err
+}
+
+// serialStatusAfter - status unit setelah movement m
+func serialStatusAfter(m *models.StockMovement) string {
+	switch {
+	case m.Delta > 0:
+		return models.SerialInStock
+	case m.Reason == models.StockReasonSale:
+		return models.SerialSold
+	default:
+		return models.SerialRemoved
+	}
+}
+
+// checkoutSerials - serial per produk dari semua item checkout
+func checkoutSerials(items []models.CheckoutItem) map[int][]string {
+	serials := make(map[int][]string)
+	for _, item := range items {
+		id := itemProductID(item)
+		serials[id] = append(serials[id], item.Serials...)
+	}
+	return serials
+}
+
+func isSerialError(err error) bool {
+	return errors.Is(err, ErrSerialRequired) || errors.Is(err, ErrInvalidSerial) || errors.Is(err, ErrSerialUnavailable)
+}
+
+// checkoutSerialError - error serial dari ledger dilengkapi nama produk
+func checkoutSerialError(err error, productName string) error {
+	if isSerialError(err) {
+		return fmt.Errorf("%w (product %s)", err, productName)
+	}
+	return err
+}
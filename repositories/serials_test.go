@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"kasir-api/models"
+)
+
+func TestNormalizeSerials(t *testing.T) {
+	got, err := NormalizeSerials([]string{" A1 ", "B2"}, 2)
+	if err != nil || !slices.Equal(got, []string{"A1", "B2"}) {
+		t.Errorf("NormalizeSerials = %v, %v", got, err)
+	}
+	if got, err := NormalizeSerials(nil, 3); err != nil || got != nil {
+		t.Errorf("empty serials = %v, %v", got, err)
+	}
+	for _, serials := range [][]string{{"A1"}, {"A1", " "}, {"A1", "A1 "}} {
+		if _, err := NormalizeSerials(serials, 2); !errors.Is(err, ErrInvalidSerial) {
+			t.Errorf("NormalizeSerials(%q) err = %v, want ErrInvalidSerial", serials, err)
+		}
+	}
+}
+
+func TestCheckSerialMovement(t *testing.T) {
+	tests := []struct {
+		name    string
+		m       models.StockMovement
+		tracked bool
+		want    error
+	}{
+		{"sale tanpa serial", models.StockMovement{Delta: -1, Reason: models.StockReasonSale}, true, ErrSerialRequired},
+		{"receipt tanpa serial", models.StockMovement{Delta: 2, Reason: models.StockReasonReceipt}, true, ErrSerialRequired},
+		{"transfer tanpa serial", models.StockMovement{Delta: -1, Reason: models.StockReasonTransfer}, true, nil},
+		{"produk biasa", models.StockMovement{Delta: -1, Reason: models.StockReasonSale, Serials: []string{"X"}}, false, ErrInvalidSerial},
+		{"jumlah beda", models.StockMovement{Delta: -2, Reason: models.StockReasonSale, Serials: []string{"X"}}, true, ErrInvalidSerial},
+		{"valid", models.StockMovement{Delta: -2, Reason: models.StockReasonSale, Serials: []string{"X", "Y"}}, true, nil},
+	}
+	for _, tt := range tests {
+		if err := checkSerialMovement(&tt.m, tt.tracked); !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"kasir-api/models"
+
+	"github.com/lib/pq"
+)
+
+// queryer - *sql.DB atau *sql.Tx
+type queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+type PostgresStockCountRepository struct {
+	db *sql.DB
+}
+
+func NewStockCountRepository(db *sql.DB) *PostgresStockCountRepository {
+	return &PostgresStockCountRepository{db: db}
+}
+
+// Create - buka sesi dan snapshot stok produk yang dipilih (by id atau category)
+// di lokasi sesi
+func (repo *PostgresStockCountRepository) Create(count *models.StockCount, productIDs, categoryIDs []int) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkLocationExists(tx, count.LocationID); err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(
+		"INSERT INTO stock_counts (location_id, note, created_by) VALUES ($1, $2, $3) RETURNING id, status, created_at",
+		count.LocationID, count.Note, count.CreatedBy,
+	).Scan(&count.ID, &count.Status, &count.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	if productIDs == nil {
+		productIDs = []int{}
+	}
+	if categoryIDs == nil {
+		categoryIDs = []int{}
+	}
+	all := len(productIDs) == 0 && len(categoryIDs) == 0
+
+	result, err := tx.Exec(`
+		INSERT INTO stock_count_lines (count_id, product_id, product_name, category_id, unit_price, unit_cost, system_stock)
+		SELECT $1, p.id, p.name, COALESCE(p.category_id, 0), p.price, p.cost_price, COALESCE(ps.stock, 0)
+		FROM products p
+		LEFT JOIN product_stocks ps ON ps.product_id = p.id AND ps.location_id = $5
+		WHERE $2 OR p.id = ANY($3) OR p.category_id = ANY($4)`,
+		count.ID, all, pq.Array(productIDs), pq.Array(categoryIDs), count.LocationID,
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrStockCountEmpty
+	}
+
+	if count.Lines, err = loadStockCountLines(tx, count.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (repo *PostgresStockCountRepository) GetAll() ([]models.StockCount, error) {
+	rows, err := repo.db.Query(`
+		SELECT id, location_id, status, note, created_by, created_at, closed_at, closed_by, COALESCE(adjustment_id, 0)
+		FROM stock_counts
+		ORDER BY id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make([]models.StockCount, 0)
+	for rows.Next() {
+		var c models.StockCount
+		if err := rows.Scan(&c.ID, &c.LocationID, &c.Status, &c.Note, &c.CreatedBy, &c.CreatedAt, &c.ClosedAt, &c.ClosedBy, &c.AdjustmentID); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+
+	return counts, rows.Err()
+}
+
+func (repo *PostgresStockCountRepository) GetByID(id int) (*models.StockCount, error) {
+	return loadStockCount(repo.db, id, false)
+}
+
+// loadStockCount - header + lines + entries. forUpdate mengunci header sesi.
+func loadStockCount(q queryer, id int, forUpdate bool) (*models.StockCount, error) {
+	query := `
+		SELECT id, location_id, status, note, created_by, created_at, closed_at, closed_by, COALESCE(adjustment_id, 0)
+		FROM stock_counts
+		WHERE id = $1`
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+
+	var c models.StockCount
+	err := q.QueryRow(query, id).Scan(&c.ID, &c.LocationID, &c.Status, &c.Note, &c.CreatedBy, &c.CreatedAt, &c.ClosedAt, &c.ClosedBy, &c.AdjustmentID)
+	if err == sql.ErrNoRows {
+		return nil, ErrStockCountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Lines, err = loadStockCountLines(q, id); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+func loadStockCountLines(q queryer, countID int) ([]models.StockCountLine, error) {
+	rows, err := q.Query(`
+		SELECT l.product_id, l.product_name, l.category_id, l.unit_price, l.unit_cost, l.system_stock,
+			e.device, e.quantity, e.counted_by, e.updated_at
+		FROM stock_count_lines l
+		LEFT JOIN stock_count_entries e ON e.count_id = l.count_id AND e.product_id = l.product_id
+		WHERE l.count_id = $1
+		ORDER BY l.product_id, e.device`, countID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := make([]models.StockCountLine, 0)
+	for rows.Next() {
+		var line models.StockCountLine
+		var device, countedBy sql.NullString
+		var quantity sql.NullInt64
+		var updatedAt sql.NullTime
+		if err := rows.Scan(
+			&line.ProductID, &line.ProductName, &line.CategoryID, &line.UnitPrice, &line.UnitCost, &line.SystemStock,
+			&device, &quantity, &countedBy, &updatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if n := len(lines); n == 0 || lines[n-1].ProductID != line.ProductID {
+			lines = append(lines, line)
+		}
+		if device.Valid {
+			last := &lines[len(lines)-1]
+			last.Entries = append(last.Entries, models.StockCountEntry{
+				Device:    device.String,
+				Quantity:  int(quantity.Int64),
+				CountedBy: countedBy.String,
+				UpdatedAt: updatedAt.Time,
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range lines {
+		fillCountVariance(&lines[i])
+	}
+
+	return lines, nil
+}
+
+// Submit - simpan hasil hitung satu device. Header dikunci FOR SHARE supaya
+// tidak bisa bersamaan dengan finalize, tapi antar device tetap paralel.
+func (repo *PostgresStockCountRepository) Submit(id int, sub models.StockCountSubmission, user string) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRow("SELECT status FROM stock_counts WHERE id = $1 FOR SHARE", id).Scan(&status)
+	if err == sql.ErrNoRows {
+		return ErrStockCountNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if status != models.StockCountOpen {
+		return ErrStockCountClosed
+	}
+
+	for _, item := range sub.Items {
+		var exists bool
+		err := tx.QueryRow(
+			"SELECT EXISTS (SELECT 1 FROM stock_count_lines WHERE count_id = $1 AND product_id = $2)", id, item.ProductID,
+		).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrStockCountUnknownProduct
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO stock_count_entries (count_id, product_id, device, quantity, counted_by)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (count_id, product_id, device)
+			DO UPDATE SET quantity = EXCLUDED.quantity, counted_by = EXCLUDED.counted_by, updated_at = NOW()`,
+			id, item.ProductID, sub.Device, item.Quantity, user,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Finalize - posting selisih sebagai satu stock adjustment lalu tutup sesi
+func (repo *PostgresStockCountRepository) Finalize(id int, user string, policy StockPolicy) (*models.StockCount, error) {
+	var count *models.StockCount
+	err := withRetry(func() error {
+		var err error
+		count, err = repo.finalize(id, user, policy)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return count, nil
+}
+
+func (repo *PostgresStockCountRepository) finalize(id int, user string, policy StockPolicy) (*models.StockCount, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	count, err := loadStockCount(tx, id, true)
+	if err != nil {
+		return nil, err
+	}
+	if count.Status != models.StockCountOpen {
+		return nil, ErrStockCountClosed
+	}
+
+	if adj := countAdjustment(count, user); len(adj.Items) > 0 {
+		if err := insertAdjustment(tx, adj, policy); err != nil {
+			return nil, err
+		}
+		count.AdjustmentID = adj.ID
+	}
+
+	err = tx.QueryRow(
+		"UPDATE stock_counts SET status = $1, closed_at = NOW(), closed_by = $2, adjustment_id = $3 WHERE id = $4 RETURNING closed_at",
+		models.StockCountFinalized, user, nullableID(count.AdjustmentID), id,
+	).Scan(&count.ClosedAt)
+	if err != nil {
+		return nil, err
+	}
+	count.Status, count.ClosedBy = models.StockCountFinalized, user
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return count, nil
+}
+
+// Cancel - tutup sesi tanpa mengubah stok
+func (repo *PostgresStockCountRepository) Cancel(id int, user string) error {
+	result, err := repo.db.Exec(
+		"UPDATE stock_counts SET status = $1, closed_at = NOW(), closed_by = $2 WHERE id = $3 AND status = $4",
+		models.StockCountCancelled, user, id, models.StockCountOpen,
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	var exists bool
+	if err := repo.db.QueryRow("SELECT EXISTS (SELECT 1 FROM stock_counts WHERE id = $1)", id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrStockCountNotFound
+	}
+	return ErrStockCountClosed
+}
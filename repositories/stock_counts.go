@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+
+	"kasir-api/models"
+)
+
+var (
+	ErrStockCountNotFound       = errors.New("sesi stock opname tidak ditemukan")
+	ErrStockCountClosed         = errors.New("sesi stock opname sudah ditutup")
+	ErrStockCountEmpty          = errors.New("tidak ada produk untuk stock opname")
+	ErrStockCountUnknownProduct = errors.New("produk tidak termasuk sesi stock opname")
+)
+
+// fillCountVariance - jumlahkan hasil hitung semua device lalu hitung selisihnya,
+// nilainya pakai HPP snapshot supaya sama dengan adjustment saat finalize
+func fillCountVariance(line *models.StockCountLine) {
+	line.CountedQuantity, line.Variance, line.VarianceValue = nil, 0, 0
+	if len(line.Entries) == 0 {
+		return
+	}
+	counted := 0
+	for _, e := range line.Entries {
+		counted += e.Quantity
+	}
+	line.CountedQuantity = &counted
+	line.Variance = counted - line.SystemStock
+	line.VarianceValue = line.Variance * line.UnitCost
+}
+
+// countAdjustment - adjustment untuk selisih produk yang sudah dihitung.
+// Selisih dihitung terhadap snapshot lalu diterapkan relatif ke stok saat ini,
+// jadi penjualan selama opname berlangsung tidak ikut terhapus.
+func countAdjustment(count *models.StockCount, user string) *models.StockAdjustment {
+	adj := &models.StockAdjustment{
+		LocationID: count.LocationID,
+		Reason:     models.AdjustmentCorrection,
+		Note:       fmt.Sprintf("stock opname #%d", count.ID),
+		User:       user,
+	}
+	for _, line := range count.Lines {
+		if line.CountedQuantity != nil && line.Variance != 0 {
+			adj.Items = append(adj.Items, models.StockAdjustmentItem{ProductID: line.ProductID, Quantity: line.Variance})
+		}
+	}
+	return adj
+}
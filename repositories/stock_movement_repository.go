@@ -0,0 +1,208 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+
+	"kasir-api/models"
+)
+
+// ErrInsufficientStock - perubahan stok akan membuat stok produk negatif
+var ErrInsufficientStock = errors.New("stok tidak mencukupi")
+
+type PostgresStockMovementRepository struct {
+	db *sql.DB
+}
+
+func NewStockMovementRepository(db *sql.DB) *PostgresStockMovementRepository {
+	return &PostgresStockMovementRepository{db: db}
+}
+
+// ListByProduct - ledger stok satu produk, urut dari yang paling lama
+func (repo *PostgresStockMovementRepository) ListByProduct(productID int) ([]models.StockMovement, error) {
+	rows, err := repo.db.Query(`
+		SELECT id, product_id, location_id, delta, stock_after, unit_cost, cost_amount, cost_method, reason, reference_type, COALESCE(reference_id, 0), created_by, note, created_at
+		FROM stock_movements
+		WHERE product_id = $1
+		ORDER BY id`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movements := make([]models.StockMovement, 0)
+	for rows.Next() {
+		var m models.StockMovement
+		if err := rows.Scan(&m.ID, &m.ProductID, &m.LocationID, &m.Delta, &m.StockAfter, &m.UnitCost, &m.CostAmount, &m.CostMethod, &m.Reason, &m.ReferenceType, &m.ReferenceID, &m.User, &m.Note, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		movements = append(movements, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	batches, err := loadMovementBatches(repo.db, productID)
+	if err != nil {
+		return nil, err
+	}
+	serials, err := loadMovementSerials(repo.db, productID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range movements {
+		movements[i].Batches = batches[movements[i].ID]
+		movements[i].Serials = serials[movements[i].ID]
+	}
+
+	return movements, nil
+}
+
+// moveStock - satu-satunya jalan mengubah stok. products.stock (total) dan
+// product_stocks (per lokasi) diubah relatif (stock + delta) lalu dicatat ke
+// stock_movements di transaksi DB yang sama. Delta negatif ditolak kalau stok di
+// lokasi tidak cukup. LocationID kosong = lokasi bawaan. ID, StockAfter dan
+// CreatedAt di m ikut diisi, begitu juga UnitCost dan CostAmount (lihat
+// costStockMovement). m.CostMethod menentukan HPP stok keluar, m.Batches lot
+// yang dipakai (lihat moveBatches), m.Serials unit yang ikut (lihat moveSerials).
+func moveStock(tx *sql.Tx, m *models.StockMovement) error {
+	return applyStockMovement(tx, m, false)
+}
+
+// applyStockMovement - moveStock dengan pilihan mengizinkan stok negatif
+func applyStockMovement(tx *sql.Tx, m *models.StockMovement, allowNegative bool) error {
+	if m.LocationID == 0 {
+		m.LocationID = models.DefaultLocationID
+	}
+
+	// baris products dikunci duluan, jadi urutan lock tetap per product id
+	// apa pun lokasinya. Untuk HPP stok masuk, stockBefore ditambah quantity
+	// dalam perjalanan (lihat costedMovement).
+	var stockBefore, averageCost int
+	var trackSerial bool
+	err := tx.QueryRow(
+		"UPDATE products SET stock = stock + $1 WHERE id = $2 RETURNING stock - $1, cost_price, track_serial",
+		m.Delta, m.ProductID,
+	).Scan(&stockBefore, &averageCost, &trackSerial)
+	if err == sql.ErrNoRows {
+		return ErrProductNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO product_stocks (product_id, location_id, stock)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (product_id, location_id) DO UPDATE SET stock = product_stocks.stock + EXCLUDED.stock
+		RETURNING stock`,
+		m.ProductID, m.LocationID, m.Delta,
+	).Scan(&m.StockAfter)
+	if isForeignKeyViolation(err) {
+		return ErrLocationNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if m.Delta < 0 && !allowNegative && m.StockAfter < 0 {
+		return ErrInsufficientStock
+	}
+	if err := moveBatches(tx, m); err != nil {
+		return err
+	}
+	if err := moveSerials(tx, m, trackSerial); err != nil {
+		return err
+	}
+
+	if costedMovement(m) && m.Delta > 0 {
+		inTransit, err := productInTransit(tx, m.ProductID)
+		if err != nil {
+			return err
+		}
+		stockBefore += inTransit
+	}
+	if costedMovement(m) {
+		if err := costStockMovement(tx, m, stockBefore, averageCost); err != nil {
+			return err
+		}
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO stock_movements (product_id, location_id, delta, stock_after, unit_cost, cost_amount, cost_method, reason, reference_type, reference_id, created_by, note)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, created_at`,
+		m.ProductID, m.LocationID, m.Delta, m.StockAfter, m.UnitCost, m.CostAmount, m.CostMethod, m.Reason, m.ReferenceType, nullableID(m.ReferenceID), m.User, m.Note,
+	).Scan(&m.ID, &m.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if err := insertBatchMovements(tx, m); err != nil {
+		return err
+	}
+	if err := insertSerialMovements(tx, m); err != nil {
+		return err
+	}
+
+	if costedMovement(m) && m.Delta > 0 {
+		_, err = tx.Exec(
+			"INSERT INTO cost_layers (product_id, movement_id, unit_cost, quantity, remaining) VALUES ($1, $2, $3, $4, $5)",
+			m.ProductID, m.ID, m.UnitCost, m.Delta, layerRemaining(stockBefore, m.Delta),
+		)
+	}
+	return err
+}
+
+// costStockMovement - stok masuk menambah layer (disisipkan setelah movement
+// tersimpan) dan memperbarui cost_price rata-rata. Stok keluar mengambil layer
+// urut FIFO, HPP-nya sesuai m.CostMethod.
+func costStockMovement(tx *sql.Tx, m *models.StockMovement, stockBefore, averageCost int) error {
+	if m.Delta > 0 {
+		unitCost := inboundUnitCost(m, averageCost)
+		m.CostMethod = ""
+		setMovementCost(m, m.Delta*unitCost)
+		_, err := tx.Exec(
+			"UPDATE products SET cost_price = $1 WHERE id = $2",
+			weightedCost(stockBefore, averageCost, m.Delta, unitCost), m.ProductID,
+		)
+		return err
+	}
+	if m.Delta == 0 {
+		return nil
+	}
+
+	rows, err := tx.Query(
+		"SELECT id, unit_cost, remaining FROM cost_layers WHERE product_id = $1 AND remaining > 0 ORDER BY id FOR UPDATE",
+		m.ProductID,
+	)
+	if err != nil {
+		return err
+	}
+	layers := make([]costLayer, 0)
+	for rows.Next() {
+		var l costLayer
+		if err := rows.Scan(&l.id, &l.unitCost, &l.remaining); err != nil {
+			rows.Close()
+			return err
+		}
+		layers = append(layers, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	taken, fifoCost, shortfall := consumeLayers(layers, -m.Delta)
+	for i, n := range taken {
+		if n == 0 {
+			continue
+		}
+		if _, err := tx.Exec("UPDATE cost_layers SET remaining = remaining - $1 WHERE id = $2", n, layers[i].id); err != nil {
+			return err
+		}
+	}
+
+	method := costingMethodOrDefault(m.CostMethod)
+	m.CostMethod = string(method)
+	setMovementCost(m, outboundCost(method, -m.Delta, fifoCost, shortfall, averageCost))
+	return nil
+}
@@ -0,0 +1,247 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"kasir-api/models"
+)
+
+type PostgresStockTransferRepository struct {
+	db *sql.DB
+}
+
+func NewStockTransferRepository(db *sql.DB) *PostgresStockTransferRepository {
+	return &PostgresStockTransferRepository{db: db}
+}
+
+const stockTransferColumns = `t.id, t.from_location_id, fl.name, t.to_location_id, tl.name, t.status, t.note,
+	t.created_by, t.created_at, t.shipped_by, t.shipped_at, t.received_by, t.received_at`
+
+const stockTransferFrom = `
+	FROM stock_transfers t
+	JOIN locations fl ON fl.id = t.from_location_id
+	JOIN locations tl ON tl.id = t.to_location_id`
+
+func scanStockTransfer(row interface{ Scan(...any) error }, t *models.StockTransfer) error {
+	return row.Scan(
+		&t.ID, &t.FromLocationID, &t.FromLocationName, &t.ToLocationID, &t.ToLocationName, &t.Status, &t.Note,
+		&t.CreatedBy, &t.CreatedAt, &t.ShippedBy, &t.ShippedAt, &t.ReceivedBy, &t.ReceivedAt,
+	)
+}
+
+func (repo *PostgresStockTransferRepository) GetAll(filter models.StockTransferFilter) ([]models.StockTransfer, error) {
+	rows, err := repo.db.Query(`
+		SELECT `+stockTransferColumns+stockTransferFrom+`
+		WHERE ($1 = '' OR t.status = $1) AND ($2 = 0 OR t.from_location_id = $2 OR t.to_location_id = $2)
+		ORDER BY t.id DESC`, filter.Status, filter.LocationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transfers := make([]models.StockTransfer, 0)
+	for rows.Next() {
+		var t models.StockTransfer
+		if err := scanStockTransfer(rows, &t); err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, t)
+	}
+
+	return transfers, rows.Err()
+}
+
+// Create - simpan dokumen berstatus requested, stok belum berubah
+func (repo *PostgresStockTransferRepository) Create(transfer *models.StockTransfer) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkLocationExists(tx, transfer.FromLocationID); err != nil {
+		return err
+	}
+	if err := checkLocationExists(tx, transfer.ToLocationID); err != nil {
+		return err
+	}
+
+	var id int
+	err = tx.QueryRow(
+		"INSERT INTO stock_transfers (from_location_id, to_location_id, note, created_by) VALUES ($1, $2, $3, $4) RETURNING id",
+		transfer.FromLocationID, transfer.ToLocationID, transfer.Note, transfer.CreatedBy,
+	).Scan(&id)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range transfer.Lines {
+		var name string
+		err := tx.QueryRow("SELECT name FROM products WHERE id = $1", line.ProductID).Scan(&name)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w (product id %d)", ErrProductNotFound, line.ProductID)
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			"INSERT INTO stock_transfer_lines (transfer_id, product_id, product_name, quantity) VALUES ($1, $2, $3, $4)",
+			id, line.ProductID, name, line.Quantity,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	created, err := loadStockTransfer(tx, id, false)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	*transfer = *created
+	return nil
+}
+
+func (repo *PostgresStockTransferRepository) GetByID(id int) (*models.StockTransfer, error) {
+	return loadStockTransfer(repo.db, id, false)
+}
+
+// loadStockTransfer - header + baris transfer. forUpdate mengunci header.
+func loadStockTransfer(q queryer, id int, forUpdate bool) (*models.StockTransfer, error) {
+	query := "SELECT " + stockTransferColumns + stockTransferFrom + " WHERE t.id = $1"
+	if forUpdate {
+		query += " FOR UPDATE OF t"
+	}
+
+	var t models.StockTransfer
+	err := scanStockTransfer(q.QueryRow(query, id), &t)
+	if err == sql.ErrNoRows {
+		return nil, ErrStockTransferNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.Query(`
+		SELECT id, product_id, product_name, quantity
+		FROM stock_transfer_lines
+		WHERE transfer_id = $1
+		ORDER BY id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line models.StockTransferLine
+		if err := rows.Scan(&line.ID, &line.ProductID, &line.ProductName, &line.Quantity); err != nil {
+			return nil, err
+		}
+		t.Lines = append(t.Lines, line)
+	}
+
+	return &t, rows.Err()
+}
+
+// Ship - requested -> shipped, stok lokasi asal berkurang dan barang dianggap
+// dalam perjalanan sampai diterima
+func (repo *PostgresStockTransferRepository) Ship(id int, user string) (*models.StockTransfer, error) {
+	return repo.advance(id, user, models.TransferRequested, models.TransferShipped)
+}
+
+// Receive - shipped -> received, stok lokasi tujuan bertambah
+func (repo *PostgresStockTransferRepository) Receive(id int, user string) (*models.StockTransfer, error) {
+	return repo.advance(id, user, models.TransferShipped, models.TransferReceived)
+}
+
+// Cancel - hanya transfer yang belum dikirim, stok tidak berubah
+func (repo *PostgresStockTransferRepository) Cancel(id int, user string) (*models.StockTransfer, error) {
+	return repo.advance(id, user, models.TransferRequested, models.TransferCancelled)
+}
+
+func (repo *PostgresStockTransferRepository) advance(id int, user, from, to string) (*models.StockTransfer, error) {
+	var transfer *models.StockTransfer
+	err := withRetry(func() error {
+		var err error
+		transfer, err = repo.advanceOnce(id, user, from, to)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+func (repo *PostgresStockTransferRepository) advanceOnce(id int, user, from, to string) (*models.StockTransfer, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	t, err := loadStockTransfer(tx, id, true)
+	if err != nil {
+		return nil, err
+	}
+	if t.Status != from {
+		return nil, ErrStockTransferStatus
+	}
+
+	if to != models.TransferCancelled {
+		for _, m := range transferMovements(t, to == models.TransferReceived, user) {
+			// lot yang dikirim ikut pindah ke lokasi tujuan
+			if m.Delta > 0 {
+				if m.Batches, err = shippedLots(tx, t.ID, m.ProductID); err != nil {
+					return nil, err
+				}
+			}
+			if err := moveStock(tx, &m); err != nil {
+				return nil, transferStockError(err, m.ProductID)
+			}
+		}
+	}
+
+	now := time.Now()
+	switch to {
+	case models.TransferShipped:
+		_, err = tx.Exec("UPDATE stock_transfers SET status = $1, shipped_at = $2, shipped_by = $3 WHERE id = $4", to, now, user, id)
+		t.ShippedAt, t.ShippedBy = &now, user
+	case models.TransferReceived:
+		_, err = tx.Exec("UPDATE stock_transfers SET status = $1, received_at = $2, received_by = $3 WHERE id = $4", to, now, user, id)
+		t.ReceivedAt, t.ReceivedBy = &now, user
+	default:
+		_, err = tx.Exec("UPDATE stock_transfers SET status = $1 WHERE id = $2", to, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.Status = to
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// productInTransit - quantity produk di transfer yang sudah dikirim tapi belum
+// diterima. Dibaca setelah baris products dikunci; pengiriman dan penerimaan
+// transfer juga mengunci baris itu lewat moveStock.
+func productInTransit(q queryer, productID int) (int, error) {
+	var quantity int
+	err := q.QueryRow(`
+		SELECT COALESCE(SUM(tl.quantity), 0)
+		FROM stock_transfer_lines tl
+		JOIN stock_transfers t ON t.id = tl.transfer_id
+		WHERE tl.product_id = $1 AND t.status = $2`,
+		productID, models.TransferShipped,
+	).Scan(&quantity)
+	return quantity, err
+}
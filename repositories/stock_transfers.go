@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"kasir-api/models"
+)
+
+var (
+	ErrStockTransferNotFound = errors.New("transfer stok tidak ditemukan")
+	ErrStockTransferStatus   = errors.New("status transfer stok tidak mengizinkan aksi ini")
+)
+
+// transferMovements - satu movement per baris, urut product id (urutan lock).
+// Saat shipped stok keluar dari lokasi asal, saat received masuk ke lokasi tujuan.
+func transferMovements(t *models.StockTransfer, receiving bool, user string) []models.StockMovement {
+	movements := make([]models.StockMovement, 0, len(t.Lines))
+	for _, line := range t.Lines {
+		m := models.StockMovement{
+			ProductID:     line.ProductID,
+			LocationID:    t.FromLocationID,
+			Delta:         -line.Quantity,
+			Reason:        models.StockReasonTransfer,
+			ReferenceType: models.StockRefTransfer,
+			ReferenceID:   t.ID,
+			User:          user,
+			Note:          fmt.Sprintf("transfer #%d ke %s", t.ID, t.ToLocationName),
+		}
+		if receiving {
+			m.LocationID, m.Delta = t.ToLocationID, line.Quantity
+			m.Note = fmt.Sprintf("transfer #%d dari %s", t.ID, t.FromLocationName)
+		}
+		movements = append(movements, m)
+	}
+	sort.Slice(movements, func(i, j int) bool { return movements[i].ProductID < movements[j].ProductID })
+	return movements
+}
+
+// transferStockError - sebutkan produk yang stoknya kurang di lokasi asal
+func transferStockError(err error, productID int) error {
+	if errors.Is(err, ErrInsufficientStock) {
+		return fmt.Errorf("%w di lokasi asal (product id %d)", err, productID)
+	}
+	return err
+}
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"kasir-api/models"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrSupplierNotFound = errors.New("supplier tidak ditemukan")
+	ErrSupplierInUse    = errors.New("supplier masih dipakai purchase order")
+)
+
+type PostgresSupplierRepository struct {
+	db *sql.DB
+}
+
+func NewSupplierRepository(db *sql.DB) *PostgresSupplierRepository {
+	return &PostgresSupplierRepository{db: db}
+}
+
+func (repo *PostgresSupplierRepository) GetAll() ([]models.Supplier, error) {
+	rows, err := repo.db.Query("SELECT id, name, phone, email, address, lead_time_days FROM suppliers ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suppliers := make([]models.Supplier, 0)
+	for rows.Next() {
+		var s models.Supplier
+		if err := rows.Scan(&s.ID, &s.Name, &s.Phone, &s.Email, &s.Address, &s.LeadTimeDays); err != nil {
+			return nil, err
+		}
+		suppliers = append(suppliers, s)
+	}
+
+	return suppliers, rows.Err()
+}
+
+func (repo *PostgresSupplierRepository) Create(supplier *models.Supplier) error {
+	return repo.db.QueryRow(
+		"INSERT INTO suppliers (name, phone, email, address, lead_time_days) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+		supplier.Name, supplier.Phone, supplier.Email, supplier.Address, supplier.LeadTimeDays,
+	).Scan(&supplier.ID)
+}
+
+func (repo *PostgresSupplierRepository) GetByID(id int) (*models.Supplier, error) {
+	var s models.Supplier
+	err := repo.db.QueryRow("SELECT id, name, phone, email, address, lead_time_days FROM suppliers WHERE id = $1", id).
+		Scan(&s.ID, &s.Name, &s.Phone, &s.Email, &s.Address, &s.LeadTimeDays)
+	if err == sql.ErrNoRows {
+		return nil, ErrSupplierNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func (repo *PostgresSupplierRepository) Update(supplier *models.Supplier) error {
+	result, err := repo.db.Exec(
+		"UPDATE suppliers SET name = $1, phone = $2, email = $3, address = $4, lead_time_days = $5 WHERE id = $6",
+		supplier.Name, supplier.Phone, supplier.Email, supplier.Address, supplier.LeadTimeDays, supplier.ID,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrSupplierNotFound
+	}
+
+	return nil
+}
+
+// Delete - supplier yang sudah punya purchase order tidak bisa dihapus
+func (repo *PostgresSupplierRepository) Delete(id int) error {
+	result, err := repo.db.Exec("DELETE FROM suppliers WHERE id = $1", id)
+	if isForeignKeyViolation(err) {
+		return ErrSupplierInUse
+	}
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrSupplierNotFound
+	}
+
+	return nil
+}
+
+// isForeignKeyViolation - foreign_key_violation (23503)
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"kasir-api/models"
+)
+
+var ErrTaxRateNotFound = errors.New("tarif pajak tidak ditemukan")
+
+type PostgresTaxRateRepository struct {
+	db *sql.DB
+}
+
+func NewTaxRateRepository(db *sql.DB) *PostgresTaxRateRepository {
+	return &PostgresTaxRateRepository{db: db}
+}
+
+func (repo *PostgresTaxRateRepository) GetAll() ([]models.TaxRate, error) {
+	rows, err := repo.db.Query("SELECT id, name, rate FROM tax_rates ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := make([]models.TaxRate, 0)
+	for rows.Next() {
+		var t models.TaxRate
+		if err := rows.Scan(&t.ID, &t.Name, &t.Rate); err != nil {
+			return nil, err
+		}
+		rates = append(rates, t)
+	}
+
+	return rates, rows.Err()
+}
+
+func (repo *PostgresTaxRateRepository) Create(rate *models.TaxRate) error {
+	return repo.db.QueryRow(
+		"INSERT INTO tax_rates (name, rate) VALUES ($1, $2) RETURNING id",
+		rate.Name, rate.Rate,
+	).Scan(&rate.ID)
+}
+
+func (repo *PostgresTaxRateRepository) GetByID(id int) (*models.TaxRate, error) {
+	var t models.TaxRate
+	err := repo.db.QueryRow("SELECT id, name, rate FROM tax_rates WHERE id = $1", id).Scan(&t.ID, &t.Name, &t.Rate)
+	if err == sql.ErrNoRows {
+		return nil, ErrTaxRateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// Update - transaksi lama tidak berubah karena tarif di-snapshot di transaction_details
+func (repo *PostgresTaxRateRepository) Update(rate *models.TaxRate) error {
+	result, err := repo.db.Exec("UPDATE tax_rates SET name = $1, rate = $2 WHERE id = $3", rate.Name, rate.Rate, rate.ID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrTaxRateNotFound
+	}
+
+	return nil
+}
+
+func (repo *PostgresTaxRateRepository) Delete(id int) error {
+	result, err := repo.db.Exec("DELETE FROM tax_rates WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrTaxRateNotFound
+	}
+
+	return nil
+}
+
+// nullableID - id 0 disimpan sebagai NULL untuk kolom foreign key opsional
+func nullableID(id int) *int {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
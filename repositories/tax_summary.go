@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"sort"
+
+	"kasir-api/models"
+)
+
+// nonTaxableName - label baris tanpa tarif pajak di rekap pajak
+const nonTaxableName = "Tidak kena pajak"
+
+type taxSummaryKey struct {
+	id   int
+	name string
+	rate float64
+}
+
+// taxSummaryBuilder - kumpulkan DPP / pajak penjualan dan retur per tarif.
+// Kuncinya snapshot tarif di transaction_details, jadi perubahan tarif
+// muncul sebagai baris terpisah.
+type taxSummaryBuilder map[taxSummaryKey]*models.TaxSummaryLine
+
+func (b taxSummaryBuilder) line(id int, name string, rate float64) *models.TaxSummaryLine {
+	key := taxSummaryKey{id: id, name: name, rate: rate}
+	line, ok := b[key]
+	if !ok {
+		if name == "" && rate == 0 {
+			name = nonTaxableName
+		}
+		line = &models.TaxSummaryLine{TaxRateID: id, Name: name, Rate: rate}
+		b[key] = line
+	}
+	return line
+}
+
+func (b taxSummaryBuilder) report(startDate, endDate string) *models.TaxSummaryReport {
+	report := &models.TaxSummaryReport{StartDate: startDate, EndDate: endDate, Rates: make([]models.TaxSummaryLine, 0, len(b))}
+	for _, line := range b {
+		line.NetTaxBase = line.TaxBase + line.ReturnTaxBase
+		line.NetTaxAmount = line.TaxAmount + line.ReturnTaxAmount
+		report.TotalTaxBase += line.NetTaxBase
+		report.TotalTaxAmount += line.NetTaxAmount
+		report.Rates = append(report.Rates, *line)
+	}
+
+	sort.Slice(report.Rates, func(i, j int) bool {
+		a, c := report.Rates[i], report.Rates[j]
+		if a.Rate != c.Rate {
+			return a.Rate > c.Rate
+		}
+		if a.TaxRateID != c.TaxRateID {
+			return a.TaxRateID < c.TaxRateID
+		}
+		return a.Name < c.Name
+	})
+
+	return report
+}
@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"kasir-api/models"
+)
+
+func TestPageOffset(t *testing.T) {
+	for _, f := range []models.TransactionFilter{{Page: 0, Limit: 20}, {Page: -3, Limit: 20}, {Page: 1, Limit: 0}} {
+		if _, err := pageOffset(f); !errors.Is(err, ErrInvalidPage) {
+			t.Errorf("pageOffset(%+v) err = %v, want ErrInvalidPage", f, err)
+		}
+	}
+	if got, _ := pageOffset(models.TransactionFilter{Page: 3, Limit: 20}); got != 40 {
+		t.Errorf("page 3 offset = %d, want 40", got)
+	}
+	if got, _ := pageOffset(models.TransactionFilter{Page: math.MaxInt, Limit: 100}); got != math.MaxInt {
+		t.Errorf("overflowing offset = %d, want MaxInt", got)
+	}
+}
+
+func TestMemoryListTransactionsInvalidPage(t *testing.T) {
+	repo := NewMemoryTransactionRepository(NewMemoryStore())
+
+	if _, err := repo.ListTransactions(models.TransactionFilter{Page: 0, Limit: 20}); !errors.Is(err, ErrInvalidPage) {
+		t.Errorf("page 0: err = %v, want ErrInvalidPage", err)
+	}
+	list, err := repo.ListTransactions(models.TransactionFilter{Page: math.MaxInt, Limit: 100})
+	if err != nil || len(list.Data) != 0 {
+		t.Errorf("huge page = %+v, %v, want empty list", list, err)
+	}
+}
@@ -2,124 +2,341 @@ package repositories
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"kasir-api/models"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
 )
 
-type TransactionRepository struct {
+type PostgresTransactionRepository struct {
 	db *sql.DB
 }
 
-func NewTransactionRepository(db *sql.DB) *TransactionRepository {
-	return &TransactionRepository{db: db}
+func NewTransactionRepository(db *sql.DB) *PostgresTransactionRepository {
+	return &PostgresTransactionRepository{db: db}
+}
+
+func (repo *PostgresTransactionRepository) CreateTransaction(req models.CheckoutRequest, opts CheckoutOptions) (*models.Transaction, error) {
+	if err := opts.Strategy.validate(); err != nil {
+		return nil, err
+	}
+	if err := validateCheckoutItems(req.Items); err != nil {
+		return nil, err
+	}
+
+	var transaction *models.Transaction
+	err := withRetry(func() error {
+		var err error
+		transaction, err = repo.createTransaction(req, opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
 }
 
-func (repo *TransactionRepository) CreateTransaction(items []models.CheckoutItem) (*models.Transaction, error) {
+func (repo *PostgresTransactionRepository) createTransaction(req models.CheckoutRequest, opts CheckoutOptions) (*models.Transaction, error) {
+	items, strategy := req.Items, opts.Strategy
+
 	tx, err := repo.db.Begin()
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback()
 
+	if err := checkLocationExists(tx, req.LocationID); err != nil {
+		return nil, err
+	}
+	err = resolveCheckoutCodes(items, func(barcode, sku string) (int, error) {
+		return findProductByCode(tx, barcode, sku)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// pessimistic: semua baris produk dikunci sekaligus, urut id
+	// optimistic: baca biasa, pengecekan stok terjadi di UPDATE bersyarat
+	ids := checkoutProductIDs(items)
+	products, err := loadCheckoutProducts(tx, ids, req.LocationID, strategy == LockPessimistic)
+	if err != nil {
+		return nil, err
+	}
+
 	totalAmount := 0
 	details := make([]models.TransactionDetail, 0)
 
 	for _, item := range items {
-		var productPrice, stock int
-		var productName string
-
-		err := tx.QueryRow("SELECT name, price, stock FROM products WHERE id = $1", item.ProductID).Scan(&productName, &productPrice, &stock)
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("product id %d not found", item.ProductID)
+		id := itemProductID(item)
+		product, ok := products[id]
+		if !ok {
+			return nil, fmt.Errorf("%w (product id %d)", ErrProductNotFound, id)
 		}
-		if err != nil {
+		if err := checkVariantItem(item, product.name, product.parentID, product.hasVariants); err != nil {
 			return nil, err
 		}
 
-		if stock < item.Quantity {
-			return nil, fmt.Errorf("insufficient stock for product %s", productName)
+		subtotal := product.price * item.Quantity
+		totalAmount += subtotal
+
+		details = append(details, models.TransactionDetail{
+			ProductID:   id,
+			ProductName: product.name,
+			CategoryID:  product.categoryID,
+			UnitPrice:   product.price,
+			UnitCost:    product.cost,
+			TaxRateID:   product.taxRateID,
+			TaxName:     product.taxName,
+			TaxRate:     product.taxRate,
+			Quantity:    item.Quantity,
+			Subtotal:    subtotal,
+			Serials:     item.Serials,
+		})
+	}
+
+	// cek awal supaya stok kurang dilaporkan sebelum error pembayaran / promo.
+	// Pada optimistic ini hanya snapshot, pengecekan final ada di moveStock.
+	quantities := checkoutQuantities(items)
+	for _, id := range ids {
+		if products[id].stock < quantities[id] {
+			return nil, checkoutStockError(products[id].name)
 		}
+	}
 
-		subtotal := productPrice * item.Quantity
-		totalAmount += subtotal
+	trx := &models.Transaction{
+		LocationID:  req.LocationID,
+		GrossAmount: totalAmount,
+		TotalAmount: totalAmount,
+		Details:     details,
+		Payments:    append([]models.Payment(nil), req.Payments...),
+	}
+	if opts.Finalize != nil {
+		if err := opts.Finalize(trx); err != nil {
+			return nil, err
+		}
+	}
 
-		_, err = tx.Exec("UPDATE products SET stock = stock - $1 WHERE id = $2", item.Quantity, item.ProductID)
+	// kuota promo dicek ulang secara atomic, validasi di service bisa saja sudah basi
+	if trx.PromotionID != 0 {
+		result, err := tx.Exec(
+			"UPDATE promotions SET used_count = used_count + 1 WHERE id = $1 AND (usage_limit = 0 OR used_count < usage_limit)",
+			trx.PromotionID,
+		)
+		if err != nil {
+			return nil, err
+		}
+		affected, err := result.RowsAffected()
 		if err != nil {
 			return nil, err
 		}
+		if affected == 0 {
+			return nil, ErrPromotionExhausted
+		}
+	}
 
-		details = append(details, models.TransactionDetail{
-			ProductID:   item.ProductID,
-			ProductName: productName,
-			Quantity:    item.Quantity,
-			Subtotal:    subtotal,
-		})
+	if err := insertTransaction(tx, trx); err != nil {
+		return nil, err
 	}
 
-	var transactionID int
-	err = tx.QueryRow("INSERT INTO transactions (total_amount) VALUES ($1) RETURNING id", totalAmount).Scan(&transactionID)
+	// stok dikurangi urut product id lewat ledger, reference ke transaksi yang baru dibuat
+	serials := checkoutSerials(items)
+	for _, id := range ids {
+		m := models.StockMovement{
+			ProductID:     id,
+			LocationID:    req.LocationID,
+			Delta:         -quantities[id],
+			Reason:        models.StockReasonSale,
+			ReferenceType: models.StockRefTransaction,
+			ReferenceID:   trx.ID,
+			User:          req.User,
+			CostMethod:    string(opts.Costing),
+			Serials:       serials[id],
+		}
+		err := moveStock(tx, &m)
+		if errors.Is(err, ErrInsufficientStock) {
+			return nil, checkoutStockError(products[id].name)
+		}
+		if errors.Is(err, ErrBatchExpired) {
+			return nil, fmt.Errorf("%w: product %s", ErrBatchExpired, products[id].name)
+		}
+		if err != nil {
+			return nil, checkoutSerialError(err, products[id].name)
+		}
+
+		// HPP final dari ledger (FIFO / average), snapshot di detail ikut diganti
+		setDetailUnitCost(trx.Details, id, m.UnitCost)
+		if _, err := tx.Exec(
+			"UPDATE transaction_details SET unit_cost = $1 WHERE transaction_id = $2 AND product_id = $3",
+			m.UnitCost, trx.ID, id,
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	trx.LowStock, err = loadLowStockEvents(tx, trx, ids, quantities)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(details) > 0 {
-		query := "INSERT INTO transaction_details (transaction_id, product_id, quantity, subtotal) VALUES "
+	// response idempotency ikut commit bersama transaksinya
+	if opts.IdempotencyKey != "" {
+		if err := completeIdempotencyKey(tx, opts.IdempotencyScope, opts.IdempotencyKey, trx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return trx, nil
+}
+
+// insertTransaction - simpan header, details, rincian promo otomatis dan pembayaran.
+// ID dan TransactionID di trx ikut diisi.
+func insertTransaction(tx *sql.Tx, trx *models.Transaction) error {
+	promotionID := nullableID(trx.PromotionID)
+	err := tx.QueryRow(`
+		INSERT INTO transactions (location_id, gross_amount, line_discount, rule_discount, order_discount, promo_code, promotion_id, promo_discount,
+			tax_inclusive, tax_base, tax_amount, total_amount, paid_amount, change_amount)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id, created_at`,
+		trx.LocationID, trx.GrossAmount, trx.LineDiscount, trx.RuleDiscount, trx.OrderDiscount, trx.PromoCode, promotionID, trx.PromoDiscount,
+		trx.TaxInclusive, trx.TaxBase, trx.TaxAmount, trx.TotalAmount, trx.PaidAmount, trx.Change,
+	).Scan(&trx.ID, &trx.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	if len(trx.Details) > 0 {
+		query := `INSERT INTO transaction_details (transaction_id, product_id, product_name, unit_price, unit_cost, quantity,
+			line_discount, rule_discount, order_discount, tax_rate_id, tax_name, tax_rate, tax_base, tax_amount, subtotal, serials) VALUES `
 		var args []interface{}
 
-		for i := range details {
-			details[i].TransactionID = transactionID
-			base := i * 4
-			query += fmt.Sprintf("($%d, $%d, $%d, $%d),", base+1, base+2, base+3, base+4)
-			args = append(args, transactionID, details[i].ProductID, details[i].Quantity, details[i].Subtotal)
+		for i := range trx.Details {
+			d := &trx.Details[i]
+			d.TransactionID = trx.ID
+			row := []interface{}{
+				trx.ID, d.ProductID, d.ProductName, d.UnitPrice, d.UnitCost, d.Quantity,
+				d.LineDiscount, d.RuleDiscount, d.OrderDiscount, nullableID(d.TaxRateID), d.TaxName, d.TaxRate, d.TaxBase, d.TaxAmount, d.Subtotal,
+				pq.Array(d.Serials),
+			}
+			placeholders := make([]string, len(row))
+			for j := range row {
+				placeholders[j] = fmt.Sprintf("$%d", len(args)+j+1)
+			}
+			query += "(" + strings.Join(placeholders, ", ") + "),"
+			args = append(args, row...)
 		}
 
-		query = query[:len(query)-1]
+		query = query[:len(query)-1] + " RETURNING id"
 
-		_, err = tx.Exec(query, args...)
+		rows, err := tx.Query(query, args...)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		for i := 0; rows.Next(); i++ {
+			if err := rows.Scan(&trx.Details[i].ID); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
-		return nil, err
+	for _, d := range trx.Details {
+		for _, p := range d.Promotions {
+			_, err := tx.Exec(`
+				INSERT INTO transaction_promotions (transaction_id, transaction_detail_id, rule_id, rule_name, rule_type, quantity, discount)
+				VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+				trx.ID, d.ID, nullableID(p.RuleID), p.Name, p.Type, p.Quantity, p.Discount,
+			)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
-	return &models.Transaction{
-		ID:          transactionID,
-		TotalAmount: totalAmount,
-		Details:     details,
-	}, nil
+	for i := range trx.Payments {
+		p := &trx.Payments[i]
+		p.TransactionID = trx.ID
+		err := tx.QueryRow(
+			"INSERT INTO payments (transaction_id, method, amount, reference) VALUES ($1, $2, $3, $4) RETURNING id",
+			trx.ID, p.Method, p.Amount, p.Reference,
+		).Scan(&p.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // SALES REPORT
 
 // Tambahkan method ini di struct TransactionRepository
 
-func (repo *TransactionRepository) GetSalesReport(startDate, endDate string) (*models.SalesReport, error) {
+func (repo *PostgresTransactionRepository) GetSalesReport(startDate, endDate string) (*models.SalesReport, error) {
 	report := &models.SalesReport{}
 
 	queryStat := `
 		SELECT 
 			COALESCE(SUM(total_amount), 0), 
+			COALESCE(SUM(line_discount + rule_discount + order_discount + promo_discount), 0),
+			COALESCE(SUM(tax_amount), 0),
 			COUNT(id) 
 		FROM transactions 
-		WHERE created_at >= $1 AND created_at <= $2`
+		WHERE created_at >= $1 AND created_at <= $2 AND voided_at IS NULL`
 
-	err := repo.db.QueryRow(queryStat, startDate, endDate).Scan(&report.TotalRevenue, &report.TotalTransaction)
+	err := repo.db.QueryRow(queryStat, startDate, endDate).Scan(&report.GrossRevenue, &report.TotalDiscount, &report.TotalTax, &report.TotalTransaction)
 	if err != nil {
 		return nil, err
 	}
 
+	// retur dihitung di periode tanggal returnya, bukan tanggal transaksi asal
+	queryReturns := `
+		SELECT COALESCE(SUM(r.total_amount), 0), COALESCE((
+			SELECT SUM(ri.tax_amount)
+			FROM return_items ri
+			JOIN returns rr ON ri.return_id = rr.id
+			WHERE rr.created_at >= $1 AND rr.created_at <= $2
+		), 0)
+		FROM returns r
+		WHERE r.created_at >= $1 AND r.created_at <= $2`
+
+	var returnTax int
+	err = repo.db.QueryRow(queryReturns, startDate, endDate).Scan(&report.TotalReturns, &returnTax)
+	if err != nil {
+		return nil, err
+	}
+	report.TotalRevenue = report.GrossRevenue + report.TotalReturns
+	report.TotalTax += returnTax
+
 	queryTop := `
 		SELECT 
-			p.name, 
-			SUM(td.quantity) as total_qty
-		FROM transaction_details td
-		JOIN transactions t ON td.transaction_id = t.id
-		JOIN products p ON td.product_id = p.id
-		WHERE t.created_at >= $1 AND t.created_at <= $2
-		GROUP BY p.name
+			name, 
+			SUM(qty) as total_qty
+		FROM (
+			SELECT td.product_name AS name, td.quantity AS qty
+			FROM transaction_details td
+			JOIN transactions t ON td.transaction_id = t.id
+			WHERE t.created_at >= $1 AND t.created_at <= $2 AND t.voided_at IS NULL
+			UNION ALL
+			SELECT ri.product_name AS name, -ri.quantity AS qty
+			FROM return_items ri
+			JOIN returns r ON ri.return_id = r.id
+			WHERE r.created_at >= $1 AND r.created_at <= $2
+		) sold
+		GROUP BY name
+		HAVING SUM(qty) > 0
 		ORDER BY total_qty DESC
 		LIMIT 1`
 
@@ -131,5 +348,489 @@ func (repo *TransactionRepository) GetSalesReport(startDate, endDate string) (*m
 		return nil, err
 	}
 
+	queryPayments := `
+		SELECT
+			p.method,
+			SUM(p.amount),
+			COUNT(DISTINCT p.transaction_id)
+		FROM payments p
+		JOIN transactions t ON p.transaction_id = t.id
+		WHERE t.created_at >= $1 AND t.created_at <= $2 AND t.voided_at IS NULL
+		GROUP BY p.method`
+
+	rows, err := repo.db.Query(queryPayments, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byMethod := make(map[string]*models.PaymentSummary)
+	for rows.Next() {
+		var summary models.PaymentSummary
+		if err := rows.Scan(&summary.Method, &summary.Total, &summary.TotalTransaction); err != nil {
+			return nil, err
+		}
+		byMethod[summary.Method] = &summary
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var changeTotal int
+	queryChange := `
+		SELECT COALESCE(SUM(change_amount), 0)
+		FROM transactions
+		WHERE created_at >= $1 AND created_at <= $2 AND voided_at IS NULL`
+	if err := repo.db.QueryRow(queryChange, startDate, endDate).Scan(&changeTotal); err != nil {
+		return nil, err
+	}
+	report.PaymentBreakdown = paymentBreakdown(byMethod, changeTotal)
+
+	profit, err := repo.GetProfitReport(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	fillSalesProfit(report, profit)
+
 	return report, nil
 }
+
+// GetTaxSummary - rekap DPP dan pajak per tarif untuk pelaporan. Penjualan dihitung
+// per tanggal transaksi (tanpa void), retur per tanggal retur.
+func (repo *PostgresTransactionRepository) GetTaxSummary(startDate, endDate string) (*models.TaxSummaryReport, error) {
+	summary := make(taxSummaryBuilder)
+
+	querySales := `
+		SELECT COALESCE(td.tax_rate_id, 0), td.tax_name, td.tax_rate, SUM(td.tax_base), SUM(td.tax_amount)
+		FROM transaction_details td
+		JOIN transactions t ON td.transaction_id = t.id
+		WHERE t.created_at >= $1 AND t.created_at <= $2 AND t.voided_at IS NULL
+		GROUP BY 1, 2, 3`
+
+	rows, err := repo.db.Query(querySales, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, base, amount int
+		var name string
+		var rate float64
+		if err := rows.Scan(&id, &name, &rate, &base, &amount); err != nil {
+			return nil, err
+		}
+		line := summary.line(id, name, rate)
+		line.TaxBase += base
+		line.TaxAmount += amount
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	queryReturns := `
+		SELECT COALESCE(td.tax_rate_id, 0), td.tax_name, td.tax_rate, SUM(ri.amount - ri.tax_amount), SUM(ri.tax_amount)
+		FROM return_items ri
+		JOIN returns r ON ri.return_id = r.id
+		JOIN transaction_details td ON ri.transaction_detail_id = td.id
+		WHERE r.created_at >= $1 AND r.created_at <= $2
+		GROUP BY 1, 2, 3`
+
+	returnRows, err := repo.db.Query(queryReturns, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer returnRows.Close()
+
+	for returnRows.Next() {
+		var id, base, amount int
+		var name string
+		var rate float64
+		if err := returnRows.Scan(&id, &name, &rate, &base, &amount); err != nil {
+			return nil, err
+		}
+		line := summary.line(id, name, rate)
+		line.ReturnTaxBase += base
+		line.ReturnTaxAmount += amount
+	}
+	if err := returnRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return summary.report(startDate, endDate), nil
+}
+
+// GetProfitReport - laba kotor per produk dan per category
+func (repo *PostgresTransactionRepository) GetProfitReport(startDate, endDate string) (*models.ProfitReport, error) {
+	profit := make(profitBuilder)
+
+	querySales := `
+		SELECT td.product_id, COALESCE(p.name, MAX(td.product_name)), COALESCE(c.id, 0), COALESCE(c.name, ''),
+			SUM(td.quantity), SUM(td.tax_base), SUM(td.unit_cost * td.quantity)
+		FROM transaction_details td
+		JOIN transactions t ON td.transaction_id = t.id
+		LEFT JOIN products p ON p.id = td.product_id
+		LEFT JOIN categories c ON c.id = p.category_id
+		WHERE t.created_at >= $1 AND t.created_at <= $2 AND t.voided_at IS NULL
+		GROUP BY td.product_id, p.name, c.id, c.name`
+
+	queryReturns := `
+		SELECT td.product_id, COALESCE(p.name, MAX(td.product_name)), COALESCE(c.id, 0), COALESCE(c.name, ''),
+			-SUM(ri.quantity), SUM(ri.amount - ri.tax_amount), -SUM(td.unit_cost * ri.quantity)
+		FROM return_items ri
+		JOIN returns r ON ri.return_id = r.id
+		JOIN transaction_details td ON ri.transaction_detail_id = td.id
+		LEFT JOIN products p ON p.id = td.product_id
+		LEFT JOIN categories c ON c.id = p.category_id
+		WHERE r.created_at >= $1 AND r.created_at <= $2
+		GROUP BY td.product_id, p.name, c.id, c.name`
+
+	for _, query := range []string{querySales, queryReturns} {
+		if err := repo.addProfitRows(profit, query, startDate, endDate); err != nil {
+			return nil, err
+		}
+	}
+
+	return profit.report(startDate, endDate), nil
+}
+
+func (repo *PostgresTransactionRepository) addProfitRows(profit profitBuilder, query string, args ...any) error {
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID, categoryID, quantity, netSales, cost int
+		var productName, categoryName string
+		if err := rows.Scan(&productID, &productName, &categoryID, &categoryName, &quantity, &netSales, &cost); err != nil {
+			return err
+		}
+		profit.add(productID, productName, categoryID, categoryName, quantity, netSales, cost)
+	}
+
+	return rows.Err()
+}
+
+const transactionColumns = `t.id, t.location_id, t.gross_amount, t.line_discount, t.rule_discount, t.order_discount, t.promo_code,
+	COALESCE(t.promotion_id, 0), t.promo_discount, t.tax_inclusive, t.tax_base, t.tax_amount,
+	t.total_amount, t.paid_amount, t.change_amount,
+	t.created_at, t.voided_at, t.void_reason, t.voided_by`
+
+func scanTransaction(row interface{ Scan(...any) error }, t *models.Transaction) error {
+	return row.Scan(
+		&t.ID,
+		&t.LocationID,
+		&t.GrossAmount,
+		&t.LineDiscount,
+		&t.RuleDiscount,
+		&t.OrderDiscount,
+		&t.PromoCode,
+		&t.PromotionID,
+		&t.PromoDiscount,
+		&t.TaxInclusive,
+		&t.TaxBase,
+		&t.TaxAmount,
+		&t.TotalAmount,
+		&t.PaidAmount,
+		&t.Change,
+		&t.CreatedAt,
+		&t.VoidedAt,
+		&t.VoidReason,
+		&t.VoidedBy,
+	)
+}
+
+// ErrInvalidPage - page / limit daftar transaksi di bawah 1
+var ErrInvalidPage = errors.New("page dan limit minimal 1")
+
+// pageOffset - baris yang dilewati untuk filter.Page. Page yang terlalu besar
+// sampai offset-nya overflow dianggap di luar data.
+func pageOffset(filter models.TransactionFilter) (int, error) {
+	if filter.Page < 1 || filter.Limit < 1 {
+		return 0, ErrInvalidPage
+	}
+	if filter.Page-1 > math.MaxInt/filter.Limit {
+		return math.MaxInt, nil
+	}
+	return (filter.Page - 1) * filter.Limit, nil
+}
+
+// ListTransactions - daftar transaksi (tanpa details) sesuai filter, terbaru di atas
+func (repo *PostgresTransactionRepository) ListTransactions(filter models.TransactionFilter) (*models.TransactionList, error) {
+	offset, err := pageOffset(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var conditions []string
+	var args []interface{}
+	addArg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.StartDate != "" {
+		conditions = append(conditions, "t.created_at >= "+addArg(filter.StartDate))
+	}
+	if filter.EndDate != "" {
+		conditions = append(conditions, "t.created_at <= "+addArg(filter.EndDate))
+	}
+	if filter.MinAmount != nil {
+		conditions = append(conditions, "t.total_amount >= "+addArg(*filter.MinAmount))
+	}
+	if filter.MaxAmount != nil {
+		conditions = append(conditions, "t.total_amount <= "+addArg(*filter.MaxAmount))
+	}
+	if filter.LocationID != 0 {
+		conditions = append(conditions, "t.location_id = "+addArg(filter.LocationID))
+	}
+	if filter.ProductID != 0 {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM transaction_details td WHERE td.transaction_id = t.id AND td.product_id = "+addArg(filter.ProductID)+")")
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	list := &models.TransactionList{Data: []models.Transaction{}, Page: filter.Page, Limit: filter.Limit}
+	err = repo.db.QueryRow("SELECT COUNT(*) FROM transactions t"+where, args...).Scan(&list.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	query := "SELECT " + transactionColumns + " FROM transactions t" + where +
+		" ORDER BY t.created_at DESC, t.id DESC LIMIT " + addArg(filter.Limit) +
+		" OFFSET " + addArg(offset)
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t models.Transaction
+		if err := scanTransaction(rows, &t); err != nil {
+			return nil, err
+		}
+		list.Data = append(list.Data, t)
+	}
+
+	return list, rows.Err()
+}
+
+// GetTransactionByID - transaksi lengkap dengan details
+func (repo *PostgresTransactionRepository) GetTransactionByID(id int) (*models.Transaction, error) {
+	var t models.Transaction
+	query := "SELECT " + transactionColumns + " FROM transactions t WHERE t.id = $1"
+	err := scanTransaction(repo.db.QueryRow(query, id), &t)
+	if err == sql.ErrNoRows {
+		return nil, ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	query = `
+		SELECT id, transaction_id, product_id, product_name, unit_price, unit_cost, quantity, line_discount, rule_discount, order_discount,
+			COALESCE(tax_rate_id, 0), tax_name, tax_rate, tax_base, tax_amount, subtotal, serials
+		FROM transaction_details
+		WHERE transaction_id = $1
+		ORDER BY id
+	`
+	rows, err := repo.db.Query(query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	t.Details = make([]models.TransactionDetail, 0)
+	for rows.Next() {
+		var d models.TransactionDetail
+		if err := rows.Scan(
+			&d.ID, &d.TransactionID, &d.ProductID, &d.ProductName, &d.UnitPrice, &d.UnitCost, &d.Quantity, &d.LineDiscount, &d.RuleDiscount, &d.OrderDiscount,
+			&d.TaxRateID, &d.TaxName, &d.TaxRate, &d.TaxBase, &d.TaxAmount, &d.Subtotal, pq.Array(&d.Serials),
+		); err != nil {
+			return nil, err
+		}
+		t.Details = append(t.Details, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := repo.loadAppliedPromotions(id, t.Details); err != nil {
+		return nil, err
+	}
+
+	payments, err := repo.db.Query("SELECT id, transaction_id, method, amount, reference FROM payments WHERE transaction_id = $1 ORDER BY id", id)
+	if err != nil {
+		return nil, err
+	}
+	defer payments.Close()
+
+	for payments.Next() {
+		var p models.Payment
+		if err := payments.Scan(&p.ID, &p.TransactionID, &p.Method, &p.Amount, &p.Reference); err != nil {
+			return nil, err
+		}
+		t.Payments = append(t.Payments, p)
+	}
+
+	return &t, payments.Err()
+}
+
+// loadAppliedPromotions - isi Promotions di setiap detail dari transaction_promotions
+func (repo *PostgresTransactionRepository) loadAppliedPromotions(transactionID int, details []models.TransactionDetail) error {
+	rows, err := repo.db.Query(`
+		SELECT transaction_detail_id, COALESCE(rule_id, 0), rule_name, rule_type, quantity, discount
+		FROM transaction_promotions
+		WHERE transaction_id = $1
+		ORDER BY id`, transactionID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	byDetail := make(map[int]int, len(details))
+	for i, d := range details {
+		byDetail[d.ID] = i
+	}
+
+	for rows.Next() {
+		var detailID int
+		var p models.AppliedPromotion
+		if err := rows.Scan(&detailID, &p.RuleID, &p.Name, &p.Type, &p.Quantity, &p.Discount); err != nil {
+			return err
+		}
+		if i, ok := byDetail[detailID]; ok {
+			details[i].Promotions = append(details[i].Promotions, p)
+		}
+	}
+
+	return rows.Err()
+}
+
+// VoidTransaction - tandai transaksi void dan kembalikan semua stoknya.
+// Transaksi yang sudah punya retur tidak bisa di-void.
+func (repo *PostgresTransactionRepository) VoidTransaction(id int, reason, voidedBy, user string, window time.Duration) (*models.Transaction, error) {
+	err := withRetry(func() error {
+		return repo.voidTransaction(id, reason, voidedBy, user, window)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetTransactionByID(id)
+}
+
+func (repo *PostgresTransactionRepository) voidTransaction(id int, reason, voidedBy, user string, window time.Duration) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// umur transaksi dihitung dengan jam database yang sama dengan created_at,
+	// di bawah row lock yang sama dengan void-nya. Window dikirim sebagai
+	// mikrodetik bulat, tanpa batas (window < 0) sebagai flag terpisah.
+	var voidedAt *time.Time
+	var locationID int
+	var inWindow bool
+	err = tx.QueryRow(`
+		SELECT voided_at, location_id, $2::boolean OR created_at >= NOW() - $3::bigint * INTERVAL '1 microsecond'
+		FROM transactions WHERE id = $1 FOR UPDATE`,
+		id, window < 0, window.Microseconds(),
+	).Scan(&voidedAt, &locationID, &inWindow)
+	if err == sql.ErrNoRows {
+		return ErrTransactionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if voidedAt != nil {
+		return ErrTransactionVoided
+	}
+	if !inWindow {
+		return ErrVoidWindowExpired
+	}
+
+	var hasReturns bool
+	if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM returns WHERE transaction_id = $1)", id).Scan(&hasReturns); err != nil {
+		return err
+	}
+	if hasReturns {
+		return ErrTransactionHasReturns
+	}
+
+	// restock ke lokasi penjualan, urut product id sama dengan urutan lock di checkout
+	rows, err := tx.Query(`
+		SELECT td.product_id, SUM(td.quantity), MAX(td.unit_cost),
+			ARRAY(SELECT UNNEST(d.serials) FROM transaction_details d WHERE d.transaction_id = $1 AND d.product_id = td.product_id)
+		FROM transaction_details td
+		WHERE td.transaction_id = $1
+		GROUP BY td.product_id
+		ORDER BY td.product_id`, id)
+	if err != nil {
+		return err
+	}
+	type restockLine struct {
+		productID, quantity, unitCost int
+		serials                       []string
+	}
+	restock := make([]restockLine, 0)
+	for rows.Next() {
+		var line restockLine
+		if err := rows.Scan(&line.productID, &line.quantity, &line.unitCost, pq.Array(&line.serials)); err != nil {
+			rows.Close()
+			return err
+		}
+		restock = append(restock, line)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, line := range restock {
+		// lot yang terjual kembali ke batch asalnya
+		lots, err := soldLots(tx, id, line.productID)
+		if err != nil {
+			return err
+		}
+		err = moveStock(tx, &models.StockMovement{
+			ProductID:     line.productID,
+			LocationID:    locationID,
+			Delta:         line.quantity,
+			UnitCost:      line.unitCost,
+			Reason:        models.StockReasonVoid,
+			ReferenceType: models.StockRefTransaction,
+			ReferenceID:   id,
+			User:          user,
+			Note:          reason,
+			Batches:       takeLots(lots, line.quantity),
+			Serials:       line.serials,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec("UPDATE transactions SET voided_at = NOW(), void_reason = $1, voided_by = $2 WHERE id = $3", reason, voidedBy, id)
+	if err != nil {
+		return err
+	}
+
+	// pemakaian promo dikembalikan, transaksi void dianggap tidak pernah terjadi
+	_, err = tx.Exec(`
+		UPDATE promotions SET used_count = GREATEST(used_count - 1, 0)
+		WHERE id = (SELECT promotion_id FROM transactions WHERE id = $1)`, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+	"kasir-api/models"
+)
+
+var (
+	ErrInvalidVariant  = errors.New("varian produk tidak valid")
+	ErrVariantRequired = errors.New("produk ini punya varian, pilih variant_id")
+)
+
+const maxAttributeLength = 50
+
+// normalizeProductFields - trim SKU, barcode dan atribut. Atribut dan
+// price_override hanya berlaku untuk varian, di produk biasa diabaikan.
+func normalizeProductFields(p *models.Product, variant bool) error {
+	if err := normalizeProductCodes(p); err != nil {
+		return err
+	}
+	p.Variants = nil
+	if !variant {
+		p.ParentID, p.Attributes, p.PriceOverride = 0, nil, false
+		return nil
+	}
+
+	if p.SKU == "" {
+		return fmt.Errorf("%w: sku wajib diisi", ErrInvalidVariant)
+	}
+	if len(p.Attributes) == 0 {
+		return fmt.Errorf("%w: attributes wajib diisi, mis. {\"size\": \"M\"}", ErrInvalidVariant)
+	}
+	attributes := make(map[string]string, len(p.Attributes))
+	for name, value := range p.Attributes {
+		name, value = strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(value)
+		if name == "" || value == "" {
+			return fmt.Errorf("%w: nama dan nilai atribut tidak boleh kosong", ErrInvalidVariant)
+		}
+		if len(name) > maxAttributeLength || len(value) > maxAttributeLength {
+			return fmt.Errorf("%w: atribut maksimal %d karakter", ErrInvalidVariant, maxAttributeLength)
+		}
+		if _, dup := attributes[name]; dup {
+			return fmt.Errorf("%w: atribut %s duplikat", ErrInvalidVariant, name)
+		}
+		attributes[name] = value
+	}
+	p.Attributes = attributes
+	return nil
+}
+
+// checkVariantParent - induk harus produk biasa (bukan varian) dan stoknya 0,
+// stok selanjutnya dikelola per varian
+func checkVariantParent(parent models.Product) error {
+	if parent.ParentID != 0 {
+		return fmt.Errorf("%w: %s sudah merupakan varian", ErrInvalidVariant, parent.Name)
+	}
+	if parent.Stock != 0 {
+		return fmt.Errorf("%w: stok %s harus 0 sebelum dibuat varian", ErrInvalidVariant, parent.Name)
+	}
+	return nil
+}
+
+// inheritFromParent - varian selalu ikut category induk; tarif pajak, supplier,
+// nama dan harga (kalau tidak di-override) diambil dari induk kalau kosong
+func inheritFromParent(p *models.Product, parent models.Product) {
+	p.ParentID = parent.ID
+	p.CategoryID = parent.CategoryID
+	if p.TaxRateID == 0 {
+		p.TaxRateID = parent.TaxRateID
+	}
+	if p.SupplierID == 0 {
+		p.SupplierID = parent.SupplierID
+	}
+	if strings.TrimSpace(p.Name) == "" {
+		p.Name = variantName(parent.Name, p.Attributes)
+	}
+	if !p.PriceOverride {
+		p.Price = parent.Price
+	}
+}
+
+// variantName - "Kaos Polos - Merah / M", nilai atribut urut nama atribut
+func variantName(parentName string, attributes map[string]string) string {
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := make([]string, len(names))
+	for i, name := range names {
+		values[i] = attributes[name]
+	}
+	return parentName + " - " + strings.Join(values, " / ")
+}
+
+// itemProductID - baris produk yang stoknya dikurangi untuk item checkout
+func itemProductID(item models.CheckoutItem) int {
+	if item.VariantID != 0 {
+		return item.VariantID
+	}
+	return item.ProductID
+}
+
+// checkVariantItem - produk induk tidak bisa dijual langsung, variant_id harus
+// varian sungguhan dan cocok dengan product_id kalau ikut diisi
+func checkVariantItem(item models.CheckoutItem, name string, parentID int, hasVariants bool) error {
+	if hasVariants {
+		return fmt.Errorf("%w (product %s)", ErrVariantRequired, name)
+	}
+	if item.VariantID == 0 {
+		return nil
+	}
+	if parentID == 0 {
+		return fmt.Errorf("%w: product id %d bukan varian", ErrInvalidVariant, item.VariantID)
+	}
+	if item.ProductID != 0 && item.ProductID != parentID {
+		return fmt.Errorf("%w: varian %s bukan bagian dari product id %d", ErrInvalidVariant, name, item.ProductID)
+	}
+	return nil
+}
+
+// isUniqueViolation - unique_violation (23505), dipakai untuk SKU / barcode duplikat
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
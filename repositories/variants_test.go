@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+
+	"kasir-api/models"
+)
+
+func TestNormalizeProductFields(t *testing.T) {
+	p := models.Product{SKU: " KP-M ", Attributes: map[string]string{" Size ": " M "}}
+	if err := normalizeProductFields(&p, true); err != nil || p.SKU != "KP-M" || p.Attributes["size"] != "M" {
+		t.Errorf("normalizeProductFields = %+v, %v", p, err)
+	}
+
+	plain := models.Product{ParentID: 3, Attributes: map[string]string{"size": "M"}, PriceOverride: true}
+	if err := normalizeProductFields(&plain, false); err != nil || plain.Attributes != nil || plain.PriceOverride {
+		t.Errorf("produk biasa = %+v, %v", plain, err)
+	}
+
+	for _, v := range []models.Product{
+		{Attributes: map[string]string{"size": "M"}},
+		{SKU: "KP"},
+		{SKU: "KP", Attributes: map[string]string{"size": " "}},
+		{SKU: "KP", Attributes: map[string]string{"Size": "M", "size": "L"}},
+	} {
+		if err := normalizeProductFields(&v, true); !errors.Is(err, ErrInvalidVariant) {
+			t.Errorf("normalizeProductFields(%+v) err = %v, want ErrInvalidVariant", v, err)
+		}
+	}
+}
+
+func TestCheckVariantItem(t *testing.T) {
+	tests := []struct {
+		name        string
+		item        models.CheckoutItem
+		parentID    int
+		hasVariants bool
+		want        error
+	}{
+		{"produk biasa", models.CheckoutItem{ProductID: 1}, 0, false, nil},
+		{"induk dijual langsung", models.CheckoutItem{ProductID: 1}, 0, true, ErrVariantRequired},
+		{"variant_id bukan varian", models.CheckoutItem{VariantID: 1}, 0, false, ErrInvalidVariant},
+		{"induk tidak cocok", models.CheckoutItem{ProductID: 2, VariantID: 5}, 1, false, ErrInvalidVariant},
+		{"induk cocok", models.CheckoutItem{ProductID: 1, VariantID: 5}, 1, false, nil},
+	}
+	for _, tt := range tests {
+		if err := checkVariantItem(tt.item, "Kaos", tt.parentID, tt.hasVariants); !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
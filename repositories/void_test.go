@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"kasir-api/models"
+)
+
+// TestPostgresVoidWindow - umur transaksi dicek di SQL dengan jam database
+func TestPostgresVoidWindow(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewTransactionRepository(db)
+	product := createTestProduct(t, db, models.Product{Name: "Indomie Goreng", Price: 3500, Stock: 10})
+
+	req := models.CheckoutRequest{LocationID: models.DefaultLocationID, Items: []models.CheckoutItem{{ProductID: product.ID, Quantity: 2}}}
+	trx, err := repo.CreateTransaction(req, CheckoutOptions{Strategy: LockPessimistic})
+	if err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+
+	if _, err := repo.VoidTransaction(trx.ID, "salah input", models.VoidedByCashier, "", 0); !errors.Is(err, ErrVoidWindowExpired) {
+		t.Errorf("void with zero window: err = %v, want ErrVoidWindowExpired", err)
+	}
+	if _, err := repo.VoidTransaction(999, "salah input", models.VoidedByCashier, "", time.Hour); !errors.Is(err, ErrTransactionNotFound) {
+		t.Errorf("void unknown transaction: err = %v, want ErrTransactionNotFound", err)
+	}
+
+	voided, err := repo.VoidTransaction(trx.ID, "salah input", models.VoidedByCashier, "", time.Hour)
+	if err != nil || voided.VoidedAt == nil {
+		t.Fatalf("void within window = %+v, %v", voided, err)
+	}
+	if _, err := repo.VoidTransaction(trx.ID, "lagi", models.VoidedByManager, "", NoVoidWindow); !errors.Is(err, ErrTransactionVoided) {
+		t.Errorf("second void: err = %v, want ErrTransactionVoided", err)
+	}
+	if stock := testProductStock(t, db, product.ID); stock != 10 {
+		t.Errorf("stock after void = %d, want 10", stock)
+	}
+
+	// manager tanpa batas umur, dan window yang bukan detik bulat
+	for _, window := range []time.Duration{NoVoidWindow, 1500 * time.Millisecond} {
+		trx, err := repo.CreateTransaction(req, CheckoutOptions{Strategy: LockPessimistic})
+		if err != nil {
+			t.Fatalf("checkout: %v", err)
+		}
+		if voided, err := repo.VoidTransaction(trx.ID, "salah input", models.VoidedByManager, "", window); err != nil || voided.VoidedAt == nil {
+			t.Errorf("void with window %v = %+v, %v", window, voided, err)
+		}
+	}
+}
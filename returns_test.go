@@ -0,0 +1,93 @@
+package main
+
+import (
+	"kasir-api/models"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestReturnPartialAndFull(t *testing.T) {
+	s := newTestServer(t)
+
+	category := s.createCategory("Makanan")
+	indomie := s.createProduct("Indomie Goreng", 3500, 10, category.ID)
+	roti := s.createProduct("Roti Tawar", 15000, 10, category.ID)
+
+	trx := s.checkout(models.CheckoutItem{ProductID: indomie.ID, Quantity: 3}, models.CheckoutItem{ProductID: roti.ID, Quantity: 1})
+	detail := s.transactionDetails(trx.ID)
+	path := "/api/transactions/" + strconv.Itoa(trx.ID) + "/returns"
+
+	// retur sebagian: 2 indomie
+	rec := s.doAuth(http.MethodPost, path, models.ReturnRequest{
+		Reason: "rusak",
+		Items:  []models.ReturnRequestItem{{TransactionDetailID: detail[0].ID, Quantity: 2}},
+	})
+	expectStatus(t, rec, http.StatusCreated)
+	ret := decodeJSON[models.Return](t, rec)
+	if ret.TotalAmount != -7000 || len(ret.Items) != 1 || ret.Items[0].Quantity != 2 {
+		t.Fatalf("return = %+v", ret)
+	}
+	if stock := s.productStock(indomie.ID); stock != 9 {
+		t.Errorf("indomie stock = %d, want 9", stock)
+	}
+
+	// melebihi sisa quantity yang terjual
+	rec = s.doAuth(http.MethodPost, path, models.ReturnRequest{
+		Items: []models.ReturnRequestItem{{TransactionDetailID: detail[0].ID, Quantity: 2}},
+	})
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	// retur penuh: sisa 1 indomie + 1 roti
+	rec = s.doAuth(http.MethodPost, path, models.ReturnRequest{Reason: "batal"})
+	expectStatus(t, rec, http.StatusCreated)
+	ret = decodeJSON[models.Return](t, rec)
+	if ret.TotalAmount != -(3500+15000) || len(ret.Items) != 2 {
+		t.Fatalf("full return = %+v", ret)
+	}
+	if stock := s.productStock(indomie.ID); stock != 10 {
+		t.Errorf("indomie stock = %d, want 10", stock)
+	}
+	if stock := s.productStock(roti.ID); stock != 10 {
+		t.Errorf("roti stock = %d, want 10", stock)
+	}
+
+	rec = s.doAuth(http.MethodPost, path, models.ReturnRequest{})
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = s.doAuth(http.MethodGet, path, nil)
+	expectStatus(t, rec, http.StatusOK)
+	if returns := decodeJSON[[]models.Return](t, rec); len(returns) != 2 {
+		t.Errorf("len(returns) = %d, want 2", len(returns))
+	}
+
+	rec = s.doAuth(http.MethodPost, "/api/transactions/999/returns", models.ReturnRequest{})
+	expectStatus(t, rec, http.StatusNotFound)
+}
+
+func TestReportNetOfReturns(t *testing.T) {
+	s := newTestServer(t)
+
+	category := s.createCategory("Makanan")
+	indomie := s.createProduct("Indomie Goreng", 3500, 10, category.ID)
+	roti := s.createProduct("Roti Tawar", 15000, 10, category.ID)
+
+	trx := s.checkout(models.CheckoutItem{ProductID: indomie.ID, Quantity: 3}, models.CheckoutItem{ProductID: roti.ID, Quantity: 2})
+	detail := s.transactionDetails(trx.ID)
+
+	rec := s.doAuth(http.MethodPost, "/api/transactions/"+strconv.Itoa(trx.ID)+"/returns", models.ReturnRequest{
+		Items: []models.ReturnRequestItem{{TransactionDetailID: detail[0].ID, Quantity: 2}},
+	})
+	expectStatus(t, rec, http.StatusCreated)
+
+	rec = s.doAuth(http.MethodGet, "/api/report", nil)
+	expectStatus(t, rec, http.StatusOK)
+	report := decodeJSON[models.SalesReport](t, rec)
+
+	if report.GrossRevenue != 40500 || report.TotalReturns != -7000 || report.TotalRevenue != 33500 {
+		t.Errorf("report = %+v, want gross 40500, returns -7000, net 33500", report)
+	}
+	if report.TopProduct.Name != "Roti Tawar" || report.TopProduct.TotalSold != 2 {
+		t.Errorf("top product = %+v, want Roti Tawar x2 after returns", report.TopProduct)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"kasir-api/handlers"
+	"kasir-api/middleware"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"kasir-api/services"
+	"log"
+	"net/http"
+)
+
+type appRepositories struct {
+	product     repositories.ProductRepository
+	category    repositories.CategoryRepository
+	transaction repositories.TransactionRepository
+	idempotency repositories.IdempotencyRepository
+	promotion   repositories.PromotionRepository
+	rule        repositories.PromotionRuleRepository
+	taxRate     repositories.TaxRateRepository
+	movement    repositories.StockMovementRepository
+	inventory   repositories.InventoryRepository
+	stockCount  repositories.StockCountRepository
+	supplier    repositories.SupplierRepository
+	purchase    repositories.PurchaseOrderRepository
+	location    repositories.LocationRepository
+	transfer    repositories.StockTransferRepository
+}
+
+// newRouter - rakit service, handler dan semua route. Dipisah dari main()
+// supaya bisa dipakai test dengan repository in-memory.
+func newRouter(repos appRepositories, config Config) http.Handler {
+	// Setup Middleware & Dependency Injection
+	apiKeyMiddleware := middleware.APIKey(config.APIKey)
+
+	costingMethod := repositories.CostingMethod(config.CostingMethod)
+
+	productService := services.NewProductService(repos.product, repos.movement, services.ProductConfig{
+		CostingMethod: costingMethod,
+		BarcodePrefix: config.BarcodePrefix,
+	})
+	productHandler := handlers.NewProductHandler(productService)
+
+	categoryService := services.NewCategoryService(repos.category)
+	categoryHandler := handlers.NewCategoryHandler(categoryService)
+
+	transactionService := services.NewTransactionService(repos.transaction, repos.idempotency, repos.promotion, repos.rule, services.TransactionConfig{
+		LockStrategy:  repositories.LockStrategy(config.CheckoutLockMode),
+		VoidWindow:    config.VoidWindow,
+		ManagerKey:    config.ManagerKey,
+		TaxPriceMode:  config.TaxPriceMode,
+		CostingMethod: costingMethod,
+	})
+	transactionService.SubscribeLowStock(func(e models.LowStockEvent) {
+		log.Printf("[LOW STOCK] %s (id %d) stok %d, reorder point %d, reorder quantity %d",
+			e.ProductName, e.ProductID, e.Stock, e.ReorderPoint, e.ReorderQuantity)
+	})
+	transactionHandler := handlers.NewTransactionHandler(transactionService)
+
+	promotionService := services.NewPromotionService(repos.promotion, repos.rule)
+	promotionHandler := handlers.NewPromotionHandler(promotionService)
+
+	taxService := services.NewTaxService(repos.taxRate)
+	taxHandler := handlers.NewTaxHandler(taxService)
+
+	inventoryService := services.NewInventoryService(repos.inventory, repos.stockCount, services.InventoryConfig{
+		AllowNegativeStock: config.AllowNegativeStock,
+		ReorderWindowDays:  config.ReorderWindowDays,
+		ReorderAlpha:       config.ReorderAlpha,
+		ReorderCoverDays:   config.ReorderCoverDays,
+		CostingMethod:      costingMethod,
+	})
+	inventoryHandler := handlers.NewInventoryHandler(inventoryService)
+
+	purchaseService := services.NewPurchaseService(repos.supplier, repos.purchase)
+	purchaseHandler := handlers.NewPurchaseHandler(purchaseService)
+
+	locationService := services.NewLocationService(repos.location, repos.transfer)
+	locationHandler := handlers.NewLocationHandler(locationService)
+
+	mux := http.NewServeMux()
+
+	// Setup Routes
+
+	// -- Product --
+	mux.HandleFunc("/api/product", productHandler.HandleProducts)
+	mux.HandleFunc("/api/product/", middleware.Logger(apiKeyMiddleware(productHandler.HandleProductByID)))
+
+	// -- Barcode & label --
+	mux.HandleFunc("/api/barcodes", middleware.Logger(apiKeyMiddleware(productHandler.HandleBarcode)))
+	mux.HandleFunc("/api/labels", middleware.Logger(apiKeyMiddleware(productHandler.HandleLabels)))
+
+	// -- Category --
+	mux.HandleFunc("/api/category", categoryHandler.HandleCategories)
+	mux.HandleFunc("/api/category/", middleware.Logger(apiKeyMiddleware(categoryHandler.HandleCategoryByID)))
+
+	// -- Checkout --
+	mux.HandleFunc("/api/checkout", middleware.Logger(apiKeyMiddleware(transactionHandler.HandleCheckout)))
+
+	// -- Transactions --
+	mux.HandleFunc("/api/transactions", middleware.Logger(apiKeyMiddleware(transactionHandler.HandleTransactions)))
+	mux.HandleFunc("/api/transactions/", middleware.Logger(apiKeyMiddleware(transactionHandler.HandleTransactionByID)))
+
+	// -- Promotions --
+	mux.HandleFunc("/api/promotions", middleware.Logger(apiKeyMiddleware(promotionHandler.HandlePromotions)))
+	mux.HandleFunc("/api/promotions/", middleware.Logger(apiKeyMiddleware(promotionHandler.HandlePromotionByID)))
+	mux.HandleFunc("/api/promotion-rules", middleware.Logger(apiKeyMiddleware(promotionHandler.HandleRules)))
+	mux.HandleFunc("/api/promotion-rules/", middleware.Logger(apiKeyMiddleware(promotionHandler.HandleRuleByID)))
+
+	// -- Tax --
+	mux.HandleFunc("/api/tax-rates", middleware.Logger(apiKeyMiddleware(taxHandler.HandleTaxRates)))
+	mux.HandleFunc("/api/tax-rates/", middleware.Logger(apiKeyMiddleware(taxHandler.HandleTaxRateByID)))
+
+	// -- Inventory --
+	mux.HandleFunc("/api/inventory/adjustments", middleware.Logger(apiKeyMiddleware(inventoryHandler.HandleAdjustments)))
+	mux.HandleFunc("/api/inventory/low-stock", middleware.Logger(apiKeyMiddleware(inventoryHandler.HandleLowStock)))
+	mux.HandleFunc("/api/inventory/reorder-suggestions", middleware.Logger(apiKeyMiddleware(inventoryHandler.HandleReorderSuggestions)))
+	mux.HandleFunc("/api/inventory/valuation", middleware.Logger(apiKeyMiddleware(inventoryHandler.HandleValuation)))
+	mux.HandleFunc("/api/inventory/batches", middleware.Logger(apiKeyMiddleware(inventoryHandler.HandleBatches)))
+	mux.HandleFunc("/api/inventory/expiring", middleware.Logger(apiKeyMiddleware(inventoryHandler.HandleExpiring)))
+	mux.HandleFunc("/api/inventory/counts", middleware.Logger(apiKeyMiddleware(inventoryHandler.HandleCounts)))
+	mux.HandleFunc("/api/inventory/counts/", middleware.Logger(apiKeyMiddleware(inventoryHandler.HandleCountByID)))
+	mux.HandleFunc("/api/inventory/transfers", middleware.Logger(apiKeyMiddleware(locationHandler.HandleTransfers)))
+	mux.HandleFunc("/api/inventory/transfers/", middleware.Logger(apiKeyMiddleware(locationHandler.HandleTransferByID)))
+
+	// -- Serial number --
+	mux.HandleFunc("/api/serials/", middleware.Logger(apiKeyMiddleware(inventoryHandler.HandleSerialByNumber)))
+
+	// -- Locations --
+	mux.HandleFunc("/api/locations", middleware.Logger(apiKeyMiddleware(locationHandler.HandleLocations)))
+	mux.HandleFunc("/api/locations/", middleware.Logger(apiKeyMiddleware(locationHandler.HandleLocationByID)))
+
+	// -- Purchasing --
+	mux.HandleFunc("/api/suppliers", middleware.Logger(apiKeyMiddleware(purchaseHandler.HandleSuppliers)))
+	mux.HandleFunc("/api/suppliers/", middleware.Logger(apiKeyMiddleware(purchaseHandler.HandleSupplierByID)))
+	mux.HandleFunc("/api/purchase-orders", middleware.Logger(apiKeyMiddleware(purchaseHandler.HandlePurchaseOrders)))
+	mux.HandleFunc("/api/purchase-orders/", middleware.Logger(apiKeyMiddleware(purchaseHandler.HandlePurchaseOrderByID)))
+
+	// -- Report --
+	// laporan memuat HPP dan laba kotor, jadi ikut dilindungi API key
+	mux.HandleFunc("/api/report/hari-ini", middleware.Logger(apiKeyMiddleware(transactionHandler.HandleReport)))
+	mux.HandleFunc("/api/report", middleware.Logger(apiKeyMiddleware(transactionHandler.HandleReport)))
+	mux.HandleFunc("/api/report/tax", middleware.Logger(apiKeyMiddleware(transactionHandler.HandleTaxReport)))
+	mux.HandleFunc("/api/report/profit", middleware.Logger(apiKeyMiddleware(transactionHandler.HandleProfitReport)))
+
+	// -- Health Check --
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "OK",
+			"message": "API running",
+		})
+	})
+
+	return middleware.CORS(mux)
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"kasir-api/models"
+	"net/http"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func (s *testServer) serialHistory(serial string) models.SerialNumber {
+	s.t.Helper()
+
+	rec := s.doAuth(http.MethodGet, "/api/serials/"+serial, nil)
+	expectStatus(s.t, rec, http.StatusOK)
+	units := decodeJSON[[]models.SerialNumber](s.t, rec)
+	if len(units) != 1 {
+		s.t.Fatalf("serial %s units = %+v", serial, units)
+	}
+	return units[0]
+}
+
+func movementReasons(history []models.StockMovement) []string {
+	reasons := make([]string, len(history))
+	for i, m := range history {
+		reasons[i] = m.Reason
+	}
+	return reasons
+}
+
+func TestSerialTracking(t *testing.T) {
+	s := newTestServer(t)
+
+	category := s.createCategory("Handphone")
+	rec := s.do(http.MethodPost, "/api/product", models.Product{
+		Name:        "Smartphone X1",
+		Price:       3500000,
+		CategoryID:  category.ID,
+		TrackSerial: true,
+	}, nil)
+	expectStatus(t, rec, http.StatusCreated)
+	hp := decodeJSON[models.Product](t, rec)
+	casing := s.createProduct("Casing X1", 50000, 10, category.ID)
+	supplier := s.createSupplier("PT Gadget")
+
+	rec = s.doAuth(http.MethodPost, "/api/purchase-orders", models.PurchaseOrderRequest{
+		SupplierID: supplier.ID,
+		Lines:      []models.PurchaseOrderLineRequest{{ProductID: hp.ID, Quantity: 3, UnitCost: 3000000}},
+	})
+	expectStatus(t, rec, http.StatusCreated)
+	po := decodeJSON[models.PurchaseOrder](t, rec)
+	path := "/api/purchase-orders/" + strconv.Itoa(po.ID)
+	expectStatus(t, s.doAuth(http.MethodPost, path+"/send", nil), http.StatusOK)
+
+	// penerimaan wajib menyertakan satu serial per unit
+	lineID := po.Lines[0].ID
+	for _, serials := range [][]string{nil, {"IMEI-1"}, {"IMEI-1", " IMEI-1 ", "IMEI-3"}} {
+		rec = s.doAuth(http.MethodPost, path+"/receipts", models.GoodsReceiptRequest{
+			Items: []models.GoodsReceiptRequestItem{{PurchaseOrderLineID: lineID, Quantity: 3, Serials: serials}},
+		})
+		expectStatus(t, rec, http.StatusBadRequest)
+	}
+	rec = s.doAuth(http.MethodPost, path+"/receipts", models.GoodsReceiptRequest{
+		Items: []models.GoodsReceiptRequestItem{{PurchaseOrderLineID: lineID, Quantity: 3, Serials: []string{"IMEI-1", " IMEI-2", "IMEI-3"}}},
+	})
+	expectStatus(t, rec, http.StatusCreated)
+	if receipt := decodeJSON[models.GoodsReceipt](t, rec); !slices.Equal(receipt.Items[0].Serials, []string{"IMEI-1", "IMEI-2", "IMEI-3"}) {
+		t.Errorf("receipt serials = %v", receipt.Items[0].Serials)
+	}
+
+	// checkout: serial wajib, harus ada di stok, produk biasa tidak boleh pakai serial
+	for _, c := range []struct {
+		item models.CheckoutItem
+		want int
+	}{
+		{models.CheckoutItem{ProductID: hp.ID, Quantity: 1}, http.StatusBadRequest},
+		{models.CheckoutItem{ProductID: hp.ID, Quantity: 2, Serials: []string{"IMEI-1"}}, http.StatusBadRequest},
+		{models.CheckoutItem{ProductID: hp.ID, Quantity: 1, Serials: []string{"IMEI-9"}}, http.StatusConflict},
+		{models.CheckoutItem{ProductID: casing.ID, Quantity: 1, Serials: []string{"C-1"}}, http.StatusBadRequest},
+	} {
+		rec = s.doAuth(http.MethodPost, "/api/checkout", models.CheckoutRequest{Items: []models.CheckoutItem{c.item}})
+		expectStatus(t, rec, c.want)
+	}
+	if stock := s.productStock(hp.ID); stock != 3 {
+		t.Fatalf("stock after rejected checkouts = %d, want 3", stock)
+	}
+
+	trx := s.checkout(
+		models.CheckoutItem{ProductID: hp.ID, Quantity: 2, Serials: []string{"IMEI-1", "IMEI-2"}},
+		models.CheckoutItem{ProductID: casing.ID, Quantity: 1},
+	)
+	details := s.transactionDetails(trx.ID)
+	if !slices.Equal(details[0].Serials, []string{"IMEI-1", "IMEI-2"}) || details[1].Serials != nil {
+		t.Errorf("detail serials = %v / %v", details[0].Serials, details[1].Serials)
+	}
+	rec = s.doAuth(http.MethodPost, "/api/checkout", models.CheckoutRequest{
+		Items: []models.CheckoutItem{{ProductID: hp.ID, Quantity: 1, Serials: []string{"IMEI-1"}}},
+	})
+	expectStatus(t, rec, http.StatusConflict)
+
+	// retur: serial harus unit dari baris itu, kosong hanya kalau semua sisa diretur
+	returns := "/api/transactions/" + strconv.Itoa(trx.ID) + "/returns"
+	for _, item := range []models.ReturnRequestItem{
+		{TransactionDetailID: details[0].ID, Quantity: 1},
+		{TransactionDetailID: details[0].ID, Quantity: 1, Serials: []string{"IMEI-3"}},
+	} {
+		rec = s.doAuth(http.MethodPost, returns, models.ReturnRequest{Reason: "rusak", Items: []models.ReturnRequestItem{item}})
+		expectStatus(t, rec, http.StatusBadRequest)
+	}
+	rec = s.doAuth(http.MethodPost, returns, models.ReturnRequest{
+		Reason: "layar mati",
+		Items:  []models.ReturnRequestItem{{TransactionDetailID: details[0].ID, Quantity: 1, Serials: []string{"IMEI-2"}}},
+	})
+	expectStatus(t, rec, http.StatusCreated)
+	if ret := decodeJSON[models.Return](t, rec); !slices.Equal(ret.Items[0].Serials, []string{"IMEI-2"}) {
+		t.Errorf("return serials = %v", ret.Items[0].Serials)
+	}
+
+	// sisa baris diretur penuh tanpa menyebut serial
+	rec = s.doAuth(http.MethodPost, returns, models.ReturnRequest{Reason: "batal"})
+	expectStatus(t, rec, http.StatusCreated)
+	if ret := decodeJSON[models.Return](t, rec); !slices.Equal(ret.Items[0].Serials, []string{"IMEI-1"}) {
+		t.Errorf("full return items = %+v", ret.Items)
+	}
+
+	// unit hilang dikeluarkan lewat adjustment
+	rec = s.doAuth(http.MethodPost, "/api/inventory/adjustments", models.StockAdjustmentRequest{
+		Reason: models.AdjustmentLost,
+		Items:  []models.StockAdjustmentItem{{ProductID: hp.ID, Quantity: -1, Serials: []string{"IMEI-3"}}},
+	})
+	expectStatus(t, rec, http.StatusCreated)
+
+	imei2 := s.serialHistory("IMEI-2")
+	if imei2.Status != models.SerialInStock || imei2.ProductName != "Smartphone X1" ||
+		!slices.Equal(movementReasons(imei2.History), []string{models.StockReasonReceipt, models.StockReasonSale, models.StockReasonReturn}) ||
+		imei2.History[1].ReferenceID != trx.ID {
+		t.Errorf("IMEI-2 = %+v", imei2)
+	}
+	if imei3 := s.serialHistory("IMEI-3"); imei3.Status != models.SerialRemoved || len(imei3.History) != 2 {
+		t.Errorf("IMEI-3 = %+v", imei3)
+	}
+	expectStatus(t, s.doAuth(http.MethodGet, "/api/serials/IMEI-404", nil), http.StatusNotFound)
+
+	movements := s.stockMovements(hp.ID)
+	if sale := movements[1]; sale.Reason != models.StockReasonSale || !slices.Equal(sale.Serials, []string{"IMEI-1", "IMEI-2"}) {
+		t.Errorf("sale movement = %+v", sale)
+	}
+}
+
+func TestTrackSerialChangeRequiresEmptyStock(t *testing.T) {
+	s := newTestServer(t)
+
+	category := s.createCategory("Handphone")
+	casing := s.createProduct("Casing X1", 50000, 10, category.ID)
+	path := "/api/product/" + strconv.Itoa(casing.ID)
+
+	// 10 unit lama tanpa serial: track_serial tidak bisa dinyalakan
+	rec := s.doAuth(http.MethodPut, path, map[string]any{"name": casing.Name, "price": casing.Price, "stock": 10, "track_serial": true})
+	expectStatus(t, rec, http.StatusConflict)
+
+	// tidak dikirim atau sama dengan yang tersimpan: tetap boleh update
+	rec = s.doAuth(http.MethodPut, path, map[string]any{"name": "Casing X1 Hitam", "price": casing.Price, "stock": 10})
+	expectStatus(t, rec, http.StatusOK)
+	rec = s.doAuth(http.MethodPut, path, map[string]any{"name": "Casing X1 Hitam", "price": casing.Price, "stock": 10, "track_serial": false})
+	expectStatus(t, rec, http.StatusOK)
+
+	// semua stok sedang dikirim ke outlet: stok 0 tapi masih ada unit dalam perjalanan
+	outlet := s.createLocation("Outlet Kemang", models.LocationOutlet)
+	rec = s.doAuth(http.MethodPost, "/api/inventory/transfers", models.StockTransferRequest{
+		FromLocationID: models.DefaultLocationID,
+		ToLocationID:   outlet.ID,
+		Items:          []models.StockTransferItem{{ProductID: casing.ID, Quantity: 10}},
+	})
+	expectStatus(t, rec, http.StatusCreated)
+	transfer := "/api/inventory/transfers/" + strconv.Itoa(decodeJSON[models.StockTransfer](t, rec).ID)
+	expectStatus(t, s.doAuth(http.MethodPost, transfer+"/ship", nil), http.StatusOK)
+	rec = s.doAuth(http.MethodPut, path, map[string]any{"name": "Casing X1 Hitam", "price": casing.Price, "stock": 0, "track_serial": true})
+	expectStatus(t, rec, http.StatusConflict)
+
+	expectStatus(t, s.doAuth(http.MethodPost, transfer+"/receive", nil), http.StatusOK)
+	rec = s.doAuth(http.MethodPut, path, map[string]any{"name": "Casing X1 Hitam", "price": casing.Price, "stock": 0, "location_id": outlet.ID})
+	expectStatus(t, rec, http.StatusOK)
+	rec = s.doAuth(http.MethodPut, path, map[string]any{"name": "Casing X1 Hitam", "price": casing.Price, "stock": 0, "track_serial": true})
+	expectStatus(t, rec, http.StatusOK)
+	if got := s.productLocations(casing.ID); !got.TrackSerial {
+		t.Errorf("product after enabling serials = %+v", got)
+	}
+
+	// stok produk serial tidak bisa diubah tanpa serial
+	rec = s.doAuth(http.MethodPut, path, map[string]any{"name": "Casing X1 Hitam", "price": casing.Price, "stock": 5, "location_id": outlet.ID})
+	expectStatus(t, rec, http.StatusBadRequest)
+	if stock := s.productStock(casing.ID); stock != 0 {
+		t.Errorf("stock after rejected update = %d, want 0", stock)
+	}
+}
+
+func TestSerialVoidRestoresUnits(t *testing.T) {
+	s := newVoidTestServer(t, time.Hour)
+
+	category := s.createCategory("Laptop")
+	rec := s.do(http.MethodPost, "/api/product", models.Product{Name: "Laptop 14", Price: 9000000, CategoryID: category.ID, TrackSerial: true}, nil)
+	expectStatus(t, rec, http.StatusCreated)
+	laptop := decodeJSON[models.Product](t, rec)
+
+	// unit lama tanpa PO didaftarkan lewat adjustment
+	rec = s.doAuth(http.MethodPost, "/api/inventory/adjustments", models.StockAdjustmentRequest{
+		Reason: models.AdjustmentFound,
+		Items:  []models.StockAdjustmentItem{{ProductID: laptop.ID, Quantity: 2, Serials: []string{"SN-A", "SN-B"}}},
+	})
+	expectStatus(t, rec, http.StatusCreated)
+
+	trx := s.checkout(models.CheckoutItem{ProductID: laptop.ID, Quantity: 1, Serials: []string{"SN-B"}})
+	rec = s.doAuth(http.MethodPost, "/api/transactions/"+strconv.Itoa(trx.ID)+"/void", models.VoidRequest{Reason: "salah unit"})
+	expectStatus(t, rec, http.StatusOK)
+
+	if sn := s.serialHistory("SN-B"); sn.Status != models.SerialInStock ||
+		!slices.Equal(movementReasons(sn.History), []string{models.StockReasonAdjustment, models.StockReasonSale, models.StockReasonVoid}) {
+		t.Errorf("SN-B = %+v", sn)
+	}
+	s.checkout(models.CheckoutItem{ProductID: laptop.ID, Quantity: 1, Serials: []string{"SN-B"}})
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+// Simbologi barcode yang bisa dirender
+const (
+	SymbologyEAN13   = "ean13"
+	SymbologyCode128 = "code128"
+)
+
+// Format gambar barcode
+const (
+	BarcodeFormatSVG = "svg"
+	BarcodeFormatPNG = "png"
+)
+
+var ErrInvalidBarcodeFormat = errors.New("format barcode harus svg atau png")
+
+const (
+	defaultBarcodePrefix = "200"
+
+	barcodeQuietZone = 10 // modul kosong kiri-kanan
+	barcodeHeight    = 60 // tinggi bar dalam modul
+	barcodeTextSize  = 12
+)
+
+// ParseBarcodePrefix - prefix EAN-13 internal, kosong = 200 (rentang GS1
+// 200-299 untuk pemakaian internal toko). Minimal 2 digit dan menyisakan
+// minimal 3 digit untuk nomor urut.
+func ParseBarcodePrefix(prefix string) (string, error) {
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return defaultBarcodePrefix, nil
+	}
+	if len(prefix) < 2 || len(prefix) > 9 || strings.Trim(prefix, "0123456789") != "" {
+		return "", fmt.Errorf("prefix barcode harus 2-9 digit angka: %s", prefix)
+	}
+	return prefix, nil
+}
+
+// RenderBarcode - gambar barcode untuk code. symbology kosong = EAN-13 kalau
+// code adalah EAN-13 / UPC-A yang valid, selain itu Code128.
+func RenderBarcode(code, symbology, format string) ([]byte, string, error) {
+	modules, text, err := encodeBarcode(strings.TrimSpace(code), strings.ToLower(strings.TrimSpace(symbology)))
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", BarcodeFormatSVG:
+		return barcodeSVG(modules, text), "image/svg+xml", nil
+	case BarcodeFormatPNG:
+		data, err := barcodePNG(modules, 2)
+		return data, "image/png", err
+	default:
+		return nil, "", ErrInvalidBarcodeFormat
+	}
+}
+
+// encodeBarcode - pola modul (true = bar hitam) dan teks di bawah barcode
+func encodeBarcode(code, symbology string) ([]bool, string, error) {
+	if symbology == "" {
+		symbology = SymbologyCode128
+		if len(code) == 12 || len(code) == 13 {
+			if _, err := retailCode(code); err == nil {
+				symbology = SymbologyEAN13
+			}
+		}
+	}
+
+	switch symbology {
+	case SymbologyEAN13:
+		ean, err := retailCode(code)
+		if err != nil {
+			return nil, "", err
+		}
+		return encodeEAN13(ean), ean, nil
+	case SymbologyCode128:
+		modules, err := encodeCode128(code)
+		return modules, code, err
+	default:
+		return nil, "", fmt.Errorf("%w: simbologi %q tidak dikenal", repositories.ErrInvalidBarcode, symbology)
+	}
+}
+
+// retailCode - EAN-13 yang valid, UPC-A (12 digit) dijadikan EAN-13 dengan
+// awalan 0 karena pola bar-nya sama
+func retailCode(code string) (string, error) {
+	barcodes, err := repositories.NormalizeBarcodes([]models.Barcode{{Code: code}})
+	if err != nil {
+		return "", err
+	}
+	switch barcodes[0].Type {
+	case models.BarcodeEAN13:
+		return code, nil
+	case models.BarcodeUPC:
+		return "0" + code, nil
+	default:
+		return "", fmt.Errorf("%w: EAN-13 harus 13 digit (atau UPC-A 12 digit)", repositories.ErrInvalidBarcode)
+	}
+}
+
+// Pola EAN-13 per digit (7 modul, "1" = bar): L dan R, G adalah R dibalik.
+// Digit pertama tidak digambar, tapi menentukan paritas L/G enam digit kiri.
+var (
+	ean13L = [10]string{
+		"0001101", "0011001", "0010011", "0111101", "0100011",
+		"0110001", "0101111", "0111011", "0110111", "0001011",
+	}
+	ean13Parity = [10]string{
+		"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG",
+		"LGGLLG", "LGGGLL", "LGLGLG", "LGLGGL", "LGGLGL",
+	}
+)
+
+func encodeEAN13(code string) []bool {
+	var b strings.Builder
+	b.WriteString("101")
+	parity := ean13Parity[code[0]-'0']
+	for i := 1; i <= 6; i++ {
+		l := ean13L[code[i]-'0']
+		if parity[i-1] == 'G' {
+			l = reverse(invert(l))
+		}
+		b.WriteString(l)
+	}
+	b.WriteString("01010")
+	for i := 7; i <= 12; i++ {
+		b.WriteString(invert(ean13L[code[i]-'0']))
+	}
+	b.WriteString("101")
+	return modulesFromBits(b.String())
+}
+
+// code128Patterns - lebar bar / spasi bergantian untuk nilai 0-106
+// (103-105 start A/B/C, 106 stop)
+var code128Patterns = [107]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+const (
+	code128StartB = 104
+	code128Stop   = 106
+)
+
+// encodeCode128 - Code 128 set B (ASCII 32-126) dengan checksum modulo 103
+func encodeCode128(data string) ([]bool, error) {
+	if data == "" || len(data) > 80 {
+		return nil, fmt.Errorf("%w: code128 harus 1-80 karakter", repositories.ErrInvalidBarcode)
+	}
+
+	values := []int{code128StartB}
+	checksum := code128StartB
+	for i, r := range data {
+		if r < 32 || r > 126 {
+			return nil, fmt.Errorf("%w: code128 hanya mendukung karakter ASCII yang bisa dicetak", repositories.ErrInvalidBarcode)
+		}
+		values = append(values, int(r)-32)
+		checksum += (i + 1) * (int(r) - 32)
+	}
+	values = append(values, checksum%103, code128Stop)
+
+	var modules []bool
+	for _, v := range values {
+		for i, w := range code128Patterns[v] {
+			for range int(w - '0') {
+				modules = append(modules, i%2 == 0)
+			}
+		}
+	}
+	return modules, nil
+}
+
+func barcodeSVG(modules []bool, text string) []byte {
+	width := len(modules) + 2*barcodeQuietZone
+	height := barcodeHeight + barcodeTextSize + 4
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d">`, width, height, width*2, height*2)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/>`, width, height)
+	// bar yang bersebelahan digabung jadi satu rect
+	for i := 0; i < len(modules); {
+		if !modules[i] {
+			i++
+			continue
+		}
+		start := i
+		for i < len(modules) && modules[i] {
+			i++
+		}
+		fmt.Fprintf(&b, `<rect x="%d" width="%d" height="%d"/>`, barcodeQuietZone+start, i-start, barcodeHeight)
+	}
+	fmt.Fprintf(&b, `<text x="%d" y="%d" font-family="monospace" font-size="%d" text-anchor="middle">%s</text>`,
+		width/2, barcodeHeight+barcodeTextSize, barcodeTextSize-2, html.EscapeString(text))
+	b.WriteString(`</svg>`)
+	return b.Bytes()
+}
+
+// barcodePNG - tanpa teks, scale piksel per modul
+func barcodePNG(modules []bool, scale int) ([]byte, error) {
+	width := (len(modules) + 2*barcodeQuietZone) * scale
+	height := barcodeHeight * scale
+
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	for i, bar := range modules {
+		if !bar {
+			continue
+		}
+		for x := (barcodeQuietZone + i) * scale; x < (barcodeQuietZone+i+1)*scale; x++ {
+			for y := 0; y < height; y++ {
+				img.SetGray(x, y, color.Gray{Y: 0})
+			}
+		}
+	}
+
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func modulesFromBits(bits string) []bool {
+	modules := make([]bool, len(bits))
+	for i, c := range bits {
+		modules[i] = c == '1'
+	}
+	return modules
+}
+
+func invert(bits string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '0' {
+			return '1'
+		}
+		return '0'
+	}, bits)
+}
+
+func reverse(bits string) string {
+	out := []byte(bits)
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
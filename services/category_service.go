@@ -6,10 +6,10 @@ import (
 )
 
 type CategoryService struct {
-	repo *repositories.CategoryRepository
+	repo repositories.CategoryRepository
 }
 
-func NewCategoryService(repo *repositories.CategoryRepository) *CategoryService {
+func NewCategoryService(repo repositories.CategoryRepository) *CategoryService {
 	return &CategoryService{repo: repo}
 }
 
@@ -0,0 +1,58 @@
+package services
+
+import "kasir-api/repositories"
+
+// Error dari layer repository yang perlu dibedakan handler (404 / 409 / 400).
+// Handler cukup mencocokkan error lewat package services, tidak perlu
+// mengimpor repositories.
+var (
+	// produk, varian, SKU dan barcode
+	ErrProductNotFound  = repositories.ErrProductNotFound
+	ErrInvalidVariant   = repositories.ErrInvalidVariant
+	ErrVariantRequired  = repositories.ErrVariantRequired
+	ErrDuplicateSKU     = repositories.ErrDuplicateSKU
+	ErrInvalidBarcode   = repositories.ErrInvalidBarcode
+	ErrDuplicateBarcode = repositories.ErrDuplicateBarcode
+	ErrBarcodeNotFound  = repositories.ErrBarcodeNotFound
+	ErrBarcodeAssigned  = repositories.ErrBarcodeAssigned
+	ErrBarcodeRangeFull = repositories.ErrBarcodeRangeFull
+	ErrTaxRateNotFound  = repositories.ErrTaxRateNotFound
+
+	// checkout, transaksi, retur dan void
+	ErrInvalidCheckout       = repositories.ErrInvalidCheckout
+	ErrInsufficientStock     = repositories.ErrInsufficientStock
+	ErrInvalidPage           = repositories.ErrInvalidPage
+	ErrTransactionNotFound   = repositories.ErrTransactionNotFound
+	ErrTransactionVoided     = repositories.ErrTransactionVoided
+	ErrTransactionHasReturns = repositories.ErrTransactionHasReturns
+	ErrPromotionNotFound     = repositories.ErrPromotionNotFound
+	ErrPromotionExhausted    = repositories.ErrPromotionExhausted
+	ErrPromotionRuleNotFound = repositories.ErrPromotionRuleNotFound
+
+	// batch dan serial number
+	ErrBatchExpired      = repositories.ErrBatchExpired
+	ErrInsufficientBatch = repositories.ErrInsufficientBatch
+	ErrSerialNotFound    = repositories.ErrSerialNotFound
+	ErrSerialRequired    = repositories.ErrSerialRequired
+	ErrInvalidSerial     = repositories.ErrInvalidSerial
+	ErrSerialUnavailable = repositories.ErrSerialUnavailable
+	ErrTrackSerialInUse  = repositories.ErrTrackSerialInUse
+
+	// stock count, lokasi dan transfer
+	ErrStockCountNotFound       = repositories.ErrStockCountNotFound
+	ErrStockCountClosed         = repositories.ErrStockCountClosed
+	ErrStockCountEmpty          = repositories.ErrStockCountEmpty
+	ErrStockCountUnknownProduct = repositories.ErrStockCountUnknownProduct
+	ErrLocationNotFound         = repositories.ErrLocationNotFound
+	ErrLocationInUse            = repositories.ErrLocationInUse
+	ErrStockLocationRequired    = repositories.ErrStockLocationRequired
+	ErrStockTransferNotFound    = repositories.ErrStockTransferNotFound
+	ErrStockTransferStatus      = repositories.ErrStockTransferStatus
+
+	// supplier dan purchase order
+	ErrSupplierNotFound      = repositories.ErrSupplierNotFound
+	ErrSupplierInUse         = repositories.ErrSupplierInUse
+	ErrPurchaseOrderNotFound = repositories.ErrPurchaseOrderNotFound
+	ErrPurchaseOrderStatus   = repositories.ErrPurchaseOrderStatus
+	ErrInvalidReceipt        = repositories.ErrInvalidReceipt
+)
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"kasir-api/models"
+)
+
+const (
+	defaultExpiryDays = 30
+	maxExpiryDays     = 365
+)
+
+var ErrInvalidExpiryWindow = errors.New("days harus antara 0 dan 365")
+
+// Batches - stok per lot, urut FEFO
+func (s *InventoryService) Batches(filter models.BatchFilter) ([]models.Batch, error) {
+	return s.repo.ListBatches(filter)
+}
+
+// ExpiringBatches - batch yang kedaluwarsa dalam days hari (nil = 30 hari),
+// termasuk yang sudah lewat tanggal supaya bisa ditarik dari rak
+func (s *InventoryService) ExpiringBatches(days *int, locationID int) (*models.ExpiringReport, error) {
+	window := defaultExpiryDays
+	if days != nil {
+		window = *days
+	}
+	if window < 0 || window > maxExpiryDays {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidExpiryWindow, window)
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	batches, err := s.repo.ListBatches(models.BatchFilter{
+		LocationID: locationID,
+		ExpiresBy:  today.AddDate(0, 0, window).Format(models.ExpiryDateLayout),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ExpiringReport{
+		Date:    today.Format(models.ExpiryDateLayout),
+		Days:    window,
+		Batches: make([]models.ExpiringBatch, 0, len(batches)),
+	}
+	for _, b := range batches {
+		expiry, err := time.Parse(models.ExpiryDateLayout, b.ExpiryDate)
+		if err != nil {
+			return nil, err
+		}
+		e := models.ExpiringBatch{Batch: b, DaysLeft: int(expiry.Sub(today).Hours() / 24)}
+		e.Expired = e.DaysLeft < 0
+		if e.Expired {
+			report.ExpiredQuantity += b.Quantity
+		} else {
+			report.ExpiringQuantity += b.Quantity
+		}
+		report.Batches = append(report.Batches, e)
+	}
+	return report, nil
+}
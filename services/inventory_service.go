@@ -0,0 +1,211 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"strings"
+)
+
+var (
+	ErrInvalidAdjustment = errors.New("adjustment tidak valid")
+	ErrInvalidStockCount = errors.New("data stock opname tidak valid")
+)
+
+// defaultCountDevice - dipakai kalau kiriman hasil hitung tidak menyebut device
+const defaultCountDevice = "default"
+
+// InventoryConfig - kebijakan stok dari environment
+type InventoryConfig struct {
+	// AllowNegativeStock - adjustment boleh membuat stok minus
+	AllowNegativeStock bool
+
+	// CostingMethod - HPP stok keluar lewat adjustment / stock opname
+	CostingMethod repositories.CostingMethod
+
+	// Default saran reorder: panjang window penjualan (hari), alpha exponential
+	// smoothing dan berapa hari stok harus cukup setelah barang datang
+	ReorderWindowDays int
+	ReorderAlpha      float64
+	ReorderCoverDays  int
+}
+
+type InventoryService struct {
+	repo      repositories.InventoryRepository
+	countRepo repositories.StockCountRepository
+	config    InventoryConfig
+}
+
+func NewInventoryService(repo repositories.InventoryRepository, countRepo repositories.StockCountRepository, config InventoryConfig) *InventoryService {
+	return &InventoryService{repo: repo, countRepo: countRepo, config: config}
+}
+
+func (s *InventoryService) stockPolicy() repositories.StockPolicy {
+	return repositories.StockPolicy{AllowNegative: s.config.AllowNegativeStock, Costing: s.config.CostingMethod}
+}
+
+// Adjust - ubah stok relatif untuk satu atau beberapa produk sekaligus
+func (s *InventoryService) Adjust(req models.StockAdjustmentRequest, user string) (*models.StockAdjustment, error) {
+	if err := validateAdjustment(&req); err != nil {
+		return nil, err
+	}
+
+	adj := &models.StockAdjustment{
+		LocationID: locationOrDefault(req.LocationID),
+		Reason:     req.Reason,
+		Note:       req.Note,
+		User:       user,
+		Items:      req.Items,
+	}
+	if err := s.repo.CreateAdjustment(adj, s.stockPolicy()); err != nil {
+		return nil, err
+	}
+
+	return adj, nil
+}
+
+func validateAdjustment(req *models.StockAdjustmentRequest) error {
+	req.Reason = strings.ToLower(strings.TrimSpace(req.Reason))
+	req.Note = strings.TrimSpace(req.Note)
+
+	switch req.Reason {
+	case models.AdjustmentDamaged, models.AdjustmentExpired, models.AdjustmentLost, models.AdjustmentFound, models.AdjustmentCorrection:
+	default:
+		return fmt.Errorf("%w: reason harus damaged, expired, lost, found atau correction", ErrInvalidAdjustment)
+	}
+	if len(req.Items) == 0 {
+		return fmt.Errorf("%w: items wajib diisi", ErrInvalidAdjustment)
+	}
+
+	seen := make(map[int]bool, len(req.Items))
+	for i := range req.Items {
+		item := &req.Items[i]
+		item.LotNumber = strings.TrimSpace(item.LotNumber)
+		if item.ProductID <= 0 {
+			return fmt.Errorf("%w: product_id wajib diisi", ErrInvalidAdjustment)
+		}
+		if item.Quantity == 0 {
+			return fmt.Errorf("%w: quantity product id %d tidak boleh 0", ErrInvalidAdjustment, item.ProductID)
+		}
+		if err := repositories.ValidateLot(item.LotNumber, item.ExpiryDate); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidAdjustment, err)
+		}
+		if item.Quantity < 0 && item.ExpiryDate != "" {
+			return fmt.Errorf("%w: expiry_date hanya untuk penambahan stok", ErrInvalidAdjustment)
+		}
+		serials, err := repositories.NormalizeSerials(item.Serials, max(item.Quantity, -item.Quantity))
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidAdjustment, err)
+		}
+		item.Serials = serials
+		if seen[item.ProductID] {
+			return fmt.Errorf("%w: product id %d muncul lebih dari sekali", ErrInvalidAdjustment, item.ProductID)
+		}
+		seen[item.ProductID] = true
+	}
+
+	return nil
+}
+
+// OpenCount - buka sesi stock opname, stok sistem produk terpilih di-snapshot
+func (s *InventoryService) OpenCount(req models.StockCountRequest, user string) (*models.StockCount, error) {
+	for _, id := range append(append([]int(nil), req.ProductIDs...), req.CategoryIDs...) {
+		if id <= 0 {
+			return nil, fmt.Errorf("%w: product_ids dan category_ids harus lebih dari 0", ErrInvalidStockCount)
+		}
+	}
+
+	count := &models.StockCount{
+		LocationID: locationOrDefault(req.LocationID),
+		Note:       strings.TrimSpace(req.Note),
+		CreatedBy:  user,
+	}
+	if err := s.countRepo.Create(count, req.ProductIDs, req.CategoryIDs); err != nil {
+		return nil, err
+	}
+
+	return count, nil
+}
+
+func (s *InventoryService) ListCounts() ([]models.StockCount, error) {
+	return s.countRepo.GetAll()
+}
+
+func (s *InventoryService) GetCount(id int) (*models.StockCount, error) {
+	return s.countRepo.GetByID(id)
+}
+
+// SubmitCount - simpan hasil hitung satu device, lalu kembalikan sesi terbaru
+func (s *InventoryService) SubmitCount(id int, sub models.StockCountSubmission, user string) (*models.StockCount, error) {
+	sub.Device = strings.TrimSpace(sub.Device)
+	if sub.Device == "" {
+		sub.Device = defaultCountDevice
+	}
+	if len(sub.Items) == 0 {
+		return nil, fmt.Errorf("%w: items wajib diisi", ErrInvalidStockCount)
+	}
+
+	seen := make(map[int]bool, len(sub.Items))
+	for _, item := range sub.Items {
+		if item.Quantity < 0 {
+			return nil, fmt.Errorf("%w: quantity product id %d tidak boleh negatif", ErrInvalidStockCount, item.ProductID)
+		}
+		if seen[item.ProductID] {
+			return nil, fmt.Errorf("%w: product id %d muncul lebih dari sekali", ErrInvalidStockCount, item.ProductID)
+		}
+		seen[item.ProductID] = true
+	}
+
+	if err := s.countRepo.Submit(id, sub, user); err != nil {
+		return nil, err
+	}
+	return s.countRepo.GetByID(id)
+}
+
+// CountVariance - laporan selisih quantity dan nilai (harga jual) sesi opname
+func (s *InventoryService) CountVariance(id int) (*models.StockCountVariance, error) {
+	count, err := s.countRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return stockCountVariance(count), nil
+}
+
+func stockCountVariance(count *models.StockCount) *models.StockCountVariance {
+	report := &models.StockCountVariance{
+		CountID:    count.ID,
+		Status:     count.Status,
+		TotalLines: len(count.Lines),
+		Lines:      make([]models.StockCountLine, 0),
+	}
+	for _, line := range count.Lines {
+		if line.CountedQuantity == nil {
+			continue
+		}
+		report.CountedLines++
+		if line.Variance == 0 {
+			continue
+		}
+
+		report.TotalVarianceQty += line.Variance
+		report.TotalVarianceValue += line.VarianceValue
+		if line.VarianceValue < 0 {
+			report.ShortageValue += line.VarianceValue
+		} else {
+			report.SurplusValue += line.VarianceValue
+		}
+		report.Lines = append(report.Lines, line)
+	}
+	return report
+}
+
+// FinalizeCount - posting selisih sebagai stock adjustment (reason correction).
+// Produk yang belum dihitung dilewati, stoknya tidak diubah.
+func (s *InventoryService) FinalizeCount(id int, user string) (*models.StockCount, error) {
+	return s.countRepo.Finalize(id, user, s.stockPolicy())
+}
+
+func (s *InventoryService) CancelCount(id int, user string) error {
+	return s.countRepo.Cancel(id, user)
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+
+	"kasir-api/models"
+)
+
+var ErrInvalidLabelRequest = errors.New("permintaan label tidak valid")
+
+const maxLabels = 500
+
+// label - satu label rak: nama, harga dan barcode (SVG inline)
+type label struct {
+	Name    string
+	Price   string
+	Code    string
+	Barcode template.HTML
+}
+
+var labelSheetTemplate = template.Must(template.New("labels").Parse(`<!DOCTYPE html>
+<html lang="id">
+<head>
+<meta charset="utf-8">
+<title>Label Produk</title>
+<style>
+  @page { size: A4; margin: 8mm; }
+  body { margin: 0; font-family: sans-serif; }
+  .sheet { display: grid; grid-template-columns: repeat(3, 1fr); gap: 4mm; }
+  .label { border: 1px dashed #999; padding: 3mm; text-align: center; page-break-inside: avoid; }
+  .name { font-size: 11pt; height: 2.6em; overflow: hidden; }
+  .price { font-size: 16pt; font-weight: bold; margin: 1mm 0; }
+  .barcode svg { width: 100%; height: auto; max-height: 22mm; }
+  .nocode { font-size: 9pt; color: #999; }
+</style>
+</head>
+<body>
+<div class="sheet">
+{{- range .}}
+  <div class="label">
+    <div class="name">{{.Name}}</div>
+    <div class="price">{{.Price}}</div>
+    {{- if .Code}}
+    <div class="barcode">{{.Barcode}}</div>
+    {{- else}}
+    <div class="nocode">tanpa barcode</div>
+    {{- end}}
+  </div>
+{{- end}}
+</div>
+</body>
+</html>
+`))
+
+// LabelSheet - halaman HTML siap cetak, copies label per produk sesuai urutan ids
+func (s *ProductService) LabelSheet(ids []int, copies int) ([]byte, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: product_ids wajib diisi", ErrInvalidLabelRequest)
+	}
+	if copies == 0 {
+		copies = 1
+	}
+	if copies < 0 || len(ids)*copies > maxLabels {
+		return nil, fmt.Errorf("%w: copies minimal 1 dan total label maksimal %d", ErrInvalidLabelRequest, maxLabels)
+	}
+
+	labels := make([]label, 0, len(ids)*copies)
+	for _, id := range ids {
+		p, err := s.repo.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+
+		l := label{Name: p.Name, Price: formatRupiah(p.Price)}
+		if code, symbology := labelBarcode(*p); code != "" {
+			modules, text, err := encodeBarcode(code, symbology)
+			if err != nil {
+				return nil, err
+			}
+			l.Code = code
+			l.Barcode = template.HTML(barcodeSVG(modules, text))
+		}
+		for range copies {
+			labels = append(labels, l)
+		}
+	}
+
+	var b bytes.Buffer
+	if err := labelSheetTemplate.Execute(&b, labels); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// labelBarcode - barcode yang dicetak di label: EAN-13 / UPC dulu, lalu kode
+// internal, terakhir SKU
+func labelBarcode(p models.ProductResponse) (string, string) {
+	for _, b := range p.Barcodes {
+		if b.Type == models.BarcodeEAN13 || b.Type == models.BarcodeUPC {
+			return b.Code, SymbologyEAN13
+		}
+	}
+	if len(p.Barcodes) > 0 {
+		return p.Barcodes[0].Code, SymbologyCode128
+	}
+	if p.SKU != "" {
+		return p.SKU, SymbologyCode128
+	}
+	return "", ""
+}
+
+// formatRupiah - 6000 -> "Rp 6.000"
+func formatRupiah(amount float64) string {
+	digits := strconv.FormatInt(int64(amount), 10)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(d)
+	}
+	return "Rp " + b.String()
+}
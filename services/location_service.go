@@ -0,0 +1,130 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"strings"
+)
+
+var (
+	ErrInvalidLocation      = errors.New("data lokasi tidak valid")
+	ErrInvalidStockTransfer = errors.New("data transfer stok tidak valid")
+)
+
+type LocationService struct {
+	repo         repositories.LocationRepository
+	transferRepo repositories.StockTransferRepository
+}
+
+func NewLocationService(repo repositories.LocationRepository, transferRepo repositories.StockTransferRepository) *LocationService {
+	return &LocationService{repo: repo, transferRepo: transferRepo}
+}
+
+// locationOrDefault - location_id kosong di request berarti lokasi bawaan
+func locationOrDefault(id int) int {
+	if id == 0 {
+		return models.DefaultLocationID
+	}
+	return id
+}
+
+func (s *LocationService) GetLocations() ([]models.Location, error) {
+	return s.repo.GetAll()
+}
+
+func (s *LocationService) CreateLocation(location *models.Location) error {
+	if err := validateLocation(location); err != nil {
+		return err
+	}
+	return s.repo.Create(location)
+}
+
+func (s *LocationService) GetLocation(id int) (*models.Location, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *LocationService) UpdateLocation(location *models.Location) error {
+	if err := validateLocation(location); err != nil {
+		return err
+	}
+	return s.repo.Update(location)
+}
+
+// DeleteLocation - lokasi bawaan dipakai sebagai default request, tidak boleh dihapus
+func (s *LocationService) DeleteLocation(id int) error {
+	if id == models.DefaultLocationID {
+		return fmt.Errorf("%w: lokasi bawaan tidak bisa dihapus", repositories.ErrLocationInUse)
+	}
+	return s.repo.Delete(id)
+}
+
+func validateLocation(location *models.Location) error {
+	location.Name = strings.TrimSpace(location.Name)
+	location.Type = strings.ToLower(strings.TrimSpace(location.Type))
+	location.Address = strings.TrimSpace(location.Address)
+	if location.Name == "" {
+		return fmt.Errorf("%w: name wajib diisi", ErrInvalidLocation)
+	}
+	if location.Type != models.LocationOutlet && location.Type != models.LocationWarehouse {
+		return fmt.Errorf("%w: type harus outlet atau warehouse", ErrInvalidLocation)
+	}
+	return nil
+}
+
+func (s *LocationService) GetTransfers(filter models.StockTransferFilter) ([]models.StockTransfer, error) {
+	return s.transferRepo.GetAll(filter)
+}
+
+// CreateTransfer - transfer baru selalu berstatus requested
+func (s *LocationService) CreateTransfer(req models.StockTransferRequest, user string) (*models.StockTransfer, error) {
+	if req.FromLocationID <= 0 || req.ToLocationID <= 0 {
+		return nil, fmt.Errorf("%w: from_location_id dan to_location_id wajib diisi", ErrInvalidStockTransfer)
+	}
+	if req.FromLocationID == req.ToLocationID {
+		return nil, fmt.Errorf("%w: lokasi asal dan tujuan tidak boleh sama", ErrInvalidStockTransfer)
+	}
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("%w: items wajib diisi", ErrInvalidStockTransfer)
+	}
+
+	transfer := &models.StockTransfer{
+		FromLocationID: req.FromLocationID,
+		ToLocationID:   req.ToLocationID,
+		Note:           strings.TrimSpace(req.Note),
+		CreatedBy:      user,
+	}
+	seen := make(map[int]bool, len(req.Items))
+	for _, item := range req.Items {
+		if item.ProductID <= 0 || item.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: product_id dan quantity harus lebih dari 0", ErrInvalidStockTransfer)
+		}
+		if seen[item.ProductID] {
+			return nil, fmt.Errorf("%w: product id %d muncul lebih dari sekali", ErrInvalidStockTransfer, item.ProductID)
+		}
+		seen[item.ProductID] = true
+		transfer.Lines = append(transfer.Lines, models.StockTransferLine{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+
+	if err := s.transferRepo.Create(transfer); err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+func (s *LocationService) GetTransfer(id int) (*models.StockTransfer, error) {
+	return s.transferRepo.GetByID(id)
+}
+
+func (s *LocationService) ShipTransfer(id int, user string) (*models.StockTransfer, error) {
+	return s.transferRepo.Ship(id, user)
+}
+
+func (s *LocationService) ReceiveTransfer(id int, user string) (*models.StockTransfer, error) {
+	return s.transferRepo.Receive(id, user)
+}
+
+func (s *LocationService) CancelTransfer(id int, user string) (*models.StockTransfer, error) {
+	return s.transferRepo.Cancel(id, user)
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"kasir-api/models"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var ErrInvalidLowStockGroup = errors.New("group_by harus category atau supplier")
+
+// LowStockHandler - subscriber event low stock. Dipanggil sinkron setelah
+// checkout tersimpan, handler yang lambat sebaiknya jalan di goroutine sendiri.
+type LowStockHandler func(event models.LowStockEvent)
+
+type lowStockSubscribers struct {
+	mu       sync.RWMutex
+	handlers []LowStockHandler
+}
+
+func (s *lowStockSubscribers) subscribe(h LowStockHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers = append(s.handlers, h)
+}
+
+// publish - panic di satu handler tidak membatalkan checkout maupun handler lain
+func (s *lowStockSubscribers) publish(events []models.LowStockEvent) {
+	if len(events) == 0 {
+		return
+	}
+
+	s.mu.RLock()
+	handlers := append([]LowStockHandler(nil), s.handlers...)
+	s.mu.RUnlock()
+
+	for _, event := range events {
+		for _, h := range handlers {
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						log.Printf("[LOW STOCK] handler panic untuk product id %d: %v", event.ProductID, r)
+					}
+				}()
+				h(event)
+			}()
+		}
+	}
+}
+
+// SubscribeLowStock - daftarkan handler yang dipanggil saat checkout membuat
+// stok produk turun sampai reorder point
+func (s *TransactionService) SubscribeLowStock(h LowStockHandler) {
+	s.lowStock.subscribe(h)
+}
+
+// LowStock - produk di atau di bawah reorder point, dikelompokkan per
+// category (default) atau supplier
+func (s *InventoryService) LowStock(groupBy string) (*models.LowStockReport, error) {
+	groupBy = strings.ToLower(strings.TrimSpace(groupBy))
+	if groupBy == "" {
+		groupBy = models.LowStockByCategory
+	}
+	if groupBy != models.LowStockByCategory && groupBy != models.LowStockBySupplier {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidLowStockGroup, groupBy)
+	}
+
+	items, err := s.repo.ListLowStock()
+	if err != nil {
+		return nil, err
+	}
+	return groupLowStock(items, groupBy), nil
+}
+
+// groupLowStock - grup urut id, produk tanpa supplier (id 0) di akhir
+func groupLowStock(items []models.LowStockItem, groupBy string) *models.LowStockReport {
+	report := &models.LowStockReport{
+		GroupBy:       groupBy,
+		TotalProducts: len(items),
+		Groups:        make([]models.LowStockGroup, 0),
+	}
+
+	index := make(map[int]int)
+	for _, item := range items {
+		id, name := item.CategoryID, item.CategoryName
+		if groupBy == models.LowStockBySupplier {
+			id, name = item.SupplierID, item.SupplierName
+			if id == 0 {
+				name = "Tanpa supplier"
+			}
+		}
+
+		i, ok := index[id]
+		if !ok {
+			i = len(report.Groups)
+			index[id] = i
+			report.Groups = append(report.Groups, models.LowStockGroup{ID: id, Name: name})
+		}
+		report.Groups[i].Products = append(report.Groups[i].Products, item)
+	}
+
+	sort.SliceStable(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i].ID, report.Groups[j].ID
+		if a == 0 || b == 0 {
+			return b == 0 && a != 0
+		}
+		return a < b
+	})
+	return report
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"testing"
+
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+func TestCheckoutPublishesLowStock(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	categories := repositories.NewMemoryCategoryRepository(store)
+	products := repositories.NewMemoryProductRepository(store)
+
+	category := &models.Category{Name: "Makanan"}
+	if err := categories.Create(category); err != nil {
+		t.Fatal(err)
+	}
+	indomie := &models.Product{Name: "Indomie Goreng", Price: 3500, Stock: 10, CategoryID: category.ID, ReorderPoint: 5, ReorderQuantity: 40}
+	if err := products.Create(indomie); err != nil {
+		t.Fatal(err)
+	}
+
+	service := NewTransactionService(
+		repositories.NewMemoryTransactionRepository(store),
+		repositories.NewMemoryIdempotencyRepository(store),
+		repositories.NewMemoryPromotionRepository(store),
+		repositories.NewMemoryPromotionRuleRepository(store),
+		TransactionConfig{LockStrategy: repositories.LockPessimistic},
+	)
+	var events []models.LowStockEvent
+	service.SubscribeLowStock(func(e models.LowStockEvent) { panic("subscriber rusak") })
+	service.SubscribeLowStock(func(e models.LowStockEvent) { events = append(events, e) })
+
+	checkout := func(quantity int) *models.Transaction {
+		t.Helper()
+		trx, err := service.Checkout(models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: indomie.ID, Quantity: quantity}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return trx
+	}
+
+	// 10 -> 6 masih di atas reorder point
+	checkout(4)
+	if len(events) != 0 {
+		t.Fatalf("events = %+v, want none", events)
+	}
+
+	// 6 -> 5 menyentuh reorder point
+	trx := checkout(1)
+	if len(events) != 1 {
+		t.Fatalf("events = %+v, want 1", events)
+	}
+	e := events[0]
+	if e.ProductID != indomie.ID || e.TransactionID != trx.ID || e.Stock != 5 || e.ReorderPoint != 5 ||
+		e.ReorderQuantity != 40 || e.LocationID != models.DefaultLocationID {
+		t.Errorf("event = %+v", e)
+	}
+
+	// sudah low stock, checkout berikutnya tidak memicu event lagi
+	checkout(2)
+	if len(events) != 1 {
+		t.Errorf("events = %d, want 1", len(events))
+	}
+}
+
+func TestGroupLowStock(t *testing.T) {
+	items := []models.LowStockItem{
+		{ProductID: 1, CategoryID: 2, CategoryName: "Minuman"},
+		{ProductID: 2, CategoryID: 1, CategoryName: "Makanan", SupplierID: 3, SupplierName: "PT Indofood"},
+		{ProductID: 3, CategoryID: 2, CategoryName: "Minuman", SupplierID: 1, SupplierName: "CV Sumber"},
+	}
+
+	report := groupLowStock(items, models.LowStockByCategory)
+	if len(report.Groups) != 2 || report.Groups[0].ID != 1 || len(report.Groups[1].Products) != 2 {
+		t.Errorf("by category = %+v", report.Groups)
+	}
+
+	report = groupLowStock(items, models.LowStockBySupplier)
+	var ids []int
+	for _, g := range report.Groups {
+		ids = append(ids, g.ID)
+	}
+	if len(ids) != 3 || ids[0] != 1 || ids[1] != 3 || ids[2] != 0 || report.Groups[2].Name != "Tanpa supplier" {
+		t.Errorf("by supplier ids = %v", ids)
+	}
+}
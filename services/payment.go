@@ -0,0 +1,58 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"kasir-api/models"
+	"strings"
+)
+
+var ErrInvalidPayment = errors.New("pembayaran tidak valid")
+
+var paymentMethods = map[string]bool{
+	models.PaymentCash:     true,
+	models.PaymentCard:     true,
+	models.PaymentQRIS:     true,
+	models.PaymentTransfer: true,
+}
+
+// settlePayments - validasi pembayaran terhadap total transaksi dan hitung kembalian.
+// Tanpa pembayaran = tunai pas. Non-tunai tidak boleh melebihi total karena
+// kembalian hanya bisa diberikan dari uang tunai.
+func settlePayments(trx *models.Transaction) error {
+	if len(trx.Payments) == 0 {
+		trx.Payments = []models.Payment{{Method: models.PaymentCash, Amount: trx.TotalAmount}}
+		if trx.TotalAmount == 0 {
+			trx.Payments = nil
+		}
+	}
+
+	tendered, nonCash := 0, 0
+	for i := range trx.Payments {
+		p := &trx.Payments[i]
+		p.Method = strings.ToLower(strings.TrimSpace(p.Method))
+
+		if !paymentMethods[p.Method] {
+			return fmt.Errorf("%w: metode %q tidak dikenal", ErrInvalidPayment, p.Method)
+		}
+		if p.Amount <= 0 {
+			return fmt.Errorf("%w: jumlah pembayaran %s harus lebih dari 0", ErrInvalidPayment, p.Method)
+		}
+
+		tendered += p.Amount
+		if p.Method != models.PaymentCash {
+			nonCash += p.Amount
+		}
+	}
+
+	if nonCash > trx.TotalAmount {
+		return fmt.Errorf("%w: non-tunai (%d) melebihi total belanja (%d)", ErrInvalidPayment, nonCash, trx.TotalAmount)
+	}
+	if tendered < trx.TotalAmount {
+		return fmt.Errorf("%w: kurang %d dari total belanja", ErrInvalidPayment, trx.TotalAmount-tendered)
+	}
+
+	trx.PaidAmount = tendered
+	trx.Change = tendered - trx.TotalAmount
+	return nil
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"kasir-api/models"
+)
+
+func TestSettlePayments(t *testing.T) {
+	tests := []struct {
+		name       string
+		total      int
+		payments   []models.Payment
+		wantErr    bool
+		wantPaid   int
+		wantChange int
+	}{
+		{"tanpa pembayaran = tunai pas", 25000, nil, false, 25000, 0},
+		{"tunai dengan kembalian", 25000, []models.Payment{{Method: "cash", Amount: 50000}}, false, 50000, 25000},
+		{"split kartu + tunai", 25000, []models.Payment{{Method: "card", Amount: 20000}, {Method: "CASH", Amount: 10000}}, false, 30000, 5000},
+		{"qris pas", 25000, []models.Payment{{Method: "qris", Amount: 25000}}, false, 25000, 0},
+		{"tunai kurang", 25000, []models.Payment{{Method: "cash", Amount: 20000}}, true, 0, 0},
+		{"non-tunai lebih dari total", 25000, []models.Payment{{Method: "transfer", Amount: 30000}}, true, 0, 0},
+		{"metode tidak dikenal", 25000, []models.Payment{{Method: "voucher", Amount: 25000}}, true, 0, 0},
+		{"jumlah nol", 25000, []models.Payment{{Method: "cash", Amount: 0}, {Method: "cash", Amount: 25000}}, true, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trx := &models.Transaction{TotalAmount: tt.total, Payments: tt.payments}
+			err := settlePayments(trx)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPayment) {
+					t.Fatalf("err = %v, want ErrInvalidPayment", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if trx.PaidAmount != tt.wantPaid || trx.Change != tt.wantChange {
+				t.Errorf("paid/change = %d/%d, want %d/%d", trx.PaidAmount, trx.Change, tt.wantPaid, tt.wantChange)
+			}
+		})
+	}
+}
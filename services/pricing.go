@@ -0,0 +1,168 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"kasir-api/models"
+	"sort"
+	"time"
+)
+
+var (
+	ErrInvalidDiscount        = errors.New("diskon tidak valid")
+	ErrPromotionNotApplicable = errors.New("promo tidak bisa dipakai")
+)
+
+// discountAmount - nilai potongan dari sebuah diskon terhadap base
+func discountAmount(d *models.Discount, base int) (int, error) {
+	if d == nil {
+		return 0, nil
+	}
+
+	switch d.Type {
+	case models.DiscountPercent:
+		if d.Value < 0 || d.Value > 100 {
+			return 0, fmt.Errorf("%w: persen harus 0-100", ErrInvalidDiscount)
+		}
+		return base * d.Value / 100, nil
+	case models.DiscountFixed:
+		if d.Value < 0 {
+			return 0, fmt.Errorf("%w: potongan tidak boleh negatif", ErrInvalidDiscount)
+		}
+		if d.Value > base {
+			return 0, fmt.Errorf("%w: potongan %d melebihi harga %d", ErrInvalidDiscount, d.Value, base)
+		}
+		return d.Value, nil
+	default:
+		return 0, fmt.Errorf("%w: tipe %q tidak dikenal", ErrInvalidDiscount, d.Type)
+	}
+}
+
+// applyDiscounts - hitung diskon per baris, promo otomatis, diskon transaksi dan promo code.
+// Urutan: diskon baris -> promo otomatis -> diskon transaksi -> promo code. Baris
+// yang sudah diberi diskon manual tidak ikut promo otomatis. Minimal belanja promo
+// dihitung dari total setelah diskon baris dan promo otomatis. Diskon transaksi +
+// promo code dialokasikan ke setiap baris (OrderDiscount) proporsional terhadap subtotalnya.
+func applyDiscounts(trx *models.Transaction, req models.CheckoutRequest, rules []models.PromotionRule, promo *models.Promotion, now time.Time) error {
+	trx.LineDiscount, trx.RuleDiscount, trx.OrderDiscount, trx.PromoDiscount = 0, 0, 0, 0
+	trx.PromoCode, trx.PromotionID = "", 0
+
+	for i := range trx.Details {
+		d := &trx.Details[i]
+		gross := d.UnitPrice * d.Quantity
+
+		amount, err := discountAmount(req.Items[i].Discount, gross)
+		if err != nil {
+			return err
+		}
+
+		d.LineDiscount = amount
+		d.RuleDiscount, d.OrderDiscount, d.Promotions = 0, 0, nil
+		d.Subtotal = gross - amount
+		trx.LineDiscount += amount
+	}
+
+	lines := make([]cartLine, len(trx.Details))
+	for i, d := range trx.Details {
+		lines[i] = cartLine{productID: d.ProductID, categoryID: d.CategoryID, unitPrice: d.UnitPrice}
+		if req.Items[i].Discount == nil {
+			lines[i].quantity = d.Quantity
+		}
+	}
+	outcome := evaluatePromotionRules(rules, lines, now)
+	for i := range trx.Details {
+		d := &trx.Details[i]
+		d.Promotions = outcome.applied[i]
+		for _, p := range d.Promotions {
+			d.RuleDiscount += p.Discount
+		}
+		d.Subtotal -= d.RuleDiscount
+		trx.RuleDiscount += d.RuleDiscount
+	}
+
+	afterLine := trx.GrossAmount - trx.LineDiscount - trx.RuleDiscount
+
+	orderDiscount, err := discountAmount(req.Discount, afterLine)
+	if err != nil {
+		return err
+	}
+	trx.OrderDiscount = orderDiscount
+
+	if promo != nil {
+		if afterLine < promo.MinSpend {
+			return fmt.Errorf("%w: minimal belanja %d", ErrPromotionNotApplicable, promo.MinSpend)
+		}
+
+		remaining := afterLine - orderDiscount
+		promoDiscount := 0
+		if promo.Type == models.DiscountFixed {
+			// potongan promo tidak boleh membuat total minus
+			promoDiscount = min(promo.Value, remaining)
+		} else {
+			promoDiscount, err = discountAmount(&models.Discount{Type: promo.Type, Value: promo.Value}, remaining)
+			if err != nil {
+				return err
+			}
+		}
+
+		trx.PromoDiscount = promoDiscount
+		trx.PromoCode = promo.Code
+		trx.PromotionID = promo.ID
+	}
+
+	allocateOrderDiscount(trx.Details, trx.OrderDiscount+trx.PromoDiscount)
+
+	trx.TotalAmount = 0
+	for _, d := range trx.Details {
+		trx.TotalAmount += d.Subtotal
+	}
+
+	return nil
+}
+
+// allocateOrderDiscount - bagi amount ke baris proporsional terhadap Subtotal
+func allocateOrderDiscount(details []models.TransactionDetail, amount int) {
+	weights := make([]int, len(details))
+	for i, d := range details {
+		weights[i] = d.Subtotal
+	}
+
+	for i, share := range allocateProportional(weights, amount) {
+		details[i].OrderDiscount = share
+		details[i].Subtotal -= share
+	}
+}
+
+// allocateProportional - bagi amount proporsional terhadap weights (largest
+// remainder), jadi jumlah hasil selalu tepat = amount
+func allocateProportional(weights []int, amount int) []int {
+	shares := make([]int, len(weights))
+	base := 0
+	for _, w := range weights {
+		base += w
+	}
+	if amount <= 0 || base <= 0 {
+		return shares
+	}
+
+	type remainder struct {
+		index int
+		value int
+	}
+	remainders := make([]remainder, 0, len(weights))
+	allocated := 0
+	for i, w := range weights {
+		portion := w * amount
+		shares[i] = portion / base
+		allocated += shares[i]
+		remainders = append(remainders, remainder{index: i, value: portion % base})
+	}
+
+	sort.SliceStable(remainders, func(i, j int) bool { return remainders[i].value > remainders[j].value })
+	for i := 0; allocated < amount; i++ {
+		shares[remainders[i%len(remainders)].index]++
+		allocated++
+	}
+
+	return shares
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"kasir-api/models"
+)
+
+func newPricingTransaction(lines ...[2]int) *models.Transaction {
+	trx := &models.Transaction{}
+	for i, line := range lines {
+		trx.Details = append(trx.Details, models.TransactionDetail{
+			ProductID: i + 1,
+			UnitPrice: line[0],
+			Quantity:  line[1],
+			Subtotal:  line[0] * line[1],
+		})
+		trx.GrossAmount += line[0] * line[1]
+	}
+	trx.TotalAmount = trx.GrossAmount
+	return trx
+}
+
+func TestApplyDiscounts(t *testing.T) {
+	promo := &models.Promotion{ID: 7, Code: "HEMAT", Type: models.DiscountFixed, Value: 5000, MinSpend: 19000}
+
+	// 3 baris: 10000, 7000 (diskon baris 10%), 3 x 1000
+	trx := newPricingTransaction([2]int{10000, 1}, [2]int{3500, 2}, [2]int{1000, 3})
+	req := models.CheckoutRequest{
+		Items: []models.CheckoutItem{
+			{},
+			{Discount: &models.Discount{Type: models.DiscountPercent, Value: 10}},
+			{},
+		},
+		Discount: &models.Discount{Type: models.DiscountFixed, Value: 1000},
+	}
+
+	if err := applyDiscounts(trx, req, nil, promo, time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if trx.LineDiscount != 700 || trx.OrderDiscount != 1000 || trx.PromoDiscount != 5000 {
+		t.Fatalf("discounts = %d/%d/%d", trx.LineDiscount, trx.OrderDiscount, trx.PromoDiscount)
+	}
+	if trx.TotalAmount != 20000-700-1000-5000 || trx.PromotionID != 7 || trx.PromoCode != "HEMAT" {
+		t.Fatalf("transaction = %+v", trx)
+	}
+
+	allocated, total := 0, 0
+	for _, d := range trx.Details {
+		allocated += d.OrderDiscount
+		total += d.Subtotal
+		if d.Subtotal != d.UnitPrice*d.Quantity-d.LineDiscount-d.OrderDiscount {
+			t.Errorf("detail %+v: subtotal tidak konsisten", d)
+		}
+	}
+	if allocated != 6000 || total != trx.TotalAmount {
+		t.Errorf("allocated = %d, sum subtotal = %d", allocated, total)
+	}
+}
+
+func TestApplyDiscountsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     models.CheckoutRequest
+		promo   *models.Promotion
+		wantErr error
+	}{
+		{"persen di atas 100", models.CheckoutRequest{Items: []models.CheckoutItem{{Discount: &models.Discount{Type: models.DiscountPercent, Value: 120}}}}, nil, ErrInvalidDiscount},
+		{"potongan melebihi harga", models.CheckoutRequest{Items: []models.CheckoutItem{{}}, Discount: &models.Discount{Type: models.DiscountFixed, Value: 20000}}, nil, ErrInvalidDiscount},
+		{"tipe tidak dikenal", models.CheckoutRequest{Items: []models.CheckoutItem{{}}, Discount: &models.Discount{Type: "gratis", Value: 1}}, nil, ErrInvalidDiscount},
+		{"minimal belanja", models.CheckoutRequest{Items: []models.CheckoutItem{{}}}, &models.Promotion{Type: models.DiscountPercent, Value: 10, MinSpend: 50000}, ErrPromotionNotApplicable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trx := newPricingTransaction([2]int{10000, 1})
+			if err := applyDiscounts(trx, tt.req, nil, tt.promo, time.Now()); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyDiscountsCapsFixedPromo(t *testing.T) {
+	trx := newPricingTransaction([2]int{3000, 1})
+	promo := &models.Promotion{Type: models.DiscountFixed, Value: 10000}
+
+	if err := applyDiscounts(trx, models.CheckoutRequest{Items: []models.CheckoutItem{{}}}, nil, promo, time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if trx.PromoDiscount != 3000 || trx.TotalAmount != 0 {
+		t.Errorf("promo discount = %d, total = %d", trx.PromoDiscount, trx.TotalAmount)
+	}
+}
+
+func TestAllocateOrderDiscountExactSum(t *testing.T) {
+	details := []models.TransactionDetail{{Subtotal: 1000}, {Subtotal: 1000}, {Subtotal: 1000}}
+	allocateOrderDiscount(details, 100)
+
+	sum := 0
+	for _, d := range details {
+		sum += d.OrderDiscount
+	}
+	if sum != 100 {
+		t.Errorf("allocated = %d, want 100", sum)
+	}
+}
+
+func TestValidatePromotion(t *testing.T) {
+	now := time.Now()
+	p := &models.Promotion{Code: " hemat10 ", Type: models.DiscountPercent, Value: 10, StartsAt: now, EndsAt: now.Add(time.Hour)}
+	if err := validatePromotion(p); err != nil || p.Code != "HEMAT10" {
+		t.Fatalf("err = %v, code = %q", err, p.Code)
+	}
+
+	p.EndsAt = now.Add(-time.Hour)
+	if err := validatePromotion(p); !errors.Is(err, ErrInvalidPromotion) {
+		t.Errorf("err = %v, want ErrInvalidPromotion", err)
+	}
+}
@@ -1,16 +1,35 @@
 package services
 
 import (
+	"errors"
 	"kasir-api/models"
 	"kasir-api/repositories"
+	"strings"
 )
 
+var (
+	ErrNegativeStock     = errors.New("stok tidak boleh negatif")
+	ErrNegativeCostPrice = errors.New("cost_price tidak boleh negatif")
+	ErrNegativeReorder   = errors.New("reorder_point dan reorder_quantity tidak boleh negatif")
+)
+
+// ProductConfig - pengaturan produk dari environment
+type ProductConfig struct {
+	// CostingMethod - HPP kalau stok dikurangi lewat update produk
+	CostingMethod repositories.CostingMethod
+
+	// BarcodePrefix - prefix EAN-13 internal yang dibuatkan otomatis, kosong = 200
+	BarcodePrefix string
+}
+
 type ProductService struct {
-	repo *repositories.ProductRepository
+	repo         repositories.ProductRepository
+	movementRepo repositories.StockMovementRepository
+	config       ProductConfig
 }
 
-func NewProductService(repo *repositories.ProductRepository) *ProductService {
-	return &ProductService{repo: repo}
+func NewProductService(repo repositories.ProductRepository, movementRepo repositories.StockMovementRepository, config ProductConfig) *ProductService {
+	return &ProductService{repo: repo, movementRepo: movementRepo, config: config}
 }
 
 func (s *ProductService) GetAll(name string) ([]models.Product, error) {
@@ -18,17 +37,96 @@ func (s *ProductService) GetAll(name string) ([]models.Product, error) {
 }
 
 func (s *ProductService) Create(data *models.Product) error {
+	if err := validateProduct(data); err != nil {
+		return err
+	}
+	data.CostMethod = string(s.config.CostingMethod)
 	return s.repo.Create(data)
 }
 
+// CreateVariant - varian baru di bawah produk parentID
+func (s *ProductService) CreateVariant(parentID int, variant *models.Product) error {
+	variant.ParentID = parentID
+	return s.Create(variant)
+}
+
+func (s *ProductService) GetVariants(parentID int) ([]models.Product, error) {
+	return s.repo.GetVariants(parentID)
+}
+
+// AssignBarcode - buatkan EAN-13 internal untuk produk tanpa barcode pabrik
+func (s *ProductService) AssignBarcode(id int) (*models.ProductResponse, error) {
+	prefix, err := ParseBarcodePrefix(s.config.BarcodePrefix)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.AssignBarcode(id, prefix); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(id)
+}
+
+// RemoveBarcode - hapus satu barcode, termasuk kode internal / EAN-13 buatan
+// sistem yang tidak ikut terhapus lewat update produk
+func (s *ProductService) RemoveBarcode(id int, code string) (*models.ProductResponse, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return nil, repositories.ErrInvalidBarcode
+	}
+	if err := s.repo.RemoveBarcode(id, code); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(id)
+}
+
+// GetByCode - produk dari hasil scan barcode (atau SKU)
+func (s *ProductService) GetByCode(code string) (*models.ProductResponse, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return nil, repositories.ErrInvalidBarcode
+	}
+	return s.repo.GetByCode(code)
+}
+
 func (s *ProductService) GetByID(id int) (*models.ProductResponse, error) {
 	return s.repo.GetByID(id)
 }
 
-func (s *ProductService) Update(product *models.Product) error {
-	return s.repo.Update(product)
+func (s *ProductService) Update(update *models.ProductUpdate) error {
+	if err := validateProduct(&update.Product); err != nil {
+		return err
+	}
+	if update.Stock != nil && *update.Stock < 0 {
+		return ErrNegativeStock
+	}
+	if (update.ReorderPoint != nil && *update.ReorderPoint < 0) || (update.ReorderQuantity != nil && *update.ReorderQuantity < 0) {
+		return ErrNegativeReorder
+	}
+	update.CostMethod = string(s.config.CostingMethod)
+	return s.repo.Update(update)
 }
 
 func (s *ProductService) Delete(id int) error {
 	return s.repo.Delete(id)
 }
+
+// GetMovements - ledger stok satu produk, error kalau produk tidak ada
+func (s *ProductService) GetMovements(id int) ([]models.StockMovement, error) {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return nil, err
+	}
+	return s.movementRepo.ListByProduct(id)
+}
+
+func validateProduct(p *models.Product) error {
+	if p.Stock < 0 {
+		return ErrNegativeStock
+	}
+	if p.CostPrice < 0 {
+		return ErrNegativeCostPrice
+	}
+	if p.ReorderPoint < 0 || p.ReorderQuantity < 0 {
+		return ErrNegativeReorder
+	}
+	return nil
+}
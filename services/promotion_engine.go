@@ -0,0 +1,275 @@
+package services
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"kasir-api/models"
+)
+
+// maxExhaustiveRules - sampai jumlah rule ini semua urutan evaluasi dicoba
+// (7! = 5040). Lebih dari itu rule diurutkan dari potongan terbesar saja.
+const maxExhaustiveRules = 7
+
+// cartLine - satu baris keranjang yang dievaluasi engine. Quantity 0 berarti
+// baris tidak ikut promo otomatis (mis. sudah diberi diskon manual).
+type cartLine struct {
+	productID  int
+	categoryID int
+	unitPrice  int
+	quantity   int
+}
+
+// ruleOutcome - hasil evaluasi satu urutan rule
+type ruleOutcome struct {
+	total   int
+	applied [][]models.AppliedPromotion // per index baris
+}
+
+// ruleUse - unit yang dipakai dan potongan satu rule di satu baris
+type ruleUse struct {
+	quantity int
+	discount int
+}
+
+// evaluatePromotionRules - pilih kombinasi promo otomatis dengan potongan terbesar.
+// Satu unit barang hanya bisa dipakai satu rule, jadi rule yang berebut unit
+// yang sama saling konflik. Setiap urutan rule dicoba, tiap rule diterapkan
+// sebanyak mungkin pada unit yang tersisa, lalu diambil urutan dengan total
+// potongan terbesar.
+func evaluatePromotionRules(rules []models.PromotionRule, lines []cartLine, now time.Time) ruleOutcome {
+	active := make([]models.PromotionRule, 0, len(rules))
+	for _, rule := range rules {
+		if ruleActiveAt(rule, now) && ruleHasEligibleLine(rule, lines) {
+			active = append(active, rule)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].ID < active[j].ID })
+
+	best := ruleOutcome{applied: make([][]models.AppliedPromotion, len(lines))}
+	if len(active) == 0 {
+		return best
+	}
+
+	if len(active) > maxExhaustiveRules {
+		value := make(map[int]int, len(active))
+		for _, rule := range active {
+			value[rule.ID] = applyRuleSequence([]models.PromotionRule{rule}, lines).total
+		}
+		sort.SliceStable(active, func(i, j int) bool { return value[active[i].ID] > value[active[j].ID] })
+		return applyRuleSequence(active, lines)
+	}
+
+	permuteRules(active, 0, func(order []models.PromotionRule) {
+		if outcome := applyRuleSequence(order, lines); outcome.total > best.total {
+			best = outcome
+		}
+	})
+
+	return best
+}
+
+// permuteRules - panggil fn untuk setiap urutan rules (rules diubah in-place)
+func permuteRules(rules []models.PromotionRule, k int, fn func([]models.PromotionRule)) {
+	if k == len(rules) {
+		fn(rules)
+		return
+	}
+	for i := k; i < len(rules); i++ {
+		rules[k], rules[i] = rules[i], rules[k]
+		permuteRules(rules, k+1, fn)
+		rules[k], rules[i] = rules[i], rules[k]
+	}
+}
+
+func applyRuleSequence(rules []models.PromotionRule, lines []cartLine) ruleOutcome {
+	remaining := make([]int, len(lines))
+	for i, line := range lines {
+		remaining[i] = line.quantity
+	}
+
+	outcome := ruleOutcome{applied: make([][]models.AppliedPromotion, len(lines))}
+	for _, rule := range rules {
+		var uses []ruleUse
+		switch rule.Type {
+		case models.RulePercent:
+			uses = applyPercentRule(rule, lines, remaining)
+		case models.RuleBuyXGetY:
+			uses = applyBuyXGetYRule(rule, lines, remaining)
+		case models.RuleBundle:
+			uses = applyBundleRule(rule, lines, remaining)
+		default:
+			continue
+		}
+
+		for i, use := range uses {
+			if use.discount <= 0 {
+				continue
+			}
+			outcome.total += use.discount
+			outcome.applied[i] = append(outcome.applied[i], models.AppliedPromotion{
+				RuleID:   rule.ID,
+				Name:     rule.Name,
+				Type:     rule.Type,
+				Quantity: use.quantity,
+				Discount: use.discount,
+			})
+		}
+	}
+
+	return outcome
+}
+
+func ruleActiveAt(rule models.PromotionRule, now time.Time) bool {
+	if !rule.Active {
+		return false
+	}
+	if rule.StartsAt != nil && now.Before(*rule.StartsAt) {
+		return false
+	}
+	if rule.EndsAt != nil && now.After(*rule.EndsAt) {
+		return false
+	}
+	if rule.StartTime == "" || rule.EndTime == "" {
+		return true
+	}
+
+	// format "15:04" bisa dibandingkan sebagai string
+	clock := now.Format("15:04")
+	if rule.StartTime <= rule.EndTime {
+		return clock >= rule.StartTime && clock < rule.EndTime
+	}
+	// jam lewat tengah malam, mis. 22:00 - 02:00
+	return clock >= rule.StartTime || clock < rule.EndTime
+}
+
+// ruleMatchesLine - untuk percent dan buy_x_get_y
+func ruleMatchesLine(rule models.PromotionRule, line cartLine) bool {
+	if slices.Contains(rule.ProductIDs, line.productID) {
+		return true
+	}
+	return rule.CategoryID != 0 && rule.CategoryID == line.categoryID
+}
+
+func ruleHasEligibleLine(rule models.PromotionRule, lines []cartLine) bool {
+	for _, line := range lines {
+		if line.quantity == 0 {
+			continue
+		}
+		if rule.Type == models.RuleBundle {
+			for _, item := range rule.BundleItems {
+				if item.ProductID == line.productID {
+					return true
+				}
+			}
+			continue
+		}
+		if ruleMatchesLine(rule, line) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyPercentRule - potongan persen untuk semua unit yang tersisa
+func applyPercentRule(rule models.PromotionRule, lines []cartLine, remaining []int) []ruleUse {
+	uses := make([]ruleUse, len(lines))
+	for i, line := range lines {
+		if remaining[i] == 0 || !ruleMatchesLine(rule, line) {
+			continue
+		}
+		uses[i] = ruleUse{
+			quantity: remaining[i],
+			discount: line.unitPrice * remaining[i] * rule.Percent / 100,
+		}
+		remaining[i] = 0
+	}
+	return uses
+}
+
+// applyBuyXGetYRule - unit yang cocok diurutkan dari yang termahal lalu dibagi
+// per kelompok BuyQuantity+GetQuantity, GetQuantity unit termurah di setiap
+// kelompok dapat potongan. Sisa unit yang tidak genap satu kelompok tidak dipakai.
+func applyBuyXGetYRule(rule models.PromotionRule, lines []cartLine, remaining []int) []ruleUse {
+	uses := make([]ruleUse, len(lines))
+	groupSize := rule.BuyQuantity + rule.GetQuantity
+	if rule.BuyQuantity <= 0 || rule.GetQuantity <= 0 {
+		return uses
+	}
+	percent := rule.Percent
+	if percent == 0 {
+		percent = 100
+	}
+
+	type unit struct{ line, price int }
+	var units []unit
+	for i, line := range lines {
+		if !ruleMatchesLine(rule, line) {
+			continue
+		}
+		for n := 0; n < remaining[i]; n++ {
+			units = append(units, unit{line: i, price: line.unitPrice})
+		}
+	}
+	sort.SliceStable(units, func(i, j int) bool { return units[i].price > units[j].price })
+
+	groups := len(units) / groupSize
+	for g := 0; g < groups; g++ {
+		for j, u := range units[g*groupSize : (g+1)*groupSize] {
+			uses[u.line].quantity++
+			remaining[u.line]--
+			if j >= rule.BuyQuantity {
+				uses[u.line].discount += u.price * percent / 100
+			}
+		}
+	}
+
+	return uses
+}
+
+// applyBundleRule - selama semua item bundle tersedia, jual seharga BundlePrice.
+// Selisih harga dibagi ke baris-baris bundle proporsional terhadap harganya.
+func applyBundleRule(rule models.PromotionRule, lines []cartLine, remaining []int) []ruleUse {
+	uses := make([]ruleUse, len(lines))
+	if len(rule.BundleItems) == 0 {
+		return uses
+	}
+
+	for {
+		take := make([]int, len(lines))
+		normal := 0
+		for _, item := range rule.BundleItems {
+			need := item.Quantity
+			for i, line := range lines {
+				if line.productID != item.ProductID || need == 0 {
+					continue
+				}
+				n := min(need, remaining[i]-take[i])
+				take[i] += n
+				need -= n
+				normal += n * line.unitPrice
+			}
+			if need > 0 {
+				return uses
+			}
+		}
+		if normal <= rule.BundlePrice {
+			return uses
+		}
+
+		weights := make([]int, len(lines))
+		for i, line := range lines {
+			weights[i] = take[i] * line.unitPrice
+		}
+		shares := allocateProportional(weights, normal-rule.BundlePrice)
+		for i := range lines {
+			if take[i] == 0 {
+				continue
+			}
+			uses[i].quantity += take[i]
+			uses[i].discount += shares[i]
+			remaining[i] -= take[i]
+		}
+	}
+}
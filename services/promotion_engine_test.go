@@ -0,0 +1,120 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"kasir-api/models"
+)
+
+func ruleDiscounts(outcome ruleOutcome) []int {
+	discounts := make([]int, len(outcome.applied))
+	for i, applied := range outcome.applied {
+		for _, p := range applied {
+			discounts[i] += p.Discount
+		}
+	}
+	return discounts
+}
+
+func TestEvaluatePromotionRules(t *testing.T) {
+	now := time.Date(2026, 1, 5, 15, 0, 0, 0, time.Local)
+	kopi := cartLine{productID: 1, categoryID: 10, unitPrice: 20000}
+	roti := cartLine{productID: 2, categoryID: 20, unitPrice: 10000}
+	teh := cartLine{productID: 3, categoryID: 10, unitPrice: 5000}
+
+	withQty := func(line cartLine, qty int) cartLine {
+		line.quantity = qty
+		return line
+	}
+
+	tests := []struct {
+		name  string
+		rules []models.PromotionRule
+		lines []cartLine
+		want  []int
+	}{
+		{
+			"beli 2 gratis 1",
+			[]models.PromotionRule{{ID: 1, Type: models.RuleBuyXGetY, ProductIDs: []int{3}, BuyQuantity: 2, GetQuantity: 1, Active: true}},
+			[]cartLine{withQty(teh, 5)},
+			[]int{5000},
+		},
+		{
+			"beli 2 gratis 1 campur, yang termurah gratis",
+			[]models.PromotionRule{{ID: 1, Type: models.RuleBuyXGetY, CategoryID: 10, BuyQuantity: 2, GetQuantity: 1, Active: true}},
+			[]cartLine{withQty(kopi, 2), withQty(teh, 1)},
+			[]int{0, 5000},
+		},
+		{
+			"bundle dibagi proporsional",
+			[]models.PromotionRule{{ID: 1, Type: models.RuleBundle, BundleItems: []models.BundleItem{{ProductID: 1, Quantity: 1}, {ProductID: 2, Quantity: 1}}, BundlePrice: 27000, Active: true}},
+			[]cartLine{withQty(kopi, 1), withQty(roti, 2)},
+			[]int{2000, 1000},
+		},
+		{
+			"happy hour di dalam jam",
+			[]models.PromotionRule{{ID: 1, Type: models.RulePercent, CategoryID: 10, Percent: 10, StartTime: "14:00", EndTime: "16:00", Active: true}},
+			[]cartLine{withQty(kopi, 1), withQty(roti, 1), withQty(teh, 2)},
+			[]int{2000, 0, 1000},
+		},
+		{
+			"happy hour di luar jam",
+			[]models.PromotionRule{{ID: 1, Type: models.RulePercent, CategoryID: 10, Percent: 10, StartTime: "16:00", EndTime: "18:00", Active: true}},
+			[]cartLine{withQty(kopi, 1)},
+			[]int{0},
+		},
+		{
+			"rule tidak aktif diabaikan",
+			[]models.PromotionRule{{ID: 1, Type: models.RulePercent, ProductIDs: []int{1}, Percent: 50}},
+			[]cartLine{withQty(kopi, 1)},
+			[]int{0},
+		},
+		{
+			"bundle lebih untung dari happy hour",
+			[]models.PromotionRule{
+				{ID: 1, Type: models.RulePercent, CategoryID: 10, Percent: 10, Active: true},
+				{ID: 2, Type: models.RuleBundle, BundleItems: []models.BundleItem{{ProductID: 1, Quantity: 1}, {ProductID: 2, Quantity: 1}}, BundlePrice: 25000, Active: true},
+			},
+			[]cartLine{withQty(kopi, 1), withQty(roti, 1)},
+			[]int{3333, 1667},
+		},
+		{
+			"happy hour lebih untung dari bundle",
+			[]models.PromotionRule{
+				{ID: 1, Type: models.RuleBundle, BundleItems: []models.BundleItem{{ProductID: 1, Quantity: 1}, {ProductID: 2, Quantity: 1}}, BundlePrice: 29000, Active: true},
+				{ID: 2, Type: models.RulePercent, CategoryID: 10, Percent: 10, Active: true},
+			},
+			[]cartLine{withQty(kopi, 1), withQty(roti, 1)},
+			[]int{2000, 0},
+		},
+		{
+			"baris diskon manual tidak ikut",
+			[]models.PromotionRule{{ID: 1, Type: models.RulePercent, ProductIDs: []int{1}, Percent: 50, Active: true}},
+			[]cartLine{withQty(kopi, 0)},
+			[]int{0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ruleDiscounts(evaluatePromotionRules(tt.rules, tt.lines, now))
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Fatalf("discounts = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRuleActiveAtOvernightWindow(t *testing.T) {
+	rule := models.PromotionRule{Active: true, StartTime: "22:00", EndTime: "02:00"}
+
+	for clock, want := range map[string]bool{"23:30": true, "01:59": true, "02:00": false, "12:00": false} {
+		now, _ := time.Parse("15:04", clock)
+		if got := ruleActiveAt(rule, now); got != want {
+			t.Errorf("%s: active = %v, want %v", clock, got, want)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"strings"
+	"time"
+)
+
+var ErrInvalidPromotion = errors.New("data promo tidak valid")
+
+type PromotionService struct {
+	repo     repositories.PromotionRepository
+	ruleRepo repositories.PromotionRuleRepository
+}
+
+func NewPromotionService(repo repositories.PromotionRepository, ruleRepo repositories.PromotionRuleRepository) *PromotionService {
+	return &PromotionService{repo: repo, ruleRepo: ruleRepo}
+}
+
+func (s *PromotionService) GetAll() ([]models.Promotion, error) {
+	return s.repo.GetAll()
+}
+
+func (s *PromotionService) Create(promotion *models.Promotion) error {
+	if err := validatePromotion(promotion); err != nil {
+		return err
+	}
+	return s.repo.Create(promotion)
+}
+
+func (s *PromotionService) GetByID(id int) (*models.Promotion, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *PromotionService) Update(promotion *models.Promotion) error {
+	if err := validatePromotion(promotion); err != nil {
+		return err
+	}
+	return s.repo.Update(promotion)
+}
+
+func (s *PromotionService) Delete(id int) error {
+	return s.repo.Delete(id)
+}
+
+func (s *PromotionService) GetRules() ([]models.PromotionRule, error) {
+	return s.ruleRepo.GetAll()
+}
+
+func (s *PromotionService) CreateRule(rule *models.PromotionRule) error {
+	if err := validatePromotionRule(rule); err != nil {
+		return err
+	}
+	return s.ruleRepo.Create(rule)
+}
+
+func (s *PromotionService) GetRuleByID(id int) (*models.PromotionRule, error) {
+	return s.ruleRepo.GetByID(id)
+}
+
+func (s *PromotionService) UpdateRule(rule *models.PromotionRule) error {
+	if err := validatePromotionRule(rule); err != nil {
+		return err
+	}
+	return s.ruleRepo.Update(rule)
+}
+
+func (s *PromotionService) DeleteRule(id int) error {
+	return s.ruleRepo.Delete(id)
+}
+
+// normalizePromoCode - kode promo tidak case sensitive
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func validatePromotion(p *models.Promotion) error {
+	p.Code = normalizePromoCode(p.Code)
+	if p.Code == "" {
+		return fmt.Errorf("%w: code wajib diisi", ErrInvalidPromotion)
+	}
+	if _, err := discountAmount(&models.Discount{Type: p.Type, Value: p.Value}, p.Value); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPromotion, err)
+	}
+	if p.MinSpend < 0 || p.UsageLimit < 0 {
+		return fmt.Errorf("%w: min_spend dan usage_limit tidak boleh negatif", ErrInvalidPromotion)
+	}
+	if p.StartsAt.IsZero() || p.EndsAt.IsZero() || !p.EndsAt.After(p.StartsAt) {
+		return fmt.Errorf("%w: ends_at harus setelah starts_at", ErrInvalidPromotion)
+	}
+	return nil
+}
+
+// promotionForCheckout - ambil promo by code dan cek masih berlaku saat ini.
+// Kuota dicek lagi secara atomic saat transaksi disimpan.
+func promotionForCheckout(repo repositories.PromotionRepository, code string, now time.Time) (*models.Promotion, error) {
+	promo, err := repo.GetByCode(normalizePromoCode(code))
+	if errors.Is(err, repositories.ErrPromotionNotFound) {
+		return nil, fmt.Errorf("%w: kode %s tidak ditemukan", ErrPromotionNotApplicable, code)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !promo.Active:
+		return nil, fmt.Errorf("%w: promo tidak aktif", ErrPromotionNotApplicable)
+	case now.Before(promo.StartsAt) || now.After(promo.EndsAt):
+		return nil, fmt.Errorf("%w: di luar periode promo", ErrPromotionNotApplicable)
+	case promo.UsageLimit > 0 && promo.UsedCount >= promo.UsageLimit:
+		return nil, fmt.Errorf("%w: %v", ErrPromotionNotApplicable, repositories.ErrPromotionExhausted)
+	}
+
+	return promo, nil
+}
+
+func validatePromotionRule(r *models.PromotionRule) error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return fmt.Errorf("%w: name wajib diisi", ErrInvalidPromotion)
+	}
+	if r.Percent < 0 || r.Percent > 100 {
+		return fmt.Errorf("%w: percent harus 0-100", ErrInvalidPromotion)
+	}
+
+	switch r.Type {
+	case models.RulePercent:
+		if r.Percent == 0 {
+			return fmt.Errorf("%w: percent wajib diisi", ErrInvalidPromotion)
+		}
+	case models.RuleBuyXGetY:
+		if r.BuyQuantity <= 0 || r.GetQuantity <= 0 {
+			return fmt.Errorf("%w: buy_quantity dan get_quantity harus lebih dari 0", ErrInvalidPromotion)
+		}
+	case models.RuleBundle:
+		if len(r.BundleItems) == 0 || r.BundlePrice <= 0 {
+			return fmt.Errorf("%w: bundle_items dan bundle_price wajib diisi", ErrInvalidPromotion)
+		}
+		for _, item := range r.BundleItems {
+			if item.ProductID <= 0 || item.Quantity <= 0 {
+				return fmt.Errorf("%w: product_id dan quantity bundle harus lebih dari 0", ErrInvalidPromotion)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: tipe %q tidak dikenal", ErrInvalidPromotion, r.Type)
+	}
+
+	if r.Type != models.RuleBundle && len(r.ProductIDs) == 0 && r.CategoryID == 0 {
+		return fmt.Errorf("%w: product_ids atau category_id wajib diisi", ErrInvalidPromotion)
+	}
+
+	if (r.StartTime == "") != (r.EndTime == "") {
+		return fmt.Errorf("%w: start_time dan end_time harus diisi berpasangan", ErrInvalidPromotion)
+	}
+	for _, clock := range []string{r.StartTime, r.EndTime} {
+		if _, err := time.Parse("15:04", clock); clock != "" && err != nil {
+			return fmt.Errorf("%w: format jam harus HH:MM", ErrInvalidPromotion)
+		}
+	}
+	if r.StartsAt != nil && r.EndsAt != nil && !r.EndsAt.After(*r.StartsAt) {
+		return fmt.Errorf("%w: ends_at harus setelah starts_at", ErrInvalidPromotion)
+	}
+
+	return nil
+}
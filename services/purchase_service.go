@@ -0,0 +1,158 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"strings"
+)
+
+var (
+	ErrInvalidSupplier      = errors.New("data supplier tidak valid")
+	ErrInvalidPurchaseOrder = errors.New("data purchase order tidak valid")
+)
+
+type PurchaseService struct {
+	supplierRepo repositories.SupplierRepository
+	orderRepo    repositories.PurchaseOrderRepository
+}
+
+func NewPurchaseService(supplierRepo repositories.SupplierRepository, orderRepo repositories.PurchaseOrderRepository) *PurchaseService {
+	return &PurchaseService{supplierRepo: supplierRepo, orderRepo: orderRepo}
+}
+
+func (s *PurchaseService) GetSuppliers() ([]models.Supplier, error) {
+	return s.supplierRepo.GetAll()
+}
+
+func (s *PurchaseService) CreateSupplier(supplier *models.Supplier) error {
+	if err := validateSupplier(supplier); err != nil {
+		return err
+	}
+	return s.supplierRepo.Create(supplier)
+}
+
+func (s *PurchaseService) GetSupplier(id int) (*models.Supplier, error) {
+	return s.supplierRepo.GetByID(id)
+}
+
+func (s *PurchaseService) UpdateSupplier(supplier *models.Supplier) error {
+	if err := validateSupplier(supplier); err != nil {
+		return err
+	}
+	return s.supplierRepo.Update(supplier)
+}
+
+func (s *PurchaseService) DeleteSupplier(id int) error {
+	return s.supplierRepo.Delete(id)
+}
+
+func validateSupplier(supplier *models.Supplier) error {
+	supplier.Name = strings.TrimSpace(supplier.Name)
+	supplier.Phone = strings.TrimSpace(supplier.Phone)
+	supplier.Email = strings.TrimSpace(supplier.Email)
+	supplier.Address = strings.TrimSpace(supplier.Address)
+	if supplier.Name == "" {
+		return fmt.Errorf("%w: name wajib diisi", ErrInvalidSupplier)
+	}
+	if supplier.LeadTimeDays < 0 {
+		return fmt.Errorf("%w: lead_time_days tidak boleh negatif", ErrInvalidSupplier)
+	}
+	return nil
+}
+
+func (s *PurchaseService) GetPurchaseOrders(filter models.PurchaseOrderFilter) ([]models.PurchaseOrder, error) {
+	return s.orderRepo.GetAll(filter)
+}
+
+// CreatePurchaseOrder - PO baru selalu berstatus draft
+func (s *PurchaseService) CreatePurchaseOrder(req models.PurchaseOrderRequest, user string) (*models.PurchaseOrder, error) {
+	po, err := purchaseOrderFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	po.CreatedBy = user
+
+	if err := s.orderRepo.Create(po); err != nil {
+		return nil, err
+	}
+	return po, nil
+}
+
+func (s *PurchaseService) GetPurchaseOrder(id int) (*models.PurchaseOrder, error) {
+	return s.orderRepo.GetByID(id)
+}
+
+func (s *PurchaseService) UpdatePurchaseOrder(id int, req models.PurchaseOrderRequest) (*models.PurchaseOrder, error) {
+	po, err := purchaseOrderFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	po.ID = id
+
+	if err := s.orderRepo.Update(po); err != nil {
+		return nil, err
+	}
+	return s.orderRepo.GetByID(id)
+}
+
+func (s *PurchaseService) DeletePurchaseOrder(id int) error {
+	return s.orderRepo.Delete(id)
+}
+
+// SendPurchaseOrder - draft -> sent, setelah ini PO tidak bisa diubah lagi
+func (s *PurchaseService) SendPurchaseOrder(id int) (*models.PurchaseOrder, error) {
+	if err := s.orderRepo.Send(id); err != nil {
+		return nil, err
+	}
+	return s.orderRepo.GetByID(id)
+}
+
+func (s *PurchaseService) ReceiveGoods(id int, req models.GoodsReceiptRequest, user string) (*models.GoodsReceipt, error) {
+	req.Note = strings.TrimSpace(req.Note)
+	return s.orderRepo.Receive(id, req, user)
+}
+
+func (s *PurchaseService) ListReceipts(id int) ([]models.GoodsReceipt, error) {
+	if _, err := s.orderRepo.GetByID(id); err != nil {
+		return nil, err
+	}
+	return s.orderRepo.ListReceipts(id)
+}
+
+func purchaseOrderFromRequest(req models.PurchaseOrderRequest) (*models.PurchaseOrder, error) {
+	if req.SupplierID <= 0 {
+		return nil, fmt.Errorf("%w: supplier_id wajib diisi", ErrInvalidPurchaseOrder)
+	}
+	if len(req.Lines) == 0 {
+		return nil, fmt.Errorf("%w: lines wajib diisi", ErrInvalidPurchaseOrder)
+	}
+
+	po := &models.PurchaseOrder{
+		SupplierID: req.SupplierID,
+		LocationID: locationOrDefault(req.LocationID),
+		Note:       strings.TrimSpace(req.Note),
+	}
+	seen := make(map[int]bool, len(req.Lines))
+	for _, line := range req.Lines {
+		if line.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: quantity product id %d harus lebih dari 0", ErrInvalidPurchaseOrder, line.ProductID)
+		}
+		if line.UnitCost < 0 {
+			return nil, fmt.Errorf("%w: unit_cost product id %d tidak boleh negatif", ErrInvalidPurchaseOrder, line.ProductID)
+		}
+		if seen[line.ProductID] {
+			return nil, fmt.Errorf("%w: product id %d muncul lebih dari sekali", ErrInvalidPurchaseOrder, line.ProductID)
+		}
+		seen[line.ProductID] = true
+
+		po.Lines = append(po.Lines, models.PurchaseOrderLine{
+			ProductID: line.ProductID,
+			Quantity:  line.Quantity,
+			UnitCost:  line.UnitCost,
+		})
+	}
+
+	return po, nil
+}
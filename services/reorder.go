@@ -0,0 +1,154 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"kasir-api/models"
+	"math"
+	"sort"
+	"time"
+)
+
+var ErrInvalidReorderParams = errors.New("parameter saran reorder tidak valid")
+
+// Default saran reorder kalau tidak diatur lewat environment / query
+const (
+	defaultReorderWindowDays = 28
+	defaultReorderAlpha      = 0.3
+	defaultReorderCoverDays  = 14
+
+	maxReorderDays = 365
+)
+
+// ReorderSuggestions - saran jumlah pembelian per produk dari velocity penjualan.
+// Hanya produk yang perlu dipesan (suggested_quantity > 0) yang dikembalikan.
+func (s *InventoryService) ReorderSuggestions(params models.ReorderParams) (*models.ReorderSuggestionReport, error) {
+	params = s.reorderParams(params)
+	if params.WindowDays < 1 || params.WindowDays > maxReorderDays {
+		return nil, fmt.Errorf("%w: window_days harus 1 sampai %d", ErrInvalidReorderParams, maxReorderDays)
+	}
+	if params.Alpha <= 0 || params.Alpha > 1 {
+		return nil, fmt.Errorf("%w: alpha harus lebih dari 0 dan maksimal 1", ErrInvalidReorderParams)
+	}
+	if params.CoverDays < 1 || params.CoverDays > maxReorderDays {
+		return nil, fmt.Errorf("%w: cover_days harus 1 sampai %d", ErrInvalidReorderParams, maxReorderDays)
+	}
+
+	now := time.Now()
+	days := reorderWindow(now, params.WindowDays)
+	since, _ := time.ParseInLocation("2006-01-02", days[0], time.Local)
+
+	demand, err := s.repo.ListProductDemand(since)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ReorderSuggestionReport{
+		GeneratedAt: now,
+		WindowDays:  params.WindowDays,
+		Alpha:       params.Alpha,
+		CoverDays:   params.CoverDays,
+		Suggestions: make([]models.ReorderSuggestion, 0),
+	}
+	for _, d := range demand {
+		if suggestion := suggestReorder(d, days, params); suggestion.SuggestedQuantity > 0 {
+			report.Suggestions = append(report.Suggestions, suggestion)
+		}
+	}
+	sortReorderSuggestions(report.Suggestions)
+
+	return report, nil
+}
+
+func (s *InventoryService) reorderParams(params models.ReorderParams) models.ReorderParams {
+	if params.WindowDays == 0 {
+		params.WindowDays = valueOr(s.config.ReorderWindowDays, defaultReorderWindowDays)
+	}
+	if params.Alpha == 0 {
+		params.Alpha = s.config.ReorderAlpha
+		if params.Alpha == 0 {
+			params.Alpha = defaultReorderAlpha
+		}
+	}
+	if params.CoverDays == 0 {
+		params.CoverDays = valueOr(s.config.ReorderCoverDays, defaultReorderCoverDays)
+	}
+	return params
+}
+
+func valueOr(v, fallback int) int {
+	if v == 0 {
+		return fallback
+	}
+	return v
+}
+
+// reorderWindow - tanggal window, terlama dulu, hari ini termasuk
+func reorderWindow(now time.Time, windowDays int) []string {
+	days := make([]string, windowDays)
+	for i := range days {
+		days[i] = now.AddDate(0, 0, i-windowDays+1).Format("2006-01-02")
+	}
+	return days
+}
+
+// suggestReorder - velocity pakai exponential smoothing yang dimulai dari
+// moving average window, jadi penjualan terakhir lebih berpengaruh.
+// Target stok = velocity x (lead time + cover days), dikurangi stok dan PO
+// yang belum diterima. reorder_quantity produk dipakai sebagai order minimum.
+func suggestReorder(d models.ProductDemand, days []string, params models.ReorderParams) models.ReorderSuggestion {
+	total := 0
+	for _, day := range days {
+		total += d.DailySales[day]
+	}
+	average := float64(total) / float64(len(days))
+
+	smoothed := average
+	for _, day := range days {
+		smoothed = params.Alpha*float64(d.DailySales[day]) + (1-params.Alpha)*smoothed
+	}
+
+	suggestion := models.ReorderSuggestion{
+		ProductID:        d.ProductID,
+		ProductName:      d.ProductName,
+		SupplierID:       d.SupplierID,
+		SupplierName:     d.SupplierName,
+		LeadTimeDays:     d.LeadTimeDays,
+		Stock:            d.Stock,
+		OnOrder:          d.OnOrder,
+		MovingAverage:    round2(average),
+		SmoothedVelocity: round2(smoothed),
+	}
+	if smoothed <= 0 {
+		return suggestion
+	}
+
+	cover := math.Max(float64(d.Stock), 0) / smoothed
+	rounded := round2(cover)
+	suggestion.DaysOfCover = &rounded
+	suggestion.Urgent = cover <= float64(d.LeadTimeDays)
+
+	suggestion.TargetStock = int(math.Ceil(smoothed * float64(d.LeadTimeDays+params.CoverDays)))
+	if need := suggestion.TargetStock - d.Stock - d.OnOrder; need > 0 {
+		suggestion.SuggestedQuantity = max(need, d.ReorderQuantity)
+	}
+	return suggestion
+}
+
+// sortReorderSuggestions - urgent dulu, lalu yang stoknya paling cepat habis
+func sortReorderSuggestions(suggestions []models.ReorderSuggestion) {
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.Urgent != b.Urgent {
+			return a.Urgent
+		}
+		if *a.DaysOfCover != *b.DaysOfCover {
+			return *a.DaysOfCover < *b.DaysOfCover
+		}
+		return a.ProductID < b.ProductID
+	})
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"testing"
+
+	"kasir-api/models"
+)
+
+func TestSuggestReorder(t *testing.T) {
+	days := []string{"2026-01-01", "2026-01-02", "2026-01-03", "2026-01-04"}
+	params := models.ReorderParams{WindowDays: len(days), Alpha: 0.5, CoverDays: 10}
+
+	tests := []struct {
+		name          string
+		demand        models.ProductDemand
+		wantAverage   float64
+		wantSmoothed  float64
+		wantTarget    int
+		wantSuggested int
+		wantUrgent    bool
+	}{
+		{
+			// rata-rata 4, smoothing 4 -> 2 -> 1 -> 4.5 -> 6.25 condong ke penjualan terakhir,
+			// penjualan di luar window diabaikan
+			name:        "penjualan naik",
+			demand:      models.ProductDemand{Stock: 20, LeadTimeDays: 2, DailySales: map[string]int{"2026-01-01": 0, "2026-01-03": 8, "2026-01-04": 8, "2025-12-31": 50}},
+			wantAverage: 4, wantSmoothed: 6.25, wantTarget: 75, wantSuggested: 55,
+		},
+		{
+			name:        "tidak terjual",
+			demand:      models.ProductDemand{Stock: 0, LeadTimeDays: 2, DailySales: map[string]int{}},
+			wantAverage: 0, wantSmoothed: 0,
+		},
+		{
+			name:        "stok dan PO cukup",
+			demand:      models.ProductDemand{Stock: 20, OnOrder: 30, LeadTimeDays: 0, DailySales: map[string]int{"2026-01-01": 4, "2026-01-02": 4, "2026-01-03": 4, "2026-01-04": 4}},
+			wantAverage: 4, wantSmoothed: 4, wantTarget: 40,
+		},
+		{
+			name:        "order minimum reorder_quantity, stok habis sebelum barang datang",
+			demand:      models.ProductDemand{Stock: 3, LeadTimeDays: 2, ReorderQuantity: 100, DailySales: map[string]int{"2026-01-01": 2, "2026-01-02": 2, "2026-01-03": 2, "2026-01-04": 2}},
+			wantAverage: 2, wantSmoothed: 2, wantTarget: 24, wantSuggested: 100, wantUrgent: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := suggestReorder(tt.demand, days, params)
+			if got.MovingAverage != tt.wantAverage || got.SmoothedVelocity != tt.wantSmoothed || got.TargetStock != tt.wantTarget ||
+				got.SuggestedQuantity != tt.wantSuggested || got.Urgent != tt.wantUrgent {
+				t.Errorf("suggestion = %+v", got)
+			}
+			if (tt.wantSmoothed == 0) != (got.DaysOfCover == nil) {
+				t.Errorf("days of cover = %v", got.DaysOfCover)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+// SerialHistory - unit dengan serial itu beserta riwayat penerimaan, penjualan,
+// retur dan void-nya. Serial unik per produk, jadi hasilnya bisa lebih dari satu.
+func (s *InventoryService) SerialHistory(serial string) ([]models.SerialNumber, error) {
+	serial = strings.TrimSpace(serial)
+	if serial == "" {
+		return nil, fmt.Errorf("%w: serial wajib diisi", repositories.ErrInvalidSerial)
+	}
+	return s.repo.FindSerials(serial)
+}
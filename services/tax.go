@@ -0,0 +1,46 @@
+package services
+
+import (
+	"fmt"
+	"math"
+
+	"kasir-api/models"
+)
+
+// ParseTaxPriceMode - kosong dianggap inclusive (harga jual sudah termasuk PPN)
+func ParseTaxPriceMode(mode string) (string, error) {
+	switch mode {
+	case "", models.TaxInclusive:
+		return models.TaxInclusive, nil
+	case models.TaxExclusive:
+		return models.TaxExclusive, nil
+	default:
+		return "", fmt.Errorf("tax price mode tidak dikenal: %s", mode)
+	}
+}
+
+// applyTax - hitung DPP dan pajak per baris dari nilai setelah diskon.
+// Inclusive: pajak diambil dari dalam harga, total tidak berubah.
+// Exclusive: pajak ditambahkan di atas harga.
+func applyTax(trx *models.Transaction, mode string) {
+	trx.TaxInclusive = mode != models.TaxExclusive
+	trx.TaxBase, trx.TaxAmount, trx.TotalAmount = 0, 0, 0
+
+	for i := range trx.Details {
+		d := &trx.Details[i]
+		net := d.Subtotal
+
+		if trx.TaxInclusive {
+			d.TaxBase = int(math.Round(float64(net) * 100 / (100 + d.TaxRate)))
+			d.TaxAmount = net - d.TaxBase
+		} else {
+			d.TaxBase = net
+			d.TaxAmount = int(math.Round(float64(net) * d.TaxRate / 100))
+		}
+		d.Subtotal = d.TaxBase + d.TaxAmount
+
+		trx.TaxBase += d.TaxBase
+		trx.TaxAmount += d.TaxAmount
+		trx.TotalAmount += d.Subtotal
+	}
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"errors"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"strings"
+)
+
+var ErrInvalidTaxRate = errors.New("tarif pajak harus antara 0 dan 100")
+
+type TaxService struct {
+	repo repositories.TaxRateRepository
+}
+
+func NewTaxService(repo repositories.TaxRateRepository) *TaxService {
+	return &TaxService{repo: repo}
+}
+
+func (s *TaxService) GetAll() ([]models.TaxRate, error) {
+	return s.repo.GetAll()
+}
+
+func (s *TaxService) Create(rate *models.TaxRate) error {
+	if err := validateTaxRate(rate); err != nil {
+		return err
+	}
+	return s.repo.Create(rate)
+}
+
+func (s *TaxService) GetByID(id int) (*models.TaxRate, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *TaxService) Update(rate *models.TaxRate) error {
+	if err := validateTaxRate(rate); err != nil {
+		return err
+	}
+	return s.repo.Update(rate)
+}
+
+func (s *TaxService) Delete(id int) error {
+	return s.repo.Delete(id)
+}
+
+func validateTaxRate(rate *models.TaxRate) error {
+	rate.Name = strings.TrimSpace(rate.Name)
+	if rate.Name == "" {
+		return errors.New("name wajib diisi")
+	}
+	if rate.Rate < 0 || rate.Rate > 100 {
+		return ErrInvalidTaxRate
+	}
+	return nil
+}
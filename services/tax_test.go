@@ -0,0 +1,46 @@
+package services
+
+import (
+	"testing"
+
+	"kasir-api/models"
+)
+
+func TestApplyTax(t *testing.T) {
+	newTrx := func() *models.Transaction {
+		return &models.Transaction{Details: []models.TransactionDetail{
+			{Subtotal: 11100, TaxRate: 11},
+			{Subtotal: 5000},
+		}}
+	}
+
+	trx := newTrx()
+	applyTax(trx, models.TaxInclusive)
+	if !trx.TaxInclusive || trx.TaxBase != 15000 || trx.TaxAmount != 1100 || trx.TotalAmount != 16100 {
+		t.Errorf("inclusive = %+v", trx)
+	}
+	if d := trx.Details[0]; d.TaxBase != 10000 || d.TaxAmount != 1100 || d.Subtotal != 11100 {
+		t.Errorf("inclusive detail = %+v", d)
+	}
+
+	trx = newTrx()
+	applyTax(trx, models.TaxExclusive)
+	if trx.TaxInclusive || trx.TaxBase != 16100 || trx.TaxAmount != 1221 || trx.TotalAmount != 17321 {
+		t.Errorf("exclusive = %+v", trx)
+	}
+	if d := trx.Details[1]; d.TaxAmount != 0 || d.Subtotal != 5000 {
+		t.Errorf("exclusive detail tanpa pajak = %+v", d)
+	}
+}
+
+func TestParseTaxPriceMode(t *testing.T) {
+	if mode, err := ParseTaxPriceMode(""); err != nil || mode != models.TaxInclusive {
+		t.Errorf("empty = %q, %v", mode, err)
+	}
+	if mode, err := ParseTaxPriceMode("exclusive"); err != nil || mode != models.TaxExclusive {
+		t.Errorf("exclusive = %q, %v", mode, err)
+	}
+	if _, err := ParseTaxPriceMode("termasuk"); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
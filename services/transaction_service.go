@@ -1,22 +1,220 @@
 package services
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"kasir-api/models"
 	"kasir-api/repositories"
+	"strings"
+	"time"
 )
 
+const checkoutIdempotencyScope = "checkout"
+
+// idempotencyStaleAfter - key yang masih processing selama ini dianggap sisa
+// request yang mati di tengah jalan dan boleh diklaim ulang oleh retry
+const idempotencyStaleAfter = 5 * time.Minute
+
+var (
+	ErrIdempotencyKeyReused     = errors.New("idempotency key sudah dipakai untuk request yang berbeda")
+	ErrIdempotencyKeyInProgress = errors.New("request dengan idempotency key ini masih diproses")
+
+	ErrVoidReasonRequired = errors.New("alasan void wajib diisi")
+	ErrVoidNotAuthorized  = errors.New("batas waktu void kasir sudah lewat, butuh otorisasi manager")
+)
+
+// TransactionConfig - pengaturan checkout & void dari environment
+type TransactionConfig struct {
+	LockStrategy repositories.LockStrategy
+
+	// VoidWindow - kasir boleh void sendiri selama transaksi belum lebih tua dari ini.
+	// Setelah itu butuh ManagerKey. ManagerKey kosong = void di luar window ditolak.
+	VoidWindow time.Duration
+	ManagerKey string
+
+	// TaxPriceMode - inclusive (default) atau exclusive, lihat ParseTaxPriceMode
+	TaxPriceMode string
+
+	// CostingMethod - HPP barang terjual: average (default) atau fifo
+	CostingMethod repositories.CostingMethod
+}
+
 type TransactionService struct {
-	repo *repositories.TransactionRepository
+	repo            repositories.TransactionRepository
+	idempotencyRepo repositories.IdempotencyRepository
+	promotionRepo   repositories.PromotionRepository
+	ruleRepo        repositories.PromotionRuleRepository
+	config          TransactionConfig
+	lowStock        lowStockSubscribers
+}
+
+func NewTransactionService(repo repositories.TransactionRepository, idempotencyRepo repositories.IdempotencyRepository, promotionRepo repositories.PromotionRepository, ruleRepo repositories.PromotionRuleRepository, config TransactionConfig) *TransactionService {
+	return &TransactionService{repo: repo, idempotencyRepo: idempotencyRepo, promotionRepo: promotionRepo, ruleRepo: ruleRepo, config: config}
 }
 
-func NewTransactionService(repo *repositories.TransactionRepository) *TransactionService {
-	return &TransactionService{repo: repo}
+func (s *TransactionService) Checkout(req models.CheckoutRequest) (*models.Transaction, error) {
+	return s.checkout(req, "")
 }
 
-func (s *TransactionService) Checkout(items []models.CheckoutItem, useLock bool) (*models.Transaction, error) {
-	return s.repo.CreateTransaction(items)
+// checkout - idempotencyKey tidak kosong = response disimpan ke key itu di
+// transaksi DB yang sama dengan transaksinya
+func (s *TransactionService) checkout(req models.CheckoutRequest, idempotencyKey string) (*models.Transaction, error) {
+	now := time.Now()
+	req.LocationID = locationOrDefault(req.LocationID)
+
+	var promo *models.Promotion
+	if strings.TrimSpace(req.PromoCode) != "" {
+		var err error
+		promo, err = promotionForCheckout(s.promotionRepo, req.PromoCode, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rules, err := s.ruleRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	trx, err := s.repo.CreateTransaction(req, repositories.CheckoutOptions{
+		Strategy:         s.config.LockStrategy,
+		Costing:          s.config.CostingMethod,
+		IdempotencyScope: checkoutIdempotencyScope,
+		IdempotencyKey:   idempotencyKey,
+		Finalize: func(trx *models.Transaction) error {
+			if err := applyDiscounts(trx, req, rules, promo, now); err != nil {
+				return err
+			}
+			applyTax(trx, s.config.TaxPriceMode)
+			return settlePayments(trx)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.lowStock.publish(trx.LowStock)
+	return trx, nil
+}
+
+// CheckoutIdempotent - checkout dengan Idempotency-Key. Key yang sama dengan body
+// yang sama mengembalikan transaksi awal (replayed = true) tanpa membuat transaksi baru.
+func (s *TransactionService) CheckoutIdempotent(key string, req models.CheckoutRequest) (*models.Transaction, bool, error) {
+	fingerprint, err := requestFingerprint(req)
+	if err != nil {
+		return nil, false, err
+	}
+
+	record, created, err := s.idempotencyRepo.Reserve(checkoutIdempotencyScope, key, fingerprint, idempotencyStaleAfter)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !created {
+		if record.Fingerprint != fingerprint {
+			return nil, false, ErrIdempotencyKeyReused
+		}
+		if record.Status != models.IdempotencyCompleted {
+			return nil, false, ErrIdempotencyKeyInProgress
+		}
+
+		var transaction models.Transaction
+		if err := json.Unmarshal(record.ResponseBody, &transaction); err != nil {
+			return nil, false, err
+		}
+		return &transaction, true, nil
+	}
+
+	transaction, err := s.checkout(req, key)
+	if errors.Is(err, repositories.ErrIdempotencyKeyLost) {
+		// request lain dengan key yang sama sudah commit duluan, retry akan dapat replay
+		return nil, false, ErrIdempotencyKeyInProgress
+	}
+	if err != nil {
+		// checkout gagal tidak disimpan, key dilepas supaya client bisa retry
+		if releaseErr := s.idempotencyRepo.Release(checkoutIdempotencyScope, key); releaseErr != nil {
+			return nil, false, fmt.Errorf("%w (idempotency key gagal dilepas: %v)", err, releaseErr)
+		}
+		return nil, false, err
+	}
+
+	return transaction, false, nil
+}
+
+// requestFingerprint - hash dari request yang sudah di-decode, jadi beda spasi /
+// urutan field JSON tidak dianggap request yang berbeda
+func requestFingerprint(req any) (string, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
 }
 
 func (s *TransactionService) GetReport(startDate, endDate string) (*models.SalesReport, error) {
 	return s.repo.GetSalesReport(startDate, endDate)
 }
+
+func (s *TransactionService) GetTaxSummary(startDate, endDate string) (*models.TaxSummaryReport, error) {
+	return s.repo.GetTaxSummary(startDate, endDate)
+}
+
+func (s *TransactionService) GetProfitReport(startDate, endDate string) (*models.ProfitReport, error) {
+	return s.repo.GetProfitReport(startDate, endDate)
+}
+
+func (s *TransactionService) ListTransactions(filter models.TransactionFilter) (*models.TransactionList, error) {
+	return s.repo.ListTransactions(filter)
+}
+
+func (s *TransactionService) GetTransactionByID(id int) (*models.Transaction, error) {
+	return s.repo.GetTransactionByID(id)
+}
+
+func (s *TransactionService) CreateReturn(transactionID int, req models.ReturnRequest) (*models.Return, error) {
+	return s.repo.CreateReturn(transactionID, req)
+}
+
+func (s *TransactionService) ListReturns(transactionID int) ([]models.Return, error) {
+	if _, err := s.repo.GetTransactionByID(transactionID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListReturns(transactionID)
+}
+
+// Void - batalkan transaksi. Di dalam VoidWindow cukup API key kasir, di luar itu
+// harus menyertakan manager key yang valid. Umur transaksi dicek repository di
+// dalam transaksi void, pakai jam database.
+func (s *TransactionService) Void(id int, reason, managerKey, user string) (*models.Transaction, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrVoidReasonRequired
+	}
+
+	// window negatif dari config tidak boleh jadi "tanpa batas" untuk kasir
+	voidedBy, window := models.VoidedByCashier, max(s.config.VoidWindow, 0)
+	if managerKey != "" {
+		if !s.isManagerKey(managerKey) {
+			return nil, ErrVoidNotAuthorized
+		}
+		voidedBy, window = models.VoidedByManager, repositories.NoVoidWindow
+	}
+
+	transaction, err := s.repo.VoidTransaction(id, reason, voidedBy, user, window)
+	if errors.Is(err, repositories.ErrVoidWindowExpired) {
+		return nil, ErrVoidNotAuthorized
+	}
+	return transaction, err
+}
+
+func (s *TransactionService) isManagerKey(key string) bool {
+	if s.config.ManagerKey == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(key), []byte(s.config.ManagerKey)) == 1
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+// Valuation - nilai persediaan per category. asOf nil = saat ini, selain itu
+// posisi di akhir hari asOf.
+func (s *InventoryService) Valuation(asOf *time.Time) (*models.ValuationReport, error) {
+	var before *time.Time
+	if asOf != nil {
+		day := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, asOf.Location()).AddDate(0, 0, 1)
+		before = &day
+	}
+
+	items, err := s.repo.ListValuation(before)
+	if err != nil {
+		return nil, err
+	}
+
+	method := s.config.CostingMethod
+	if method == "" {
+		method = repositories.CostAverage
+	}
+	report := groupValuation(items)
+	report.Method = string(method)
+	if asOf != nil {
+		report.AsOf = asOf.Format("2006-01-02")
+	}
+	return report, nil
+}
+
+// groupValuation - category urut id, produk tanpa category (id 0) di akhir
+func groupValuation(items []models.ValuationItem) *models.ValuationReport {
+	report := &models.ValuationReport{Categories: make([]models.ValuationCategory, 0)}
+
+	index := make(map[int]int)
+	for _, item := range items {
+		i, ok := index[item.CategoryID]
+		if !ok {
+			i = len(report.Categories)
+			index[item.CategoryID] = i
+			report.Categories = append(report.Categories, models.ValuationCategory{ID: item.CategoryID, Name: item.CategoryName})
+		}
+		c := &report.Categories[i]
+		c.Quantity += item.Quantity
+		c.Value += item.Value
+		c.Products = append(c.Products, item)
+		report.TotalQuantity += item.Quantity
+		report.TotalValue += item.Value
+	}
+
+	sort.SliceStable(report.Categories, func(i, j int) bool {
+		a, b := report.Categories[i].ID, report.Categories[j].ID
+		if a == 0 || b == 0 {
+			return b == 0 && a != 0
+		}
+		return a < b
+	})
+	return report
+}
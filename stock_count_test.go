@@ -0,0 +1,106 @@
+package main
+
+import (
+	"kasir-api/models"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestStockCountSession(t *testing.T) {
+	s := newTestServer(t)
+
+	makanan := s.createCategory("Makanan")
+	minuman := s.createCategory("Minuman")
+	indomie := s.createProductWithCost("Indomie Goreng", 3500, 2800, 10, makanan.ID)
+	roti := s.createProduct("Roti Tawar", 15000, 5, makanan.ID)
+	teh := s.createProduct("Teh Botol", 5000, 8, minuman.ID)
+
+	rec := s.doAuth(http.MethodPost, "/api/inventory/counts", models.StockCountRequest{CategoryIDs: []int{makanan.ID}, Note: "opname bulanan"})
+	expectStatus(t, rec, http.StatusCreated)
+	count := decodeJSON[models.StockCount](t, rec)
+	if count.Status != models.StockCountOpen || len(count.Lines) != 2 || count.Lines[0].SystemStock != 10 {
+		t.Fatalf("count = %+v", count)
+	}
+	path := "/api/inventory/counts/" + strconv.Itoa(count.ID)
+
+	submit := func(device string, items ...models.StockCountItem) *models.StockCount {
+		t.Helper()
+		rec := s.doAuth(http.MethodPost, path+"/submissions", models.StockCountSubmission{Device: device, Items: items})
+		expectStatus(t, rec, http.StatusOK)
+		c := decodeJSON[models.StockCount](t, rec)
+		return &c
+	}
+
+	// rak depan dan gudang dihitung dari device berbeda, kiriman ulang menimpa
+	submit("rak-depan", models.StockCountItem{ProductID: indomie.ID, Quantity: 5})
+	submit("gudang", models.StockCountItem{ProductID: indomie.ID, Quantity: 3})
+	current := submit("rak-depan", models.StockCountItem{ProductID: indomie.ID, Quantity: 4})
+	if got := current.Lines[0].CountedQuantity; got == nil || *got != 7 || len(current.Lines[0].Entries) != 2 {
+		t.Fatalf("indomie line = %+v", current.Lines[0])
+	}
+	if current.Lines[1].CountedQuantity != nil {
+		t.Errorf("roti should be uncounted, got %+v", current.Lines[1])
+	}
+
+	rec = s.doAuth(http.MethodPost, path+"/submissions", models.StockCountSubmission{Items: []models.StockCountItem{{ProductID: teh.ID, Quantity: 1}}})
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	// selisih dinilai dengan HPP (3 x 2800), bukan harga jual
+	rec = s.doAuth(http.MethodGet, path+"/variance", nil)
+	expectStatus(t, rec, http.StatusOK)
+	report := decodeJSON[models.StockCountVariance](t, rec)
+	if report.TotalLines != 2 || report.CountedLines != 1 || report.TotalVarianceQty != -3 || report.TotalVarianceValue != -8400 || report.ShortageValue != -8400 {
+		t.Errorf("variance = %+v", report)
+	}
+
+	// penjualan selama opname tidak boleh ikut hilang saat finalize
+	s.checkout(models.CheckoutItem{ProductID: indomie.ID, Quantity: 2})
+
+	rec = s.doAuth(http.MethodPost, path+"/finalize", nil)
+	expectStatus(t, rec, http.StatusOK)
+	finalized := decodeJSON[models.StockCount](t, rec)
+	if finalized.Status != models.StockCountFinalized || finalized.AdjustmentID == 0 {
+		t.Errorf("finalized = %+v", finalized)
+	}
+	if stock := s.productStock(indomie.ID); stock != 5 {
+		t.Errorf("indomie stock = %d, want 5 (10 - 2 sold - 3 variance)", stock)
+	}
+	if stock := s.productStock(roti.ID); stock != 5 {
+		t.Errorf("uncounted roti stock = %d, want 5", stock)
+	}
+
+	rec = s.doAuth(http.MethodPost, path+"/submissions", models.StockCountSubmission{Items: []models.StockCountItem{{ProductID: roti.ID, Quantity: 1}}})
+	expectStatus(t, rec, http.StatusConflict)
+	rec = s.doAuth(http.MethodPost, path+"/finalize", nil)
+	expectStatus(t, rec, http.StatusConflict)
+
+	rec = s.doAuth(http.MethodGet, "/api/inventory/counts/999", nil)
+	expectStatus(t, rec, http.StatusNotFound)
+}
+
+func TestStockCountCancel(t *testing.T) {
+	s := newTestServer(t)
+
+	category := s.createCategory("Makanan")
+	indomie := s.createProduct("Indomie Goreng", 3500, 10, category.ID)
+
+	rec := s.doAuth(http.MethodPost, "/api/inventory/counts", models.StockCountRequest{ProductIDs: []int{999}})
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = s.doAuth(http.MethodPost, "/api/inventory/counts", models.StockCountRequest{})
+	expectStatus(t, rec, http.StatusCreated)
+	count := decodeJSON[models.StockCount](t, rec)
+	path := "/api/inventory/counts/" + strconv.Itoa(count.ID)
+
+	rec = s.doAuth(http.MethodPost, path+"/submissions", models.StockCountSubmission{Items: []models.StockCountItem{{ProductID: indomie.ID, Quantity: 0}}})
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = s.doAuth(http.MethodPost, path+"/cancel", nil)
+	expectStatus(t, rec, http.StatusOK)
+	rec = s.doAuth(http.MethodPost, path+"/finalize", nil)
+	expectStatus(t, rec, http.StatusConflict)
+	if stock := s.productStock(indomie.ID); stock != 10 {
+		t.Errorf("stock = %d, want 10 after cancelled count", stock)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"kasir-api/models"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func (s *testServer) stockMovements(productID int) []models.StockMovement {
+	s.t.Helper()
+
+	rec := s.doAuth(http.MethodGet, "/api/product/"+strconv.Itoa(productID)+"/movements", nil)
+	expectStatus(s.t, rec, http.StatusOK)
+	return decodeJSON[[]models.StockMovement](s.t, rec)
+}
+
+func TestStockMovementLedger(t *testing.T) {
+	s := newVoidTestServer(t, time.Hour)
+
+	category := s.createCategory("Makanan")
+	indomie := s.createProduct("Indomie Goreng", 3500, 10, category.ID)
+	path := "/api/product/" + strconv.Itoa(indomie.ID)
+
+	rec := s.do(http.MethodPut, path, models.Product{Name: "Indomie Goreng", Price: 3500, Stock: 12, LocationID: models.DefaultLocationID}, map[string]string{
+		"X-Api-Key": testAPIKey,
+		"X-User":    "budi",
+	})
+	expectStatus(t, rec, http.StatusOK)
+
+	// stok negatif ditolak, ledger tidak berubah
+	rec = s.doAuth(http.MethodPut, path, models.Product{Name: "Indomie Goreng", Price: 3500, Stock: -1, LocationID: models.DefaultLocationID})
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	sold := s.checkout(models.CheckoutItem{ProductID: indomie.ID, Quantity: 3})
+	detail := s.transactionDetails(sold.ID)
+	rec = s.doAuth(http.MethodPost, "/api/transactions/"+strconv.Itoa(sold.ID)+"/returns", models.ReturnRequest{
+		Reason: "rusak",
+		Items:  []models.ReturnRequestItem{{TransactionDetailID: detail[0].ID, Quantity: 1}},
+	})
+	expectStatus(t, rec, http.StatusCreated)
+	ret := decodeJSON[models.Return](t, rec)
+
+	voided := s.checkout(models.CheckoutItem{ProductID: indomie.ID, Quantity: 2})
+	rec = s.doAuth(http.MethodPost, "/api/transactions/"+strconv.Itoa(voided.ID)+"/void", models.VoidRequest{Reason: "salah input"})
+	expectStatus(t, rec, http.StatusOK)
+
+	movements := s.stockMovements(indomie.ID)
+	want := []struct {
+		delta, after int
+		reason, ref  string
+		refID        int
+	}{
+		{10, 10, models.StockReasonAdjustment, models.StockRefProduct, indomie.ID},
+		{2, 12, models.StockReasonAdjustment, models.StockRefProduct, indomie.ID},
+		{-3, 9, models.StockReasonSale, models.StockRefTransaction, sold.ID},
+		{1, 10, models.StockReasonReturn, models.StockRefReturn, ret.ID},
+		{-2, 8, models.StockReasonSale, models.StockRefTransaction, voided.ID},
+		{2, 10, models.StockReasonVoid, models.StockRefTransaction, voided.ID},
+	}
+	if len(movements) != len(want) {
+		t.Fatalf("movements = %+v, want %d rows", movements, len(want))
+	}
+	total := 0
+	for i, w := range want {
+		m := movements[i]
+		total += m.Delta
+		if m.Delta != w.delta || m.StockAfter != w.after || m.Reason != w.reason || m.ReferenceType != w.ref || m.ReferenceID != w.refID {
+			t.Errorf("movement %d = %+v, want %+v", i, m, w)
+		}
+	}
+	if movements[1].User != "budi" {
+		t.Errorf("adjustment user = %q, want budi", movements[1].User)
+	}
+	if stock := s.productStock(indomie.ID); stock != total {
+		t.Errorf("stock = %d, ledger total = %d", stock, total)
+	}
+
+	rec = s.doAuth(http.MethodGet, "/api/product/999/movements", nil)
+	expectStatus(t, rec, http.StatusNotFound)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func (s *testServer) createTaxRate(name string, rate float64) models.TaxRate {
+	s.t.Helper()
+
+	rec := s.doAuth(http.MethodPost, "/api/tax-rates", models.TaxRate{Name: name, Rate: rate})
+	expectStatus(s.t, rec, http.StatusCreated)
+	return decodeJSON[models.TaxRate](s.t, rec)
+}
+
+func TestTaxRateCRUD(t *testing.T) {
+	s := newTestServer(t)
+
+	rec := s.doAuth(http.MethodPost, "/api/tax-rates", models.TaxRate{Name: "Salah", Rate: 120})
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	ppn := s.createTaxRate("PPN", 11)
+	path := "/api/tax-rates/" + strconv.Itoa(ppn.ID)
+
+	rec = s.doAuth(http.MethodPut, path, models.TaxRate{Name: "PPN", Rate: 12})
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = s.doAuth(http.MethodGet, path, nil)
+	expectStatus(t, rec, http.StatusOK)
+	if got := decodeJSON[models.TaxRate](t, rec); got.Rate != 12 {
+		t.Errorf("rate = %v, want 12", got.Rate)
+	}
+
+	// produk dengan tarif yang tidak ada ditolak
+	category := s.createCategory("Makanan")
+	rec = s.do(http.MethodPost, "/api/product", models.Product{Name: "Roti", Price: 1000, Stock: 1, CategoryID: category.ID, TaxRateID: 99}, nil)
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = s.doAuth(http.MethodDelete, path, nil)
+	expectStatus(t, rec, http.StatusOK)
+	rec = s.doAuth(http.MethodGet, path, nil)
+	expectStatus(t, rec, http.StatusNotFound)
+}
+
+func TestCheckoutTaxExclusive(t *testing.T) {
+	s := newTestServerWithConfig(t, Config{
+		APIKey:           testAPIKey,
+		CheckoutLockMode: string(repositories.LockPessimistic),
+		TaxPriceMode:     "exclusive",
+	})
+
+	ppn := s.createTaxRate("PPN", 11)
+	ppnBarangMewah := s.createTaxRate("PPN 12%", 12)
+
+	rec := s.do(http.MethodPost, "/api/category", models.Category{Name: "Minuman", TaxRateID: ppn.ID}, nil)
+	expectStatus(t, rec, http.StatusCreated)
+	minuman := decodeJSON[models.Category](t, rec)
+	sembako := s.createCategory("Sembako")
+
+	teh := s.createProduct("Es Teh", 10000, 10, minuman.ID)
+	beras := s.createProduct("Beras", 50000, 10, sembako.ID)
+
+	rec = s.do(http.MethodPost, "/api/product", models.Product{Name: "Wine", Price: 100000, Stock: 5, CategoryID: minuman.ID, TaxRateID: ppnBarangMewah.ID}, nil)
+	expectStatus(t, rec, http.StatusCreated)
+	wine := decodeJSON[models.Product](t, rec)
+
+	trx := s.checkout(
+		models.CheckoutItem{ProductID: teh.ID, Quantity: 2},
+		models.CheckoutItem{ProductID: beras.ID, Quantity: 1},
+		models.CheckoutItem{ProductID: wine.ID, Quantity: 1},
+	)
+	// DPP 170000, pajak 2200 (teh, tarif category) + 12000 (wine, tarif produk)
+	if trx.TaxInclusive || trx.TaxBase != 170000 || trx.TaxAmount != 14200 || trx.TotalAmount != 184200 {
+		t.Fatalf("transaction = %+v", trx)
+	}
+
+	details := s.transactionDetails(trx.ID)
+	if details[0].TaxName != "PPN" || details[0].TaxAmount != 2200 || details[1].TaxAmount != 0 || details[2].TaxRate != 12 {
+		t.Errorf("details = %+v", details)
+	}
+
+	// retur 1 teh: pajak ikut berkurang
+	rec = s.doAuth(http.MethodPost, "/api/transactions/"+strconv.Itoa(trx.ID)+"/returns", models.ReturnRequest{
+		Items: []models.ReturnRequestItem{{TransactionDetailID: details[0].ID, Quantity: 1}},
+	})
+	expectStatus(t, rec, http.StatusCreated)
+	if ret := decodeJSON[models.Return](t, rec); ret.TotalAmount != -11100 || ret.Items[0].TaxAmount != -1100 {
+		t.Errorf("return = %+v", ret)
+	}
+
+	rec = s.doAuth(http.MethodGet, "/api/report/tax", nil)
+	expectStatus(t, rec, http.StatusOK)
+	report := decodeJSON[models.TaxSummaryReport](t, rec)
+	if report.TotalTaxAmount != 13100 || report.TotalTaxBase != 160000 || len(report.Rates) != 3 {
+		t.Fatalf("tax report = %+v", report)
+	}
+	if r := report.Rates[1]; r.TaxRateID != ppn.ID || r.TaxAmount != 2200 || r.ReturnTaxAmount != -1100 || r.NetTaxAmount != 1100 || r.NetTaxBase != 10000 {
+		t.Errorf("PPN 11%% line = %+v", r)
+	}
+
+	rec = s.doAuth(http.MethodGet, "/api/report/tax?start_date=kemarin", nil)
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = s.doAuth(http.MethodGet, "/api/report", nil)
+	expectStatus(t, rec, http.StatusOK)
+	if sales := decodeJSON[models.SalesReport](t, rec); sales.TotalTax != 13100 {
+		t.Errorf("sales report total tax = %d, want 13100", sales.TotalTax)
+	}
+}
+
+func TestCheckoutTaxInclusiveKeepsTotal(t *testing.T) {
+	s := newTestServer(t)
+
+	ppn := s.createTaxRate("PPN", 11)
+	rec := s.do(http.MethodPost, "/api/category", models.Category{Name: "Minuman", TaxRateID: ppn.ID}, nil)
+	expectStatus(t, rec, http.StatusCreated)
+	minuman := decodeJSON[models.Category](t, rec)
+	kopi := s.createProduct("Kopi", 22200, 10, minuman.ID)
+
+	trx := s.checkout(models.CheckoutItem{ProductID: kopi.ID, Quantity: 1})
+	if !trx.TaxInclusive || trx.TotalAmount != 22200 || trx.TaxBase != 20000 || trx.TaxAmount != 2200 {
+		t.Errorf("transaction = %+v", trx)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"kasir-api/models"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func (s *testServer) checkout(items ...models.CheckoutItem) models.Transaction {
+	s.t.Helper()
+
+	rec := s.doAuth(http.MethodPost, "/api/checkout", models.CheckoutRequest{Items: items})
+	expectStatus(s.t, rec, http.StatusOK)
+	return decodeJSON[models.Transaction](s.t, rec)
+}
+
+func TestListTransactions(t *testing.T) {
+	s := newTestServer(t)
+
+	category := s.createCategory("Makanan")
+	indomie := s.createProduct("Indomie Goreng", 3500, 100, category.ID)
+	roti := s.createProduct("Roti Tawar", 15000, 100, category.ID)
+
+	s.checkout(models.CheckoutItem{ProductID: indomie.ID, Quantity: 1})                                                       // 3500
+	s.checkout(models.CheckoutItem{ProductID: roti.ID, Quantity: 2})                                                          // 30000
+	s.checkout(models.CheckoutItem{ProductID: indomie.ID, Quantity: 2}, models.CheckoutItem{ProductID: roti.ID, Quantity: 1}) // 22000
+
+	tests := []struct {
+		query   string
+		wantIDs []int
+	}{
+		{"", []int{3, 2, 1}},
+		{"?product_id=" + strconv.Itoa(roti.ID), []int{3, 2}},
+		{"?min_amount=10000", []int{3, 2}},
+		{"?max_amount=25000", []int{3, 1}},
+		{"?min_amount=10000&max_amount=25000", []int{3}},
+		{"?limit=2&page=2", []int{1}},
+		{"?limit=100&page=9223372036854775807", []int{}},
+		{"?start_date=" + time.Now().Format("2006-01-02") + "&end_date=" + time.Now().Format("2006-01-02"), []int{3, 2, 1}},
+		{"?end_date=" + time.Now().AddDate(0, 0, -1).Format("2006-01-02"), []int{}},
+	}
+
+	for _, tt := range tests {
+		rec := s.doAuth(http.MethodGet, "/api/transactions"+tt.query, nil)
+		expectStatus(t, rec, http.StatusOK)
+
+		list := decodeJSON[models.TransactionList](t, rec)
+		var got []int
+		for _, trx := range list.Data {
+			got = append(got, trx.ID)
+		}
+		if len(got) != len(tt.wantIDs) {
+			t.Errorf("%q: ids = %v, want %v", tt.query, got, tt.wantIDs)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.wantIDs[i] {
+				t.Errorf("%q: ids = %v, want %v", tt.query, got, tt.wantIDs)
+				break
+			}
+		}
+	}
+
+	rec := s.doAuth(http.MethodGet, "/api/transactions?limit=2", nil)
+	if list := decodeJSON[models.TransactionList](t, rec); list.Total != 3 || list.Page != 1 || list.Limit != 2 {
+		t.Errorf("pagination = total %d page %d limit %d, want 3/1/2", list.Total, list.Page, list.Limit)
+	}
+
+	for _, bad := range []string{"?page=0", "?limit=abc", "?start_date=17-10-2026"} {
+		rec := s.doAuth(http.MethodGet, "/api/transactions"+bad, nil)
+		expectStatus(t, rec, http.StatusBadRequest)
+	}
+}
+
+func TestGetTransactionByID(t *testing.T) {
+	s := newTestServer(t)
+
+	category := s.createCategory("Makanan")
+	indomie := s.createProduct("Indomie Goreng", 3500, 100, category.ID)
+	roti := s.createProduct("Roti Tawar", 15000, 100, category.ID)
+
+	created := s.checkout(models.CheckoutItem{ProductID: indomie.ID, Quantity: 2}, models.CheckoutItem{ProductID: roti.ID, Quantity: 1})
+
+	// nama di detail transaksi tidak ikut berubah kalau produk di-rename
+	rec := s.doAuth(http.MethodPut, "/api/product/"+strconv.Itoa(indomie.ID), models.Product{Name: "Indomie Baru", Price: 3500, Stock: 98})
+	expectStatus(t, rec, http.StatusOK)
+
+	rec = s.doAuth(http.MethodGet, "/api/transactions/"+strconv.Itoa(created.ID), nil)
+	expectStatus(t, rec, http.StatusOK)
+
+	trx := decodeJSON[models.Transaction](t, rec)
+	if trx.TotalAmount != 22000 || len(trx.Details) != 2 {
+		t.Fatalf("transaction = %+v", trx)
+	}
+	if trx.Details[0].ProductName != "Indomie Goreng" || trx.Details[0].Quantity != 2 || trx.Details[0].ID == 0 {
+		t.Errorf("detail[0] = %+v", trx.Details[0])
+	}
+
+	rec = s.doAuth(http.MethodGet, "/api/transactions/999", nil)
+	expectStatus(t, rec, http.StatusNotFound)
+
+	rec = s.doAuth(http.MethodGet, "/api/transactions/abc", nil)
+	expectStatus(t, rec, http.StatusBadRequest)
+}
+
+func (s *testServer) transactionDetails(id int) []models.TransactionDetail {
+	s.t.Helper()
+
+	rec := s.doAuth(http.MethodGet, "/api/transactions/"+strconv.Itoa(id), nil)
+	expectStatus(s.t, rec, http.StatusOK)
+	return decodeJSON[models.Transaction](s.t, rec).Details
+}
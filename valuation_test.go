@@ -0,0 +1,157 @@
+package main
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// receivePurchase - PO satu baris, langsung dikirim dan diterima semua
+func (s *testServer) receivePurchase(supplierID, productID, quantity, unitCost int) {
+	s.t.Helper()
+
+	rec := s.doAuth(http.MethodPost, "/api/purchase-orders", models.PurchaseOrderRequest{
+		SupplierID: supplierID,
+		Lines:      []models.PurchaseOrderLineRequest{{ProductID: productID, Quantity: quantity, UnitCost: unitCost}},
+	})
+	expectStatus(s.t, rec, http.StatusCreated)
+	path := "/api/purchase-orders/" + strconv.Itoa(decodeJSON[models.PurchaseOrder](s.t, rec).ID)
+
+	rec = s.doAuth(http.MethodPost, path+"/send", nil)
+	expectStatus(s.t, rec, http.StatusOK)
+	rec = s.doAuth(http.MethodPost, path+"/receipts", models.GoodsReceiptRequest{})
+	expectStatus(s.t, rec, http.StatusCreated)
+}
+
+func (s *testServer) valuation(query string) models.ValuationReport {
+	s.t.Helper()
+
+	rec := s.doAuth(http.MethodGet, "/api/inventory/valuation"+query, nil)
+	expectStatus(s.t, rec, http.StatusOK)
+	return decodeJSON[models.ValuationReport](s.t, rec)
+}
+
+func TestCostingAndValuation(t *testing.T) {
+	tests := []struct {
+		method   repositories.CostingMethod
+		unitCost int // HPP per unit 15 beras yang terjual
+		cogs     int
+		value    int // nilai 5 beras yang tersisa
+	}{
+		// 10 x 60.000 dari layer stok awal + 5 x 70.000 dari layer PO
+		{repositories.CostFIFO, 63333, 950000, 350000},
+		// 15 x rata-rata (10*60.000 + 10*70.000) / 20
+		{repositories.CostAverage, 65000, 975000, 325000},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			s := newTestServerWithConfig(t, Config{
+				APIKey:           testAPIKey,
+				CheckoutLockMode: string(repositories.LockPessimistic),
+				CostingMethod:    string(tt.method),
+			})
+
+			sembako := s.createCategory("Sembako")
+			minuman := s.createCategory("Minuman")
+			beras := s.createProductWithCost("Beras 5kg", 80000, 60000, 10, sembako.ID)
+			teh := s.createProductWithCost("Teh Botol", 5000, 3000, 4, minuman.ID)
+			supplier := s.createSupplier("PT Sumber Rejeki")
+			s.receivePurchase(supplier.ID, beras.ID, 10, 70000)
+
+			trx := s.checkout(models.CheckoutItem{ProductID: beras.ID, Quantity: 15})
+			if trx.Details[0].UnitCost != tt.unitCost {
+				t.Errorf("detail unit cost = %d, want %d", trx.Details[0].UnitCost, tt.unitCost)
+			}
+			movements := s.stockMovements(beras.ID)
+			sale := movements[len(movements)-1]
+			if sale.CostAmount != -tt.cogs || sale.CostMethod != string(tt.method) {
+				t.Errorf("sale movement = %+v, want cost %d", sale, -tt.cogs)
+			}
+
+			report := s.valuation("")
+			if report.Method != string(tt.method) || report.AsOf != "" {
+				t.Errorf("report = %+v", report)
+			}
+			if report.TotalQuantity != 9 || report.TotalValue != tt.value+12000 || len(report.Categories) != 2 {
+				t.Fatalf("report = %+v", report)
+			}
+			if c := report.Categories[0]; c.ID != sembako.ID || c.Value != tt.value || c.Products[0].UnitCost != tt.value/5 {
+				t.Errorf("sembako = %+v", c)
+			}
+			if c := report.Categories[1]; c.Name != "Minuman" || c.Quantity != 4 || c.Products[0].ProductID != teh.ID {
+				t.Errorf("minuman = %+v", c)
+			}
+
+			today := time.Now().Format("2006-01-02")
+			if past := s.valuation("?as_of=" + time.Now().AddDate(0, 0, -1).Format("2006-01-02")); past.TotalValue != 0 || len(past.Categories) != 0 {
+				t.Errorf("valuation yesterday = %+v", past)
+			}
+			if current := s.valuation("?as_of=" + today); current.AsOf != today || current.TotalValue != report.TotalValue {
+				t.Errorf("valuation today = %+v", current)
+			}
+
+			rec := s.doAuth(http.MethodGet, "/api/inventory/valuation?as_of=kemarin", nil)
+			expectStatus(t, rec, http.StatusBadRequest)
+		})
+	}
+}
+
+func TestReturnRestocksAtSaleCost(t *testing.T) {
+	s := newTestServerWithConfig(t, Config{
+		APIKey:           testAPIKey,
+		CheckoutLockMode: string(repositories.LockPessimistic),
+		CostingMethod:    string(repositories.CostFIFO),
+	})
+
+	category := s.createCategory("Sembako")
+	gula := s.createProductWithCost("Gula 1kg", 15000, 10000, 5, category.ID)
+	supplier := s.createSupplier("PT Sumber Rejeki")
+	s.receivePurchase(supplier.ID, gula.ID, 5, 12000)
+
+	trx := s.checkout(models.CheckoutItem{ProductID: gula.ID, Quantity: 4})
+	rec := s.doAuth(http.MethodPost, "/api/transactions/"+strconv.Itoa(trx.ID)+"/returns", models.ReturnRequest{Reason: "salah beli"})
+	expectStatus(t, rec, http.StatusCreated)
+
+	// 4 gula kembali dengan HPP 10.000, nilai stok sama dengan sebelum penjualan
+	if report := s.valuation(""); report.TotalQuantity != 10 || report.TotalValue != 5*10000+5*12000 {
+		t.Errorf("valuation after return = %+v", report)
+	}
+}
+
+// TestInTransitStockKeepsAverageCost - barang dalam perjalanan masih milik toko,
+// jadi ikut dihitung di harga rata-rata waktu ada penerimaan barang baru
+func TestInTransitStockKeepsAverageCost(t *testing.T) {
+	s := newTestServer(t)
+
+	category := s.createCategory("Sembako")
+	supplier := s.createSupplier("PT Sumber Rejeki")
+	outlet := s.createLocation("Outlet Kemang", models.LocationOutlet)
+	gula := s.createProductWithCost("Gula 1kg", 15000, 10000, 10, category.ID)
+
+	rec := s.doAuth(http.MethodPost, "/api/inventory/transfers", models.StockTransferRequest{
+		FromLocationID: models.DefaultLocationID,
+		ToLocationID:   outlet.ID,
+		Items:          []models.StockTransferItem{{ProductID: gula.ID, Quantity: 10}},
+	})
+	expectStatus(t, rec, http.StatusCreated)
+	path := "/api/inventory/transfers/" + strconv.Itoa(decodeJSON[models.StockTransfer](t, rec).ID)
+	expectStatus(t, s.doAuth(http.MethodPost, path+"/ship", nil), http.StatusOK)
+
+	// stok 0, 10 dalam perjalanan: (10 x 10.000 + 10 x 12.000) / 20, bukan 12.000
+	s.receivePurchase(supplier.ID, gula.ID, 10, 12000)
+	if got := s.productLocations(gula.ID); got.Stock != 10 || got.InTransit != 10 || got.CostPrice != 11000 {
+		t.Errorf("product after receipt = %+v", got)
+	}
+
+	expectStatus(t, s.doAuth(http.MethodPost, path+"/receive", nil), http.StatusOK)
+	trx := s.checkout(models.CheckoutItem{ProductID: gula.ID, Quantity: 1})
+	if trx.Details[0].UnitCost != 11000 {
+		t.Errorf("unit cost = %d, want 11000", trx.Details[0].UnitCost)
+	}
+	if report := s.valuation(""); report.TotalQuantity != 19 || report.TotalValue != 19*11000 {
+		t.Errorf("valuation = %+v", report)
+	}
+}
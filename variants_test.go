@@ -0,0 +1,91 @@
+package main
+
+import (
+	"kasir-api/models"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func (s *testServer) createVariant(parentID int, variant models.Product) models.Product {
+	s.t.Helper()
+
+	rec := s.doAuth(http.MethodPost, "/api/product/"+strconv.Itoa(parentID)+"/variants", variant)
+	expectStatus(s.t, rec, http.StatusCreated)
+	return decodeJSON[models.Product](s.t, rec)
+}
+
+func TestProductVariants(t *testing.T) {
+	s := newTestServer(t)
+
+	category := s.createCategory("Pakaian")
+	kaos := s.createProduct("Kaos Polos", 75000, 0, category.ID)
+	s.createProduct("Kemeja Flanel", 150000, 5, category.ID)
+
+	m := s.createVariant(kaos.ID, models.Product{SKU: " KP-M-MRH ", Attributes: map[string]string{"Size": "M", "colour": "Merah"}, Stock: 10})
+	if m.Name != "Kaos Polos - Merah / M" || m.SKU != "KP-M-MRH" || m.Price != 75000 || m.CategoryID != category.ID ||
+		m.ParentID != kaos.ID || m.Attributes["size"] != "M" {
+		t.Errorf("variant M = %+v", m)
+	}
+	xl := s.createVariant(kaos.ID, models.Product{
+		SKU: "KP-XL-MRH", Attributes: map[string]string{"size": "XL", "colour": "Merah"}, Price: 85000, PriceOverride: true, Stock: 4,
+	})
+
+	// SKU wajib & unik, atribut wajib, varian tidak bisa punya varian
+	path := "/api/product/" + strconv.Itoa(kaos.ID) + "/variants"
+	expectStatus(t, s.doAuth(http.MethodPost, path, models.Product{SKU: "KP-M-MRH", Attributes: map[string]string{"size": "L"}}), http.StatusConflict)
+	expectStatus(t, s.doAuth(http.MethodPost, path, models.Product{Attributes: map[string]string{"size": "L"}}), http.StatusBadRequest)
+	expectStatus(t, s.doAuth(http.MethodPost, path, models.Product{SKU: "KP-L"}), http.StatusBadRequest)
+	expectStatus(t, s.doAuth(http.MethodPost, "/api/product/"+strconv.Itoa(m.ID)+"/variants",
+		models.Product{SKU: "KP-X", Attributes: map[string]string{"size": "L"}}), http.StatusBadRequest)
+	expectStatus(t, s.doAuth(http.MethodPost, "/api/product/9999/variants", models.Product{SKU: "X", Attributes: map[string]string{"size": "L"}}), http.StatusBadRequest)
+
+	// katalog: varian di bawah induknya, filter nama juga mencari SKU varian
+	rec := s.do(http.MethodGet, "/api/product", nil, nil)
+	expectStatus(t, rec, http.StatusOK)
+	catalog := decodeJSON[[]models.Product](t, rec)
+	if len(catalog) != 2 || len(catalog[0].Variants) != 2 || catalog[0].Variants[1].ID != xl.ID || catalog[1].Variants != nil {
+		t.Fatalf("catalog = %+v", catalog)
+	}
+	rec = s.do(http.MethodGet, "/api/product?name=kp-xl", nil, nil)
+	expectStatus(t, rec, http.StatusOK)
+	if found := decodeJSON[[]models.Product](t, rec); len(found) != 1 || found[0].ID != kaos.ID {
+		t.Errorf("search by variant sku = %+v", found)
+	}
+
+	// harga induk ikut ke varian tanpa override, stok induk dikelola per varian
+	rec = s.doAuth(http.MethodPut, "/api/product/"+strconv.Itoa(kaos.ID), models.Product{Name: "Kaos Polos", Price: 80000})
+	expectStatus(t, rec, http.StatusOK)
+	expectStatus(t, s.doAuth(http.MethodPut, "/api/product/"+strconv.Itoa(kaos.ID), models.Product{Name: "Kaos Polos", Price: 80000, Stock: 3}), http.StatusBadRequest)
+	rec = s.doAuth(http.MethodGet, "/api/product/"+strconv.Itoa(kaos.ID), nil)
+	expectStatus(t, rec, http.StatusOK)
+	parent := decodeJSON[models.ProductResponse](t, rec)
+	if len(parent.Variants) != 2 || parent.Variants[0].Price != 80000 || parent.Variants[1].Price != 85000 {
+		t.Errorf("variants after parent price change = %+v", parent.Variants)
+	}
+
+	// checkout wajib lewat varian, product_id (kalau diisi) harus induknya
+	for _, item := range []models.CheckoutItem{
+		{ProductID: kaos.ID, Quantity: 1},
+		{ProductID: kaos.ID, VariantID: kaos.ID, Quantity: 1},
+		{ProductID: xl.ID + 1, VariantID: m.ID, Quantity: 1},
+	} {
+		rec = s.doAuth(http.MethodPost, "/api/checkout", models.CheckoutRequest{Items: []models.CheckoutItem{item}})
+		expectStatus(t, rec, http.StatusBadRequest)
+	}
+	trx := s.checkout(
+		models.CheckoutItem{ProductID: kaos.ID, VariantID: m.ID, Quantity: 2},
+		models.CheckoutItem{VariantID: xl.ID, Quantity: 1},
+		models.CheckoutItem{ProductID: m.ID, Quantity: 1},
+	)
+	if trx.TotalAmount != 3*80000+85000 || trx.Details[0].ProductID != m.ID || trx.Details[0].ProductName != "Kaos Polos - Merah / M" {
+		t.Errorf("transaction = %+v", trx)
+	}
+	if s.productStock(m.ID) != 7 || s.productStock(xl.ID) != 3 || s.productStock(kaos.ID) != 0 {
+		t.Errorf("stock M=%d XL=%d parent=%d", s.productStock(m.ID), s.productStock(xl.ID), s.productStock(kaos.ID))
+	}
+
+	// hapus induk ikut menghapus variannya
+	expectStatus(t, s.doAuth(http.MethodDelete, "/api/product/"+strconv.Itoa(kaos.ID), nil), http.StatusOK)
+	expectStatus(t, s.doAuth(http.MethodGet, "/api/product/"+strconv.Itoa(m.ID), nil), http.StatusNotFound)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const testManagerKey = "manager-key"
+
+func newVoidTestServer(t *testing.T, window time.Duration) *testServer {
+	t.Helper()
+	return newTestServerWithConfig(t, Config{
+		APIKey:           testAPIKey,
+		CheckoutLockMode: string(repositories.LockPessimistic),
+		VoidWindow:       window,
+		ManagerKey:       testManagerKey,
+	})
+}
+
+func TestVoidWithinWindow(t *testing.T) {
+	s := newVoidTestServer(t, time.Hour)
+
+	category := s.createCategory("Makanan")
+	indomie := s.createProduct("Indomie Goreng", 3500, 10, category.ID)
+	kept := s.checkout(models.CheckoutItem{ProductID: indomie.ID, Quantity: 1})
+	trx := s.checkout(models.CheckoutItem{ProductID: indomie.ID, Quantity: 4})
+	path := "/api/transactions/" + strconv.Itoa(trx.ID) + "/void"
+
+	rec := s.doAuth(http.MethodPost, path, models.VoidRequest{Reason: " "})
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = s.doAuth(http.MethodPost, path, models.VoidRequest{Reason: "salah input"})
+	expectStatus(t, rec, http.StatusOK)
+	voided := decodeJSON[models.Transaction](t, rec)
+	if voided.VoidedAt == nil || voided.VoidReason != "salah input" || voided.VoidedBy != models.VoidedByCashier {
+		t.Errorf("voided transaction = %+v", voided)
+	}
+	if stock := s.productStock(indomie.ID); stock != 9 {
+		t.Errorf("stock = %d, want 9", stock)
+	}
+
+	rec = s.doAuth(http.MethodPost, path, models.VoidRequest{Reason: "lagi"})
+	expectStatus(t, rec, http.StatusConflict)
+
+	// transaksi void tidak bisa diretur dan tidak masuk laporan
+	rec = s.doAuth(http.MethodPost, "/api/transactions/"+strconv.Itoa(trx.ID)+"/returns", models.ReturnRequest{})
+	expectStatus(t, rec, http.StatusConflict)
+
+	rec = s.doAuth(http.MethodGet, "/api/report", nil)
+	report := decodeJSON[models.SalesReport](t, rec)
+	if report.TotalTransaction != 1 || report.TotalRevenue != kept.TotalAmount || report.TopProduct.TotalSold != 1 {
+		t.Errorf("report = %+v, want only the non-voided transaction", report)
+	}
+}
+
+func TestVoidOutsideWindowNeedsManager(t *testing.T) {
+	s := newVoidTestServer(t, 0)
+
+	category := s.createCategory("Makanan")
+	indomie := s.createProduct("Indomie Goreng", 3500, 10, category.ID)
+	trx := s.checkout(models.CheckoutItem{ProductID: indomie.ID, Quantity: 2})
+	path := "/api/transactions/" + strconv.Itoa(trx.ID) + "/void"
+
+	rec := s.doAuth(http.MethodPost, path, models.VoidRequest{Reason: "salah input"})
+	expectStatus(t, rec, http.StatusForbidden)
+
+	// API key kasir bukan kredensial manager
+	rec = s.do(http.MethodPost, path, models.VoidRequest{Reason: "salah input"}, map[string]string{
+		"X-Api-Key":     testAPIKey,
+		"X-Manager-Key": testAPIKey,
+	})
+	expectStatus(t, rec, http.StatusForbidden)
+
+	rec = s.do(http.MethodPost, path, models.VoidRequest{Reason: "salah input"}, map[string]string{
+		"X-Api-Key":     testAPIKey,
+		"X-Manager-Key": testManagerKey,
+	})
+	expectStatus(t, rec, http.StatusOK)
+	if voided := decodeJSON[models.Transaction](t, rec); voided.VoidedBy != models.VoidedByManager {
+		t.Errorf("voided_by = %q, want manager", voided.VoidedBy)
+	}
+	if stock := s.productStock(indomie.ID); stock != 10 {
+		t.Errorf("stock = %d, want 10", stock)
+	}
+}
+
+func TestVoidRejectedAfterReturn(t *testing.T) {
+	s := newVoidTestServer(t, time.Hour)
+
+	category := s.createCategory("Makanan")
+	indomie := s.createProduct("Indomie Goreng", 3500, 10, category.ID)
+	trx := s.checkout(models.CheckoutItem{ProductID: indomie.ID, Quantity: 2})
+	detail := s.transactionDetails(trx.ID)
+
+	rec := s.doAuth(http.MethodPost, "/api/transactions/"+strconv.Itoa(trx.ID)+"/returns", models.ReturnRequest{
+		Items: []models.ReturnRequestItem{{TransactionDetailID: detail[0].ID, Quantity: 1}},
+	})
+	expectStatus(t, rec, http.StatusCreated)
+
+	rec = s.doAuth(http.MethodPost, "/api/transactions/"+strconv.Itoa(trx.ID)+"/void", models.VoidRequest{Reason: "batal"})
+	expectStatus(t, rec, http.StatusConflict)
+
+	rec = s.doAuth(http.MethodPost, "/api/transactions/999/void", models.VoidRequest{Reason: "batal"})
+	expectStatus(t, rec, http.StatusNotFound)
+}
+
+func TestVoidNegativeWindowIsNotUnlimited(t *testing.T) {
+	s := newVoidTestServer(t, -time.Hour)
+
+	category := s.createCategory("Makanan")
+	indomie := s.createProduct("Indomie Goreng", 3500, 10, category.ID)
+	trx := s.checkout(models.CheckoutItem{ProductID: indomie.ID, Quantity: 2})
+
+	rec := s.doAuth(http.MethodPost, "/api/transactions/"+strconv.Itoa(trx.ID)+"/void", models.VoidRequest{Reason: "salah input"})
+	expectStatus(t, rec, http.StatusForbidden)
+
+	// transaksi yang tidak ada tetap 404, bukan 403
+	rec = s.doAuth(http.MethodPost, "/api/transactions/999/void", models.VoidRequest{Reason: "salah input"})
+	expectStatus(t, rec, http.StatusNotFound)
+}